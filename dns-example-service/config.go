@@ -4,11 +4,14 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
 	"os"
+	"time"
 )
 
 type Config struct {
 	ListenAddress string `json:"listen_address" split_words:"true" default:"0.0.0.0:8082"`
-	Token         string `json:"token" split_words:"true" required:"true"`
+	// Token is the key requests are signed with, DOMAIN_DNS_HOOK_KEY in supa-manager
+	Token   string        `json:"token" split_words:"true" required:"true"`
+	MaxSkew time.Duration `json:"max_skew" split_words:"true" default:"5m"`
 }
 
 func LoadConfig(filename string) (*Config, error) {
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"encoding/json"
 	"github.com/gin-gonic/gin"
+	"io"
+	"log"
 	"net/http"
 )
 
@@ -16,6 +19,10 @@ type RequestBody struct {
 	Type       DNSUpdateType `json:"type"`
 	Hostname   string        `json:"hostname"`
 	ProjectRef string        `json:"project_ref"`
+	// RecordType is empty for a project's own record, TXT for ACME DNS-01 challenges and CNAME for
+	// custom hostnames
+	RecordType string `json:"record_type"`
+	Value      string `json:"value"`
 }
 
 func main() {
@@ -28,14 +35,19 @@ func main() {
 	r := gin.Default()
 
 	r.POST("/", func(c *gin.Context) {
-		tokenHeader := c.GetHeader("x-api-key")
-		if tokenHeader != config.Token {
+		payload, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, 1<<20))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+		if err := verifySignature(config.Token, c.GetHeader(timestampHeader), c.GetHeader(signatureHeader), payload, config.MaxSkew); err != nil {
+			log.Printf("Rejected DNS update: %v", err)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 			return
 		}
 
 		var body RequestBody
-		if err := c.BindJSON(&body); err != nil {
+		if err := json.Unmarshal(payload, &body); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 			return
 		}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Headers of the signature supa-manager sends: an HMAC-SHA256 of "<timestamp>.<body>" keyed with
+// the shared token, and the Unix time it was made at
+const (
+	timestampHeader = "X-Supamanager-Timestamp"
+	signatureHeader = "X-Supamanager-Signature"
+)
+
+// verifySignature checks that body was signed with key no more than maxSkew away from now, older
+// requests could be replays
+func verifySignature(key string, timestamp string, signature string, body []byte, maxSkew time.Duration) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("invalid timestamp")
+	}
+	skew := time.Since(time.Unix(seconds, 0))
+	if skew > maxSkew || skew < -maxSkew {
+		return errors.New("timestamp out of range")
+	}
+
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	expected := "v1=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
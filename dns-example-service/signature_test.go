@@ -0,0 +1,69 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func sign(key string, timestamp string, body string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(timestamp + "." + body))
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignature(t *testing.T) {
+	const key = "mysecretkey"
+	const body = `{"type":"CREATE","hostname":"abc.supamanager.io"}`
+	const maxSkew = 5 * time.Minute
+	at := func(offset time.Duration) string {
+		return strconv.FormatInt(time.Now().Add(offset).Unix(), 10)
+	}
+	now, behind, ahead, old, future := at(0), at(-4*time.Minute), at(4*time.Minute), at(-6*time.Minute), at(6*time.Minute)
+
+	tests := []struct {
+		name      string
+		timestamp string
+		signature string
+		body      string
+		wantErr   bool
+	}{
+		{"valid", now, sign(key, now, body), body, false},
+		{"slightly behind", behind, sign(key, behind, body), body, false},
+		{"slightly ahead", ahead, sign(key, ahead, body), body, false},
+		{"replayed later", old, sign(key, old, body), body, true},
+		{"too far ahead", future, sign(key, future, body), body, true},
+		{"wrong key", now, sign("otherkey", now, body), body, true},
+		{"body changed", now, sign(key, now, body), `{"type":"DELETE","hostname":"abc.supamanager.io"}`, true},
+		{"timestamp changed", behind, sign(key, now, body), body, true},
+		{"missing signature", now, "", body, true},
+		{"missing version", now, sign(key, now, body)[len("v1="):], body, true},
+		{"missing timestamp", "", sign(key, "", body), body, true},
+		{"invalid timestamp", "soon", sign(key, "soon", body), body, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifySignature(key, tt.timestamp, tt.signature, []byte(tt.body), maxSkew)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("verifySignature() error = %v, want error: %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// The vector supa-manager's dnshook tests sign, so both ends agree on the format
+func TestVerifySignatureVector(t *testing.T) {
+	const signature = "v1=45a61464fa45925486ceb6957fc84302abd2433ff203d3f97e4df0ad4350f21e"
+	body := []byte(`{"type":"CREATE","hostname":"abc.supamanager.io"}`)
+	sent := time.Unix(1700000000, 0)
+
+	if err := verifySignature("mysecretkey", "1700000000", signature, body, time.Since(sent)+time.Hour); err != nil {
+		t.Fatalf("vector rejected: %v", err)
+	}
+	if err := verifySignature("mysecretkey", "1700000000", signature, body, 5*time.Minute); err == nil {
+		t.Fatal("vector accepted long after it was signed")
+	}
+}
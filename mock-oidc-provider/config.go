@@ -0,0 +1,48 @@
+package main
+
+import (
+	"github.com/joho/godotenv"
+	"github.com/kelseyhightower/envconfig"
+	"os"
+)
+
+type Config struct {
+	ListenAddress string   `json:"listen_address" split_words:"true" default:"0.0.0.0:8083"`
+	Issuer        string   `json:"issuer" default:"http://localhost:8083"`
+	ClientId      string   `json:"client_id" split_words:"true" required:"true"`
+	ClientSecret  string   `json:"client_secret" split_words:"true"`
+	RedirectUris  []string `json:"redirect_uris" split_words:"true"`
+	// AutoApprove skips the login form and signs in the default user straight away
+	AutoApprove   bool     `json:"auto_approve" split_words:"true" default:"false"`
+	UserEmail     string   `json:"user_email" split_words:"true" default:"user@example.com"`
+	UserName      string   `json:"user_name" split_words:"true" default:"Example User"`
+	UserGroups    []string `json:"user_groups" split_words:"true"`
+	EmailVerified bool     `json:"email_verified" split_words:"true" default:"true"`
+}
+
+func LoadConfig(filename string) (*Config, error) {
+	if _, err := os.Stat("./.env"); !os.IsNotExist(err) {
+		if err := loadEnvironment(filename); err != nil {
+			return nil, err
+		}
+	}
+	config := new(Config)
+	if err := envconfig.Process("", config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func loadEnvironment(filename string) error {
+	var err error
+	if filename != "" {
+		err = godotenv.Load(filename)
+	} else {
+		err = godotenv.Load()
+		// handle if .env file does not exist, this is OK
+		if os.IsNotExist(err) {
+			return nil
+		}
+	}
+	return err
+}
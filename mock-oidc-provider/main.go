@@ -0,0 +1,357 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"html/template"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	keyID    = "mock"
+	codeTtl  = time.Minute
+	tokenTtl = time.Hour
+)
+
+// User is the identity signed in at the authorize endpoint
+type User struct {
+	Email         string
+	Name          string
+	Groups        []string
+	EmailVerified bool
+}
+
+// authorization is what an issued code or access token stands for
+type authorization struct {
+	User          User
+	ClientId      string
+	RedirectUri   string
+	Nonce         string
+	CodeChallenge string
+	ExpiresAt     time.Time
+}
+
+type Provider struct {
+	config *Config
+	key    *rsa.PrivateKey
+
+	mu           sync.Mutex
+	codes        map[string]authorization
+	accessTokens map[string]authorization
+}
+
+var loginForm = template.Must(template.New("login").Parse(`<!doctype html>
+<html>
+<head><title>Mock OIDC Provider</title></head>
+<body>
+<h1>Mock OIDC Provider</h1>
+<p>Sign in to <b>{{.ClientId}}</b> as any user.</p>
+<form method="post" action="authorize">
+{{range $name, $value := .Params}}<input type="hidden" name="{{$name}}" value="{{$value}}">
+{{end}}<p><label>Email <input name="email" value="{{.User.Email}}"></label></p>
+<p><label>Name <input name="name" value="{{.User.Name}}"></label></p>
+<p><label>Groups <input name="groups" value="{{.Groups}}"></label> (comma separated)</p>
+<p><label><input type="checkbox" name="email_verified" value="true"{{if .User.EmailVerified}} checked{{end}}> Email verified</label></p>
+<p><button type="submit">Sign in</button> <button type="submit" name="deny" value="true">Deny</button></p>
+</form>
+</body>
+</html>
+`))
+
+func main() {
+	config, err := LoadConfig(".env")
+	if err != nil {
+		slog.Error("Failed to load configuration, ensure the required environment variables are set", "error", err)
+		os.Exit(1)
+	}
+
+	// The key only lives as long as the process, restarting invalidates every issued token
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		slog.Error("Failed to generate signing key", "error", err)
+		os.Exit(1)
+	}
+
+	if err := NewProvider(config, key).Router().Run(config.ListenAddress); err != nil {
+		slog.Error("Failed to serve", "error", err)
+		os.Exit(1)
+	}
+}
+
+func NewProvider(config *Config, key *rsa.PrivateKey) *Provider {
+	return &Provider{
+		config:       config,
+		key:          key,
+		codes:        map[string]authorization{},
+		accessTokens: map[string]authorization{},
+	}
+}
+
+func (p *Provider) Router() *gin.Engine {
+	r := gin.Default()
+	r.GET("/.well-known/openid-configuration", p.discovery)
+	r.GET("/jwks", p.jwks)
+	r.GET("/authorize", p.authorize)
+	r.POST("/authorize", p.authorize)
+	r.POST("/token", p.token)
+	r.GET("/userinfo", p.userinfo)
+	return r
+}
+
+func (p *Provider) endpoint(path string) string {
+	return strings.TrimRight(p.config.Issuer, "/") + path
+}
+
+func (p *Provider) discovery(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"issuer":                                p.config.Issuer,
+		"authorization_endpoint":                p.endpoint("/authorize"),
+		"token_endpoint":                        p.endpoint("/token"),
+		"userinfo_endpoint":                     p.endpoint("/userinfo"),
+		"jwks_uri":                              p.endpoint("/jwks"),
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"scopes_supported":                      []string{"openid", "email", "profile", "groups"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+	})
+}
+
+func (p *Provider) jwks(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"keys": []gin.H{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": keyID,
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}},
+	})
+}
+
+// authorize shows the login form, or issues a code when the form is submitted or AUTO_APPROVE is set
+func (p *Provider) authorize(c *gin.Context) {
+	clientId := c.Request.FormValue("client_id")
+	redirectUri := c.Request.FormValue("redirect_uri")
+	if clientId != p.config.ClientId {
+		c.String(http.StatusBadRequest, "unknown client_id")
+		return
+	}
+	if len(p.config.RedirectUris) > 0 && !slices.Contains(p.config.RedirectUris, redirectUri) {
+		c.String(http.StatusBadRequest, "redirect_uri is not registered")
+		return
+	}
+	target, err := url.Parse(redirectUri)
+	if err != nil || !target.IsAbs() {
+		c.String(http.StatusBadRequest, "invalid redirect_uri")
+		return
+	}
+
+	state := c.Request.FormValue("state")
+	redirectError := func(code string, description string) {
+		query := target.Query()
+		query.Set("error", code)
+		query.Set("error_description", description)
+		query.Set("state", state)
+		target.RawQuery = query.Encode()
+		c.Redirect(http.StatusFound, target.String())
+	}
+
+	if c.Request.FormValue("response_type") != "code" {
+		redirectError("unsupported_response_type", "only the code flow is supported")
+		return
+	}
+	if c.Request.FormValue("code_challenge") == "" || c.Request.FormValue("code_challenge_method") != "S256" {
+		redirectError("invalid_request", "PKCE with S256 is required")
+		return
+	}
+
+	user := User{
+		Email:         p.config.UserEmail,
+		Name:          p.config.UserName,
+		Groups:        p.config.UserGroups,
+		EmailVerified: p.config.EmailVerified,
+	}
+	if c.Request.Method == http.MethodGet && !p.config.AutoApprove {
+		params := map[string]string{}
+		for _, name := range []string{"client_id", "redirect_uri", "response_type", "scope", "state", "nonce", "code_challenge", "code_challenge_method"} {
+			params[name] = c.Request.FormValue(name)
+		}
+		c.Status(http.StatusOK)
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		loginForm.Execute(c.Writer, gin.H{
+			"ClientId": clientId,
+			"Params":   params,
+			"User":     user,
+			"Groups":   strings.Join(user.Groups, ","),
+		})
+		return
+	}
+	if c.Request.Method == http.MethodPost {
+		if c.PostForm("deny") != "" {
+			redirectError("access_denied", "the user denied the request")
+			return
+		}
+		user = User{
+			Email:         strings.TrimSpace(c.PostForm("email")),
+			Name:          strings.TrimSpace(c.PostForm("name")),
+			Groups:        splitList(c.PostForm("groups")),
+			EmailVerified: c.PostForm("email_verified") == "true",
+		}
+	}
+
+	code := randomToken()
+	p.mu.Lock()
+	p.codes[code] = authorization{
+		User:          user,
+		ClientId:      clientId,
+		RedirectUri:   redirectUri,
+		Nonce:         c.Request.FormValue("nonce"),
+		CodeChallenge: c.Request.FormValue("code_challenge"),
+		ExpiresAt:     time.Now().Add(codeTtl),
+	}
+	p.mu.Unlock()
+
+	query := target.Query()
+	query.Set("code", code)
+	query.Set("state", state)
+	target.RawQuery = query.Encode()
+	c.Redirect(http.StatusFound, target.String())
+}
+
+// token exchanges a code for an ID token after checking the client, redirect URI and PKCE verifier
+func (p *Provider) token(c *gin.Context) {
+	tokenError := func(status int, code string, description string) {
+		c.JSON(status, gin.H{"error": code, "error_description": description})
+	}
+
+	clientId, clientSecret, ok := c.Request.BasicAuth()
+	if ok {
+		clientId, _ = url.QueryUnescape(clientId)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientId = c.PostForm("client_id")
+		clientSecret = c.PostForm("client_secret")
+	}
+	if clientId != p.config.ClientId || subtle.ConstantTimeCompare([]byte(clientSecret), []byte(p.config.ClientSecret)) != 1 {
+		tokenError(http.StatusUnauthorized, "invalid_client", "client authentication failed")
+		return
+	}
+	if c.PostForm("grant_type") != "authorization_code" {
+		tokenError(http.StatusBadRequest, "unsupported_grant_type", "only authorization_code is supported")
+		return
+	}
+
+	// Codes are single use, even a failed exchange spends it
+	code := c.PostForm("code")
+	p.mu.Lock()
+	auth, found := p.codes[code]
+	delete(p.codes, code)
+	p.mu.Unlock()
+	if !found || time.Now().After(auth.ExpiresAt) || auth.ClientId != clientId {
+		tokenError(http.StatusBadRequest, "invalid_grant", "the code is invalid or has expired")
+		return
+	}
+	if c.PostForm("redirect_uri") != auth.RedirectUri {
+		tokenError(http.StatusBadRequest, "invalid_grant", "redirect_uri does not match the authorization request")
+		return
+	}
+	sum := sha256.Sum256([]byte(c.PostForm("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != auth.CodeChallenge {
+		tokenError(http.StatusBadRequest, "invalid_grant", "code_verifier does not match the code_challenge")
+		return
+	}
+
+	now := time.Now()
+	claims := p.userClaims(auth.User)
+	claims["iss"] = p.config.Issuer
+	claims["aud"] = clientId
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(tokenTtl).Unix()
+	if auth.Nonce != "" {
+		claims["nonce"] = auth.Nonce
+	}
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	idToken.Header["kid"] = keyID
+	signed, err := idToken.SignedString(p.key)
+	if err != nil {
+		tokenError(http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+
+	accessToken := randomToken()
+	auth.ExpiresAt = now.Add(tokenTtl)
+	p.mu.Lock()
+	p.accessTokens[accessToken] = auth
+	p.mu.Unlock()
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   int(tokenTtl.Seconds()),
+		"id_token":     signed,
+	})
+}
+
+func (p *Provider) userinfo(c *gin.Context) {
+	accessToken, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	p.mu.Lock()
+	auth, ok := p.accessTokens[accessToken]
+	p.mu.Unlock()
+	if !found || !ok || time.Now().After(auth.ExpiresAt) {
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_token"})
+		return
+	}
+	c.JSON(http.StatusOK, p.userClaims(auth.User))
+}
+
+// userClaims derives a stable subject from the email so repeated logins map to the same identity
+func (p *Provider) userClaims(user User) jwt.MapClaims {
+	sum := sha256.Sum256([]byte(strings.ToLower(user.Email)))
+	groups := user.Groups
+	if groups == nil {
+		groups = []string{}
+	}
+	return jwt.MapClaims{
+		"sub":            base64.RawURLEncoding.EncodeToString(sum[:16]),
+		"email":          user.Email,
+		"email_verified": user.EmailVerified,
+		"name":           user.Name,
+		"groups":         groups,
+	}
+}
+
+func splitList(value string) []string {
+	result := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func randomToken() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testRedirectUri = "http://localhost:8080/auth/sso/callback"
+
+var testKey = sync.OnceValue(func() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return key
+})
+
+func newTestProvider(t *testing.T) (*Provider, *httptest.Server) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	config := &Config{
+		ClientId:      "supa-manager",
+		ClientSecret:  "secret",
+		RedirectUris:  []string{testRedirectUri},
+		AutoApprove:   true,
+		UserEmail:     "user@example.com",
+		UserName:      "Example User",
+		UserGroups:    []string{"admins"},
+		EmailVerified: true,
+	}
+	p := NewProvider(config, testKey())
+	server := httptest.NewServer(p.Router())
+	t.Cleanup(server.Close)
+	config.Issuer = server.URL
+	return p, server
+}
+
+var noRedirects = &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+	return http.ErrUseLastResponse
+}}
+
+func challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// authorize runs the authorization request and returns the query of the redirect back
+func authorize(t *testing.T, server *httptest.Server, params url.Values) url.Values {
+	t.Helper()
+	resp, err := noRedirects.Get(server.URL + "/authorize?" + params.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize returned status %d", resp.StatusCode)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return location.Query()
+}
+
+func authorizeParams(verifier string) url.Values {
+	return url.Values{
+		"client_id":             {"supa-manager"},
+		"redirect_uri":          {testRedirectUri},
+		"response_type":         {"code"},
+		"scope":                 {"openid email"},
+		"state":                 {"state"},
+		"nonce":                 {"nonce"},
+		"code_challenge":        {challenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+}
+
+func exchange(server *httptest.Server, form url.Values, clientSecret string) (*http.Response, map[string]interface{}, error) {
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("supa-manager", clientSecret)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	body := map[string]interface{}{}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	return resp, body, err
+}
+
+func TestCodeFlow(t *testing.T) {
+	p, server := newTestProvider(t)
+
+	query := authorize(t, server, authorizeParams("verifier"))
+	if query.Get("state") != "state" || query.Get("code") == "" {
+		t.Fatalf("redirect query %v", query)
+	}
+
+	resp, body, err := exchange(server, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {query.Get("code")},
+		"code_verifier": {"verifier"},
+		"redirect_uri":  {testRedirectUri},
+	}, "secret")
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("token exchange: status %d, %v, %v", resp.StatusCode, body, err)
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(body["id_token"].(string), claims, func(*jwt.Token) (interface{}, error) {
+		return &p.key.PublicKey, nil
+	}, jwt.WithIssuer(server.URL), jwt.WithAudience("supa-manager"), jwt.WithExpirationRequired())
+	if err != nil {
+		t.Fatalf("invalid id token: %v", err)
+	}
+	if claims["nonce"] != "nonce" || claims["email"] != "user@example.com" || claims["sub"] == "" {
+		t.Errorf("id token claims %v", claims)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/userinfo", nil)
+	req.Header.Set("Authorization", "Bearer "+body["access_token"].(string))
+	info, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer info.Body.Close()
+	var userinfo map[string]interface{}
+	if err := json.NewDecoder(info.Body).Decode(&userinfo); err != nil || userinfo["sub"] != claims["sub"] {
+		t.Errorf("userinfo %v, %v, want the id token's subject", userinfo, err)
+	}
+}
+
+func TestAuthorizeRejects(t *testing.T) {
+	tests := []struct {
+		name      string
+		change    func(url.Values)
+		status    int
+		wantError string
+	}{
+		{"unknown client", func(v url.Values) { v.Set("client_id", "other") }, http.StatusBadRequest, ""},
+		{"unregistered redirect", func(v url.Values) { v.Set("redirect_uri", "http://evil.example.com/callback") }, http.StatusBadRequest, ""},
+		{"implicit flow", func(v url.Values) { v.Set("response_type", "token") }, http.StatusFound, "unsupported_response_type"},
+		{"without PKCE", func(v url.Values) { v.Del("code_challenge") }, http.StatusFound, "invalid_request"},
+		{"plain PKCE", func(v url.Values) { v.Set("code_challenge_method", "plain") }, http.StatusFound, "invalid_request"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, server := newTestProvider(t)
+			params := authorizeParams("verifier")
+			tt.change(params)
+			resp, err := noRedirects.Get(server.URL + "/authorize?" + params.Encode())
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.wantError == "" {
+				return
+			}
+			location, _ := url.Parse(resp.Header.Get("Location"))
+			if got := location.Query().Get("error"); got != tt.wantError || location.Query().Get("code") != "" {
+				t.Errorf("redirect %s, want error %s without a code", location, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestTokenRejects(t *testing.T) {
+	tests := []struct {
+		name         string
+		change       func(url.Values)
+		clientSecret string
+		status       int
+		wantError    string
+	}{
+		{"wrong client secret", func(url.Values) {}, "wrong", http.StatusUnauthorized, "invalid_client"},
+		{"wrong verifier", func(v url.Values) { v.Set("code_verifier", "other") }, "secret", http.StatusBadRequest, "invalid_grant"},
+		{"missing verifier", func(v url.Values) { v.Del("code_verifier") }, "secret", http.StatusBadRequest, "invalid_grant"},
+		{"other redirect", func(v url.Values) { v.Set("redirect_uri", "http://localhost:8080/other") }, "secret", http.StatusBadRequest, "invalid_grant"},
+		{"unknown code", func(v url.Values) { v.Set("code", "made-up") }, "secret", http.StatusBadRequest, "invalid_grant"},
+		{"other grant", func(v url.Values) { v.Set("grant_type", "client_credentials") }, "secret", http.StatusBadRequest, "unsupported_grant_type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, server := newTestProvider(t)
+			form := url.Values{
+				"grant_type":    {"authorization_code"},
+				"code":          {authorize(t, server, authorizeParams("verifier")).Get("code")},
+				"code_verifier": {"verifier"},
+				"redirect_uri":  {testRedirectUri},
+			}
+			tt.change(form)
+			resp, body, err := exchange(server, form, tt.clientSecret)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status || body["error"] != tt.wantError {
+				t.Errorf("status %d, body %v, want %d %s", resp.StatusCode, body, tt.status, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestCodesAreSingleUse(t *testing.T) {
+	_, server := newTestProvider(t)
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {authorize(t, server, authorizeParams("verifier")).Get("code")},
+		"code_verifier": {"wrong"},
+		"redirect_uri":  {testRedirectUri},
+	}
+
+	// a failed exchange spends the code too
+	if resp, _, _ := exchange(server, form, "secret"); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("exchange with the wrong verifier: status %d", resp.StatusCode)
+	}
+	form.Set("code_verifier", "verifier")
+	if resp, body, _ := exchange(server, form, "secret"); resp.StatusCode != http.StatusBadRequest || body["error"] != "invalid_grant" {
+		t.Fatalf("second exchange of a code: status %d, %v", resp.StatusCode, body)
+	}
+}
+
+func TestUserinfoRequiresAccessToken(t *testing.T) {
+	_, server := newTestProvider(t)
+
+	for _, header := range []string{"", "Bearer made-up", "Basic dXNlcjpwYXNz"} {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/userinfo", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: status %d, want 401", header, resp.StatusCode)
+		}
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"admins", "admins"},
+		{" admins , developers,,", "admins|developers"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(splitList(tt.value), "|"); got != tt.want {
+			t.Errorf("splitList(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/url"
+	"strings"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/mailer"
+	"supamanager.io/supa-manager/utils"
+	"time"
+)
+
+// Purposes of the single-use links sent by email, they match GoTrue's verify types
+const (
+	tokenPurposeSignup      = "signup"
+	tokenPurposeRecovery    = "recovery"
+	tokenPurposeEmailChange = "email_change"
+)
+
+var accountTokenTtl = map[string]time.Duration{
+	tokenPurposeSignup:      24 * time.Hour,
+	tokenPurposeRecovery:    time.Hour,
+	tokenPurposeEmailChange: 24 * time.Hour,
+}
+
+// accountTokenMessages builds the email carrying a link, per purpose
+var accountTokenMessages = map[string]func(to string, link string) (mailer.Message, error){
+	tokenPurposeSignup:      mailer.ConfirmationMessage,
+	tokenPurposeRecovery:    mailer.RecoveryMessage,
+	tokenPurposeEmailChange: mailer.EmailChangeMessage,
+}
+
+// accountTokenAmr is the authentication method recorded on sessions created from a link
+var accountTokenAmr = map[string]string{
+	tokenPurposeSignup:      "otp",
+	tokenPurposeRecovery:    "recovery",
+	tokenPurposeEmailChange: "email_change",
+}
+
+// accountTokenInterval is the minimum time between two emails of the same kind to an account
+const accountTokenInterval = time.Minute
+
+const accountTokenSize = 32
+
+var (
+	errInvalidAccountToken   = errors.New("email link is invalid or has expired")
+	errAccountTokenThrottled = errors.New("an email was sent recently, try again later")
+	errEmailInUse            = errors.New("email address already in use")
+)
+
+// sendAccountToken mails a single-use link to email, replacing unused links of the same purpose
+func (a *Api) sendAccountToken(ctx context.Context, account database.Account, purpose string, email string, redirectTo string) error {
+	return a.sendAccountTokenMessage(ctx, account, purpose, email, redirectTo, accountTokenMessages[purpose])
+}
+
+// sendAccountTokenMessage is sendAccountToken with another message carrying the link
+func (a *Api) sendAccountTokenMessage(ctx context.Context, account database.Account, purpose string, email string, redirectTo string, message func(to string, link string) (mailer.Message, error)) error {
+	latest, err := a.queries.GetLatestAccountToken(ctx, database.GetLatestAccountTokenParams{
+		AccountID: account.ID,
+		Purpose:   purpose,
+	})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	if err == nil && time.Since(latest.CreatedAt.Time) < accountTokenInterval {
+		return errAccountTokenThrottled
+	}
+
+	token, err := utils.RandomToken(accountTokenSize)
+	if err != nil {
+		return err
+	}
+	if err := a.queries.DeleteUnusedAccountTokens(ctx, database.DeleteUnusedAccountTokensParams{
+		AccountID: account.ID,
+		Purpose:   purpose,
+	}); err != nil {
+		return err
+	}
+	_, err = a.queries.CreateAccountToken(ctx, database.CreateAccountTokenParams{
+		AccountID: account.ID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(token),
+		Email:     email,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(accountTokenTtl[purpose]), Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("token", token)
+	query.Set("type", purpose)
+	query.Set("redirect_to", a.redirectURL(redirectTo))
+	link := strings.TrimRight(a.config.Auth.ExternalUrl, "/") + "/auth/verify?" + query.Encode()
+
+	msg, err := message(email, link)
+	if err != nil {
+		return err
+	}
+	if err := a.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("failed to send %s email: %w", purpose, err)
+	}
+	return nil
+}
+
+// useAccountToken consumes a link and signs the account in, the link proves control of the address
+func (a *Api) useAccountToken(ctx context.Context, c *gin.Context, purpose string, token string) (database.Account, database.Session, string, error) {
+	if _, ok := accountTokenTtl[purpose]; !ok || token == "" {
+		return database.Account{}, database.Session{}, "", errInvalidAccountToken
+	}
+
+	tx, err := a.pgPool.Begin(ctx)
+	if err != nil {
+		return database.Account{}, database.Session{}, "", err
+	}
+	defer tx.Rollback(ctx)
+	queries := a.queries.WithTx(tx)
+
+	used, err := queries.UseAccountToken(ctx, database.UseAccountTokenParams{
+		TokenHash: utils.HashToken(token),
+		Purpose:   purpose,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return database.Account{}, database.Session{}, "", errInvalidAccountToken
+	}
+	if err != nil {
+		return database.Account{}, database.Session{}, "", err
+	}
+
+	if purpose == tokenPurposeEmailChange {
+		_, err := queries.GetAccountByEmail(ctx, used.Email)
+		if err == nil {
+			return database.Account{}, database.Session{}, "", errEmailInUse
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return database.Account{}, database.Session{}, "", err
+		}
+		err = queries.SetAccountEmail(ctx, database.SetAccountEmailParams{ID: used.AccountID, Email: used.Email})
+		if err != nil {
+			return database.Account{}, database.Session{}, "", err
+		}
+	} else if err := queries.ConfirmAccountEmail(ctx, used.AccountID); err != nil {
+		return database.Account{}, database.Session{}, "", err
+	}
+
+	account, err := queries.GetAccountByID(ctx, used.AccountID)
+	if err != nil {
+		return database.Account{}, database.Session{}, "", err
+	}
+	session, refreshToken, err := a.createSession(ctx, queries, c, account.ID, utils.NewUUID(), pgtype.Text{},
+		SessionGrant{AAL: aal1, AMR: []string{accountTokenAmr[purpose]}})
+	if err != nil {
+		return database.Account{}, database.Session{}, "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return database.Account{}, database.Session{}, "", err
+	}
+	return account, session, refreshToken, nil
+}
+
+// redirectURL only allows redirects into Studio, anything else falls back to the Studio URL
+func (a *Api) redirectURL(redirectTo string) string {
+	studio := strings.TrimRight(a.config.Domain.StudioUrl, "/")
+	if redirectTo == studio || strings.HasPrefix(redirectTo, studio+"/") || strings.HasPrefix(redirectTo, studio+"?") {
+		return redirectTo
+	}
+	return studio
+}
+
+// validatePassword applies the password policy to new passwords
+func validatePassword(password string) error {
+	if len(password) < 8 {
+		return errors.New("password should be at least 8 characters")
+	}
+	return nil
+}
@@ -2,18 +2,23 @@ package api
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/matthewhartstonge/argon2"
 	"log/slog"
 	"net/http"
+	"supamanager.io/supa-manager/certs"
 	"supamanager.io/supa-manager/conf"
 	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/dnshook"
+	"supamanager.io/supa-manager/edge"
+	"supamanager.io/supa-manager/mailer"
+	"supamanager.io/supa-manager/pgmeta"
 	"supamanager.io/supa-manager/provisioner"
+	"supamanager.io/supa-manager/ratelimit"
+	"supamanager.io/supa-manager/sso"
 	"time"
 )
 
@@ -25,6 +30,28 @@ type Api struct {
 	pgPool      *pgxpool.Pool
 	argon       argon2.Config
 	provisioner provisioner.Provisioner
+
+	backupVerifier *provisioner.BackupVerifier
+	alerter        provisioner.Alerter
+	pgMetaPools    *pgmeta.Pools
+	mailer         mailer.Mailer
+
+	sso              *sso.Provider
+	ssoGroupMappings []ssoGroupMapping
+
+	limiter *ratelimit.Limiter
+
+	// edgeRoutes and edgeHosts cache the project and custom hostname lookups of the edge proxy,
+	// nil when it's disabled
+	edgeRoutes *edge.Cache
+	edgeHosts  *edge.Cache
+	// certs serves the edge proxy's certificates from ACME, nil when it's disabled
+	certs *certs.Manager
+	// dnsHook publishes DNS records through the configured hook, nil when there's none
+	dnsHook *dnshook.Client
+
+	// publicRoutes are the "METHOD /path" routes registered with public, see authenticate
+	publicRoutes map[string]bool
 }
 
 func CreateApi(logger *slog.Logger, config *conf.Config) (*Api, error) {
@@ -65,54 +92,119 @@ func CreateApi(logger *slog.Logger, config *conf.Config) (*Api, error) {
 		logger.Info("Provisioner is disabled")
 	}
 
-	return &Api{
-		logger:      logger,
-		config:      config,
-		queries:     queries,
-		pgPool:      conn,
-		argon:       argon2.DefaultConfig(),
-		provisioner: prov,
-	}, nil
-}
+	var alerter provisioner.Alerter = provisioner.NewLogAlerter(logger)
+	if config.Backups.AlertWebhookUrl != nil && *config.Backups.AlertWebhookUrl != "" {
+		alerter = provisioner.NewWebhookAlerter(*config.Backups.AlertWebhookUrl, alerter)
+	}
 
-func (a *Api) GetAccountIdFromRequest(c *gin.Context) (string, error) {
-	authHeader := c.GetHeader("Authorization")
-	if authHeader == "" {
-		return "", errors.New("missing Authorization header")
+	var verifier *provisioner.BackupVerifier
+	if config.Backups.VerifyEnabled {
+		verifier, err = provisioner.NewBackupVerifier(
+			fmt.Sprintf("%s:%s", config.Postgres.DockerImage, config.Postgres.DefaultVersion),
+			config.Backups.VerifyTimeout,
+		)
+		if err != nil {
+			logger.Warn(fmt.Sprintf("Failed to initialize backup verifier: %v", err))
+			logger.Info("Continuing without backup verification")
+		}
 	}
 
-	tokenString := authHeader[len("Bearer "):]
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(a.config.JwtSecret), nil
-	})
+	mail, err := newMailer(logger, config.Mailer)
 	if err != nil {
-		return "", err
+		logger.Error(fmt.Sprintf("Failed to initialize mailer: %v", err))
+		return nil, err
 	}
 
-	claims, ok := token.Claims.(*jwt.RegisteredClaims)
-	if !ok {
-		return "", errors.New("invalid token claims")
+	ssoProvider, ssoGroupMappings, err := newSsoProvider(config.Sso)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to initialize single sign-on: %v", err))
+		return nil, err
 	}
 
-	return claims.Subject, nil
-}
+	limiter, err := newRateLimiter(config.RateLimit, queries)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to initialize rate limiter: %v", err))
+		return nil, err
+	}
 
-func (a *Api) GetAccountFromRequest(c *gin.Context) (*database.Account, error) {
-	id, err := a.GetAccountIdFromRequest(c)
+	dnsHook, err := newDnsHook(config)
 	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to initialize DNS hook: %v", err))
 		return nil, err
 	}
+	var certManager *certs.Manager
+	if config.Acme.Enabled {
+		if certManager, err = newCertificateManager(logger, config, queries, dnsHook); err != nil {
+			logger.Error(fmt.Sprintf("Failed to initialize certificates: %v", err))
+			return nil, err
+		}
+	}
 
-	if id == "" {
-		return nil, errors.New("missing account ID")
+	api := &Api{
+		logger:         logger,
+		config:         config,
+		queries:        queries,
+		pgPool:         conn,
+		argon:          argon2.DefaultConfig(),
+		backupVerifier: verifier,
+		alerter:        alerter,
+		pgMetaPools: pgmeta.NewPools(pgmeta.PoolSettings{
+			MaxConns:         config.PgMeta.MaxConns,
+			StatementTimeout: config.PgMeta.QueryTimeout,
+			IdleTimeout:      config.PgMeta.PoolIdleTimeout,
+		}),
+		mailer:           mail,
+		sso:              ssoProvider,
+		ssoGroupMappings: ssoGroupMappings,
+		limiter:          limiter,
+		certs:            certManager,
+		dnsHook:          dnsHook,
+		publicRoutes:     map[string]bool{},
 	}
 
-	account, err := a.queries.GetAccountByGoTrueID(c.Request.Context(), id)
-	if err != nil {
-		return nil, err
+	if prov != nil {
+		api.provisioner = &auditedProvisioner{Provisioner: prov, api: api}
+	}
+	if config.Edge.Enabled {
+		api.edgeRoutes = edge.NewCache(api.edgeRoute, config.Edge.CacheTtl)
+		api.edgeHosts = edge.NewCache(api.edgeHostRoute, config.Edge.CacheTtl)
 	}
 
-	return &account, nil
+	if verifier != nil {
+		go api.runBackupVerificationJob(context.Background())
+	}
+	go api.runRateLimitPruneJob(context.Background())
+	go api.runJwtSecretRotationRecoveryJob(context.Background())
+	if certManager != nil {
+		go api.runCertificateJob(context.Background())
+	}
+	go api.runCustomHostnameJob(context.Background())
+	if dnsHook != nil {
+		go api.runDnsHookOutboxJob(context.Background())
+	}
+
+	return api, nil
+}
+
+func newMailer(logger *slog.Logger, settings conf.MailerSettings) (mailer.Mailer, error) {
+	switch settings.Driver {
+	case "log":
+		return mailer.NewLogMailer(logger), nil
+	case "file":
+		return mailer.NewFileMailer(settings.FileDir, settings.From)
+	case "smtp":
+		return mailer.NewSMTPMailer(mailer.SMTPSettings{
+			Host:     settings.SmtpHost,
+			Port:     settings.SmtpPort,
+			Username: settings.SmtpUsername,
+			Password: settings.SmtpPassword,
+			Security: settings.SmtpSecurity,
+			From:     settings.From,
+			Timeout:  settings.SmtpTimeout,
+		})
+	default:
+		return nil, fmt.Errorf("unknown mailer driver %q", settings.Driver)
+	}
 }
 
 func (a *Api) ListenAddress() string {
@@ -133,8 +225,21 @@ func (a *Api) telemetry(c *gin.Context) {
 
 const INDEX = ""
 
-func (a *Api) Router() *gin.Engine {
+// newEngine creates the gin engine. Client addresses, which rate limits and the audit log are keyed
+// on, are only taken from X-Forwarded-For when the request comes from one of the trusted proxies
+func (a *Api) newEngine() (*gin.Engine, error) {
 	r := gin.Default()
+	if err := r.SetTrustedProxies(a.config.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+	return r, nil
+}
+
+func (a *Api) Router() (*gin.Engine, error) {
+	r, err := a.newEngine()
+	if err != nil {
+		return nil, err
+	}
 
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
@@ -143,33 +248,50 @@ func (a *Api) Router() *gin.Engine {
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
+	r.Use(a.requestID, a.audit, a.authenticate, a.limitTokenRequests)
 
-	r.GET("/", a.index)
-	r.GET("/status", a.status)
+	a.public(&r.RouterGroup, http.MethodGet, "/", a.index)
+	a.public(&r.RouterGroup, http.MethodGet, "/status", a.status)
 
 	profile := r.Group("/profile")
 	{
 		profile.GET(INDEX, a.getProfile)
 		profile.GET("/permissions", a.getProfilePermissions)
-		profile.POST("/password-check", a.postPasswordCheck)
+		a.public(profile, http.MethodPost, "/password-check", a.postPasswordCheck)
+		profile.GET("/sessions", a.getProfileSessions)
+		profile.POST("/sessions/sign-out-others", a.postProfileSessionsSignOutOthers)
+		profile.DELETE("/sessions/:id", a.deleteProfileSession)
+		profile.GET("/access-tokens", a.getProfileAccessTokens)
+		profile.POST("/access-tokens", a.postProfileAccessTokens)
+		profile.DELETE("/access-tokens/:id", a.deleteProfileAccessToken)
 	}
 
 	organization := r.Group("/organizations")
 	{
 		organization.GET(INDEX, a.getOrganizations)
+		// Invitees aren't members yet, so these can't load the organization
+		organization.GET("/:slug/members/invitations/:token", a.getOrganizationInvitationByToken)
+		organization.POST("/:slug/members/invitations/:token", a.postOrganizationInvitationByToken)
 
-		specificOrganization := organization.Group("/:slug")
+		specificOrganization := organization.Group("/:slug", a.loadOrganization)
 		{
+			specificOrganization.GET("/roles", a.getOrganizationRoles)
 			members := specificOrganization.Group("/members")
 			{
+				members.GET(INDEX, a.authorize(actionMembersRead), a.getOrganizationMembers)
 				members.GET("/reached-free-project-limit", a.getOrganizationMembersReachedFreeProjectLimit)
+				members.PATCH("/:gotrue_id", a.patchOrganizationMember)
+				members.DELETE("/:gotrue_id", a.deleteOrganizationMember)
+				members.GET("/invitations", a.authorize(actionInvitationsRead), a.getOrganizationInvitations)
+				members.POST("/invitations", a.postOrganizationInvitations)
+				members.DELETE("/invitations/:id", a.deleteOrganizationInvitation)
 			}
 		}
 	}
 
 	projects := r.Group("/projects")
 	{
-		specificProject := projects.Group("/:ref")
+		specificProject := projects.Group("/:ref", a.loadProject)
 		{
 			specificProject.GET("/status", a.getProjectStatus)
 			specificProject.GET("/jwt-secret-update-status", a.getProjectJwtSecretUpdateStatus)
@@ -180,7 +302,6 @@ func (a *Api) Router() *gin.Engine {
 			// TODO: Uncomment when implementing provisioning
 			// specificProject.POST("/pause", a.postProjectPause)
 			// specificProject.POST("/resume", a.postProjectResume)
-			// specificProject.DELETE(INDEX, a.deleteProject)
 
 			// Analytics routes
 			analytics := specificProject.Group("/analytics/endpoints")
@@ -194,7 +315,7 @@ func (a *Api) Router() *gin.Engine {
 	// Singular /project routes (some Studio UI calls use singular)
 	project := r.Group("/project")
 	{
-		specificProject := project.Group("/:ref")
+		specificProject := project.Group("/:ref", a.loadProject)
 		{
 			specificProject.GET("/status", a.getProjectStatus)
 			specificProject.GET("/jwt-secret-update-status", a.getProjectJwtSecretUpdateStatus)
@@ -209,7 +330,7 @@ func (a *Api) Router() *gin.Engine {
 	{
 		propsProject := props.Group("/project")
 		{
-			specificProject := propsProject.Group("/:ref")
+			specificProject := propsProject.Group("/:ref", a.loadProject)
 			{
 				specificProject.GET("/jwt-secret-update-status", a.getPropsProjectJwtSecretUpdateStatus)
 			}
@@ -218,12 +339,29 @@ func (a *Api) Router() *gin.Engine {
 
 	gotrue := r.Group("/auth")
 	{
-		gotrue.POST("/token", a.postGotrueToken)
+		a.public(gotrue, http.MethodPost, "/token", a.postGotrueToken)
+		gotrue.POST("/logout", a.postGotrueLogout)
+		a.public(gotrue, http.MethodPost, "/recover", a.limitAuthAttempts, a.postGotrueRecover)
+		a.public(gotrue, http.MethodPost, "/resend", a.limitAuthAttempts, a.postGotrueResend)
+		a.public(gotrue, http.MethodGet, "/verify", a.limitAuthAttempts, a.getGotrueVerify)
+		a.public(gotrue, http.MethodPost, "/verify", a.limitAuthAttempts, a.postGotrueVerify)
+		a.public(gotrue, http.MethodGet, "/settings", a.getGotrueSettings)
+		a.public(gotrue, http.MethodGet, "/sso/authorize", a.getGotrueSsoAuthorize)
+		a.public(gotrue, http.MethodPost, "/sso", a.limitAuthAttempts, a.postGotrueSso)
+		a.public(gotrue, http.MethodGet, "/sso/callback", a.getGotrueSsoCallback)
+		gotrue.GET("/user", a.getGotrueUser)
+		gotrue.PUT("/user", a.putGotrueUser)
+		gotrue.POST("/factors", a.postGotrueFactors)
+		gotrue.POST("/factors/recover", a.limitAuthAttempts, a.postGotrueFactorsRecover)
+		gotrue.POST("/factors/recovery-codes", a.postGotrueFactorsRecoveryCodes)
+		gotrue.POST("/factors/:id/challenge", a.postGotrueFactorChallenge)
+		gotrue.POST("/factors/:id/verify", a.limitAuthAttempts, a.postGotrueFactorVerify)
+		gotrue.DELETE("/factors/:id", a.deleteGotrueFactor)
 	}
 
 	platform := r.Group("/platform")
 	{
-		platform.POST("/signup", a.postPlatformSignup)
+		a.public(platform, http.MethodPost, "/signup", a.limitAuthAttempts, a.postPlatformSignup)
 		platform.GET("/notifications", a.getPlatformNotifications)
 		platform.GET("/notifications/summary", a.getPlatformNotificationsSummary)
 		platform.GET("/stripe/invoices/overdue", a.getPlatformOverdueInvoices)
@@ -232,15 +370,39 @@ func (a *Api) Router() *gin.Engine {
 		// pg-meta routes for database metadata queries
 		platformPgMeta := platform.Group("/pg-meta")
 		{
-			specificProject := platformPgMeta.Group("/:ref")
+			specificProject := platformPgMeta.Group("/:ref", a.loadProject, a.authorize(actionDatabaseRead))
 			{
-				specificProject.POST("/query", a.postPlatformPgMetaQuery)
+				specificProject.POST("/query", a.requireAAL2, a.authorize(actionDatabaseWrite), a.postPlatformPgMetaQuery)
 				specificProject.GET("/tables", a.getPlatformPgMetaTables)
-				specificProject.POST("/tables", a.postPlatformPgMetaTables)
-				specificProject.PATCH("/tables", a.patchPlatformPgMetaTables)
-				specificProject.DELETE("/tables", a.deletePlatformPgMetaTables)
-				specificProject.POST("/columns", a.postPlatformPgMetaColumns)
+				specificProject.POST("/tables", a.authorize(actionDatabaseWrite), a.postPlatformPgMetaTables)
+				specificProject.PATCH("/tables", a.authorize(actionDatabaseWrite), a.patchPlatformPgMetaTables)
+				specificProject.DELETE("/tables", a.authorize(actionDatabaseWrite), a.deletePlatformPgMetaTables)
+				specificProject.GET("/columns", a.getPlatformPgMetaColumns)
+				specificProject.POST("/columns", a.authorize(actionDatabaseWrite), a.postPlatformPgMetaColumns)
+				specificProject.PATCH("/columns", a.authorize(actionDatabaseWrite), a.patchPlatformPgMetaColumns)
+				specificProject.DELETE("/columns", a.authorize(actionDatabaseWrite), a.deletePlatformPgMetaColumns)
 				specificProject.GET("/types", a.getPlatformPgMetaTypes)
+				specificProject.GET("/policies", a.getPlatformPgMetaPolicies)
+				specificProject.POST("/policies", a.authorize(actionDatabaseWrite), a.postPlatformPgMetaPolicies)
+				specificProject.PATCH("/policies", a.authorize(actionDatabaseWrite), a.patchPlatformPgMetaPolicies)
+				specificProject.DELETE("/policies", a.authorize(actionDatabaseWrite), a.deletePlatformPgMetaPolicies)
+				specificProject.GET("/functions", a.getPlatformPgMetaFunctions)
+				specificProject.POST("/functions", a.authorize(actionDatabaseWrite), a.postPlatformPgMetaFunctions)
+				specificProject.PATCH("/functions", a.authorize(actionDatabaseWrite), a.patchPlatformPgMetaFunctions)
+				specificProject.DELETE("/functions", a.authorize(actionDatabaseWrite), a.deletePlatformPgMetaFunctions)
+				specificProject.GET("/triggers", a.getPlatformPgMetaTriggers)
+				specificProject.POST("/triggers", a.authorize(actionDatabaseWrite), a.postPlatformPgMetaTriggers)
+				specificProject.PATCH("/triggers", a.authorize(actionDatabaseWrite), a.patchPlatformPgMetaTriggers)
+				specificProject.DELETE("/triggers", a.authorize(actionDatabaseWrite), a.deletePlatformPgMetaTriggers)
+				specificProject.GET("/roles", a.getPlatformPgMetaRoles)
+				specificProject.POST("/roles", a.authorize(actionDatabaseWrite), a.postPlatformPgMetaRoles)
+				specificProject.PATCH("/roles", a.authorize(actionDatabaseWrite), a.patchPlatformPgMetaRoles)
+				specificProject.DELETE("/roles", a.authorize(actionDatabaseWrite), a.deletePlatformPgMetaRoles)
+				specificProject.GET("/extensions", a.getPlatformPgMetaExtensions)
+				specificProject.POST("/extensions", a.authorize(actionDatabaseWrite), a.postPlatformPgMetaExtensions)
+				specificProject.PATCH("/extensions", a.authorize(actionDatabaseWrite), a.patchPlatformPgMetaExtensions)
+				specificProject.DELETE("/extensions", a.authorize(actionDatabaseWrite), a.deletePlatformPgMetaExtensions)
+				specificProject.GET("/indexes", a.getPlatformPgMetaIndexes)
 				specificProject.GET("/publications", a.getPlatformPgMetaPublications)
 			}
 		}
@@ -249,11 +411,23 @@ func (a *Api) Router() *gin.Engine {
 		{
 			platformProjects.GET(INDEX, a.getPlatformProjects)
 			platformProjects.POST(INDEX, a.postPlatformProjects)
-			specificProject := platformProjects.Group("/:ref")
+			specificProject := platformProjects.Group("/:ref", a.loadProject)
 			{
 				specificProject.GET(INDEX, a.getPlatformProject)
+				specificProject.DELETE(INDEX, a.requireAAL2, a.authorize(actionProjectDelete), a.deleteProject)
+				specificProject.POST("/transfer/preview", a.postProjectTransferPreview)
+				specificProject.POST("/transfer", a.requireAAL2, a.postProjectTransfer)
+				specificProject.PATCH("/config/secrets", a.requireAAL2, a.authorize(actionProjectUpdate), a.patchProjectConfigSecrets)
 				specificProject.GET("/settings", a.getPlatformProjectSettings)
-				specificProject.GET("/billing/addons", a.getPlatformProjectBillingAddons)
+				specificProject.GET("/billing/addons", a.authorize(actionBillingRead), a.getPlatformProjectBillingAddons)
+				specificProject.GET("/content", a.getPlatformProjectContent)
+				specificProject.GET("/content/item/:id", a.getPlatformProjectContentItem)
+				specificProject.POST("/content", a.postPlatformProjectContent)
+				specificProject.PUT("/content", a.putPlatformProjectContent)
+				specificProject.PATCH("/content", a.patchPlatformProjectContent)
+				specificProject.DELETE("/content", a.deletePlatformProjectContent)
+				specificProject.GET("/query-history", a.getPlatformProjectQueryHistory)
+				specificProject.DELETE("/query-history", a.deletePlatformProjectQueryHistory)
 
 				// Analytics routes
 				analytics := specificProject.Group("/analytics/endpoints")
@@ -267,21 +441,25 @@ func (a *Api) Router() *gin.Engine {
 		// Singular /project routes (some Studio UI calls use singular)
 		platformProject := platform.Group("/project")
 		{
-			specificProject := platformProject.Group("/:ref")
+			specificProject := platformProject.Group("/:ref", a.loadProject)
 			{
 				specificProject.GET(INDEX, a.getPlatformProject)
 				specificProject.GET("/settings", a.getPlatformProjectSettings)
-				specificProject.GET("/billing/addons", a.getPlatformProjectBillingAddons)
+				specificProject.GET("/billing/addons", a.authorize(actionBillingRead), a.getPlatformProjectBillingAddons)
 			}
 		}
 
 		platformOrganizations := platform.Group("/organizations")
 		{
 			platformOrganizations.POST(INDEX, a.postPlatformOrganizations)
-			specificOrganization := platformOrganizations.Group("/:slug")
+			specificOrganization := platformOrganizations.Group("/:slug", a.loadOrganization)
 			{
-				specificOrganization.GET("/billing/subscription", a.getPlatformOrganizationSubscription)
+				specificOrganization.PATCH(INDEX, a.authorize(actionOrganizationUpdate), a.patchPlatformOrganization)
+				specificOrganization.DELETE(INDEX, a.requireAAL2, a.authorize(actionOrganizationDelete), a.deletePlatformOrganization)
+				specificOrganization.GET("/billing/subscription", a.authorize(actionBillingRead), a.getPlatformOrganizationSubscription)
 				specificOrganization.GET("/usage", a.getPlatformOrganizationUsage)
+				specificOrganization.GET("/audit", a.authorize(actionAuditRead), a.getPlatformOrganizationAudit)
+				specificOrganization.GET("/audit/export", a.authorize(actionAuditRead), a.getPlatformOrganizationAuditExport)
 			}
 		}
 
@@ -298,20 +476,31 @@ func (a *Api) Router() *gin.Engine {
 
 	configcat := r.Group("/configcat")
 	{
-		configcat.GET("/configuration-files/:key/config_v5.json", a.getConfigCatConfiguration)
+		a.public(configcat, http.MethodGet, "/configuration-files/:key/config_v5.json", a.getConfigCatConfiguration)
 	}
 
 	v1 := r.Group("/v1")
 	{
 		v1Projects := v1.Group("/projects")
 		{
-			specificProject := v1Projects.Group("/:ref")
+			specificProject := v1Projects.Group("/:ref", a.loadProject)
 			{
 				specificProject.GET("/custom-hostname", a.getProjectCustomHostname)
+				specificProject.POST("/custom-hostname/initialize", a.requireAAL2, a.authorize(actionProjectUpdate), a.postProjectCustomHostnameInitialize)
+				specificProject.POST("/custom-hostname/reverify", a.authorize(actionProjectUpdate), a.postProjectCustomHostnameReverify)
+				specificProject.DELETE("/custom-hostname", a.requireAAL2, a.authorize(actionProjectUpdate), a.deleteProjectCustomHostname)
+				specificProject.GET("/api-keys", a.authorize(actionProjectSecretsRead), a.getProjectApiKeys)
+				specificProject.POST("/api-keys", a.requireAAL2, a.authorize(actionProjectUpdate), a.postProjectApiKeys)
+				specificProject.DELETE("/api-keys/:id", a.requireAAL2, a.authorize(actionProjectUpdate), a.deleteProjectApiKey)
 				specificProject.GET("/upgrade/eligibility", a.getProjectUpgradeEligibility)
+				specificProject.GET("/database/backups", a.authorize(actionDatabaseRead), a.getProjectDatabaseBackups)
+				specificProject.POST("/database/backups", a.authorize(actionDatabaseWrite), a.postProjectDatabaseBackups)
+				specificProject.GET("/database/migrations", a.authorize(actionDatabaseRead), a.getProjectDatabaseMigrations)
+				specificProject.POST("/database/migrations", a.requireAAL2, a.authorize(actionDatabaseWrite), a.postProjectDatabaseMigrations)
+				specificProject.POST("/database/migrations/diff", a.authorize(actionDatabaseRead), a.postProjectDatabaseMigrationsDiff)
 			}
 		}
 	}
 
-	return r
+	return r, nil
 }
@@ -0,0 +1,366 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/utils"
+	"time"
+)
+
+// requestIDHeader carries the id of a request, taken from the caller when it sends a sane one so
+// events can be correlated with the proxy in front of supa-manager
+const requestIDHeader = "X-Request-Id"
+
+const contextRequestIDKey = "request_id"
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// auditBodyLimit caps how much of a request body is read for its audit event
+const auditBodyLimit = 64 << 10
+
+const auditRedacted = "[REDACTED]"
+
+// Page sizes of the audit event listing, exports read in batches of auditExportBatch
+const (
+	auditDefaultLimit = 100
+	auditMaxLimit     = 1000
+	auditExportBatch  = 1000
+)
+
+// auditSensitiveKeys never reach the audit log, on top of any key mentioning a password, secret or token
+var auditSensitiveKeys = map[string]bool{
+	"code":             true,
+	"db_pass":          true,
+	"key":              true,
+	"api_key":          true,
+	"anon_key":         true,
+	"service_key":      true,
+	"service_role_key": true,
+	"otp":              true,
+	"hcaptchatoken":    true,
+	"recovery_code":    true,
+}
+
+// auditedMethods are the methods that change something, only they are recorded
+var auditedMethods = map[string]bool{
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+type auditContextKey struct{}
+
+// auditEntry is who and where a request comes from. It travels in the request context so the
+// provisioner operations a request starts are attributed to the same actor and request
+type auditEntry struct {
+	ActorID    pgtype.Int4
+	ActorEmail pgtype.Text
+	TokenID    pgtype.Int8
+	Ip         pgtype.Text
+	UserAgent  pgtype.Text
+	RequestID  pgtype.Text
+
+	// OrganizationID and ProjectRef are set by handlers acting on a resource the route doesn't
+	// name, like the organization a project is created in
+	OrganizationID pgtype.Int4
+	ProjectRef     pgtype.Text
+}
+
+// requestID assigns every request an id and returns it in the response
+func (a *Api) requestID(c *gin.Context) {
+	id := c.GetHeader(requestIDHeader)
+	if !validRequestID.MatchString(id) {
+		id = uuid.New().String()
+	}
+	c.Set(contextRequestIDKey, id)
+	c.Header(requestIDHeader, id)
+	c.Next()
+}
+
+// audit records an event for every mutating request once it has been handled, including the ones
+// rejected by authenticate. The body is read ahead and put back for the handler
+func (a *Api) audit(c *gin.Context) {
+	entry := &auditEntry{
+		Ip:        pgtype.Text{String: c.ClientIP(), Valid: c.ClientIP() != ""},
+		UserAgent: pgtype.Text{String: c.Request.UserAgent(), Valid: c.Request.UserAgent() != ""},
+		RequestID: pgtype.Text{String: c.GetString(contextRequestIDKey), Valid: c.GetString(contextRequestIDKey) != ""},
+	}
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), auditContextKey{}, entry))
+
+	// Unknown routes fall through to the 404 handler and aren't worth an event
+	if !auditedMethods[c.Request.Method] || c.FullPath() == "" {
+		c.Next()
+		return
+	}
+
+	var body []byte
+	if c.Request.Body != nil {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(c.Request.Body, auditBodyLimit+1))
+		if err != nil {
+			c.AbortWithStatusJSON(400, gin.H{"error": "Invalid request body"})
+			return
+		}
+		c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(body), c.Request.Body), c.Request.Body}
+	}
+
+	c.Next()
+
+	if org, ok := c.Get(contextOrganizationKey); ok {
+		entry.OrganizationID = pgtype.Int4{Int32: org.(*database.Organization).ID, Valid: true}
+	}
+	if project, ok := c.Get(contextProjectKey); ok {
+		entry.OrganizationID = pgtype.Int4{Int32: project.(*database.Project).OrganizationID, Valid: true}
+		entry.ProjectRef = pgtype.Text{String: project.(*database.Project).ProjectRef, Valid: true}
+	}
+
+	a.recordAuditEvent(context.WithoutCancel(c.Request.Context()), entry, database.CreateAuditEventParams{
+		Action:  c.Request.Method + " " + c.FullPath(),
+		Target:  auditTarget(c),
+		Status:  pgtype.Int4{Int32: int32(c.Writer.Status()), Valid: true},
+		Payload: auditPayload(c, body),
+	})
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// setAuditActor attributes the events of a request to the account authenticate loaded
+func setAuditActor(c *gin.Context, account *database.Account, claims *AccessTokenClaims) {
+	entry := auditEntryFrom(c.Request.Context())
+	if entry == nil {
+		return
+	}
+	entry.ActorID = pgtype.Int4{Int32: account.ID, Valid: true}
+	entry.ActorEmail = pgtype.Text{String: account.Email, Valid: true}
+	if claims.PersonalAccessToken != nil {
+		entry.TokenID = pgtype.Int8{Int64: claims.PersonalAccessToken.ID, Valid: true}
+	}
+}
+
+// setAuditScope records the organization and project a request acts on when its route doesn't load them
+func setAuditScope(c *gin.Context, organizationID int32, projectRef string) {
+	entry := auditEntryFrom(c.Request.Context())
+	if entry == nil {
+		return
+	}
+	entry.OrganizationID = pgtype.Int4{Int32: organizationID, Valid: true}
+	entry.ProjectRef = pgtype.Text{String: projectRef, Valid: projectRef != ""}
+}
+
+func auditEntryFrom(ctx context.Context) *auditEntry {
+	entry, _ := ctx.Value(auditContextKey{}).(*auditEntry)
+	return entry
+}
+
+// recordAuditEvent completes an event with the entry and stores it. Failing to audit doesn't fail
+// the operation that already happened, it is logged instead
+func (a *Api) recordAuditEvent(ctx context.Context, entry *auditEntry, event database.CreateAuditEventParams) {
+	if entry != nil {
+		event.ActorID = entry.ActorID
+		event.ActorEmail = entry.ActorEmail
+		event.TokenID = entry.TokenID
+		event.Ip = entry.Ip
+		event.UserAgent = entry.UserAgent
+		event.RequestID = entry.RequestID
+		if !event.OrganizationID.Valid {
+			event.OrganizationID = entry.OrganizationID
+		}
+		if !event.ProjectRef.Valid {
+			event.ProjectRef = entry.ProjectRef
+		}
+	}
+	if err := a.queries.CreateAuditEvent(ctx, event); err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to record audit event %s %s: %v", event.Action, event.Target, err))
+	}
+}
+
+// auditTarget is the request path with sensitive parameters, like invitation tokens, redacted
+func auditTarget(c *gin.Context) string {
+	segments := strings.Split(c.FullPath(), "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") && !strings.HasPrefix(segment, "*") {
+			continue
+		}
+		key := segment[1:]
+		value := c.Param(key)
+		if auditSensitive(key) {
+			value = auditRedacted
+		}
+		segments[i] = strings.TrimPrefix(value, "/")
+	}
+	return strings.Join(segments, "/")
+}
+
+// auditPayload keeps the query and the JSON body of a request with sensitive values redacted.
+// Bodies that aren't JSON or are over the limit can't be redacted, only their size is kept
+func auditPayload(c *gin.Context, body []byte) []byte {
+	payload := map[string]interface{}{}
+	if query := c.Request.URL.Query(); len(query) > 0 {
+		values := map[string]interface{}{}
+		for key, value := range query {
+			if auditSensitive(key) {
+				values[key] = auditRedacted
+			} else if len(value) == 1 {
+				values[key] = value[0]
+			} else {
+				values[key] = value
+			}
+		}
+		payload["query"] = values
+	}
+	if len(body) > 0 {
+		var parsed interface{}
+		if len(body) <= auditBodyLimit && json.Unmarshal(body, &parsed) == nil {
+			payload["body"] = redactAuditValue(parsed)
+		} else {
+			payload["body_omitted"] = true
+			payload["content_length"] = c.Request.ContentLength
+		}
+	}
+	if len(payload) == 0 {
+		return nil
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil
+	}
+	return raw
+}
+
+func redactAuditValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, item := range value {
+			if auditSensitive(key) {
+				value[key] = auditRedacted
+			} else {
+				value[key] = redactAuditValue(item)
+			}
+		}
+		return value
+	case []interface{}:
+		for i, item := range value {
+			value[i] = redactAuditValue(item)
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+func auditSensitive(key string) bool {
+	key = strings.ToLower(key)
+	return auditSensitiveKeys[key] || strings.Contains(key, "password") || strings.Contains(key, "secret") || strings.Contains(key, "token")
+}
+
+// AuditEvent is an audit event as the query and export endpoints return it
+type AuditEvent struct {
+	ID             int64           `json:"id"`
+	OccurredAt     string          `json:"occurred_at"`
+	Action         string          `json:"action"`
+	Target         string          `json:"target"`
+	Status         *int32          `json:"status"`
+	Error          *string         `json:"error"`
+	Actor          AuditActor      `json:"actor"`
+	OrganizationID *int32          `json:"organization_id"`
+	ProjectRef     *string         `json:"project_ref"`
+	Ip             *string         `json:"ip"`
+	UserAgent      *string         `json:"user_agent"`
+	RequestID      *string         `json:"request_id"`
+	Payload        json.RawMessage `json:"payload"`
+}
+
+// AuditActor is who caused an event, all fields are null for unauthenticated requests and
+// operations supa-manager started on its own
+type AuditActor struct {
+	ID      *int32  `json:"id"`
+	Email   *string `json:"email"`
+	TokenID *int64  `json:"token_id"`
+}
+
+func auditEventResponse(event database.AuditEvent) AuditEvent {
+	payload := json.RawMessage("null")
+	if len(event.Payload) > 0 {
+		payload = event.Payload
+	}
+	return AuditEvent{
+		ID:         event.ID,
+		OccurredAt: event.OccurredAt.Time.UTC().Format(time.RFC3339Nano),
+		Action:     event.Action,
+		Target:     event.Target,
+		Status:     utils.PgInt4ToPointer(event.Status),
+		Error:      utils.PgTextToPointer(event.Error),
+		Actor: AuditActor{
+			ID:      utils.PgInt4ToPointer(event.ActorID),
+			Email:   utils.PgTextToPointer(event.ActorEmail),
+			TokenID: utils.PgInt8ToPointer(event.TokenID),
+		},
+		OrganizationID: utils.PgInt4ToPointer(event.OrganizationID),
+		ProjectRef:     utils.PgTextToPointer(event.ProjectRef),
+		Ip:             utils.PgTextToPointer(event.Ip),
+		UserAgent:      utils.PgTextToPointer(event.UserAgent),
+		RequestID:      utils.PgTextToPointer(event.RequestID),
+		Payload:        payload,
+	}
+}
+
+// auditEventsFilter reads the filters shared by the query and export endpoints: actor_id, project,
+// action (a prefix like "DELETE " or "provisioner."), iso_timestamp_start, iso_timestamp_end and
+// cursor, the id of the last event of the previous page. It writes the error response itself
+func auditEventsFilter(c *gin.Context, org *database.Organization) (database.GetAuditEventsParams, bool) {
+	params := database.GetAuditEventsParams{OrganizationID: pgtype.Int4{Int32: org.ID, Valid: true}}
+
+	if actor := c.Query("actor_id"); actor != "" {
+		id, err := strconv.ParseInt(actor, 10, 32)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid actor_id"})
+			return params, false
+		}
+		params.ActorID = pgtype.Int4{Int32: int32(id), Valid: true}
+	}
+	if project := c.Query("project"); project != "" {
+		params.ProjectRef = pgtype.Text{String: project, Valid: true}
+	}
+	if action := c.Query("action"); action != "" {
+		params.Action = pgtype.Text{String: action, Valid: true}
+	}
+	for query, param := range map[string]*pgtype.Timestamptz{
+		"iso_timestamp_start": &params.OccurredAfter,
+		"iso_timestamp_end":   &params.OccurredBefore,
+	} {
+		value := c.Query(query)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(400, gin.H{"error": fmt.Sprintf("Invalid %s, expected an ISO 8601 timestamp", query)})
+			return params, false
+		}
+		*param = pgtype.Timestamptz{Time: t, Valid: true}
+	}
+	if cursor := c.Query("cursor"); cursor != "" {
+		id, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid cursor"})
+			return params, false
+		}
+		params.BeforeID = pgtype.Int8{Int64: id, Valid: true}
+	}
+	return params, true
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/jackc/pgx/v5/pgtype"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/provisioner"
+)
+
+// auditedProvisioner records an audit event for every provisioner operation that changes a
+// project. Operations started by a request are attributed to its actor through the context
+type auditedProvisioner struct {
+	provisioner.Provisioner
+	api *Api
+}
+
+func (p *auditedProvisioner) CreateProject(ctx context.Context, config *provisioner.ProjectConfig) (*provisioner.ProjectInfo, error) {
+	info, err := p.Provisioner.CreateProject(ctx, config)
+	p.record(ctx, "create_project", config.ProjectID, map[string]interface{}{
+		"project_name": config.ProjectName,
+		"region":       config.Region,
+	}, err)
+	return info, err
+}
+
+func (p *auditedProvisioner) UpdateProject(ctx context.Context, projectID string, config *provisioner.ProjectConfig) error {
+	err := p.Provisioner.UpdateProject(ctx, projectID, config)
+	p.record(ctx, "update_project", projectID, map[string]interface{}{
+		"cpu_limit":     config.CPULimit,
+		"memory_limit":  config.MemoryLimit,
+		"storage_limit": config.StorageLimit,
+	}, err)
+	return err
+}
+
+func (p *auditedProvisioner) PauseProject(ctx context.Context, projectID string) error {
+	err := p.Provisioner.PauseProject(ctx, projectID)
+	p.record(ctx, "pause_project", projectID, nil, err)
+	return err
+}
+
+func (p *auditedProvisioner) ResumeProject(ctx context.Context, projectID string) error {
+	err := p.Provisioner.ResumeProject(ctx, projectID)
+	p.record(ctx, "resume_project", projectID, nil, err)
+	return err
+}
+
+func (p *auditedProvisioner) DeleteProject(ctx context.Context, projectID string) error {
+	err := p.Provisioner.DeleteProject(ctx, projectID)
+	p.record(ctx, "delete_project", projectID, nil, err)
+	return err
+}
+
+// ExecuteCommand only records the program that ran, its arguments can carry credentials
+func (p *auditedProvisioner) ExecuteCommand(ctx context.Context, projectID string, service string, cmd []string) (string, error) {
+	output, err := p.Provisioner.ExecuteCommand(ctx, projectID, service, cmd)
+	program := ""
+	if len(cmd) > 0 {
+		program = cmd[0]
+	}
+	p.record(ctx, "execute_command", projectID, map[string]interface{}{
+		"service": service,
+		"program": program,
+	}, err)
+	return output, err
+}
+
+// RenderProject doesn't record the configuration, it is mostly secrets
+func (p *auditedProvisioner) RenderProject(ctx context.Context, config *provisioner.ProjectConfig) error {
+	err := p.Provisioner.RenderProject(ctx, config)
+	p.record(ctx, "render_project", config.ProjectID, nil, err)
+	return err
+}
+
+func (p *auditedProvisioner) RecreateServices(ctx context.Context, projectID string, services []string) error {
+	err := p.Provisioner.RecreateServices(ctx, projectID, services)
+	p.record(ctx, "recreate_services", projectID, map[string]interface{}{
+		"services": services,
+	}, err)
+	return err
+}
+
+func (p *auditedProvisioner) ReloadGateway(ctx context.Context, projectID string) error {
+	err := p.Provisioner.ReloadGateway(ctx, projectID)
+	p.record(ctx, "reload_gateway", projectID, nil, err)
+	return err
+}
+
+func (p *auditedProvisioner) CreateBackup(ctx context.Context, config *provisioner.BackupConfig) (*provisioner.BackupInfo, error) {
+	info, err := p.Provisioner.CreateBackup(ctx, config)
+	payload := map[string]interface{}{"backup_type": config.BackupType}
+	if info != nil {
+		payload["backup_id"] = info.BackupID
+	}
+	p.record(ctx, "create_backup", config.ProjectID, payload, err)
+	return info, err
+}
+
+func (p *auditedProvisioner) record(ctx context.Context, operation string, projectRef string, payload map[string]interface{}, opErr error) {
+	ctx = context.WithoutCancel(ctx)
+	event := database.CreateAuditEventParams{
+		Action:     "provisioner." + operation,
+		Target:     projectRef,
+		ProjectRef: pgtype.Text{String: projectRef, Valid: true},
+	}
+	if project, err := p.api.queries.GetProjectByRef(ctx, projectRef); err == nil {
+		event.OrganizationID = pgtype.Int4{Int32: project.OrganizationID, Valid: true}
+	}
+	if opErr != nil {
+		event.Error = pgtype.Text{String: opErr.Error(), Valid: true}
+	}
+	if payload != nil {
+		event.Payload, _ = json.Marshal(payload)
+	}
+	p.api.recordAuditEvent(ctx, auditEntryFrom(ctx), event)
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+	"path"
+	"strings"
+	"supamanager.io/supa-manager/database"
+	"time"
+)
+
+// Issuer and audience of the access tokens minted by signAccessToken
+const (
+	accessTokenIssuer   = "supamanager.io"
+	accessTokenAudience = "supamanager.io"
+)
+
+// accessTokenLeeway absorbs clock skew between supa-manager replicas
+const accessTokenLeeway = 30 * time.Second
+
+// Keys of the authenticated account and claims in the gin context
+const (
+	contextAccountKey = "account"
+	contextClaimsKey  = "claims"
+)
+
+var errUnauthenticated = errors.New("missing or malformed Authorization header")
+
+// public registers a route that can be called without an access token. Every other route is
+// rejected by authenticate when the request has no valid token
+func (a *Api) public(group *gin.RouterGroup, method string, relativePath string, handlers ...gin.HandlerFunc) {
+	group.Handle(method, relativePath, handlers...)
+	a.publicRoutes[method+" "+path.Join(group.BasePath(), relativePath)] = true
+}
+
+// authenticate validates the bearer token, a platform access token or a personal access token, and
+// loads the account into the context once for the handlers
+func (a *Api) authenticate(c *gin.Context) {
+	// Unknown routes fall through to the 404 handler
+	if c.FullPath() == "" || a.publicRoutes[c.Request.Method+" "+c.FullPath()] {
+		c.Next()
+		return
+	}
+
+	token, err := bearerToken(c.GetHeader("Authorization"))
+	if err != nil {
+		c.AbortWithStatusJSON(401, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var account database.Account
+	var claims *AccessTokenClaims
+	if strings.HasPrefix(token, personalAccessTokenPrefix) {
+		account, claims, err = a.personalAccessTokenClaims(c, token)
+	} else {
+		account, claims, err = a.accessTokenClaims(c, token)
+	}
+	if errors.Is(err, errTokenScope) {
+		c.AbortWithStatusJSON(403, gin.H{"error": "Forbidden", "message": err.Error()})
+		return
+	}
+	if errors.Is(err, errUnauthenticated) || errors.Is(err, pgx.ErrNoRows) {
+		c.AbortWithStatusJSON(401, gin.H{"error": "Unauthorized"})
+		return
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to authenticate request: %v", err))
+		c.AbortWithStatusJSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	c.Set(contextAccountKey, &account)
+	c.Set(contextClaimsKey, claims)
+	setAuditActor(c, &account, claims)
+	c.Next()
+}
+
+// bearerToken extracts the token of an Authorization header, the scheme is case-insensitive
+func bearerToken(header string) (string, error) {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", errUnauthenticated
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", errUnauthenticated
+	}
+	return token, nil
+}
+
+// accessTokenClaims validates a platform access token: the algorithm is pinned to HS256 and the
+// issuer, audience, expiry and not-before are required
+func (a *Api) accessTokenClaims(c *gin.Context, raw string) (database.Account, *AccessTokenClaims, error) {
+	claims := &AccessTokenClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(a.config.JwtSecret), nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(accessTokenIssuer),
+		jwt.WithAudience(accessTokenAudience),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(accessTokenLeeway),
+	)
+	if err != nil {
+		return database.Account{}, nil, errUnauthenticated
+	}
+	if claims.NotBefore == nil || claims.Subject == "" {
+		return database.Account{}, nil, errUnauthenticated
+	}
+
+	// Access tokens die with their session, so logging out takes effect before they expire
+	if claims.SessionID != "" {
+		active, err := a.queries.IsSessionActive(c.Request.Context(), claims.SessionID)
+		if err != nil {
+			return database.Account{}, nil, err
+		}
+		if !active {
+			return database.Account{}, nil, errUnauthenticated
+		}
+	}
+
+	account, err := a.queries.GetAccountByGoTrueID(c.Request.Context(), claims.Subject)
+	if err != nil {
+		return database.Account{}, nil, err
+	}
+	return account, claims, nil
+}
+
+// currentAccount is the account authenticate loaded, only valid on routes that aren't public
+func currentAccount(c *gin.Context) *database.Account {
+	return c.MustGet(contextAccountKey).(*database.Account)
+}
+
+// currentClaims are the claims of the token authenticate validated, only valid on routes that aren't public
+func currentClaims(c *gin.Context) *AccessTokenClaims {
+	return c.MustGet(contextClaimsKey).(*AccessTokenClaims)
+}
@@ -0,0 +1,226 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"supamanager.io/supa-manager/conf"
+	"supamanager.io/supa-manager/database"
+	"testing"
+	"time"
+)
+
+const testJwtSecret = "test-jwt-secret-of-at-least-32-characters"
+
+// fakeDB answers :one queries by their sqlc name, the values are scanned into the first columns
+// and queries without values find no rows. Statements run with Exec are recorded
+type fakeDB struct {
+	rows  map[string][]interface{}
+	execs []string
+}
+
+// queryName is the sqlc name of a generated query, its SQL starts with "-- name: <name> :<kind>"
+func queryName(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) < 3 || fields[0] != "--" || fields[1] != "name:" {
+		return ""
+	}
+	return fields[2]
+}
+
+func (db *fakeDB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	db.execs = append(db.execs, queryName(sql))
+	return pgconn.CommandTag{}, nil
+}
+
+func (db *fakeDB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return nil, errors.New("unexpected query " + queryName(sql))
+}
+
+func (db *fakeDB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	values, ok := db.rows[queryName(sql)]
+	if !ok {
+		return fakeRow{err: pgx.ErrNoRows}
+	}
+	return fakeRow{values: values}
+}
+
+type fakeRow struct {
+	values []interface{}
+	err    error
+}
+
+func (r fakeRow) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	for i, value := range r.values {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(value))
+	}
+	return nil
+}
+
+func newAuthTestApi(db *fakeDB) *Api {
+	gin.SetMode(gin.TestMode)
+	return &Api{
+		logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+		config:  &conf.Config{JwtSecret: testJwtSecret},
+		queries: database.New(db),
+	}
+}
+
+func testContext() *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	return c
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		header  string
+		want    string
+		wantErr bool
+	}{
+		{"Bearer abc.def.ghi", "abc.def.ghi", false},
+		{"bearer abc", "abc", false},
+		{"BEARER abc", "abc", false},
+		{"  Bearer   abc  ", "abc", false},
+		{"", "", true},
+		{"Bearer", "", true},
+		{"Bearer ", "", true},
+		{"Bearer    ", "", true},
+		{"Bearerabc", "", true},
+		{"Basic dXNlcjpwYXNz", "", true},
+		{"Token abc", "", true},
+		{"abc", "", true},
+	}
+	for _, tt := range tests {
+		got, err := bearerToken(tt.header)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("bearerToken(%q) = %q, %v, want %q, error: %t", tt.header, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func validAccessTokenClaims(now time.Time) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":        accessTokenIssuer,
+		"aud":        []string{accessTokenAudience},
+		"sub":        "gotrue-id",
+		"exp":        now.Add(time.Hour).Unix(),
+		"nbf":        now.Unix(),
+		"iat":        now.Unix(),
+		"session_id": "session",
+		"aal":        aal1,
+	}
+}
+
+func TestAccessTokenClaims(t *testing.T) {
+	now := time.Now()
+	sign := func(method jwt.SigningMethod, key interface{}, change func(jwt.MapClaims)) string {
+		claims := validAccessTokenClaims(now)
+		change(claims)
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	hs256 := func(change func(jwt.MapClaims)) string {
+		return sign(jwt.SigningMethodHS256, []byte(testJwtSecret), change)
+	}
+	unchanged := func(jwt.MapClaims) {}
+
+	tests := []struct {
+		name    string
+		token   string
+		active  bool
+		wantErr error
+	}{
+		{"valid", hs256(unchanged), true, nil},
+		{"expired within the leeway", hs256(func(c jwt.MapClaims) { c["exp"] = now.Add(-10 * time.Second).Unix() }), true, nil},
+		{"without a session", hs256(func(c jwt.MapClaims) { delete(c, "session_id") }), false, nil},
+		{"wrong secret", sign(jwt.SigningMethodHS256, []byte("another-secret-of-at-least-32-characters"), unchanged), true, errUnauthenticated},
+		{"HS512", sign(jwt.SigningMethodHS512, []byte(testJwtSecret), unchanged), true, errUnauthenticated},
+		{"alg none", sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, unchanged), true, errUnauthenticated},
+		{"wrong issuer", hs256(func(c jwt.MapClaims) { c["iss"] = "gotrue" }), true, errUnauthenticated},
+		{"missing issuer", hs256(func(c jwt.MapClaims) { delete(c, "iss") }), true, errUnauthenticated},
+		{"wrong audience", hs256(func(c jwt.MapClaims) { c["aud"] = "authenticated" }), true, errUnauthenticated},
+		{"missing audience", hs256(func(c jwt.MapClaims) { delete(c, "aud") }), true, errUnauthenticated},
+		{"expired", hs256(func(c jwt.MapClaims) { c["exp"] = now.Add(-time.Minute).Unix() }), true, errUnauthenticated},
+		{"missing expiry", hs256(func(c jwt.MapClaims) { delete(c, "exp") }), true, errUnauthenticated},
+		{"not valid yet", hs256(func(c jwt.MapClaims) { c["nbf"] = now.Add(time.Minute).Unix() }), true, errUnauthenticated},
+		{"missing not before", hs256(func(c jwt.MapClaims) { delete(c, "nbf") }), true, errUnauthenticated},
+		{"missing subject", hs256(func(c jwt.MapClaims) { delete(c, "sub") }), true, errUnauthenticated},
+		{"inactive session", hs256(unchanged), false, errUnauthenticated},
+		{"malformed", "not.a.token", true, errUnauthenticated},
+		{"empty", "", true, errUnauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newAuthTestApi(&fakeDB{rows: map[string][]interface{}{
+				"IsSessionActive":      {tt.active},
+				"GetAccountByGoTrueID": {int32(7)},
+			}})
+			account, claims, err := a.accessTokenClaims(testContext(), tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("accessTokenClaims() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (account.ID != 7 || claims.Subject != "gotrue-id") {
+				t.Errorf("accessTokenClaims() = %+v, %+v", account, claims)
+			}
+		})
+	}
+}
+
+func TestAccessTokenClaimsUnknownAccount(t *testing.T) {
+	a := newAuthTestApi(&fakeDB{rows: map[string][]interface{}{"IsSessionActive": {true}}})
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, validAccessTokenClaims(time.Now())).SignedString([]byte(testJwtSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := a.accessTokenClaims(testContext(), token); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("accessTokenClaims() error = %v, want no rows", err)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	a := newAuthTestApi(&fakeDB{})
+	a.publicRoutes = map[string]bool{}
+	r := gin.New()
+	r.Use(a.authenticate)
+	a.public(&r.RouterGroup, http.MethodGet, "/public", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/private", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		path   string
+		header string
+		want   int
+	}{
+		{"/public", "", http.StatusOK},
+		{"/private", "", http.StatusUnauthorized},
+		{"/private", "Basic dXNlcjpwYXNz", http.StatusUnauthorized},
+		{"/private", "Bearer not.a.token", http.StatusUnauthorized},
+		{"/missing", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("GET %s with %q: status %d, want %d", tt.path, tt.header, w.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/permisions"
+)
+
+// authzAction is a permission as Studio checks it: an action on a resource. It is evaluated
+// against the permissions of the member's role, the same ones getProfilePermissions serves
+type authzAction struct {
+	Action   string
+	Resource string
+}
+
+var (
+	actionOrganizationRead   = authzAction{Action: "read:Read", Resource: "organizations"}
+	actionOrganizationUpdate = authzAction{Action: "write:Update", Resource: "organizations"}
+	actionOrganizationDelete = authzAction{Action: "write:Delete", Resource: "organizations"}
+	actionMembersRead        = authzAction{Action: "read:Read", Resource: "members"}
+	actionMemberRoleUpdate   = authzAction{Action: "write:Update", Resource: "auth.subject_roles"}
+	actionMemberRemove       = authzAction{Action: "write:Delete", Resource: "auth.subject_roles"}
+	actionInvitationsRead    = authzAction{Action: "read:Read", Resource: "user_invites"}
+	actionInvitationCreate   = authzAction{Action: "write:Create", Resource: "user_invites"}
+	actionInvitationDelete   = authzAction{Action: "write:Delete", Resource: "user_invites"}
+	actionBillingRead        = authzAction{Action: "billing:Read", Resource: "stripe.subscriptions"}
+	actionBillingWrite       = authzAction{Action: "billing:Write", Resource: "stripe.subscriptions"}
+	actionProjectCreate      = authzAction{Action: "write:Create", Resource: "projects"}
+	actionProjectRead        = authzAction{Action: "read:Read", Resource: "projects"}
+	actionProjectUpdate      = authzAction{Action: "write:Update", Resource: "projects"}
+	actionProjectDelete      = authzAction{Action: "write:Delete", Resource: "projects"}
+	actionProjectTransfer    = authzAction{Action: "write:Transfer", Resource: "projects"}
+	actionProjectSecretsRead = authzAction{Action: "read:Read", Resource: "field.jwt_secret"}
+	actionDatabaseRead       = authzAction{Action: "tenant:Sql:Read:Select", Resource: "tables"}
+	actionDatabaseWrite      = authzAction{Action: "tenant:Sql:Admin:Write", Resource: "tables"}
+	actionAuditRead          = authzAction{Action: "read:Read", Resource: "audit_logs"}
+)
+
+func (action authzAction) String() string {
+	return action.Action + " on " + action.Resource
+}
+
+// Keys of the organization and project resolved by loadOrganization and loadProject
+const (
+	contextOrganizationKey = "organization"
+	contextProjectKey      = "project"
+)
+
+var errNotMember = errors.New("account is not a member of the organization")
+
+// authzResource is what an action is performed on, ProjectID is zero for organization-level actions.
+// Attributes are the resource fields permission conditions refer to, like owner_id or role_id
+type authzResource struct {
+	OrganizationID int32
+	ProjectID      int32
+	Attributes     map[string]interface{}
+}
+
+// can is the single authorization check for organization and project routes. It returns
+// errNotMember when the account has no role in the resource's organization
+func (a *Api) can(ctx context.Context, account *database.Account, action authzAction, resource authzResource) (bool, error) {
+	role, err := a.queries.GetOrganizationMemberRole(ctx, database.GetOrganizationMemberRoleParams{
+		OrganizationID: resource.OrganizationID,
+		AccountID:      account.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, errNotMember
+	}
+	if err != nil {
+		return false, err
+	}
+
+	attributes := map[string]interface{}{}
+	for k, v := range resource.Attributes {
+		attributes[k] = v
+	}
+	if resource.ProjectID != 0 {
+		attributes["project_id"] = resource.ProjectID
+	}
+	data := map[string]interface{}{
+		"resource": attributes,
+		"subject": map[string]interface{}{
+			"id":        account.ID,
+			"gotrue_id": account.GotrueID,
+		},
+	}
+	permissions := permisions.ForRole(resource.OrganizationID, role, nil)
+	return permisions.Can(permissions, resource.OrganizationID, resource.ProjectID, action.Action, action.Resource, data), nil
+}
+
+// loadOrganization resolves the :slug organization for the routes below it. Organizations the
+// account isn't a member of are reported as missing rather than forbidden
+func (a *Api) loadOrganization(c *gin.Context) {
+	org, err := a.queries.GetOrganizationById(c.Request.Context(), c.Param("slug"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	c.Set(contextOrganizationKey, &org)
+	a.authorize(actionOrganizationRead)(c)
+}
+
+// loadProject resolves the :ref project for the routes below it, like loadOrganization
+func (a *Api) loadProject(c *gin.Context) {
+	project, err := a.queries.GetProjectByRef(c.Request.Context(), c.Param("ref"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	c.Set(contextProjectKey, &project)
+	a.authorize(actionProjectRead)(c)
+}
+
+// authorize requires action on the project or organization loaded by loadProject or loadOrganization
+func (a *Api) authorize(action authzAction) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed, err := a.allowed(c, action)
+		if errors.Is(err, errNotMember) {
+			notFound := "Organization not found"
+			if _, ok := c.Get(contextProjectKey); ok {
+				notFound = "Project not found"
+			}
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": notFound})
+			return
+		}
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Failed to check %s permission: %v", action, err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+			return
+		}
+		if !allowed {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Forbidden", "message": fmt.Sprintf("Your role does not allow %s", action)})
+			return
+		}
+		c.Next()
+	}
+}
+
+// allowed is can on the project or organization of the route, for handlers that adapt their
+// response to the role instead of refusing it
+func (a *Api) allowed(c *gin.Context, action authzAction) (bool, error) {
+	return a.allowedOn(c, action, nil)
+}
+
+// allowedOn is allowed for actions whose conditions depend on the target, like the role of a member
+func (a *Api) allowedOn(c *gin.Context, action authzAction, attributes map[string]interface{}) (bool, error) {
+	var resource authzResource
+	if project, ok := c.Get(contextProjectKey); ok {
+		resource = authzResource{
+			OrganizationID: project.(*database.Project).OrganizationID,
+			ProjectID:      project.(*database.Project).ID,
+		}
+	} else if org, ok := c.Get(contextOrganizationKey); ok {
+		resource = authzResource{OrganizationID: org.(*database.Organization).ID}
+	} else {
+		panic("authorization checked on a route without loadProject or loadOrganization")
+	}
+	resource.Attributes = attributes
+	return a.can(c.Request.Context(), currentAccount(c), action, resource)
+}
+
+// currentOrganization is the organization loadOrganization resolved
+func currentOrganization(c *gin.Context) *database.Organization {
+	return c.MustGet(contextOrganizationKey).(*database.Organization)
+}
+
+// currentProject is the project loadProject resolved
+func currentProject(c *gin.Context) *database.Project {
+	return c.MustGet(contextProjectKey).(*database.Project)
+}
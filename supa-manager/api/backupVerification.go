@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgtype"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/provisioner"
+	"time"
+)
+
+// runBackupVerificationJob periodically restores catalogued backups into a scratch
+// database so that broken backups are noticed before they are needed
+func (a *Api) runBackupVerificationJob(ctx context.Context) {
+	a.logger.Info(fmt.Sprintf("Backup verification job started, interval %s", a.config.Backups.VerifyInterval))
+
+	ticker := time.NewTicker(a.config.Backups.VerifyInterval)
+	defer ticker.Stop()
+
+	for {
+		a.verifyDueBackups(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// verificationStaleBefore is when a verification still VERIFYING must have started for it to be
+// abandoned, its replica died or was restarted mid-way
+func (a *Api) verificationStaleBefore() pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: time.Now().Add(-2 * a.config.Backups.VerifyTimeout), Valid: true}
+}
+
+func (a *Api) verifyDueBackups(ctx context.Context) {
+	backups, err := a.queries.GetBackupsDueForVerification(ctx, database.GetBackupsDueForVerificationParams{
+		VerifiedBefore: pgtype.Timestamptz{Time: time.Now().Add(-a.config.Backups.VerifyInterval), Valid: true},
+		StaleBefore:    a.verificationStaleBefore(),
+		MaxResults:     int32(a.config.Backups.VerifyBatchSize),
+	})
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to load backups due for verification: %v", err))
+		return
+	}
+
+	for _, backup := range backups {
+		if err := a.verifyBackup(ctx, backup); err != nil {
+			a.logger.Error(fmt.Sprintf("Failed to verify backup %s: %v", backup.ID, err))
+		}
+	}
+}
+
+// verifyBackup runs a single restore test and records the outcome in the catalog
+func (a *Api) verifyBackup(ctx context.Context, backup database.Backup) error {
+	started, err := a.queries.StartBackupVerification(ctx, database.StartBackupVerificationParams{
+		ID:          backup.ID,
+		StaleBefore: a.verificationStaleBefore(),
+	})
+	if err != nil {
+		return err
+	}
+	if started == 0 {
+		// Another replica started verifying it since it was loaded
+		return nil
+	}
+
+	result, err := a.backupVerifier.Verify(ctx, backupInfoFromRow(backup))
+	if err != nil {
+		// Verification could not run at all, verified_at is kept so the backup stays eligible for
+		// the next pass
+		previous := backup.VerificationStatus
+		if previous == string(provisioner.VerificationVerifying) {
+			previous = string(provisioner.VerificationUnverified)
+		}
+		if updateErr := a.queries.ResetBackupVerification(ctx, database.ResetBackupVerificationParams{
+			ID:                 backup.ID,
+			VerificationStatus: previous,
+			VerificationError:  pgtype.Text{String: err.Error(), Valid: true},
+		}); updateErr != nil {
+			a.logger.Error(fmt.Sprintf("Failed to reset verification status for backup %s: %v", backup.ID, updateErr))
+		}
+		return err
+	}
+
+	if !backup.ChecksumSha256.Valid && result.Checksum != "" {
+		if err := a.queries.SetBackupChecksum(ctx, database.SetBackupChecksumParams{
+			ID:             backup.ID,
+			ChecksumSha256: pgtype.Text{String: result.Checksum, Valid: true},
+			SizeBytes:      backup.SizeBytes,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if _, err := a.queries.UpdateBackupVerification(ctx, database.UpdateBackupVerificationParams{
+		ID:                 backup.ID,
+		VerificationStatus: string(result.Status),
+		VerificationError:  pgtype.Text{String: result.Error, Valid: result.Error != ""},
+	}); err != nil {
+		return err
+	}
+
+	if result.Status == provisioner.VerificationCorrupt {
+		a.logger.Warn(fmt.Sprintf("Backup %s of project %s failed verification: %s", backup.ID, backup.ProjectRef, result.Error))
+		return a.alerter.Alert(ctx, provisioner.Alert{
+			Severity:  provisioner.AlertCritical,
+			ProjectID: backup.ProjectRef,
+			Subject:   fmt.Sprintf("Backup %s failed verification", backup.ID),
+			Message:   result.Error,
+			Time:      result.FinishedAt,
+		})
+	}
+
+	a.logger.Info(fmt.Sprintf("Backup %s verified, %d tables restored in %s",
+		backup.ID, result.TableCount, result.FinishedAt.Sub(result.StartedAt).Round(time.Second)))
+	return nil
+}
+
+// createProjectBackup dumps the project's database and adds the backup to the catalog, where the
+// verification job picks it up
+func (a *Api) createProjectBackup(ctx context.Context, project database.Project) (database.Backup, error) {
+	info, err := a.provisioner.CreateBackup(ctx, &provisioner.BackupConfig{
+		ProjectID:  project.ProjectRef,
+		BackupType: provisioner.BackupTypeDatabase,
+		Dir:        a.config.Backups.Dir,
+	})
+	if err != nil {
+		return database.Backup{}, err
+	}
+	return a.recordBackup(ctx, info)
+}
+
+// recordBackup adds a completed backup to the catalog. The SHA-256 is computed from
+// the file when the caller has not already provided one.
+func (a *Api) recordBackup(ctx context.Context, info *provisioner.BackupInfo) (database.Backup, error) {
+	if info.Checksum == "" {
+		checksum, err := provisioner.ComputeChecksum(info.FilePath)
+		if err != nil {
+			return database.Backup{}, fmt.Errorf("failed to checksum backup: %w", err)
+		}
+		info.Checksum = checksum
+	}
+
+	return a.queries.CreateBackup(ctx, database.CreateBackupParams{
+		ID:             info.BackupID,
+		ProjectRef:     info.ProjectID,
+		BackupType:     string(info.BackupType),
+		Status:         info.Status,
+		FilePath:       info.FilePath,
+		SizeBytes:      info.Size,
+		Compressed:     info.Compressed,
+		Encrypted:      info.Encrypted,
+		ChecksumSha256: pgtype.Text{String: info.Checksum, Valid: true},
+		CompletedAt:    pgtype.Timestamptz{Time: info.CompletedAt, Valid: !info.CompletedAt.IsZero()},
+		ExpiresAt:      pgtype.Timestamptz{Time: info.ExpiresAt, Valid: !info.ExpiresAt.IsZero()},
+	})
+}
+
+func backupInfoFromRow(backup database.Backup) *provisioner.BackupInfo {
+	return &provisioner.BackupInfo{
+		BackupID:           backup.ID,
+		ProjectID:          backup.ProjectRef,
+		BackupType:         provisioner.BackupType(backup.BackupType),
+		Size:               backup.SizeBytes,
+		Compressed:         backup.Compressed,
+		Encrypted:          backup.Encrypted,
+		FilePath:           backup.FilePath,
+		S3Key:              backup.S3Key.String,
+		Status:             backup.Status,
+		CreatedAt:          backup.CreatedAt.Time,
+		CompletedAt:        backup.CompletedAt.Time,
+		ExpiresAt:          backup.ExpiresAt.Time,
+		Checksum:           backup.ChecksumSha256.String,
+		VerificationStatus: provisioner.VerificationStatus(backup.VerificationStatus),
+		VerificationError:  backup.VerificationError.String,
+		VerifiedAt:         backup.VerifiedAt.Time,
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"supamanager.io/supa-manager/certs"
+	"supamanager.io/supa-manager/conf"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/dnshook"
+	"time"
+)
+
+// certificateCheckInterval is how often certificates due are issued and those issued by other
+// replicas are picked up
+const certificateCheckInterval = 5 * time.Minute
+
+// dnsHookTXT publishes ACME challenge records through the DNS hook
+type dnsHookTXT struct {
+	client *dnshook.Client
+}
+
+func (d dnsHookTXT) SetTXT(ctx context.Context, name string, value string) error {
+	return d.client.Send(ctx, dnshook.Update{Type: dnshook.UpdateCreate, Hostname: name, RecordType: "TXT", Value: value})
+}
+
+func (d dnsHookTXT) ClearTXT(ctx context.Context, name string, value string) error {
+	return d.client.Send(ctx, dnshook.Update{Type: dnshook.UpdateDelete, Hostname: name, RecordType: "TXT", Value: value})
+}
+
+// newDnsHook returns the client of the configured DNS hook, nil when there's none. The key signs
+// every request, so a hook without one is refused
+func newDnsHook(config *conf.Config) (*dnshook.Client, error) {
+	if config.Domain.DnsHookUrl == nil || *config.Domain.DnsHookUrl == "" {
+		return nil, nil
+	}
+	if config.Domain.DnsHookKey == nil || *config.Domain.DnsHookKey == "" {
+		return nil, fmt.Errorf("DOMAIN_DNS_HOOK_URL needs DOMAIN_DNS_HOOK_KEY to sign requests")
+	}
+	return dnshook.NewClient(*config.Domain.DnsHookUrl, *config.Domain.DnsHookKey), nil
+}
+
+func newCertificateManager(logger *slog.Logger, config *conf.Config, queries *database.Queries, hook *dnshook.Client) (*certs.Manager, error) {
+	if hook == nil {
+		return nil, fmt.Errorf("ACME needs DOMAIN_DNS_HOOK_URL to publish DNS-01 challenges")
+	}
+	return certs.New(queries, config.EncryptionSecret, dnsHookTXT{client: hook}, certs.Settings{
+		DirectoryURL:       config.Acme.DirectoryUrl,
+		Email:              config.Acme.Email,
+		CAFile:             config.Acme.CaFile,
+		RenewBefore:        config.Acme.RenewBefore,
+		RetryAfter:         config.Acme.RetryAfter,
+		DNSResolver:        config.Acme.DnsResolver,
+		PropagationTimeout: config.Acme.PropagationTimeout,
+	}, logger)
+}
+
+// wildcardCertificateName names the certificate of every project subdomain
+func (a *Api) wildcardCertificateName() string {
+	return "*." + a.config.Domain.Base
+}
+
+// runCertificateJob requests the wildcard certificate, then keeps certificates issued and renewed
+func (a *Api) runCertificateJob(ctx context.Context) {
+	wildcard := a.wildcardCertificateName()
+	if _, err := a.certs.Request(ctx, wildcard, []string{wildcard}, certs.ChallengeDNS01); err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to request certificate %s: %v", wildcard, err))
+	}
+
+	ticker := time.NewTicker(certificateCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := a.certs.Reload(ctx); err != nil {
+			a.logger.Error(fmt.Sprintf("Failed to load certificates: %v", err))
+		}
+		if err := a.certs.RenewDue(ctx); err != nil {
+			a.logger.Error(fmt.Sprintf("Failed to renew certificates: %v", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net"
+	"regexp"
+	"slices"
+	"strings"
+	"supamanager.io/supa-manager/certs"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/dnshook"
+	"supamanager.io/supa-manager/utils"
+	"time"
+)
+
+// Statuses a custom hostname moves through. It's verified once both DNS records are in place and
+// active once its certificate is issued. Only a verified or active hostname belongs to its project
+const (
+	CustomHostnamePendingVerification = "pending_verification"
+	CustomHostnameVerified            = "verified"
+	CustomHostnameActive              = "active"
+)
+
+const (
+	customHostnameCheckInterval = time.Minute
+	customHostnameChallenge     = "_supamanager-challenge."
+	customHostnameDNSTimeout    = 10 * time.Second
+)
+
+var validHostnameLabel = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+type CustomHostnameRecord struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// normalizeCustomHostname validates a hostname a project wants to be served on
+func (a *Api) normalizeCustomHostname(hostname string) (string, error) {
+	hostname = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(hostname)), ".")
+	if len(hostname) > 253 {
+		return "", errors.New("Hostname must be at most 253 characters")
+	}
+	labels := strings.Split(hostname, ".")
+	if len(labels) < 2 {
+		return "", errors.New("Hostname must be a fully qualified domain name")
+	}
+	for _, label := range labels {
+		if !validHostnameLabel.MatchString(label) {
+			return "", errors.New("Hostname must be a valid domain name")
+		}
+	}
+	base := strings.Trim(strings.ToLower(a.config.Domain.Base), ".")
+	if hostname == base || strings.HasSuffix(hostname, "."+base) {
+		return "", fmt.Errorf("Hostname can't be under %s", base)
+	}
+	return hostname, nil
+}
+
+// customHostnameTarget is what a custom hostname must be a CNAME of, the project's own subdomain
+func (a *Api) customHostnameTarget(projectRef string) string {
+	return projectRef + "." + strings.Trim(a.config.Domain.Base, ".")
+}
+
+func (a *Api) customHostnameRecords(projectRef string, custom database.CustomHostname) []CustomHostnameRecord {
+	return []CustomHostnameRecord{
+		{Type: "TXT", Name: customHostnameChallenge + custom.Hostname, Value: custom.VerificationToken},
+		{Type: "CNAME", Name: custom.Hostname, Value: a.customHostnameTarget(projectRef)},
+	}
+}
+
+// ownsCustomHostname reports whether the hostname belongs to the project. Certificates are named by
+// hostname, so only the owner may see or touch the certificate, other projects may have the
+// hostname pending too
+func ownsCustomHostname(custom database.CustomHostname) bool {
+	return custom.Status == CustomHostnameVerified || custom.Status == CustomHostnameActive
+}
+
+func (a *Api) customHostnameResponse(ctx context.Context, projectRef string, custom database.CustomHostname) gin.H {
+	certificateStatus := "not_requested"
+	if a.certs == nil {
+		certificateStatus = "not_managed"
+	} else if ownsCustomHostname(custom) {
+		if cert, err := a.certs.Status(ctx, custom.Hostname); err == nil {
+			certificateStatus = cert.Status
+		}
+	}
+
+	return gin.H{
+		"customHostname": custom.Hostname,
+		"status":         custom.Status,
+		"data": gin.H{
+			"verification_records": a.customHostnameRecords(projectRef, custom),
+			"certificate_status":   certificateStatus,
+			"error":                utils.PgTextToPointer(custom.Error),
+			"last_checked_at":      utils.PgTimestamptzToPointer(custom.LastCheckedAt),
+			"verified_at":          utils.PgTimestamptzToPointer(custom.VerifiedAt),
+			"activated_at":         utils.PgTimestamptzToPointer(custom.ActivatedAt),
+			"created_at":           custom.CreatedAt.Time,
+		},
+	}
+}
+
+// verifyCustomHostnameDNS checks the ownership TXT record and the CNAME to the project
+func (a *Api) verifyCustomHostnameDNS(ctx context.Context, projectRef string, custom database.CustomHostname) error {
+	ctx, cancel := context.WithTimeout(ctx, customHostnameDNSTimeout)
+	defer cancel()
+
+	name := customHostnameChallenge + custom.Hostname
+	records, err := net.DefaultResolver.LookupTXT(ctx, name)
+	if err != nil || !slices.Contains(records, custom.VerificationToken) {
+		return fmt.Errorf("TXT record %s does not contain the verification token", name)
+	}
+
+	target := a.customHostnameTarget(projectRef)
+	cname, err := net.DefaultResolver.LookupCNAME(ctx, custom.Hostname)
+	if err != nil || !strings.EqualFold(strings.TrimSuffix(cname, "."), target) {
+		return fmt.Errorf("%s is not a CNAME of %s", custom.Hostname, target)
+	}
+	return nil
+}
+
+// checkCustomHostname moves a custom hostname as far along as it can go: verified once its DNS
+// records are found, active once its certificate is issued
+func (a *Api) checkCustomHostname(ctx context.Context, projectRef string, custom database.CustomHostname) (database.CustomHostname, error) {
+	if custom.Status == CustomHostnamePendingVerification {
+		if err := a.verifyCustomHostnameDNS(ctx, projectRef, custom); err != nil {
+			return a.queries.RecordCustomHostnameCheck(ctx, database.RecordCustomHostnameCheckParams{
+				ID:    custom.ID,
+				Error: pgtype.Text{String: err.Error(), Valid: true},
+			})
+		}
+		verified, err := a.queries.VerifyCustomHostname(ctx, custom.ID)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return a.queries.RecordCustomHostnameCheck(ctx, database.RecordCustomHostnameCheckParams{
+				ID:    custom.ID,
+				Error: pgtype.Text{String: fmt.Sprintf("%s is already used by another project", custom.Hostname), Valid: true},
+			})
+		}
+		if err != nil {
+			return custom, err
+		}
+		custom = verified
+		if a.certs != nil {
+			if _, err := a.certs.Request(ctx, custom.Hostname, []string{custom.Hostname}, certs.ChallengeHTTP01); err != nil {
+				return custom, err
+			}
+		}
+		a.logger.Info("Verified custom hostname", "hostname", custom.Hostname, "project", projectRef)
+	}
+
+	if custom.Status != CustomHostnameVerified {
+		return custom, nil
+	}
+	// the edge has no certificate to serve it with until ACME issues one, so it isn't activated
+	if a.certs == nil {
+		return a.queries.RecordCustomHostnameCheck(ctx, database.RecordCustomHostnameCheckParams{
+			ID:    custom.ID,
+			Error: pgtype.Text{String: "Certificates are not managed, enable ACME to activate the hostname", Valid: true},
+		})
+	}
+	cert, err := a.certs.Status(ctx, custom.Hostname)
+	if errors.Is(err, pgx.ErrNoRows) {
+		_, err = a.certs.Request(ctx, custom.Hostname, []string{custom.Hostname}, certs.ChallengeHTTP01)
+		return custom, err
+	}
+	if err != nil {
+		return custom, err
+	}
+	switch cert.Status {
+	case "issued":
+	case "failed":
+		return a.queries.RecordCustomHostnameCheck(ctx, database.RecordCustomHostnameCheckParams{
+			ID:    custom.ID,
+			Error: pgtype.Text{String: "Certificate could not be issued: " + cert.Error.String, Valid: true},
+		})
+	default:
+		return custom, nil
+	}
+
+	tx, err := a.pgPool.Begin(ctx)
+	if err != nil {
+		return custom, err
+	}
+	defer tx.Rollback(ctx)
+	queries := a.queries.WithTx(tx)
+
+	active, err := queries.ActivateCustomHostname(ctx, custom.ID)
+	if err != nil {
+		return custom, err
+	}
+	if err := a.enqueueDnsUpdate(ctx, queries, a.customHostnameDnsUpdate(dnshook.UpdateCreate, projectRef, active.Hostname)); err != nil {
+		return custom, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return custom, err
+	}
+	a.invalidateEdgeHost(active.Hostname)
+	a.logger.Info("Activated custom hostname", "hostname", active.Hostname, "project", projectRef)
+	return active, nil
+}
+
+// customHostnameDnsUpdate is the update about the CNAME of an active custom hostname
+func (a *Api) customHostnameDnsUpdate(updateType dnshook.UpdateType, projectRef string, hostname string) dnshook.Update {
+	return dnshook.Update{
+		Type:       updateType,
+		Hostname:   hostname,
+		ProjectRef: projectRef,
+		RecordType: "CNAME",
+		Value:      a.customHostnameTarget(projectRef),
+	}
+}
+
+// removeCustomHostname deletes a custom hostname with its certificate and DNS record. A pending
+// hostname has neither, the certificate of that name belongs to whichever project verified it
+func (a *Api) removeCustomHostname(ctx context.Context, projectRef string, custom database.CustomHostname) error {
+	if a.certs != nil && ownsCustomHostname(custom) {
+		if err := a.certs.Remove(ctx, custom.Hostname); err != nil {
+			return err
+		}
+	}
+
+	tx, err := a.pgPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	queries := a.queries.WithTx(tx)
+
+	if err := queries.DeleteCustomHostname(ctx, custom.ID); err != nil {
+		return err
+	}
+	if custom.Status == CustomHostnameActive {
+		if err := a.enqueueDnsUpdate(ctx, queries, a.customHostnameDnsUpdate(dnshook.UpdateDelete, projectRef, custom.Hostname)); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	a.invalidateEdgeHost(custom.Hostname)
+	return nil
+}
+
+// runCustomHostnameJob keeps checking custom hostnames that aren't active yet
+func (a *Api) runCustomHostnameJob(ctx context.Context) {
+	ticker := time.NewTicker(customHostnameCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		unfinished, err := a.queries.GetUnfinishedCustomHostnames(ctx)
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Failed to load custom hostnames: %v", err))
+			continue
+		}
+		for _, custom := range unfinished {
+			project, err := a.queries.GetProjectByID(ctx, custom.ProjectID)
+			if err != nil {
+				a.logger.Error(fmt.Sprintf("Failed to load project of custom hostname %s: %v", custom.Hostname, err))
+				continue
+			}
+			if _, err := a.checkCustomHostname(ctx, project.ProjectRef, custom); err != nil {
+				a.logger.Error(fmt.Sprintf("Failed to check custom hostname %s: %v", custom.Hostname, err))
+			}
+		}
+	}
+}
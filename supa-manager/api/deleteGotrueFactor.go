@@ -0,0 +1,63 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"supamanager.io/supa-manager/database"
+)
+
+// deleteGotrueFactor unenrolls a factor, removing a verified one needs an aal2 session
+func (a *Api) deleteGotrueFactor(c *gin.Context) {
+	account := currentAccount(c)
+	claims := currentClaims(c)
+
+	ctx := c.Request.Context()
+	factor, err := a.queries.GetMfaFactor(ctx, database.GetMfaFactorParams{
+		ID:        c.Param("id"),
+		AccountID: account.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(404, gin.H{"error": "Factor not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if factor.Status == "verified" && claims.AAL != aal2 {
+		c.JSON(403, gin.H{"error": "insufficient_aal", "message": "Verify a factor before removing it"})
+		return
+	}
+
+	tx, err := a.pgPool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+	queries := a.queries.WithTx(tx)
+
+	if err := queries.DeleteMfaFactor(ctx, database.DeleteMfaFactorParams{ID: factor.ID, AccountID: account.ID}); err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	// Recovery codes are worthless once no verified factor is left
+	verified, err := queries.CountVerifiedMfaFactors(ctx, account.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if verified == 0 {
+		if err := queries.DeleteMfaRecoveryCodes(ctx, account.ID); err != nil {
+			c.JSON(500, gin.H{"error": "Internal Server Error"})
+			return
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	c.JSON(200, gin.H{"id": factor.ID})
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"strconv"
+	"supamanager.io/supa-manager/database"
+)
+
+// deleteOrganizationInvitation revokes a pending invitation, its link stops working immediately
+func (a *Api) deleteOrganizationInvitation(c *gin.Context) {
+	org := currentOrganization(c)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Invitation not found"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	invitation, err := a.queries.GetPendingOrganizationInvitation(ctx, database.GetPendingOrganizationInvitationParams{
+		ID:             id,
+		OrganizationID: org.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(404, gin.H{"error": "Invitation not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	allowed, err := a.allowedOn(c, actionInvitationDelete, roleAttributes(invitation.Role))
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden", "message": "Your role does not allow revoking this invitation"})
+		return
+	}
+
+	deleted, err := a.queries.DeleteOrganizationInvitation(ctx, database.DeleteOrganizationInvitationParams{
+		ID:             id,
+		OrganizationID: org.ID,
+	})
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to revoke invitation %d: %v", id, err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if deleted == 0 {
+		c.JSON(404, gin.H{"error": "Invitation not found"})
+		return
+	}
+
+	c.Status(204)
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"supamanager.io/supa-manager/database"
+)
+
+// deleteOrganizationMember removes a member, members may also remove themselves to leave
+func (a *Api) deleteOrganizationMember(c *gin.Context) {
+	org := currentOrganization(c)
+	gotrueID := c.Param("gotrue_id")
+
+	ctx := c.Request.Context()
+	member, err := a.queries.GetOrganizationMemberByGotrueId(ctx, database.GetOrganizationMemberByGotrueIdParams{
+		OrganizationID: org.ID,
+		GotrueID:       gotrueID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(404, gin.H{"error": "Member not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	attributes := roleAttributes(member.Role)
+	attributes["subject_id"] = gotrueID
+	allowed, err := a.allowedOn(c, actionMemberRemove, attributes)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden", "message": "Your role does not allow removing this member"})
+		return
+	}
+
+	tx, err := a.pgPool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+	queries := a.queries.WithTx(tx)
+
+	err = guardLastOwner(ctx, queries, org.ID, member.ID, "")
+	if errors.Is(err, errLastOwner) {
+		c.JSON(409, gin.H{"error": errLastOwner.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	removed, err := queries.DeleteOrganizationMembership(ctx, database.DeleteOrganizationMembershipParams{
+		OrganizationID: org.ID,
+		AccountID:      member.ID,
+	})
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to remove member: %v", err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if removed == 0 {
+		c.JSON(404, gin.H{"error": "Member not found"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	a.logger.Info("Removed member", "organization", org.Slug, "member", gotrueID)
+	c.Status(204)
+}
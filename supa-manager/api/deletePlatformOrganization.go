@@ -0,0 +1,72 @@
+package api
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// deletePlatformOrganization deletes an organization without projects. With cascade=true and
+// confirm=<slug> its projects are deprovisioned and deleted first
+func (a *Api) deletePlatformOrganization(c *gin.Context) {
+	org := currentOrganization(c)
+	ctx := c.Request.Context()
+
+	projects, err := a.queries.GetProjectsForOrganization(ctx, org.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if len(projects) > 0 {
+		if c.Query("cascade") != "true" {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("The organization still has %d projects, delete or transfer them first", len(projects))})
+			return
+		}
+		if c.Query("confirm") != org.Slug {
+			c.JSON(422, gin.H{"error": "Deleting the projects of an organization must be confirmed with its slug"})
+			return
+		}
+		for _, project := range projects {
+			if err := a.removeProject(ctx, project); err != nil {
+				a.logger.Error(fmt.Sprintf("Failed to delete project %s of organization %s: %v", project.ProjectRef, org.Slug, err))
+				c.JSON(500, gin.H{"error": "Internal Server Error"})
+				return
+			}
+		}
+	}
+
+	tx, err := a.pgPool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+	queries := a.queries.WithTx(tx)
+
+	// Projects created while the others were being removed keep the organization alive
+	if _, err := queries.LockOrganization(ctx, org.ID); err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	remaining, err := queries.CountProjectsForOrganization(ctx, org.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if remaining > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("The organization still has %d projects, delete or transfer them first", remaining)})
+		return
+	}
+	if err := queries.DeleteOrganization(ctx, org.ID); err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to delete organization %s: %v", org.Slug, err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	a.logger.Info("Deleted organization", "organization", org.Slug, "projects", len(projects), "account", currentAccount(c).ID)
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"supamanager.io/supa-manager/pgmeta"
+)
+
+func (a *Api) deletePlatformPgMetaColumns(c *gin.Context) {
+	id := c.Query("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing required query parameter: id"})
+		return
+	}
+
+	pool, ok := a.projectPoolFromRequest(c)
+	if !ok {
+		return
+	}
+
+	column, err := pgmeta.DeleteColumn(c.Request.Context(), pool, id, c.Query("cascade") == "true")
+	if err != nil {
+		a.respondPgMetaError(c, "column", id, err)
+		return
+	}
+
+	a.logger.Info("Dropped column", "project", c.Param("ref"), "table", column.String("table"), "column", column.String("name"))
+	c.JSON(http.StatusOK, column)
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"supamanager.io/supa-manager/pgmeta"
+)
+
+func (a *Api) deletePlatformPgMetaExtensions(c *gin.Context) {
+	id := c.Query("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing required query parameter: id"})
+		return
+	}
+
+	pool, ok := a.projectPoolFromRequest(c)
+	if !ok {
+		return
+	}
+
+	extension, err := pgmeta.DeleteExtension(c.Request.Context(), pool, id, c.Query("cascade") == "true")
+	if err != nil {
+		a.respondExtensionError(c, id, err)
+		return
+	}
+
+	a.logger.Info("Dropped extension", "project", c.Param("ref"), "extension", extension.String("name"))
+	c.JSON(http.StatusOK, extension)
+}
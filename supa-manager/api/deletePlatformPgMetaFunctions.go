@@ -0,0 +1,28 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"supamanager.io/supa-manager/pgmeta"
+)
+
+func (a *Api) deletePlatformPgMetaFunctions(c *gin.Context) {
+	id, ok := pgMetaID(c)
+	if !ok {
+		return
+	}
+
+	pool, ok := a.projectPoolFromRequest(c)
+	if !ok {
+		return
+	}
+
+	function, err := pgmeta.DeleteFunction(c.Request.Context(), pool, id, c.Query("cascade") == "true")
+	if err != nil {
+		a.respondPgMetaError(c, "function", id, err)
+		return
+	}
+
+	a.logger.Info("Dropped function", "project", c.Param("ref"), "function", function.String("name"))
+	c.JSON(http.StatusOK, function)
+}
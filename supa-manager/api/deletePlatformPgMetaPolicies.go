@@ -0,0 +1,28 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"supamanager.io/supa-manager/pgmeta"
+)
+
+func (a *Api) deletePlatformPgMetaPolicies(c *gin.Context) {
+	id, ok := pgMetaID(c)
+	if !ok {
+		return
+	}
+
+	pool, ok := a.projectPoolFromRequest(c)
+	if !ok {
+		return
+	}
+
+	policy, err := pgmeta.DeletePolicy(c.Request.Context(), pool, id)
+	if err != nil {
+		a.respondPgMetaError(c, "policy", id, err)
+		return
+	}
+
+	a.logger.Info("Dropped policy", "project", c.Param("ref"), "policy", policy.String("name"))
+	c.JSON(http.StatusOK, policy)
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"supamanager.io/supa-manager/pgmeta"
+)
+
+func (a *Api) deletePlatformPgMetaRoles(c *gin.Context) {
+	id, ok := pgMetaID(c)
+	if !ok {
+		return
+	}
+
+	pool, ok := a.projectPoolFromRequest(c)
+	if !ok {
+		return
+	}
+
+	role, err := pgmeta.DeleteRole(c.Request.Context(), pool, id)
+	if err != nil {
+		a.respondPgMetaError(c, "role", id, err)
+		return
+	}
+
+	a.logger.Info("Dropped role", "project", c.Param("ref"), "role", role.String("name"))
+	c.JSON(http.StatusOK, role)
+}
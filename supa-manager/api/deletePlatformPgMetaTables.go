@@ -3,35 +3,26 @@ package api
 import (
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"supamanager.io/supa-manager/pgmeta"
 )
 
 func (a *Api) deletePlatformPgMetaTables(c *gin.Context) {
-	_, err := a.GetAccountFromRequest(c)
-	if err != nil {
-		c.JSON(401, gin.H{"error": "Unauthorized"})
+	id, ok := pgMetaID(c)
+	if !ok {
 		return
 	}
 
-	projectRef := c.Param("ref")
-	tableID := c.Query("id")
-	cascade := c.Query("cascade") == "true"
-
-	if tableID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing required query parameter: id"})
+	pool, ok := a.projectPoolFromRequest(c)
+	if !ok {
 		return
 	}
 
-	// TODO: Phase 3/4 - Connect to actual project PostgreSQL and delete table
-	// For now, return a mock successful response
-
-	a.logger.Info("Table deletion requested (stub)",
-		"project", projectRef,
-		"table_id", tableID,
-		"cascade", cascade)
+	table, err := pgmeta.DeleteTable(c.Request.Context(), pool, id, c.Query("cascade") == "true")
+	if err != nil {
+		a.respondPgMetaError(c, "table", id, err)
+		return
+	}
 
-	// Return success response (Studio expects no content or a simple object)
-	c.JSON(http.StatusOK, gin.H{
-		"id":      tableID,
-		"message": "Table deleted successfully",
-	})
+	a.logger.Info("Dropped table", "project", c.Param("ref"), "schema", table.String("schema"), "table", table.String("name"))
+	c.JSON(http.StatusOK, table)
 }
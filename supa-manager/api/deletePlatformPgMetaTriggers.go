@@ -0,0 +1,28 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"supamanager.io/supa-manager/pgmeta"
+)
+
+func (a *Api) deletePlatformPgMetaTriggers(c *gin.Context) {
+	id, ok := pgMetaID(c)
+	if !ok {
+		return
+	}
+
+	pool, ok := a.projectPoolFromRequest(c)
+	if !ok {
+		return
+	}
+
+	trigger, err := pgmeta.DeleteTrigger(c.Request.Context(), pool, id, c.Query("cascade") == "true")
+	if err != nil {
+		a.respondPgMetaError(c, "trigger", id, err)
+		return
+	}
+
+	a.logger.Info("Dropped trigger", "project", c.Param("ref"), "trigger", trigger.String("name"))
+	c.JSON(http.StatusOK, trigger)
+}
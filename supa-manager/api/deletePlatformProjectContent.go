@@ -0,0 +1,48 @@
+package api
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+func (a *Api) deletePlatformProjectContent(c *gin.Context) {
+	account := currentAccount(c)
+
+	ids := splitQueryList(c.Query("ids"))
+	if len(ids) == 0 {
+		c.JSON(400, gin.H{"error": "Missing required query parameter: ids"})
+		return
+	}
+
+	project := *currentProject(c)
+
+	// Check every item first so a forbidden id does not leave a partial delete behind
+	for _, id := range ids {
+		existing, ok := a.userContentForAccount(c, account, project, id)
+		if !ok {
+			return
+		}
+		allowed, err := a.canOnUserContent(c.Request.Context(), account, project, "write:Delete", existing.OwnerID, existing.Type, existing.Visibility)
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Failed to check content permission: %v", err))
+			c.JSON(500, gin.H{"error": "Internal Server Error"})
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("You do not have permission to delete content %s", id)})
+			return
+		}
+	}
+	for _, id := range ids {
+		if err := a.queries.DeleteUserContent(c.Request.Context(), id); err != nil {
+			a.logger.Error(fmt.Sprintf("Failed to delete content %s: %v", id, err))
+			c.JSON(500, gin.H{"error": "Internal Server Error"})
+			return
+		}
+	}
+
+	a.logger.Info("Deleted content", "project", project.ProjectRef, "ids", strings.Join(ids, ","))
+	c.JSON(http.StatusOK, ids)
+}
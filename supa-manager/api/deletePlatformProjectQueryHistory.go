@@ -0,0 +1,24 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"supamanager.io/supa-manager/database"
+)
+
+func (a *Api) deletePlatformProjectQueryHistory(c *gin.Context) {
+	account := currentAccount(c)
+
+	project := *currentProject(c)
+
+	err := a.queries.DeleteQueryHistory(c.Request.Context(), database.DeleteQueryHistoryParams{
+		ProjectID: project.ID,
+		AccountID: account.ID,
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
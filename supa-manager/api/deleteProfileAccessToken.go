@@ -0,0 +1,33 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"supamanager.io/supa-manager/database"
+)
+
+// deleteProfileAccessToken revokes a personal access token, it stops working immediately
+func (a *Api) deleteProfileAccessToken(c *gin.Context) {
+	account := currentAccount(c)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Access token not found"})
+		return
+	}
+
+	revoked, err := a.queries.RevokePersonalAccessToken(c.Request.Context(), database.RevokePersonalAccessTokenParams{
+		ID:        id,
+		AccountID: account.ID,
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if revoked == 0 {
+		c.JSON(404, gin.H{"error": "Access token not found"})
+		return
+	}
+
+	c.Status(204)
+}
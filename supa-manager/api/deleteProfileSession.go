@@ -0,0 +1,23 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"supamanager.io/supa-manager/database"
+)
+
+// deleteProfileSession signs out a single session of the current account
+func (a *Api) deleteProfileSession(c *gin.Context) {
+	account := currentAccount(c)
+
+	err := a.queries.RevokeSessionFamily(c.Request.Context(), database.RevokeSessionFamilyParams{
+		FamilyID:      c.Param("id"),
+		AccountID:     account.ID,
+		RevokedReason: revokedReason(sessionRevokedLogout),
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	c.Status(204)
+}
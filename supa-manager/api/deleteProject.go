@@ -0,0 +1,20 @@
+package api
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func (a *Api) deleteProject(c *gin.Context) {
+	project := *currentProject(c)
+
+	if err := a.removeProject(c.Request.Context(), project); err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to delete project %s: %v", project.ProjectRef, err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	a.logger.Info("Deleted project", "project", project.ProjectRef, "account", currentAccount(c).ID)
+	c.JSON(http.StatusOK, gin.H{"id": project.ID, "ref": project.ProjectRef, "name": project.ProjectName})
+}
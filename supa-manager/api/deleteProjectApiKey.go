@@ -0,0 +1,52 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"supamanager.io/supa-manager/database"
+)
+
+var errApiKeyNotFound = errors.New("API key not found")
+
+// deleteProjectApiKey revokes a named API key, Kong stops accepting it before this returns
+func (a *Api) deleteProjectApiKey(c *gin.Context) {
+	project := currentProject(c)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "API key not found"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	err = a.changeProjectApiKeys(ctx, project, func(queries *database.Queries, _ database.Project) error {
+		revoked, err := queries.RevokeProjectApiKey(ctx, database.RevokeProjectApiKeyParams{
+			ID:        id,
+			ProjectID: project.ID,
+		})
+		if err != nil {
+			return err
+		}
+		if revoked == 0 {
+			return errApiKeyNotFound
+		}
+		return nil
+	})
+	if errors.Is(err, errApiKeyNotFound) {
+		c.JSON(404, gin.H{"error": "API key not found"})
+		return
+	}
+	if errors.Is(err, errJwtSecretRotating) {
+		c.JSON(409, gin.H{"error": "API keys can't be changed while the JWT secret is being rotated"})
+		return
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to revoke API key %d of project %s: %v", id, project.ProjectRef, err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	c.Status(204)
+}
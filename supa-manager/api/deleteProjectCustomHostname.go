@@ -0,0 +1,33 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// deleteProjectCustomHostname stops serving the project on its custom hostname and removes the
+// hostname's certificate and DNS record
+func (a *Api) deleteProjectCustomHostname(c *gin.Context) {
+	project := currentProject(c)
+
+	ctx := c.Request.Context()
+	custom, err := a.queries.GetCustomHostnameByProject(ctx, project.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(404, gin.H{"error": "Custom hostname not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	if err := a.removeCustomHostname(ctx, project.ProjectRef, custom); err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to delete custom hostname %s: %v", custom.Hostname, err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	c.Status(204)
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"math/rand/v2"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/dnshook"
+	"time"
+)
+
+const (
+	dnsHookOutboxInterval  = 5 * time.Second
+	dnsHookOutboxBatchSize = 50
+	// dnsHookDeliveryTimeout bounds one delivery, the update stays claimed by this replica as long.
+	// Updates are claimed one at a time right before their delivery so the claim can't run out
+	// while the update waits behind others
+	dnsHookDeliveryTimeout = time.Minute
+	dnsHookRetryBase       = 10 * time.Second
+	dnsHookRetryMax        = time.Hour
+)
+
+// enqueueDnsUpdate records an update for the DNS hook. Pass the queries of the transaction making
+// the change, so the update is sent if and only if the change is committed. Nothing is recorded
+// when no hook is configured
+func (a *Api) enqueueDnsUpdate(ctx context.Context, queries *database.Queries, update dnshook.Update) error {
+	if a.dnsHook == nil {
+		return nil
+	}
+	return queries.EnqueueDnsHookUpdate(ctx, database.EnqueueDnsHookUpdateParams{
+		Type:       string(update.Type),
+		Hostname:   update.Hostname,
+		ProjectRef: pgtype.Text{String: update.ProjectRef, Valid: update.ProjectRef != ""},
+		RecordType: pgtype.Text{String: update.RecordType, Valid: update.RecordType != ""},
+		Value:      pgtype.Text{String: update.Value, Valid: update.Value != ""},
+	})
+}
+
+// projectDnsUpdate is the update about the record pointing a project's hostname at the edge
+func (a *Api) projectDnsUpdate(updateType dnshook.UpdateType, projectRef string) dnshook.Update {
+	return dnshook.Update{
+		Type:       updateType,
+		Hostname:   a.customHostnameTarget(projectRef),
+		ProjectRef: projectRef,
+	}
+}
+
+// dnsHookRetryDelay doubles with each failed attempt up to dnsHookRetryMax, with jitter so updates
+// that failed together don't all come back at once
+func dnsHookRetryDelay(attempts int32) time.Duration {
+	delay := dnsHookRetryMax
+	if attempts < 10 {
+		delay = min(dnsHookRetryBase<<attempts, dnsHookRetryMax)
+	}
+	return delay/2 + rand.N(delay/2)
+}
+
+// runDnsHookOutboxJob delivers the recorded updates. Each is claimed first so replicas don't send
+// it twice, and a hostname's updates wait for its earlier ones, a DELETE never overtakes its CREATE
+// unless the CREATE was given up on
+func (a *Api) runDnsHookOutboxJob(ctx context.Context) {
+	ticker := time.NewTicker(dnsHookOutboxInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for range dnsHookOutboxBatchSize {
+			if !a.deliverNextDnsUpdate(ctx) {
+				break
+			}
+		}
+	}
+}
+
+// deliverNextDnsUpdate claims and delivers one due update, it reports false when none was due
+func (a *Api) deliverNextDnsUpdate(ctx context.Context) bool {
+	update, err := a.queries.ClaimDueDnsHookUpdate(ctx, pgtype.Timestamptz{Time: time.Now().Add(dnsHookDeliveryTimeout), Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to claim DNS hook updates: %v", err))
+		return false
+	}
+	a.deliverDnsUpdate(ctx, update)
+	return true
+}
+
+func (a *Api) deliverDnsUpdate(ctx context.Context, update database.DnsHookOutbox) {
+	sendCtx, cancel := context.WithTimeout(ctx, dnsHookDeliveryTimeout)
+	err := a.dnsHook.Send(sendCtx, dnshook.Update{
+		Type:       dnshook.UpdateType(update.Type),
+		Hostname:   update.Hostname,
+		ProjectRef: update.ProjectRef.String,
+		RecordType: update.RecordType.String,
+		Value:      update.Value.String,
+	})
+	cancel()
+
+	if err == nil {
+		if err := a.queries.DeleteDnsHookUpdate(ctx, update.ID); err != nil {
+			a.logger.Error(fmt.Sprintf("Failed to remove delivered DNS hook update %d: %v", update.ID, err))
+		}
+		return
+	}
+
+	var statusErr *dnshook.StatusError
+	if (errors.As(err, &statusErr) && !statusErr.Retryable()) || update.Attempts+1 >= a.config.Domain.DnsHookMaxAttempts {
+		a.logger.Error(fmt.Sprintf("Giving up on DNS %s of %s after %d attempts: %v", update.Type, update.Hostname, update.Attempts+1, err))
+		if err := a.queries.FailDnsHookUpdate(ctx, database.FailDnsHookUpdateParams{
+			ID:        update.ID,
+			LastError: pgtype.Text{String: err.Error(), Valid: true},
+		}); err != nil {
+			a.logger.Error(fmt.Sprintf("Failed to mark DNS hook update %d as failed: %v", update.ID, err))
+		}
+		return
+	}
+
+	delay := dnsHookRetryDelay(update.Attempts)
+	a.logger.Warn(fmt.Sprintf("Failed to deliver DNS %s of %s, retrying in %s: %v", update.Type, update.Hostname, delay.Round(time.Second), err))
+	if err := a.queries.RetryDnsHookUpdate(ctx, database.RetryDnsHookUpdateParams{
+		ID:            update.ID,
+		NextAttemptAt: pgtype.Timestamptz{Time: time.Now().Add(delay), Valid: true},
+		LastError:     pgtype.Text{String: err.Error(), Valid: true},
+	}); err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to reschedule DNS hook update %d: %v", update.ID, err))
+	}
+}
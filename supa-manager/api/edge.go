@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/edge"
+	"time"
+)
+
+// The containers of projects in these statuses are up, a migration or function deployment
+// doesn't stop them
+var edgeActiveStatuses = map[string]bool{
+	StatusActiveHealthy:     true,
+	StatusActiveUnhealthy:   true,
+	StatusRunningMigrations: true,
+	StatusMigrationsPassed:  true,
+	StatusMigrationsFailed:  true,
+	StatusFunctionsDeployed: true,
+	StatusFunctionsFailed:   true,
+}
+
+// Projects in these statuses are stopped until restored, the others that aren't active are on
+// their way up or down
+var edgePausedStatuses = map[string]bool{
+	StatusInactive:  true,
+	StatusPausing:   true,
+	StatusGoingDown: true,
+}
+
+// edgeRoute looks up where the edge proxy sends the requests of a project
+func (a *Api) edgeRoute(ctx context.Context, ref string) (edge.Route, error) {
+	project, err := a.queries.GetProjectByRef(ctx, ref)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && project.Status == StatusRemoved) {
+		return edge.Route{}, edge.ErrNotFound
+	}
+	if err != nil {
+		return edge.Route{}, err
+	}
+	return projectEdgeRoute(project), nil
+}
+
+// edgeHostRoute looks up the project of an active custom hostname
+func (a *Api) edgeHostRoute(ctx context.Context, hostname string) (edge.Route, error) {
+	custom, err := a.queries.GetActiveCustomHostname(ctx, hostname)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return edge.Route{}, edge.ErrNotFound
+	}
+	if err != nil {
+		return edge.Route{}, err
+	}
+	project, err := a.queries.GetProjectByID(ctx, custom.ProjectID)
+	if err != nil {
+		return edge.Route{}, err
+	}
+	if project.Status == StatusRemoved {
+		return edge.Route{}, edge.ErrNotFound
+	}
+	return projectEdgeRoute(project), nil
+}
+
+func projectEdgeRoute(project database.Project) edge.Route {
+	route := edge.Route{Ref: project.ProjectRef, Port: project.KongHttpPort.Int32}
+	switch {
+	case edgeActiveStatuses[project.Status]:
+		route.State = edge.StateActive
+	case edgePausedStatuses[project.Status]:
+		route.State = edge.StatePaused
+	default:
+		route.State = edge.StateUnavailable
+	}
+	return route
+}
+
+// invalidateEdgeRoute makes the edge proxy of this replica look the project up again, by its
+// subdomain and by its custom hostname
+func (a *Api) invalidateEdgeRoute(ref string) {
+	if a.edgeRoutes != nil {
+		a.edgeRoutes.Invalidate(ref)
+	}
+	if a.edgeHosts != nil {
+		a.edgeHosts.InvalidateRoutesOf(ref)
+	}
+}
+
+// invalidateEdgeHost makes the edge proxy of this replica look a custom hostname up again
+func (a *Api) invalidateEdgeHost(hostname string) {
+	if a.edgeHosts != nil {
+		a.edgeHosts.Invalidate(hostname)
+	}
+}
+
+// RunEdge serves the edge proxy until a listener fails. Requests that aren't for a project are
+// served by fallback, usually the API router. HTTPS uses the ACME certificates when enabled,
+// otherwise the configured certificate files
+func (a *Api) RunEdge(fallback http.Handler) error {
+	settings := a.config.Edge
+	var handler http.Handler = edge.New(a.config.Domain.Base, settings.UpstreamHost, a.edgeRoutes, a.edgeHosts, fallback, a.logger)
+
+	errs := make(chan error, 2)
+	httpHandler := handler
+	if a.certs != nil {
+		httpHandler = a.certs.HTTPHandler(handler)
+	}
+	go func() {
+		errs <- newEdgeServer(settings.HttpAddress, httpHandler).ListenAndServe()
+	}()
+	a.logger.Info("Edge proxy listening", "address", settings.HttpAddress)
+
+	switch {
+	case a.certs != nil:
+		server := newEdgeServer(settings.HttpsAddress, handler)
+		server.TLSConfig = &tls.Config{GetCertificate: a.certs.GetCertificate, MinVersion: tls.VersionTLS12}
+		go func() {
+			errs <- server.ListenAndServeTLS("", "")
+		}()
+		a.logger.Info("Edge proxy listening", "address", settings.HttpsAddress, "tls", "acme")
+	case settings.TlsCertFile != "" && settings.TlsKeyFile != "":
+		go func() {
+			errs <- newEdgeServer(settings.HttpsAddress, handler).ListenAndServeTLS(settings.TlsCertFile, settings.TlsKeyFile)
+		}()
+		a.logger.Info("Edge proxy listening", "address", settings.HttpsAddress, "tls", "files")
+	}
+	return <-errs
+}
+
+// newEdgeServer has no write or idle read timeouts, Realtime keeps WebSockets open for hours
+func newEdgeServer(address string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              address,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+}
@@ -0,0 +1,21 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// getGotrueSettings tells Studio which sign in methods are available
+func (a *Api) getGotrueSettings(c *gin.Context) {
+	c.JSON(200, gin.H{
+		"external": gin.H{
+			"email": true,
+			"sso":   a.sso != nil,
+		},
+		"disable_signup":     !a.config.AllowSignup,
+		"mailer_autoconfirm": a.config.Auth.Autoconfirm,
+		"sso": gin.H{
+			"enabled":                   a.sso != nil,
+			"password_disabled_domains": a.config.Sso.PasswordDisabledDomains,
+		},
+	})
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// getGotrueSsoAuthorize starts a single sign-on login by redirecting the browser to the identity provider
+func (a *Api) getGotrueSsoAuthorize(c *gin.Context) {
+	if a.sso == nil {
+		c.JSON(404, gin.H{"error": "Single sign-on is not enabled"})
+		return
+	}
+
+	target, err := a.ssoAuthorizeURL(c.Request.Context(), c.Query("redirect_to"))
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to start sso login: %v", err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	c.Redirect(http.StatusSeeOther, target)
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+	"net/url"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/sso"
+	"supamanager.io/supa-manager/utils"
+)
+
+// getGotrueSsoCallback is the redirect URI registered at the identity provider. It signs the account in
+// and redirects to Studio with the session in the URL fragment, like getGotrueVerify
+func (a *Api) getGotrueSsoCallback(c *gin.Context) {
+	if a.sso == nil {
+		c.JSON(404, gin.H{"error": "Single sign-on is not enabled"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	fragment := url.Values{}
+	fail := func(redirectTo string, description string) {
+		fragment.Set("error", "access_denied")
+		fragment.Set("error_code", "403")
+		fragment.Set("error_description", description)
+		c.Redirect(http.StatusSeeOther, a.redirectURL(redirectTo)+"#"+fragment.Encode())
+	}
+
+	// The user declined or the provider failed, the state is still spent so it can't be replayed
+	if providerError := c.Query("error"); providerError != "" {
+		stored, _ := a.queries.ConsumeSsoState(ctx, utils.HashToken(c.Query("state")))
+		fail(stored.RedirectTo, fmt.Sprintf("%s: %s", providerError, c.Query("error_description")))
+		return
+	}
+
+	stored, claims, err := a.ssoCallback(ctx, c.Query("state"), c.Query("code"))
+	if errors.Is(err, errSsoStateInvalid) {
+		fail(stored.RedirectTo, err.Error())
+		return
+	}
+	if err != nil {
+		if !errors.Is(err, sso.ErrInvalidIDToken) {
+			a.logger.Error(fmt.Sprintf("Failed to complete sso login: %v", err))
+		}
+		fail(stored.RedirectTo, "Single sign-on failed")
+		return
+	}
+
+	account, session, refreshToken, err := a.ssoSignIn(ctx, c, claims)
+	if err != nil {
+		if errors.Is(err, errSsoAccountMissing) || errors.Is(err, errSsoEmailUnlinked) || errors.Is(err, errSsoEmailMissing) {
+			fail(stored.RedirectTo, err.Error())
+			return
+		}
+		a.logger.Error(fmt.Sprintf("Failed to sign in sso account: %v", err))
+		fail(stored.RedirectTo, "Single sign-on failed")
+		return
+	}
+
+	response, err := a.sessionResponse(ctx, account, session, refreshToken)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	for _, key := range []string{"access_token", "expires_in", "expires_at", "refresh_token", "token_type"} {
+		fragment.Set(key, fmt.Sprint(response[key]))
+	}
+	c.Redirect(http.StatusSeeOther, a.redirectURL(stored.RedirectTo)+"#"+fragment.Encode())
+}
+
+// ssoSignIn resolves the account of the identity and creates its session in one transaction
+func (a *Api) ssoSignIn(ctx context.Context, c *gin.Context, claims sso.Claims) (database.Account, database.Session, string, error) {
+	tx, err := a.pgPool.Begin(ctx)
+	if err != nil {
+		return database.Account{}, database.Session{}, "", err
+	}
+	defer tx.Rollback(ctx)
+	queries := a.queries.WithTx(tx)
+
+	account, err := a.ssoAccount(ctx, queries, claims)
+	if err != nil {
+		return database.Account{}, database.Session{}, "", err
+	}
+	session, refreshToken, err := a.createSession(ctx, queries, c, account.ID, utils.NewUUID(), pgtype.Text{},
+		SessionGrant{AAL: aal1, AMR: []string{"sso/oidc"}})
+	if err != nil {
+		return database.Account{}, database.Session{}, "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return database.Account{}, database.Session{}, "", err
+	}
+	return account, session, refreshToken, nil
+}
@@ -0,0 +1,17 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func (a *Api) getGotrueUser(c *gin.Context) {
+	account := currentAccount(c)
+
+	factors, err := a.queries.GetMfaFactors(c.Request.Context(), account.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	c.JSON(200, gotrueUser(*account, factors))
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/url"
+)
+
+// getGotrueVerify is the target of emailed links. It consumes the token and redirects to Studio with
+// the new session in the URL fragment, like GoTrue
+func (a *Api) getGotrueVerify(c *gin.Context) {
+	purpose := c.Query("type")
+	redirectTo := a.redirectURL(c.Query("redirect_to"))
+
+	account, session, refreshToken, err := a.useAccountToken(c.Request.Context(), c, purpose, c.Query("token"))
+	fragment := url.Values{}
+	if err != nil {
+		if !errors.Is(err, errInvalidAccountToken) && !errors.Is(err, errEmailInUse) {
+			a.logger.Error(fmt.Sprintf("Failed to verify %s link: %v", purpose, err))
+		}
+		fragment.Set("error", "access_denied")
+		fragment.Set("error_code", "403")
+		fragment.Set("error_description", err.Error())
+		c.Redirect(http.StatusSeeOther, redirectTo+"#"+fragment.Encode())
+		return
+	}
+
+	response, err := a.sessionResponse(c.Request.Context(), account, session, refreshToken)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	for _, key := range []string{"access_token", "expires_in", "expires_at", "refresh_token", "token_type"} {
+		fragment.Set(key, fmt.Sprint(response[key]))
+	}
+	fragment.Set("type", purpose)
+	c.Redirect(http.StatusSeeOther, redirectTo+"#"+fragment.Encode())
+}
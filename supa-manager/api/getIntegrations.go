@@ -6,13 +6,6 @@ import (
 )
 
 func (a *Api) getIntegrations(c *gin.Context) {
-	_, err := a.GetAccountFromRequest(c)
-	if err != nil {
-		println(err.Error())
-		c.JSON(401, gin.H{"error": "Unauthorized"})
-		return
-	}
-
 	// orgId := c.Param("id")
 	// expand := c.Query("expand")
 
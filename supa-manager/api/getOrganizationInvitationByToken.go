@@ -0,0 +1,52 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"strings"
+	"supamanager.io/supa-manager/utils"
+	"time"
+)
+
+type OrganizationInvitationDetails struct {
+	OrganizationName  string `json:"organization_name"`
+	InviteID          int64  `json:"invite_id,omitempty"`
+	TokenDoesNotExist bool   `json:"token_does_not_exist"`
+	ExpiredToken      bool   `json:"expired_token"`
+	EmailMismatch     bool   `json:"email_mismatch"`
+	SsoMismatch       bool   `json:"sso_mismatch"`
+	AuthorizedUser    bool   `json:"authorized_user"`
+}
+
+// getOrganizationInvitationByToken tells Studio's join page whether the signed-in account can
+// accept an invitation. The invitee isn't a member yet, so this route doesn't load the organization
+func (a *Api) getOrganizationInvitationByToken(c *gin.Context) {
+	account := currentAccount(c)
+	ctx := c.Request.Context()
+
+	org, err := a.queries.GetOrganizationById(ctx, c.Param("slug"))
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	invitation, err := a.queries.GetOrganizationInvitationByToken(ctx, utils.HashToken(c.Param("token")))
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && invitation.OrganizationID != org.ID) {
+		c.JSON(http.StatusOK, OrganizationInvitationDetails{TokenDoesNotExist: true})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	details := OrganizationInvitationDetails{
+		OrganizationName: org.Name,
+		InviteID:         invitation.ID,
+		ExpiredToken:     time.Now().After(invitation.ExpiresAt.Time),
+		EmailMismatch:    !strings.EqualFold(invitation.InvitedEmail, account.Email) || !account.EmailConfirmedAt.Valid,
+	}
+	details.AuthorizedUser = !details.ExpiredToken && !details.EmailMismatch
+	c.JSON(http.StatusOK, details)
+}
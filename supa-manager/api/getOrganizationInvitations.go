@@ -0,0 +1,22 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func (a *Api) getOrganizationInvitations(c *gin.Context) {
+	org := currentOrganization(c)
+
+	rows, err := a.queries.GetPendingOrganizationInvitations(c.Request.Context(), org.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	invitations := make([]OrganizationInvitation, 0, len(rows))
+	for _, row := range rows {
+		invitations = append(invitations, organizationInvitationResponse(row))
+	}
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
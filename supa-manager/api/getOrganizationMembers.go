@@ -0,0 +1,30 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"supamanager.io/supa-manager/permisions"
+)
+
+func (a *Api) getOrganizationMembers(c *gin.Context) {
+	org := currentOrganization(c)
+
+	rows, err := a.queries.GetOrganizationMembers(c.Request.Context(), org.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	members := make([]OrganizationMember, 0, len(rows))
+	for _, row := range rows {
+		members = append(members, OrganizationMember{
+			GotrueID:     row.GotrueID,
+			PrimaryEmail: row.Email,
+			Username:     row.Username,
+			RoleIDs:      []int32{permisions.RoleID(row.Role)},
+			MfaEnabled:   row.MfaEnabled,
+			IsSsoUser:    row.IsSsoUser,
+		})
+	}
+	c.JSON(http.StatusOK, members)
+}
@@ -6,11 +6,5 @@ import (
 )
 
 func (a *Api) getOrganizationMembersReachedFreeProjectLimit(c *gin.Context) {
-	_, err := a.GetAccountFromRequest(c)
-	if err != nil {
-		c.JSON(401, gin.H{"error": "Unauthorized"})
-		return
-	}
-
 	c.JSON(http.StatusOK, []interface{}{})
 }
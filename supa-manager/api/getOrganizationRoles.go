@@ -0,0 +1,31 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"supamanager.io/supa-manager/permisions"
+)
+
+type OrganizationRole struct {
+	ID          int32   `json:"id"`
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+	BaseRoleID  int32   `json:"base_role_id"`
+	ProjectIDs  []int32 `json:"project_ids"`
+}
+
+// getOrganizationRoles lists the roles members can be given, Studio refers to them by id
+func (a *Api) getOrganizationRoles(c *gin.Context) {
+	roles := make([]OrganizationRole, 0, len(permisions.Roles))
+	for _, role := range permisions.Roles {
+		roles = append(roles, OrganizationRole{
+			ID:         permisions.RoleID(role),
+			Name:       permisions.RoleName(role),
+			BaseRoleID: permisions.RoleID(role),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"org_scoped_roles":     roles,
+		"project_scoped_roles": []OrganizationRole{},
+	})
+}
@@ -2,28 +2,26 @@ package api
 
 import (
 	"github.com/gin-gonic/gin"
-	"strings"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/permisions"
 )
 
 type Organization struct {
-	Slug              string        `json:"slug"`
-	Name              string        `json:"name"`
-	StripeCustomerId  string        `json:"stripe_customer_id"`
-	SubscriptionId    string        `json:"subscription_id"`
-	BillingEmail      interface{}   `json:"billing_email"`
-	IsOwner           bool          `json:"is_owner"`
-	OptInTags         []interface{} `json:"opt_in_tags"`
-	Id                int32         `json:"id"`
-	RestrictionData   interface{}   `json:"restriction_data"`
-	RestrictionStatus interface{}   `json:"restriction_status"`
+	Slug              string           `json:"slug"`
+	Name              string           `json:"name"`
+	StripeCustomerId  string           `json:"stripe_customer_id"`
+	SubscriptionId    string           `json:"subscription_id"`
+	BillingEmail      interface{}      `json:"billing_email"`
+	IsOwner           bool             `json:"is_owner"`
+	OptInTags         []string         `json:"opt_in_tags"`
+	Id                int32            `json:"id"`
+	Plan              OrganizationPlan `json:"plan"`
+	RestrictionData   interface{}      `json:"restriction_data"`
+	RestrictionStatus interface{}      `json:"restriction_status"`
 }
 
 func (a *Api) getOrganizations(c *gin.Context) {
-	account, err := a.GetAccountFromRequest(c)
-	if err != nil {
-		c.JSON(401, gin.H{"error": "Unauthorized"})
-		return
-	}
+	account := currentAccount(c)
 
 	orgs, err := a.queries.GetOrganizationsForAccountId(c, account.ID)
 	if err != nil {
@@ -33,18 +31,18 @@ func (a *Api) getOrganizations(c *gin.Context) {
 
 	supaOrgs := []Organization{}
 	for _, org := range orgs {
-		supaOrgs = append(supaOrgs, Organization{
-			Slug:              org.Slug,
-			Name:              org.Name,
-			StripeCustomerId:  "",
-			SubscriptionId:    "",
-			BillingEmail:      "billing@supamanager.io",
-			IsOwner:           strings.ToLower(org.MemberRole) == "owner",
-			OptInTags:         []interface{}{},
-			Id:                org.ID,
-			RestrictionData:   nil,
-			RestrictionStatus: nil,
-		})
+		supaOrgs = append(supaOrgs, organizationResponse(database.Organization{
+			ID:           org.ID,
+			Slug:         org.Slug,
+			Name:         org.Name,
+			CreatedAt:    org.CreatedAt,
+			UpdatedAt:    org.UpdatedAt,
+			Kind:         org.Kind,
+			Size:         org.Size,
+			Plan:         org.Plan,
+			BillingEmail: org.BillingEmail,
+			OptInTags:    org.OptInTags,
+		}, org.MemberRole == permisions.RoleOwner))
 	}
 
 	c.JSON(200, supaOrgs)
@@ -6,13 +6,6 @@ import (
 )
 
 func (a *Api) getPlatformIntegrationRepositories(c *gin.Context) {
-	_, err := a.GetAccountFromRequest(c)
-	if err != nil {
-		println(err.Error())
-		c.JSON(401, gin.H{"error": "Unauthorized"})
-		return
-	}
-
 	// integration := c.Param("integration") // e.g., "github"
 
 	// Return empty repositories list
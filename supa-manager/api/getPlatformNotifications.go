@@ -6,12 +6,5 @@ import (
 )
 
 func (a *Api) getPlatformNotifications(c *gin.Context) {
-	_, err := a.GetAccountFromRequest(c)
-	if err != nil {
-		println(err.Error())
-		c.JSON(401, gin.H{"error": "Unauthorized"})
-		return
-	}
-
 	c.JSON(http.StatusOK, []interface{}{})
 }
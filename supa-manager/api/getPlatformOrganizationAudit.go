@@ -0,0 +1,47 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// getPlatformOrganizationAudit lists the organization's audit events, newest first. next_cursor
+// is passed as cursor to get the following page and is null on the last one
+func (a *Api) getPlatformOrganizationAudit(c *gin.Context) {
+	params, ok := auditEventsFilter(c, currentOrganization(c))
+	if !ok {
+		return
+	}
+
+	limit := auditDefaultLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > auditMaxLimit {
+			c.JSON(400, gin.H{"error": "Invalid limit, expected 1 to " + strconv.Itoa(auditMaxLimit)})
+			return
+		}
+		limit = parsed
+	}
+	// One more than asked tells whether there is a next page
+	params.MaxEvents = int32(limit + 1)
+
+	rows, err := a.queries.GetAuditEvents(c.Request.Context(), params)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	var nextCursor *string
+	if len(rows) > limit {
+		rows = rows[:limit]
+		cursor := strconv.FormatInt(rows[limit-1].ID, 10)
+		nextCursor = &cursor
+	}
+
+	events := make([]AuditEvent, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, auditEventResponse(row))
+	}
+	c.JSON(http.StatusOK, gin.H{"result": events, "next_cursor": nextCursor})
+}
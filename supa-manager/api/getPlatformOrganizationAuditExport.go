@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// auditExportTrailer is the last line of an export, an importer can tell a complete export from
+// one that was cut short by it
+type auditExportTrailer struct {
+	Complete bool   `json:"complete"`
+	Events   int    `json:"events"`
+	Error    string `json:"error,omitempty"`
+}
+
+// getPlatformOrganizationAuditExport streams the organization's audit events matching the filters
+// as JSON Lines, one event per line and newest first, for ingestion by a SIEM. The last line is
+// an auditExportTrailer
+func (a *Api) getPlatformOrganizationAuditExport(c *gin.Context) {
+	org := currentOrganization(c)
+	params, ok := auditEventsFilter(c, org)
+	if !ok {
+		return
+	}
+	params.MaxEvents = auditExportBatch
+
+	ctx := c.Request.Context()
+	rows, err := a.queries.GetAuditEvents(ctx, params)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	filename := fmt.Sprintf("audit-%s-%s.jsonl", org.Slug, time.Now().UTC().Format("20060102T150405Z"))
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	// Once streaming has started the status is sent, a failure is reported by the trailer
+	encoder := json.NewEncoder(c.Writer)
+	trailer := auditExportTrailer{Complete: true}
+	for len(rows) > 0 {
+		for _, row := range rows {
+			if err := encoder.Encode(auditEventResponse(row)); err != nil {
+				// The client is gone, nothing more reaches it
+				return
+			}
+			trailer.Events++
+		}
+		c.Writer.Flush()
+		if len(rows) < auditExportBatch {
+			break
+		}
+
+		params.BeforeID.Int64, params.BeforeID.Valid = rows[len(rows)-1].ID, true
+		rows, err = a.queries.GetAuditEvents(ctx, params)
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Failed to export audit events of organization %s: %v", org.Slug, err))
+			trailer = auditExportTrailer{Events: trailer.Events, Error: "The export was interrupted, export the remaining events again"}
+			break
+		}
+	}
+	encoder.Encode(trailer)
+}
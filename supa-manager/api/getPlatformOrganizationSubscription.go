@@ -20,11 +20,7 @@ type PlatformSubscriptionOrganizationSubscriptionBody struct {
 }
 
 func (a *Api) getPlatformOrganizationSubscription(c *gin.Context) {
-	_, err := a.GetAccountFromRequest(c)
-	if err != nil {
-		c.JSON(401, gin.H{"error": "Unauthorized"})
-		return
-	}
+	org := currentOrganization(c)
 
 	c.JSON(200, PlatformSubscriptionOrganizationSubscriptionBody{
 		NanoEnabled:        false,
@@ -37,8 +33,8 @@ func (a *Api) getPlatformOrganizationSubscription(c *gin.Context) {
 			Id   string `json:"id"`
 			Name string `json:"name"`
 		}{
-			Id:   "enterprise",
-			Name: "Enterprise",
+			Id:   org.Plan,
+			Name: organizationPlanNames[org.Plan],
 		},
 		UsageBillingEnabled: false,
 		Addons:              []interface{}{},
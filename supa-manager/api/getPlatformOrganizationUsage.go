@@ -6,13 +6,6 @@ import (
 )
 
 func (a *Api) getPlatformOrganizationUsage(c *gin.Context) {
-	_, err := a.GetAccountFromRequest(c)
-	if err != nil {
-		println(err.Error())
-		c.JSON(401, gin.H{"error": "Unauthorized"})
-		return
-	}
-
 	// orgId := c.Param("slug") // Organization ID
 
 	// Return empty usage data
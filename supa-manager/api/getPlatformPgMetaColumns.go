@@ -0,0 +1,37 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"supamanager.io/supa-manager/pgmeta"
+)
+
+func (a *Api) getPlatformPgMetaColumns(c *gin.Context) {
+	pool, ok := a.projectPoolFromRequest(c)
+	if !ok {
+		return
+	}
+
+	if id := c.Query("id"); id != "" {
+		column, err := pgmeta.RetrieveColumn(c.Request.Context(), pool, id)
+		if err != nil {
+			a.respondPgMetaError(c, "column", id, err)
+			return
+		}
+		c.JSON(http.StatusOK, column)
+		return
+	}
+
+	tableID, _ := strconv.ParseInt(c.Query("table_id"), 10, 64)
+	columns, err := pgmeta.ListColumns(c.Request.Context(), pool, pgmeta.ColumnListOptions{
+		ListOptions: pgMetaListOptions(c),
+		TableID:     tableID,
+	})
+	if err != nil {
+		a.respondQueryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, columns)
+}
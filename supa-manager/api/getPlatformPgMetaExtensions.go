@@ -0,0 +1,43 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"supamanager.io/supa-manager/pgmeta"
+)
+
+func (a *Api) getPlatformPgMetaExtensions(c *gin.Context) {
+	pool, ok := a.projectPoolFromRequest(c)
+	if !ok {
+		return
+	}
+
+	if id := c.Query("id"); id != "" {
+		extension, err := pgmeta.RetrieveExtension(c.Request.Context(), pool, id)
+		if err != nil {
+			a.respondExtensionError(c, id, err)
+			return
+		}
+		c.JSON(http.StatusOK, extension)
+		return
+	}
+
+	extensions, err := pgmeta.ListExtensions(c.Request.Context(), pool, pgMetaListOptions(c))
+	if err != nil {
+		a.respondQueryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, extensions)
+}
+
+// respondExtensionError reports a missing extension the way pg-meta does, anything else as a query error
+func (a *Api) respondExtensionError(c *gin.Context, name string, err error) {
+	if errors.Is(err, pgmeta.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Cannot find an extension named %s", name)})
+		return
+	}
+	a.respondQueryError(c, err)
+}
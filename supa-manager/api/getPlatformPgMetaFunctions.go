@@ -0,0 +1,36 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"supamanager.io/supa-manager/pgmeta"
+)
+
+func (a *Api) getPlatformPgMetaFunctions(c *gin.Context) {
+	pool, ok := a.projectPoolFromRequest(c)
+	if !ok {
+		return
+	}
+
+	if c.Query("id") != "" {
+		id, ok := pgMetaID(c)
+		if !ok {
+			return
+		}
+		function, err := pgmeta.RetrieveFunction(c.Request.Context(), pool, id)
+		if err != nil {
+			a.respondPgMetaError(c, "function", id, err)
+			return
+		}
+		c.JSON(http.StatusOK, function)
+		return
+	}
+
+	functions, err := pgmeta.ListFunctions(c.Request.Context(), pool, pgMetaListOptions(c))
+	if err != nil {
+		a.respondQueryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, functions)
+}
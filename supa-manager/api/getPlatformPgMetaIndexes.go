@@ -0,0 +1,36 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"supamanager.io/supa-manager/pgmeta"
+)
+
+func (a *Api) getPlatformPgMetaIndexes(c *gin.Context) {
+	pool, ok := a.projectPoolFromRequest(c)
+	if !ok {
+		return
+	}
+
+	if c.Query("id") != "" {
+		id, ok := pgMetaID(c)
+		if !ok {
+			return
+		}
+		index, err := pgmeta.RetrieveIndex(c.Request.Context(), pool, id)
+		if err != nil {
+			a.respondPgMetaError(c, "index", id, err)
+			return
+		}
+		c.JSON(http.StatusOK, index)
+		return
+	}
+
+	indexes, err := pgmeta.ListIndexes(c.Request.Context(), pool, pgMetaListOptions(c))
+	if err != nil {
+		a.respondQueryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, indexes)
+}
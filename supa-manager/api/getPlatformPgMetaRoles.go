@@ -0,0 +1,39 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"supamanager.io/supa-manager/pgmeta"
+)
+
+func (a *Api) getPlatformPgMetaRoles(c *gin.Context) {
+	pool, ok := a.projectPoolFromRequest(c)
+	if !ok {
+		return
+	}
+
+	if c.Query("id") != "" {
+		id, ok := pgMetaID(c)
+		if !ok {
+			return
+		}
+		role, err := pgmeta.RetrieveRole(c.Request.Context(), pool, id)
+		if err != nil {
+			a.respondPgMetaError(c, "role", id, err)
+			return
+		}
+		c.JSON(http.StatusOK, role)
+		return
+	}
+
+	roles, err := pgmeta.ListRoles(c.Request.Context(), pool, pgmeta.RoleListOptions{
+		ListOptions:         pgMetaListOptions(c),
+		IncludeDefaultRoles: c.Query("include_default_roles") == "true",
+	})
+	if err != nil {
+		a.respondQueryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"supamanager.io/supa-manager/pgmeta"
+)
+
+func (a *Api) getPlatformPgMetaTriggers(c *gin.Context) {
+	pool, ok := a.projectPoolFromRequest(c)
+	if !ok {
+		return
+	}
+
+	if c.Query("id") != "" {
+		id, ok := pgMetaID(c)
+		if !ok {
+			return
+		}
+		trigger, err := pgmeta.RetrieveTrigger(c.Request.Context(), pool, id)
+		if err != nil {
+			a.respondPgMetaError(c, "trigger", id, err)
+			return
+		}
+		c.JSON(http.StatusOK, trigger)
+		return
+	}
+
+	triggers, err := pgmeta.ListTriggers(c.Request.Context(), pool, pgMetaListOptions(c))
+	if err != nil {
+		a.respondQueryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, triggers)
+}
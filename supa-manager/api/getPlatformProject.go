@@ -6,12 +6,6 @@ import (
 )
 
 func (a *Api) getPlatformProject(c *gin.Context) {
-	_, err := a.GetAccountFromRequest(c)
-	if err != nil {
-		c.JSON(401, gin.H{"error": "Unauthorized"})
-		return
-	}
-
 	projectRef := c.Param("ref")
 	project, err := a.queries.GetProjectByRef(c, projectRef)
 	if err != nil {
@@ -6,13 +6,6 @@ import (
 )
 
 func (a *Api) getPlatformProjectBillingAddons(c *gin.Context) {
-	_, err := a.GetAccountFromRequest(c)
-	if err != nil {
-		println(err.Error())
-		c.JSON(401, gin.H{"error": "Unauthorized"})
-		return
-	}
-
 	// Return empty addons array
 	// TODO: Implement billing addons (compute, storage, bandwidth upgrades) in future phases
 	c.JSON(http.StatusOK, gin.H{
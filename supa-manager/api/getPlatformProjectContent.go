@@ -0,0 +1,34 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+	"supamanager.io/supa-manager/database"
+)
+
+func (a *Api) getPlatformProjectContent(c *gin.Context) {
+	account := currentAccount(c)
+
+	project := *currentProject(c)
+
+	contentType := pgtype.Text{}
+	if t := c.Query("type"); t != "" {
+		contentType = pgtype.Text{String: t, Valid: true}
+	}
+	rows, err := a.queries.GetVisibleUserContent(c.Request.Context(), database.GetVisibleUserContentParams{
+		ProjectID:   project.ID,
+		AccountID:   account.ID,
+		ContentType: contentType,
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	content := make([]UserContent, len(rows))
+	for i, row := range rows {
+		content[i] = userContentFromRow(row)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": content})
+}
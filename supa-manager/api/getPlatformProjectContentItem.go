@@ -0,0 +1,19 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func (a *Api) getPlatformProjectContentItem(c *gin.Context) {
+	account := currentAccount(c)
+
+	project := *currentProject(c)
+
+	row, ok := a.userContentForAccount(c, account, project, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, userContentFromRow(row))
+}
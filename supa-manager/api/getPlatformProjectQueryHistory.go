@@ -0,0 +1,56 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"supamanager.io/supa-manager/database"
+	"time"
+)
+
+type QueryHistoryEntry struct {
+	ID           int64     `json:"id"`
+	Query        string    `json:"query"`
+	Success      bool      `json:"success"`
+	ErrorMessage *string   `json:"error_message"`
+	RowCount     int32     `json:"row_count"`
+	DurationMs   int32     `json:"duration_ms"`
+	ExecutedAt   time.Time `json:"executed_at"`
+}
+
+func (a *Api) getPlatformProjectQueryHistory(c *gin.Context) {
+	account := currentAccount(c)
+
+	project := *currentProject(c)
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	rows, err := a.queries.GetQueryHistory(c.Request.Context(), database.GetQueryHistoryParams{
+		ProjectID: project.ID,
+		AccountID: account.ID,
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	history := make([]QueryHistoryEntry, len(rows))
+	for i, row := range rows {
+		history[i] = QueryHistoryEntry{
+			ID:         row.ID,
+			Query:      row.Query,
+			Success:    row.Success,
+			RowCount:   row.RowCount,
+			DurationMs: row.DurationMs,
+			ExecutedAt: row.ExecutedAt.Time,
+		}
+		if row.ErrorMessage.Valid {
+			history[i].ErrorMessage = &row.ErrorMessage.String
+		}
+	}
+	c.JSON(http.StatusOK, history)
+}
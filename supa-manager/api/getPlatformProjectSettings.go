@@ -7,18 +7,18 @@ import (
 )
 
 func (a *Api) getPlatformProjectSettings(c *gin.Context) {
-	_, err := a.GetAccountFromRequest(c)
-	if err != nil {
-		c.JSON(401, gin.H{"error": "Unauthorized"})
-		return
-	}
+	proj := currentProject(c)
 
-	projectRef := c.Param("ref")
-	proj, err := a.queries.GetProjectByRef(c, projectRef)
+	// Members who can't read secrets see the settings without the JWT secret
+	canReadSecrets, err := a.allowed(c, actionProjectSecretsRead)
 	if err != nil {
 		c.JSON(500, gin.H{"error": "Internal Server Error"})
 		return
 	}
+	jwtSecret := proj.JwtSecret
+	if !canReadSecrets {
+		jwtSecret = ""
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"project": Project{
@@ -45,7 +45,7 @@ func (a *Api) getPlatformProjectSettings(c *gin.Context) {
 			PreviewBranchRefs:        []interface{}{},
 			IsBranchEnabled:          false,
 			IsPhysicalBackupsEnabled: false,
-			JwtSecret:                proj.JwtSecret, // Include JWT secret for reveal
+			JwtSecret:                jwtSecret, // Include JWT secret for reveal
 		},
 		"services": []interface{}{
 			ProjectAutoApiService{
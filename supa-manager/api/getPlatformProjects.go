@@ -6,11 +6,7 @@ import (
 )
 
 func (a *Api) getPlatformProjects(c *gin.Context) {
-	account, err := a.GetAccountFromRequest(c)
-	if err != nil {
-		c.JSON(401, gin.H{"error": "Unauthorized"})
-		return
-	}
+	account := currentAccount(c)
 
 	projects, err := a.queries.GetProjectsForAccountId(c, account.ID)
 	if err != nil {
@@ -20,11 +20,7 @@ type ProfileReturn struct {
 }
 
 func (a *Api) getProfile(c *gin.Context) {
-	account, err := a.GetAccountFromRequest(c)
-	if err != nil {
-		c.JSON(401, gin.H{"error": "Unauthorized"})
-		return
-	}
+	account := currentAccount(c)
 
 	c.JSON(200, ProfileReturn{
 		Id:               account.ID,
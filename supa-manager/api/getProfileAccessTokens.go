@@ -0,0 +1,36 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func (a *Api) getProfileAccessTokens(c *gin.Context) {
+	account := currentAccount(c)
+
+	ctx := c.Request.Context()
+	tokens, err := a.queries.GetPersonalAccessTokens(ctx, account.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	organizations, err := a.queries.GetOrganizationsForAccountId(ctx, account.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	slugs := map[int32]string{}
+	for _, org := range organizations {
+		slugs[org.ID] = org.Slug
+	}
+
+	response := make([]PersonalAccessToken, len(tokens))
+	for i, token := range tokens {
+		var slug *string
+		if s, ok := slugs[token.OrganizationID.Int32]; ok && token.OrganizationID.Valid {
+			slug = &s
+		}
+		response[i] = personalAccessTokenResponse(token, slug)
+	}
+
+	c.JSON(200, response)
+}
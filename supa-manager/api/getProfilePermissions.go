@@ -6,18 +6,19 @@ import (
 	"supamanager.io/supa-manager/permisions"
 )
 
+// getProfilePermissions serves the permissions of the account's role in each of its organizations
 func (a *Api) getProfilePermissions(c *gin.Context) {
-	acc, err := a.GetAccountFromRequest(c)
-	if err != nil {
-		c.JSON(401, gin.H{"error": "Unauthorized"})
-		return
-	}
+	acc := currentAccount(c)
 
-	orgIds, err := a.queries.GetOrganizationIdsForAccountId(c, acc.ID)
+	orgs, err := a.queries.GetOrganizationsForAccountId(c, acc.ID)
 	if err != nil {
 		c.JSON(500, gin.H{"error": "Internal Server Error"})
 		return
 	}
 
-	c.JSON(http.StatusOK, permisions.ConstructPermissions(orgIds))
+	memberships := make([]permisions.Membership, 0, len(orgs))
+	for _, org := range orgs {
+		memberships = append(memberships, permisions.Membership{OrganizationID: org.ID, Role: org.MemberRole})
+	}
+	c.JSON(http.StatusOK, permisions.ForMemberships(memberships))
 }
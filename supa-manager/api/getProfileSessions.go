@@ -0,0 +1,42 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"supamanager.io/supa-manager/utils"
+	"time"
+)
+
+// ProfileSession is an active login of the current account
+type ProfileSession struct {
+	Id              string    `json:"id"`
+	UserAgent       *string   `json:"user_agent"`
+	Ip              *string   `json:"ip"`
+	LastRefreshedAt time.Time `json:"last_refreshed_at"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	Current         bool      `json:"current"`
+}
+
+func (a *Api) getProfileSessions(c *gin.Context) {
+	account := currentAccount(c)
+	claims := currentClaims(c)
+
+	sessions, err := a.queries.GetActiveSessions(c.Request.Context(), account.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	response := make([]ProfileSession, len(sessions))
+	for i, session := range sessions {
+		response[i] = ProfileSession{
+			Id:              session.FamilyID,
+			UserAgent:       utils.PgTextToPointer(session.UserAgent),
+			Ip:              utils.PgTextToPointer(session.Ip),
+			LastRefreshedAt: session.CreatedAt.Time,
+			ExpiresAt:       session.ExpiresAt.Time,
+			Current:         session.FamilyID == claims.SessionID,
+		}
+	}
+
+	c.JSON(200, response)
+}
@@ -7,12 +7,6 @@ import (
 )
 
 func (a *Api) getProjectAnalyticsEndpointUsage(c *gin.Context) {
-	_, err := a.GetAccountFromRequest(c)
-	if err != nil {
-		c.JSON(401, gin.H{"error": "Unauthorized"})
-		return
-	}
-
 	// Return empty analytics data
 	// TODO: Implement actual analytics tracking in future phases
 	c.JSON(http.StatusOK, gin.H{
@@ -22,12 +16,6 @@ func (a *Api) getProjectAnalyticsEndpointUsage(c *gin.Context) {
 }
 
 func (a *Api) getPlatformProjectAnalyticsEndpointUsage(c *gin.Context) {
-	_, err := a.GetAccountFromRequest(c)
-	if err != nil {
-		c.JSON(401, gin.H{"error": "Unauthorized"})
-		return
-	}
-
 	// Return mock analytics data for the dashboard
 	now := time.Now()
 	data := []gin.H{}
@@ -34,14 +34,8 @@ type ProjectAutoApiService struct {
 }
 
 func (a *Api) getProjectApi(c *gin.Context) {
-	_, err := a.GetAccountFromRequest(c)
-	if err != nil {
-		c.JSON(401, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	projectRef := c.Param("ref")
-	proj, err := a.queries.GetProjectByRef(c, projectRef)
+	proj := currentProject(c)
+	canReadSecrets, err := a.allowed(c, actionProjectSecretsRead)
 	if err != nil {
 		c.JSON(500, gin.H{"error": "Internal Server Error"})
 		return
@@ -62,7 +56,10 @@ func (a *Api) getProjectApi(c *gin.Context) {
 		}
 	}
 
-	if proj.ServiceRoleKey.Valid && proj.ServiceRoleKey.String != "" {
+	// The service_role key bypasses row level security, it's only shown to roles that can read secrets
+	if !canReadSecrets {
+		serviceKey = ""
+	} else if proj.ServiceRoleKey.Valid && proj.ServiceRoleKey.String != "" {
 		serviceKey = proj.ServiceRoleKey.String
 	} else {
 		// Generate service_role key from JWT secret
@@ -122,12 +119,20 @@ func (a *Api) getProjectApi(c *gin.Context) {
 // generateProjectJWT generates a Supabase-compatible JWT token
 // role can be "anon" or "service_role"
 func (a *Api) generateProjectJWT(jwtSecret string, role string) (string, error) {
+	return a.signProjectJWT(jwtSecret, role, time.Now().AddDate(10, 0, 0), nil) // 10 years expiry (like Supabase)
+}
+
+// signProjectJWT signs a project JWT for role valid until expiresAt, extra claims are added as is
+func (a *Api) signProjectJWT(jwtSecret string, role string, expiresAt time.Time, extra jwt.MapClaims) (string, error) {
 	// Supabase JWT claims
 	claims := jwt.MapClaims{
 		"iss":  "supamanager",
 		"role": role,
 		"iat":  time.Now().Unix(),
-		"exp":  time.Now().AddDate(10, 0, 0).Unix(), // 10 years expiry (like Supabase)
+		"exp":  expiresAt.Unix(),
+	}
+	for name, value := range extra {
+		claims[name] = value
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
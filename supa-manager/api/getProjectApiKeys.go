@@ -0,0 +1,23 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// getProjectApiKeys lists the project's named API keys, expired keys are listed until revoked
+func (a *Api) getProjectApiKeys(c *gin.Context) {
+	project := currentProject(c)
+
+	keys, err := a.queries.GetProjectApiKeys(c.Request.Context(), project.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	response := make([]ProjectApiKey, len(keys))
+	for i, key := range keys {
+		response[i] = projectApiKeyResponse(key)
+	}
+
+	c.JSON(200, response)
+}
@@ -1,25 +1,32 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"net/http"
 )
 
+// getProjectCustomHostname returns the project's custom hostname with the DNS records it needs
 func (a *Api) getProjectCustomHostname(c *gin.Context) {
-	_, err := a.GetAccountFromRequest(c)
+	project := currentProject(c)
+
+	ctx := c.Request.Context()
+	custom, err := a.queries.GetCustomHostnameByProject(ctx, project.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusOK, gin.H{
+			"customHostname": "",
+			"status":         "not_configured",
+			"data":           gin.H{},
+		})
+		return
+	}
 	if err != nil {
-		println(err.Error())
-		c.JSON(401, gin.H{"error": "Unauthorized"})
+		a.logger.Error(fmt.Sprintf("Failed to load custom hostname of project %s: %v", project.ProjectRef, err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
 		return
 	}
 
-	// ref := c.Param("ref")
-
-	// Return empty custom hostname configuration
-	// TODO: Implement custom domain management in future phases
-	c.JSON(http.StatusOK, gin.H{
-		"customHostname": "",
-		"status":         "not_configured",
-		"data":           gin.H{},
-	})
+	c.JSON(http.StatusOK, a.customHostnameResponse(ctx, project.ProjectRef, custom))
 }
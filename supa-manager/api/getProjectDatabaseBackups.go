@@ -0,0 +1,22 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// getProjectDatabaseBackups lists the project's backups, newest first, with their verification
+func (a *Api) getProjectDatabaseBackups(c *gin.Context) {
+	project := currentProject(c)
+
+	backups, err := a.queries.GetBackupsForProject(c.Request.Context(), project.ProjectRef)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	response := make([]ProjectBackup, 0, len(backups))
+	for _, backup := range backups {
+		response = append(response, projectBackupResponse(backup))
+	}
+	c.JSON(200, response)
+}
@@ -6,13 +6,6 @@ import (
 )
 
 func (a *Api) getProjectUpgradeEligibility(c *gin.Context) {
-	_, err := a.GetAccountFromRequest(c)
-	if err != nil {
-		println(err.Error())
-		c.JSON(401, gin.H{"error": "Unauthorized"})
-		return
-	}
-
 	// Return upgrade eligibility info
 	// For self-hosted, upgrades are managed differently
 	c.JSON(http.StatusOK, gin.H{
@@ -18,12 +18,6 @@ type UpgradeStatusResponse struct {
 }
 
 func (a *Api) getProjectUpgradeStatus(c *gin.Context) {
-	_, err := a.GetAccountFromRequest(c)
-	if err != nil {
-		c.JSON(401, gin.H{"error": "Unauthorized"})
-		return
-	}
-
 	projectRef := c.Param("ref")
 	if projectRef == "" {
 		c.JSON(400, gin.H{"error": "Project reference is required"})
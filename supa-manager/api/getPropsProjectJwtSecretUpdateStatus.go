@@ -6,20 +6,11 @@ import (
 )
 
 func (a *Api) getPropsProjectJwtSecretUpdateStatus(c *gin.Context) {
-	_, err := a.GetAccountFromRequest(c)
+	status, err := a.jwtSecretUpdateStatus(c.Request.Context(), currentProject(c))
 	if err != nil {
-		println(err.Error())
-		c.JSON(401, gin.H{"error": "Unauthorized"})
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
 		return
 	}
 
-	// projectRef := c.Param("ref")
-
-	// Return success status - JWT secret is already set
-	c.JSON(http.StatusOK, gin.H{
-		"jwtSecretUpdateStatus": gin.H{
-			"status":   "success",
-			"progress": 100,
-		},
-	})
+	c.JSON(http.StatusOK, gin.H{"jwtSecretUpdateStatus": status})
 }
@@ -0,0 +1,292 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/provisioner"
+	"supamanager.io/supa-manager/utils"
+	"time"
+)
+
+// Statuses of a JWT secret rotation
+const (
+	jwtRotationPending    = "pending"
+	jwtRotationInProgress = "in_progress"
+	jwtRotationCompleted  = "completed"
+	jwtRotationFailed     = "failed"
+)
+
+// jwtRotationServices are recreated in this order: the services verifying JWTs with the secret
+// first, then Kong, which checks the API keys
+var jwtRotationServices = []string{"auth", "rest", "realtime", "storage", "kong"}
+
+// minJwtSecretLength is the shortest secret GoTrue accepts
+const minJwtSecretLength = 32
+
+// A rotation that made no progress for jwtRotationStaleAfter was interrupted, a replica stopped in
+// the middle of it. Every step takes a few minutes at most
+const (
+	jwtRotationStaleAfter       = 15 * time.Minute
+	jwtRotationRecoveryInterval = 5 * time.Minute
+)
+
+// JwtSecretUpdateStatus is the progress of a project's latest JWT secret rotation
+type JwtSecretUpdateStatus struct {
+	ChangeTrackingID *string    `json:"change_tracking_id"`
+	Status           string     `json:"status"`
+	Step             string     `json:"step"`
+	Progress         int32      `json:"progress"`
+	Error            *string    `json:"error"`
+	RolledBack       bool       `json:"rolled_back"`
+	UpdatedAt        *time.Time `json:"updated_at"`
+}
+
+func jwtSecretUpdateStatusResponse(rotation database.JwtSecretRotation) JwtSecretUpdateStatus {
+	return JwtSecretUpdateStatus{
+		ChangeTrackingID: &rotation.ChangeTrackingID,
+		Status:           rotation.Status,
+		Step:             rotation.Step,
+		Progress:         rotation.Progress,
+		Error:            utils.PgTextToPointer(rotation.Error),
+		RolledBack:       rotation.RolledBack,
+		UpdatedAt:        &rotation.UpdatedAt.Time,
+	}
+}
+
+// jwtSecretUpdateStatus returns the latest rotation of a project, projects that never rotated
+// their secret report it as completed
+func (a *Api) jwtSecretUpdateStatus(ctx context.Context, project *database.Project) (JwtSecretUpdateStatus, error) {
+	rotation, err := a.queries.GetLatestJwtSecretRotation(ctx, project.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return JwtSecretUpdateStatus{Status: jwtRotationCompleted, Step: jwtRotationCompleted, Progress: 100}, nil
+	}
+	if err != nil {
+		return JwtSecretUpdateStatus{}, err
+	}
+	return jwtSecretUpdateStatusResponse(rotation), nil
+}
+
+// rotateJwtSecret switches a project to a new JWT secret and keys, recording its progress. The
+// project row keeps the previous secret until every service runs with the new one, on failure
+// the services are put back on the previous secret
+func (a *Api) rotateJwtSecret(ctx context.Context, rotation database.JwtSecretRotation, project database.Project, secret string) {
+	rolledBack, err := a.runJwtSecretRotation(ctx, rotation, project, secret)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("JWT secret rotation of project %s failed: %v", project.ProjectRef, err), "rolled_back", rolledBack)
+		if err := a.queries.FailJwtSecretRotation(ctx, database.FailJwtSecretRotationParams{
+			ID:         rotation.ID,
+			Error:      pgtype.Text{String: err.Error(), Valid: true},
+			RolledBack: rolledBack,
+		}); err != nil {
+			a.logger.Error(fmt.Sprintf("Failed to record failed JWT secret rotation %d: %v", rotation.ID, err))
+		}
+		return
+	}
+
+	if err := a.queries.CompleteJwtSecretRotation(ctx, rotation.ID); err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to record completed JWT secret rotation %d: %v", rotation.ID, err))
+	}
+	a.logger.Info("Rotated JWT secret", "project", project.ProjectRef, "rotation", rotation.ID)
+}
+
+// runJwtSecretRotation performs the rotation and reports whether a failure was rolled back
+func (a *Api) runJwtSecretRotation(ctx context.Context, rotation database.JwtSecretRotation, project database.Project, secret string) (bool, error) {
+	a.jwtRotationStep(ctx, rotation, "generating_keys", 10)
+	anonKey, err := a.generateProjectJWT(secret, "anon")
+	if err != nil {
+		return true, err
+	}
+	serviceKey, err := a.generateProjectJWT(secret, "service_role")
+	if err != nil {
+		return true, err
+	}
+	// Named keys are signed with the secret too, they are reissued with the same role and expiry
+	keys, err := a.queries.GetActiveProjectApiKeys(ctx, project.ID)
+	if err != nil {
+		return true, err
+	}
+	consumers := make([]provisioner.APIKey, len(keys))
+	for i := range keys {
+		var plain string
+		plain, keys[i].KeyAlias, keys[i].KeyEncrypted, err = a.mintProjectApiKey(secret, keys[i].Role, keys[i].ExpiresAt)
+		if err != nil {
+			return true, err
+		}
+		consumers[i] = provisioner.APIKey{Consumer: apiKeyConsumer(keys[i].ID), Key: plain, Group: apiKeyGroup(keys[i].Role)}
+	}
+
+	// Projects without containers only need the new secret stored
+	provisioned := a.provisioner != nil && project.ProvisionedAt.Valid
+	var previous *provisioner.ProjectConfig
+	var recreated []string
+	if provisioned {
+		a.jwtRotationStep(ctx, rotation, "rendering_config", 20)
+		previous, err = a.provisioner.LoadProjectConfig(ctx, project.ProjectRef)
+		if err != nil {
+			return true, err
+		}
+		next := *previous
+		next.JWTSecret = secret
+		next.AnonKey = anonKey
+		next.ServiceKey = serviceKey
+		next.APIKeys = consumers
+		if err := a.provisioner.RenderProject(ctx, &next); err != nil {
+			return a.rollbackJwtSecret(ctx, project.ProjectRef, previous, nil), err
+		}
+
+		for i, service := range jwtRotationServices {
+			a.jwtRotationStep(ctx, rotation, "recreating_"+service, int32(30+i*12))
+			recreated = append(recreated, service)
+			if err := a.provisioner.RecreateServices(ctx, project.ProjectRef, []string{service}); err != nil {
+				return a.rollbackJwtSecret(ctx, project.ProjectRef, previous, recreated), err
+			}
+		}
+	}
+
+	a.jwtRotationStep(ctx, rotation, "saving", 95)
+	err = a.saveProjectJwtSecret(ctx, project, secret, anonKey, serviceKey, keys)
+	if err != nil {
+		if provisioned {
+			return a.rollbackJwtSecret(ctx, project.ProjectRef, previous, recreated), err
+		}
+		return true, err
+	}
+	return false, nil
+}
+
+// saveProjectJwtSecret stores the new secret with every key signed with it
+func (a *Api) saveProjectJwtSecret(ctx context.Context, project database.Project, secret string, anonKey string, serviceKey string, keys []database.ProjectApiKey) error {
+	tx, err := a.pgPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	queries := a.queries.WithTx(tx)
+
+	_, err = queries.UpdateProjectJwtSecret(ctx, database.UpdateProjectJwtSecretParams{
+		ProjectRef:     project.ProjectRef,
+		JwtSecret:      secret,
+		AnonKey:        pgtype.Text{String: anonKey, Valid: true},
+		ServiceRoleKey: pgtype.Text{String: serviceKey, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		err := queries.UpdateProjectApiKeySecret(ctx, database.UpdateProjectApiKeySecretParams{
+			ID:           key.ID,
+			KeyEncrypted: key.KeyEncrypted,
+			KeyAlias:     key.KeyAlias,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (a *Api) jwtRotationStep(ctx context.Context, rotation database.JwtSecretRotation, step string, progress int32) {
+	if err := a.queries.UpdateJwtSecretRotationProgress(ctx, database.UpdateJwtSecretRotationProgressParams{
+		ID:       rotation.ID,
+		Step:     step,
+		Progress: progress,
+	}); err != nil {
+		a.logger.Warn(fmt.Sprintf("Failed to record progress of JWT secret rotation %d: %v", rotation.ID, err))
+	}
+}
+
+// rollbackJwtSecret renders the previous configuration and recreates the services that may already
+// run with the new one. It reports whether the project is back on the previous secret
+func (a *Api) rollbackJwtSecret(ctx context.Context, projectRef string, previous *provisioner.ProjectConfig, services []string) bool {
+	if err := a.provisioner.RenderProject(ctx, previous); err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to roll back JWT secret of project %s: %v", projectRef, err))
+		return false
+	}
+	if err := a.provisioner.RecreateServices(ctx, projectRef, services); err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to roll back JWT secret of project %s: %v", projectRef, err))
+		return false
+	}
+	return true
+}
+
+// runJwtSecretRotationRecoveryJob fails rotations interrupted by a restart and puts their project
+// back on the secret it has stored
+func (a *Api) runJwtSecretRotationRecoveryJob(ctx context.Context) {
+	ticker := time.NewTicker(jwtRotationRecoveryInterval)
+	defer ticker.Stop()
+
+	for {
+		a.recoverStaleJwtSecretRotations(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *Api) recoverStaleJwtSecretRotations(ctx context.Context) {
+	rotations, err := a.queries.GetStaleJwtSecretRotations(ctx, pgtype.Timestamptz{Time: time.Now().Add(-jwtRotationStaleAfter), Valid: true})
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to load stale JWT secret rotations: %v", err))
+		return
+	}
+
+	for _, rotation := range rotations {
+		project, err := a.queries.GetProjectByID(ctx, rotation.ProjectID)
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Failed to load project of JWT secret rotation %d: %v", rotation.ID, err))
+			continue
+		}
+
+		rolledBack := true
+		if a.provisioner != nil && project.ProvisionedAt.Valid {
+			rolledBack, err = a.restoreProjectJwtSecret(ctx, project)
+			if err != nil {
+				a.logger.Error(fmt.Sprintf("Failed to restore JWT secret of project %s: %v", project.ProjectRef, err))
+			}
+		}
+		if err := a.queries.FailJwtSecretRotation(ctx, database.FailJwtSecretRotationParams{
+			ID:         rotation.ID,
+			Error:      pgtype.Text{String: "Rotation was interrupted before it completed", Valid: true},
+			RolledBack: rolledBack,
+		}); err != nil {
+			a.logger.Error(fmt.Sprintf("Failed to record interrupted JWT secret rotation %d: %v", rotation.ID, err))
+			continue
+		}
+		a.logger.Warn("Interrupted JWT secret rotation failed", "project", project.ProjectRef, "rotation", rotation.ID, "rolled_back", rolledBack)
+	}
+}
+
+// restoreProjectJwtSecret renders the project with the secret and keys stored for it, the rendered
+// files may hold the secret of the interrupted rotation
+func (a *Api) restoreProjectJwtSecret(ctx context.Context, project database.Project) (bool, error) {
+	config, err := a.provisioner.LoadProjectConfig(ctx, project.ProjectRef)
+	if errors.Is(err, provisioner.ErrProjectNotRendered) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	config.JWTSecret = project.JwtSecret
+	config.AnonKey = project.AnonKey.String
+	config.ServiceKey = project.ServiceRoleKey.String
+	if config.APIKeys, err = a.projectGatewayKeys(ctx, a.queries, project.ID); err != nil {
+		return false, err
+	}
+	if !project.AnonKey.Valid || !project.ServiceRoleKey.Valid {
+		if config.AnonKey, err = a.generateProjectJWT(project.JwtSecret, "anon"); err != nil {
+			return false, err
+		}
+		if config.ServiceKey, err = a.generateProjectJWT(project.JwtSecret, "service_role"); err != nil {
+			return false, err
+		}
+	}
+	return a.rollbackJwtSecret(ctx, project.ProjectRef, config, jwtRotationServices), nil
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"strings"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/utils"
+	"time"
+)
+
+const (
+	maxMfaFactors       = 10
+	mfaChallengeTtl     = 5 * time.Minute
+	mfaRecoveryCodes    = 10
+	mfaRecoveryCodeSize = 10
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func gotrueFactors(factors []database.MfaFactor) []gin.H {
+	result := []gin.H{}
+	for _, factor := range factors {
+		result = append(result, gotrueFactor(factor))
+	}
+	return result
+}
+
+func gotrueFactor(factor database.MfaFactor) gin.H {
+	return gin.H{
+		"id":            factor.ID,
+		"friendly_name": utils.PgTextToPointer(factor.FriendlyName),
+		"factor_type":   factor.FactorType,
+		"status":        factor.Status,
+		"created_at":    factor.CreatedAt.Time,
+		"updated_at":    factor.UpdatedAt.Time,
+	}
+}
+
+// mfaRequired reports whether sensitive routes need an aal2 session for the account
+func (a *Api) mfaRequired(ctx context.Context, account *database.Account) (bool, error) {
+	if a.config.Auth.MfaRequired {
+		return true, nil
+	}
+	verified, err := a.queries.CountVerifiedMfaFactors(ctx, account.ID)
+	if err != nil {
+		return false, err
+	}
+	return verified > 0, nil
+}
+
+// requireAAL2 guards routes that reach tenant databases or secrets. Sessions must have passed a
+// second factor when MFA is enforced globally or the account has enrolled one
+func (a *Api) requireAAL2(c *gin.Context) {
+	account := currentAccount(c)
+	claims := currentClaims(c)
+	if claims.AAL == aal2 {
+		c.Next()
+		return
+	}
+
+	required, err := a.mfaRequired(c.Request.Context(), account)
+	if err != nil {
+		c.AbortWithStatusJSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if required {
+		c.AbortWithStatusJSON(403, gin.H{"error": "insufficient_aal", "message": "This action requires multi-factor authentication"})
+		return
+	}
+	c.Next()
+}
+
+// upgradeSession rotates the caller's session into an aal2 one after a second factor was verified
+func (a *Api) upgradeSession(ctx context.Context, queries *database.Queries, c *gin.Context, account *database.Account, claims *AccessTokenClaims, method string) (database.Session, string, error) {
+	grant := SessionGrant{AAL: aal2, AMR: []string{method}}
+	familyID := claims.SessionID
+	parentID := pgtype.Text{}
+
+	if familyID == "" {
+		familyID = utils.NewUUID()
+		grant.AMR = append(grant.AMR, "password")
+	} else {
+		current, err := queries.GetActiveSessionForUpdate(ctx, database.GetActiveSessionForUpdateParams{
+			FamilyID:  familyID,
+			AccountID: account.ID,
+		})
+		if err != nil {
+			return database.Session{}, "", err
+		}
+		if err := queries.RotateSession(ctx, current.ID); err != nil {
+			return database.Session{}, "", err
+		}
+		parentID = pgtype.Text{String: current.ID, Valid: true}
+		for _, m := range current.Amr {
+			if m != method {
+				grant.AMR = append(grant.AMR, m)
+			}
+		}
+	}
+
+	return a.createSession(ctx, queries, c, account.ID, familyID, parentID, grant)
+}
+
+// newRecoveryCodes replaces the account's recovery codes and returns the plain codes, shown only once
+func newRecoveryCodes(ctx context.Context, queries *database.Queries, accountID int32) ([]string, error) {
+	if err := queries.DeleteMfaRecoveryCodes(ctx, accountID); err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, mfaRecoveryCodes)
+	for i := range codes {
+		b := make([]byte, mfaRecoveryCodeSize)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+		codes[i] = fmt.Sprintf("%s-%s-%s-%s", code[0:4], code[4:8], code[8:12], code[12:16])
+
+		err := queries.CreateMfaRecoveryCode(ctx, database.CreateMfaRecoveryCodeParams{
+			AccountID: accountID,
+			CodeHash:  hashRecoveryCode(codes[i]),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return codes, nil
+}
+
+// totpReplayed reports whether a code of step can't be accepted anymore. A code is only accepted
+// once, even within its validity window, and never after a later one
+func totpReplayed(factor database.MfaFactor, step int64) bool {
+	return factor.LastUsedStep.Valid && step <= factor.LastUsedStep.Int64
+}
+
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return utils.HashToken(normalized)
+}
+
+func (a *Api) decryptFactorSecret(factor database.MfaFactor) (string, error) {
+	secret, err := utils.Decrypt(a.config.EncryptionSecret, factor.SecretEncrypted)
+	if err != nil {
+		return "", errors.New("failed to decrypt factor secret")
+	}
+	return string(secret), nil
+}
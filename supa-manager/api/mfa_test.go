@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"supamanager.io/supa-manager/database"
+	"testing"
+)
+
+func TestRequireAAL2(t *testing.T) {
+	tests := []struct {
+		name            string
+		aal             string
+		mfaRequired     bool
+		verifiedFactors []interface{} // CountVerifiedMfaFactors, nil fails the query
+		want            int
+	}{
+		{"aal2", aal2, true, []interface{}{int64(1)}, http.StatusOK},
+		{"aal1 without factors", aal1, false, []interface{}{int64(0)}, http.StatusOK},
+		{"aal1 with a verified factor", aal1, false, []interface{}{int64(1)}, http.StatusForbidden},
+		{"aal1 when MFA is required", aal1, true, []interface{}{int64(0)}, http.StatusForbidden},
+		{"aal1 when the factors can't be counted", aal1, false, nil, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{rows: map[string][]interface{}{}}
+			if tt.verifiedFactors != nil {
+				db.rows["CountVerifiedMfaFactors"] = tt.verifiedFactors
+			}
+			a := newAuthTestApi(db)
+			a.config.Auth.MfaRequired = tt.mfaRequired
+
+			r := gin.New()
+			r.GET("/secrets", func(c *gin.Context) {
+				c.Set(contextAccountKey, &database.Account{ID: 7})
+				c.Set(contextClaimsKey, &AccessTokenClaims{AAL: tt.aal})
+			}, a.requireAAL2, func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/secrets", nil))
+			if w.Code != tt.want {
+				t.Errorf("status %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestTotpReplayed(t *testing.T) {
+	tests := []struct {
+		name     string
+		lastUsed pgtype.Int8
+		step     int64
+		want     bool
+	}{
+		{"never used", pgtype.Int8{}, 100, false},
+		{"later step", pgtype.Int8{Int64: 100, Valid: true}, 101, false},
+		{"same step", pgtype.Int8{Int64: 100, Valid: true}, 100, true},
+		{"earlier step", pgtype.Int8{Int64: 100, Valid: true}, 99, true},
+	}
+	for _, tt := range tests {
+		if got := totpReplayed(database.MfaFactor{LastUsedStep: tt.lastUsed}, tt.step); got != tt.want {
+			t.Errorf("%s: totpReplayed() = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewRecoveryCodes(t *testing.T) {
+	db := &fakeDB{}
+	codes, err := newRecoveryCodes(context.Background(), database.New(db), 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != mfaRecoveryCodes {
+		t.Fatalf("%d codes, want %d", len(codes), mfaRecoveryCodes)
+	}
+	format := regexp.MustCompile(`^[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}$`)
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if !format.MatchString(code) {
+			t.Errorf("code %q isn't four groups of four base32 characters", code)
+		}
+		if seen[code] {
+			t.Errorf("code %q was issued twice", code)
+		}
+		seen[code] = true
+	}
+	// the old codes are deleted before the new ones are stored
+	if len(db.execs) != mfaRecoveryCodes+1 || db.execs[0] != "DeleteMfaRecoveryCodes" || db.execs[1] != "CreateMfaRecoveryCode" {
+		t.Errorf("statements %v", db.execs)
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	hash := hashRecoveryCode("abcd-efgh-ijkl-mnop")
+	for _, typed := range []string{"ABCD-EFGH-IJKL-MNOP", "abcdefghijklmnop", "abcd efgh ijkl mnop"} {
+		if hashRecoveryCode(typed) != hash {
+			t.Errorf("hashRecoveryCode(%q) differs from the issued code's", typed)
+		}
+	}
+	if hashRecoveryCode("abcd-efgh-ijkl-mnoq") == hash {
+		t.Error("different codes have the same hash")
+	}
+}
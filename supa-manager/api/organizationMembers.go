@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/url"
+	"strings"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/mailer"
+	"supamanager.io/supa-manager/permisions"
+	"supamanager.io/supa-manager/utils"
+	"time"
+)
+
+// invitationTtl is how long an emailed invitation link can be accepted
+const invitationTtl = 7 * 24 * time.Hour
+
+var (
+	errLastOwner         = errors.New("an organization must keep at least one owner")
+	errInvitationInvalid = errors.New("invitation is invalid or has expired")
+)
+
+// OrganizationMember is a row of Studio's team settings page
+type OrganizationMember struct {
+	GotrueID     string  `json:"gotrue_id"`
+	PrimaryEmail string  `json:"primary_email"`
+	Username     string  `json:"username"`
+	RoleIDs      []int32 `json:"role_ids"`
+	MfaEnabled   bool    `json:"mfa_enabled"`
+	IsSsoUser    bool    `json:"is_sso_user"`
+}
+
+// OrganizationInvitation is a pending invitation, the token is only ever sent by email
+type OrganizationInvitation struct {
+	ID           int64     `json:"id"`
+	InvitedEmail string    `json:"invited_email"`
+	InvitedAt    time.Time `json:"invited_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	RoleID       int32     `json:"role_id"`
+}
+
+func organizationInvitationResponse(invitation database.OrganizationInvitation) OrganizationInvitation {
+	return OrganizationInvitation{
+		ID:           invitation.ID,
+		InvitedEmail: invitation.InvitedEmail,
+		InvitedAt:    invitation.CreatedAt.Time,
+		ExpiresAt:    invitation.ExpiresAt.Time,
+		RoleID:       permisions.RoleID(invitation.Role),
+	}
+}
+
+// roleAttributes are the permission condition attributes of a member or invitation holding role
+func roleAttributes(role string) map[string]interface{} {
+	return map[string]interface{}{"role_id": permisions.RoleID(role)}
+}
+
+// guardLastOwner fails when account losing the owner role would leave the organization without
+// owners. The owners stay locked until the transaction ends, so concurrent changes can't race it
+func guardLastOwner(ctx context.Context, queries *database.Queries, organizationID int32, accountID int32, newRole string) error {
+	if newRole == permisions.RoleOwner {
+		return nil
+	}
+	owners, err := queries.LockOrganizationOwners(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+	for _, owner := range owners {
+		if owner != accountID {
+			return nil
+		}
+	}
+	if len(owners) == 0 {
+		return nil
+	}
+	return errLastOwner
+}
+
+// sendInvitation creates an invitation, replacing pending ones for the same address, and mails
+// its single-use link. Nothing is stored when the email can't be sent
+func (a *Api) sendInvitation(ctx context.Context, c *gin.Context, org database.Organization, email string, role string) (database.OrganizationInvitation, error) {
+	token, err := utils.RandomToken(accountTokenSize)
+	if err != nil {
+		return database.OrganizationInvitation{}, err
+	}
+
+	tx, err := a.pgPool.Begin(ctx)
+	if err != nil {
+		return database.OrganizationInvitation{}, err
+	}
+	defer tx.Rollback(ctx)
+	queries := a.queries.WithTx(tx)
+
+	err = queries.DeletePendingOrganizationInvitations(ctx, database.DeletePendingOrganizationInvitationsParams{
+		OrganizationID: org.ID,
+		InvitedEmail:   email,
+	})
+	if err != nil {
+		return database.OrganizationInvitation{}, err
+	}
+	invitation, err := queries.CreateOrganizationInvitation(ctx, database.CreateOrganizationInvitationParams{
+		OrganizationID: org.ID,
+		InvitedEmail:   email,
+		Role:           role,
+		TokenHash:      utils.HashToken(token),
+		InvitedBy:      pgtype.Int4{Int32: currentAccount(c).ID, Valid: true},
+		ExpiresAt:      pgtype.Timestamptz{Time: time.Now().Add(invitationTtl), Valid: true},
+	})
+	if err != nil {
+		return database.OrganizationInvitation{}, err
+	}
+
+	msg, err := mailer.InvitationMessage(email, a.invitationLink(org.Slug, token), org.Name)
+	if err != nil {
+		return database.OrganizationInvitation{}, err
+	}
+	if err := a.mailer.Send(ctx, msg); err != nil {
+		return database.OrganizationInvitation{}, fmt.Errorf("failed to send invitation email: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return database.OrganizationInvitation{}, err
+	}
+	return invitation, nil
+}
+
+// invitationLink points at Studio's join page, which accepts the invitation through the API
+func (a *Api) invitationLink(slug string, token string) string {
+	query := url.Values{}
+	query.Set("token", token)
+	query.Set("slug", slug)
+	return strings.TrimRight(a.config.Domain.StudioUrl, "/") + "/join?" + query.Encode()
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/mail"
+	"strings"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/provisioner"
+)
+
+// organizationPlans are Studio's plan ids, each mapped to the quotas its projects get
+var organizationPlans = map[string]provisioner.QuotaPlan{
+	"free":       provisioner.PlanFree,
+	"pro":        provisioner.PlanPro,
+	"team":       provisioner.PlanPro,
+	"enterprise": provisioner.PlanEnterprise,
+}
+
+var organizationPlanNames = map[string]string{
+	"free":       "Free",
+	"pro":        "Pro",
+	"team":       "Team",
+	"enterprise": "Enterprise",
+}
+
+// planProjectLimits caps the projects of an organization, plans missing here are unlimited
+var planProjectLimits = map[string]int64{
+	"free": 2,
+}
+
+// organizationOptInTags are the opt-in tags Studio offers on the organization settings page
+var organizationOptInTags = map[string]bool{
+	"AI_SQL_GENERATOR_OPT_IN":  true,
+	"AI_DATA_GENERATOR_OPT_IN": true,
+	"AI_LOG_GENERATOR_OPT_IN":  true,
+}
+
+// planFromTier turns the tier Studio sends when creating an organization, like tier_pro, into a plan id
+func planFromTier(tier string) (string, bool) {
+	if tier == "" {
+		return "free", true
+	}
+	plan := strings.TrimPrefix(strings.ToLower(tier), "tier_")
+	_, ok := organizationPlans[plan]
+	return plan, ok
+}
+
+type OrganizationPlan struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func organizationPlan(plan string) OrganizationPlan {
+	return OrganizationPlan{Id: plan, Name: organizationPlanNames[plan]}
+}
+
+func organizationResponse(org database.Organization, isOwner bool) Organization {
+	var billingEmail interface{}
+	if org.BillingEmail.Valid {
+		billingEmail = org.BillingEmail.String
+	}
+	optInTags := org.OptInTags
+	if optInTags == nil {
+		optInTags = []string{}
+	}
+	return Organization{
+		Slug:              org.Slug,
+		Name:              org.Name,
+		StripeCustomerId:  "",
+		SubscriptionId:    "",
+		BillingEmail:      billingEmail,
+		IsOwner:           isOwner,
+		OptInTags:         optInTags,
+		Id:                org.ID,
+		Plan:              organizationPlan(org.Plan),
+		RestrictionData:   nil,
+		RestrictionStatus: nil,
+	}
+}
+
+func textOrEmpty(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
+
+// validEmail accepts bare addresses, without a display name
+func validEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/permisions"
+)
+
+type UpdateOrganizationMember struct {
+	RoleID int32 `json:"role_id" binding:"required"`
+}
+
+// patchOrganizationMember changes the role of a member, the caller must be allowed to manage
+// both the current and the new role
+func (a *Api) patchOrganizationMember(c *gin.Context) {
+	org := currentOrganization(c)
+
+	var body UpdateOrganizationMember
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	role, ok := permisions.RoleByID(body.RoleID)
+	if !ok {
+		c.JSON(422, gin.H{"error": fmt.Sprintf("Unknown role %d", body.RoleID)})
+		return
+	}
+
+	ctx := c.Request.Context()
+	member, err := a.queries.GetOrganizationMemberByGotrueId(ctx, database.GetOrganizationMemberByGotrueIdParams{
+		OrganizationID: org.ID,
+		GotrueID:       c.Param("gotrue_id"),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(404, gin.H{"error": "Member not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	for _, checked := range []string{member.Role, role} {
+		allowed, err := a.allowedOn(c, actionMemberRoleUpdate, roleAttributes(checked))
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal Server Error"})
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden", "message": fmt.Sprintf("Your role does not allow assigning or changing the %s role", permisions.RoleName(checked))})
+			return
+		}
+	}
+
+	tx, err := a.pgPool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+	queries := a.queries.WithTx(tx)
+
+	err = guardLastOwner(ctx, queries, org.ID, member.ID, role)
+	if errors.Is(err, errLastOwner) {
+		c.JSON(409, gin.H{"error": errLastOwner.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	err = queries.UpdateOrganizationMemberRole(ctx, database.UpdateOrganizationMemberRoleParams{
+		OrganizationID: org.ID,
+		AccountID:      member.ID,
+		Role:           role,
+	})
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to update member role: %v", err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	a.logger.Info("Updated member role", "organization", org.Slug, "member", c.Param("gotrue_id"), "role", role)
+	c.JSON(http.StatusOK, gin.H{"gotrue_id": c.Param("gotrue_id"), "role_ids": []int32{body.RoleID}})
+}
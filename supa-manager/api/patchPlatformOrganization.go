@@ -0,0 +1,79 @@
+package api
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+	"strings"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/permisions"
+)
+
+// UpdateOrganization only changes the fields that are sent, an empty billing email clears it
+type UpdateOrganization struct {
+	Name         *string   `json:"name"`
+	BillingEmail *string   `json:"billing_email"`
+	OptInTags    *[]string `json:"opt_in_tags"`
+}
+
+func (a *Api) patchPlatformOrganization(c *gin.Context) {
+	org := currentOrganization(c)
+
+	var body UpdateOrganization
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	params := database.UpdateOrganizationParams{
+		ID:           org.ID,
+		Name:         org.Name,
+		BillingEmail: org.BillingEmail,
+		OptInTags:    org.OptInTags,
+	}
+	if body.Name != nil {
+		params.Name = strings.TrimSpace(*body.Name)
+		if params.Name == "" || len(params.Name) > 100 {
+			c.JSON(422, gin.H{"error": "Name must be between 1 and 100 characters"})
+			return
+		}
+	}
+	if body.BillingEmail != nil {
+		email := strings.TrimSpace(*body.BillingEmail)
+		if email != "" && !validEmail(email) {
+			c.JSON(422, gin.H{"error": "Billing email must be a valid email address"})
+			return
+		}
+		params.BillingEmail = pgtype.Text{String: email, Valid: email != ""}
+	}
+	if body.OptInTags != nil {
+		for _, tag := range *body.OptInTags {
+			if !organizationOptInTags[tag] {
+				c.JSON(422, gin.H{"error": fmt.Sprintf("Unknown opt-in tag %q", tag)})
+				return
+			}
+		}
+		params.OptInTags = *body.OptInTags
+	}
+	if params.OptInTags == nil {
+		params.OptInTags = []string{}
+	}
+
+	updated, err := a.queries.UpdateOrganization(c.Request.Context(), params)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to update organization %s: %v", org.Slug, err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	role, err := a.queries.GetOrganizationMemberRole(c.Request.Context(), database.GetOrganizationMemberRoleParams{
+		OrganizationID: org.ID,
+		AccountID:      currentAccount(c).ID,
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	c.JSON(http.StatusOK, organizationResponse(updated, role == permisions.RoleOwner))
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"supamanager.io/supa-manager/pgmeta"
+)
+
+func (a *Api) patchPlatformPgMetaColumns(c *gin.Context) {
+	id := c.Query("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing required query parameter: id"})
+		return
+	}
+
+	var req pgmeta.ColumnUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	pool, ok := a.projectPoolFromRequest(c)
+	if !ok {
+		return
+	}
+
+	column, err := pgmeta.UpdateColumn(c.Request.Context(), pool, id, req)
+	if err != nil {
+		a.respondPgMetaError(c, "column", id, err)
+		return
+	}
+
+	a.logger.Info("Updated column", "project", c.Param("ref"), "column_id", id)
+	c.JSON(http.StatusOK, column)
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"supamanager.io/supa-manager/pgmeta"
+)
+
+func (a *Api) patchPlatformPgMetaExtensions(c *gin.Context) {
+	id := c.Query("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing required query parameter: id"})
+		return
+	}
+
+	var req pgmeta.ExtensionUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	pool, ok := a.projectPoolFromRequest(c)
+	if !ok {
+		return
+	}
+
+	extension, err := pgmeta.UpdateExtension(c.Request.Context(), pool, id, req)
+	if err != nil {
+		a.respondExtensionError(c, id, err)
+		return
+	}
+
+	a.logger.Info("Updated extension", "project", c.Param("ref"), "extension_id", id)
+	c.JSON(http.StatusOK, extension)
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"supamanager.io/supa-manager/pgmeta"
+)
+
+func (a *Api) patchPlatformPgMetaPolicies(c *gin.Context) {
+	id, ok := pgMetaID(c)
+	if !ok {
+		return
+	}
+
+	var req pgmeta.PolicyUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	pool, ok := a.projectPoolFromRequest(c)
+	if !ok {
+		return
+	}
+
+	policy, err := pgmeta.UpdatePolicy(c.Request.Context(), pool, id, req)
+	if err != nil {
+		a.respondPgMetaError(c, "policy", id, err)
+		return
+	}
+
+	a.logger.Info("Updated policy", "project", c.Param("ref"), "policy_id", id)
+	c.JSON(http.StatusOK, policy)
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"supamanager.io/supa-manager/pgmeta"
+)
+
+func (a *Api) patchPlatformPgMetaTriggers(c *gin.Context) {
+	id, ok := pgMetaID(c)
+	if !ok {
+		return
+	}
+
+	var req pgmeta.TriggerUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	pool, ok := a.projectPoolFromRequest(c)
+	if !ok {
+		return
+	}
+
+	trigger, err := pgmeta.UpdateTrigger(c.Request.Context(), pool, id, req)
+	if err != nil {
+		a.respondPgMetaError(c, "trigger", id, err)
+		return
+	}
+
+	a.logger.Info("Updated trigger", "project", c.Param("ref"), "trigger_id", id)
+	c.JSON(http.StatusOK, trigger)
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+)
+
+// UserContentPatch changes only the fields that are present
+type UserContentPatch struct {
+	Name        *string         `json:"name"`
+	Description *string         `json:"description"`
+	Visibility  *string         `json:"visibility"`
+	Content     json.RawMessage `json:"content"`
+	Favorite    *bool           `json:"favorite"`
+	FolderID    *string         `json:"folder_id"`
+}
+
+func (a *Api) patchPlatformProjectContent(c *gin.Context) {
+	account := currentAccount(c)
+
+	id := c.Query("id")
+	if id == "" {
+		c.JSON(400, gin.H{"error": "Missing required query parameter: id"})
+		return
+	}
+
+	var patch UserContentPatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	project := *currentProject(c)
+	existing, ok := a.userContentForAccount(c, account, project, id)
+	if !ok {
+		return
+	}
+
+	req := UserContentRequest{
+		ID:         existing.ID,
+		Name:       existing.Name,
+		Type:       existing.Type,
+		Visibility: existing.Visibility,
+		Content:    json.RawMessage(existing.Content),
+		Favorite:   patch.Favorite,
+	}
+	if existing.Description.Valid {
+		req.Description = &existing.Description.String
+	}
+	if existing.FolderID.Valid {
+		req.FolderID = &existing.FolderID.String
+	}
+	if patch.Name != nil {
+		req.Name = *patch.Name
+	}
+	if patch.Description != nil {
+		req.Description = patch.Description
+	}
+	if patch.Visibility != nil {
+		req.Visibility = *patch.Visibility
+	}
+	if patch.Content != nil {
+		req.Content = patch.Content
+	}
+	if patch.FolderID != nil {
+		req.FolderID = patch.FolderID
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	a.updateUserContent(c, account, project, existing, req)
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/utils"
+)
+
+type JwtSecretUpdateBody struct {
+	// JwtSecret is generated when empty
+	JwtSecret        string `json:"jwt_secret"`
+	ChangeTrackingID string `json:"change_tracking_id" binding:"omitempty,max=128"`
+}
+
+// patchProjectConfigSecrets starts rotating the project's JWT secret. The rotation runs in the
+// background, its progress is served by the jwt-secret-update-status endpoints
+func (a *Api) patchProjectConfigSecrets(c *gin.Context) {
+	account := currentAccount(c)
+	project := currentProject(c)
+
+	var body JwtSecretUpdateBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if body.JwtSecret != "" && len(body.JwtSecret) < minJwtSecretLength {
+		c.JSON(422, gin.H{"error": fmt.Sprintf("JWT secret must be at least %d characters", minJwtSecretLength)})
+		return
+	}
+	// Recreating the services of a project that is stopped or changing would start them
+	if projectBusyStatuses[project.Status] || project.Status == StatusInactive {
+		c.JSON(409, gin.H{"error": fmt.Sprintf("The JWT secret can't be rotated while the project is %s", project.Status)})
+		return
+	}
+
+	secret := body.JwtSecret
+	if secret == "" {
+		var err error
+		if secret, err = utils.RandomToken(48); err != nil {
+			c.JSON(500, gin.H{"error": "Internal Server Error"})
+			return
+		}
+	}
+	trackingID := body.ChangeTrackingID
+	if trackingID == "" {
+		trackingID = utils.NewUUID()
+	}
+
+	ctx := c.Request.Context()
+	rotation, err := a.startJwtSecretRotation(ctx, database.CreateJwtSecretRotationParams{
+		ProjectID:        project.ID,
+		ChangeTrackingID: trackingID,
+		RequestedBy:      pgtype.Int4{Int32: account.ID, Valid: true},
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		c.JSON(409, gin.H{"error": "A JWT secret rotation is already in progress"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	// The request context ends with the response, the audit entry in it attributes the
+	// provisioner operations to the caller
+	go a.rotateJwtSecret(context.WithoutCancel(ctx), rotation, *project, secret)
+
+	a.logger.Info("Started JWT secret rotation", "project", project.ProjectRef, "rotation", rotation.ID, "account", account.ID)
+	c.JSON(http.StatusAccepted, gin.H{"jwtSecretUpdateStatus": jwtSecretUpdateStatusResponse(rotation)})
+}
+
+// startJwtSecretRotation records a rotation under the project's lock, so it waits for API key
+// changes in flight and the ones after it see it running
+func (a *Api) startJwtSecretRotation(ctx context.Context, params database.CreateJwtSecretRotationParams) (database.JwtSecretRotation, error) {
+	tx, err := a.pgPool.Begin(ctx)
+	if err != nil {
+		return database.JwtSecretRotation{}, err
+	}
+	defer tx.Rollback(ctx)
+	queries := a.queries.WithTx(tx)
+
+	if err := queries.LockProject(ctx, params.ProjectID); err != nil {
+		return database.JwtSecretRotation{}, err
+	}
+	rotation, err := queries.CreateJwtSecretRotation(ctx, params)
+	if err != nil {
+		return database.JwtSecretRotation{}, err
+	}
+	return rotation, tx.Commit(ctx)
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+	"strings"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/utils"
+	"time"
+)
+
+// personalAccessTokenPrefix marks personal access tokens, it's the prefix the Supabase CLI expects
+const personalAccessTokenPrefix = "sbp_"
+
+// personalAccessTokenSize is the number of random bytes, hex encoded after the prefix
+const personalAccessTokenSize = 20
+
+const maxPersonalAccessTokens = 50
+
+var errTokenScope = errors.New("personal access token is not allowed to access this resource")
+
+// newPersonalAccessToken returns a token and the alias shown when listing tokens
+func newPersonalAccessToken() (string, string, error) {
+	b := make([]byte, personalAccessTokenSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := personalAccessTokenPrefix + hex.EncodeToString(b)
+	alias := token[:len(personalAccessTokenPrefix)+4] + "..." + token[len(token)-4:]
+	return token, alias, nil
+}
+
+// personalAccessTokenClaims authenticates a personal access token and checks its scope against the
+// route. The claims look like those of a session, without a session id
+func (a *Api) personalAccessTokenClaims(c *gin.Context, raw string) (database.Account, *AccessTokenClaims, error) {
+	ctx := c.Request.Context()
+	token, err := a.queries.GetActivePersonalAccessToken(ctx, utils.HashToken(raw))
+	if err != nil {
+		return database.Account{}, nil, err
+	}
+	if err := a.checkTokenScope(c, token); err != nil {
+		return database.Account{}, nil, err
+	}
+	account, err := a.queries.GetAccountByID(ctx, token.AccountID)
+	if err != nil {
+		return database.Account{}, nil, err
+	}
+
+	err = a.queries.TouchPersonalAccessToken(ctx, database.TouchPersonalAccessTokenParams{
+		ID:         token.ID,
+		LastUsedIp: pgtype.Text{String: c.ClientIP(), Valid: c.ClientIP() != ""},
+	})
+	if err != nil {
+		a.logger.Warn(fmt.Sprintf("Failed to record personal access token use: %v", err))
+	}
+
+	return account, &AccessTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: account.GotrueID,
+		},
+		Email:               account.Email,
+		AAL:                 token.Aal,
+		AMR:                 []AMREntry{{Method: "token", Timestamp: token.CreatedAt.Time.Unix()}},
+		PersonalAccessToken: &token,
+	}, nil
+}
+
+// checkTokenScope limits a token to its organization or project and to safe methods when read only.
+// Tokens can never manage credentials, a leaked token must not be able to mint more
+func (a *Api) checkTokenScope(c *gin.Context, token database.PersonalAccessToken) error {
+	path := c.FullPath()
+	if strings.HasPrefix(path, "/auth/") || strings.HasPrefix(path, "/profile/access-tokens") || strings.HasPrefix(path, "/profile/sessions") {
+		return errTokenScope
+	}
+	if token.ReadOnly {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			return errTokenScope
+		}
+	}
+
+	if token.ProjectRef.Valid && c.Param("ref") != token.ProjectRef.String {
+		return errTokenScope
+	}
+	if token.OrganizationID.Valid {
+		var organizationID int32
+		switch {
+		case c.Param("slug") != "":
+			org, err := a.queries.GetOrganizationById(c.Request.Context(), c.Param("slug"))
+			if err != nil {
+				return errTokenScope
+			}
+			organizationID = org.ID
+		case c.Param("ref") != "":
+			project, err := a.queries.GetProjectByRef(c.Request.Context(), c.Param("ref"))
+			if err != nil {
+				return errTokenScope
+			}
+			organizationID = project.OrganizationID
+		default:
+			return errTokenScope
+		}
+		if organizationID != token.OrganizationID.Int32 {
+			return errTokenScope
+		}
+	}
+	return nil
+}
+
+// PersonalAccessToken is a token as listed in the profile, the token itself is only returned on creation
+type PersonalAccessToken struct {
+	Id               int64      `json:"id"`
+	Name             string     `json:"name"`
+	TokenAlias       string     `json:"token_alias"`
+	Token            string     `json:"token,omitempty"`
+	ReadOnly         bool       `json:"read_only"`
+	OrganizationSlug *string    `json:"organization_slug"`
+	ProjectRef       *string    `json:"project_ref"`
+	CreatedAt        time.Time  `json:"created_at"`
+	ExpiresAt        *time.Time `json:"expires_at"`
+	LastUsedAt       *time.Time `json:"last_used_at"`
+}
+
+func personalAccessTokenResponse(token database.PersonalAccessToken, organizationSlug *string) PersonalAccessToken {
+	return PersonalAccessToken{
+		Id:               token.ID,
+		Name:             token.Name,
+		TokenAlias:       token.TokenAlias,
+		ReadOnly:         token.ReadOnly,
+		OrganizationSlug: organizationSlug,
+		ProjectRef:       utils.PgTextToPointer(token.ProjectRef),
+		CreatedAt:        token.CreatedAt.Time,
+		ExpiresAt:        utils.PgTimestamptzToPointer(token.ExpiresAt),
+		LastUsedAt:       utils.PgTimestamptzToPointer(token.LastUsedAt),
+	}
+}
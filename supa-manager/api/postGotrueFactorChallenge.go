@@ -0,0 +1,41 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"supamanager.io/supa-manager/database"
+)
+
+func (a *Api) postGotrueFactorChallenge(c *gin.Context) {
+	account := currentAccount(c)
+
+	factor, err := a.queries.GetMfaFactor(c.Request.Context(), database.GetMfaFactorParams{
+		ID:        c.Param("id"),
+		AccountID: account.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(404, gin.H{"error": "Factor not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	challenge, err := a.queries.CreateMfaChallenge(c.Request.Context(), database.CreateMfaChallengeParams{
+		FactorID: factor.ID,
+		Ip:       pgtype.Text{String: c.ClientIP(), Valid: c.ClientIP() != ""},
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"id":         challenge.ID,
+		"type":       factor.FactorType,
+		"expires_at": challenge.CreatedAt.Time.Add(mfaChallengeTtl).Unix(),
+	})
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/utils"
+	"time"
+)
+
+type GotrueFactorVerify struct {
+	ChallengeID string `json:"challenge_id" binding:"required"`
+	Code        string `json:"code" binding:"required"`
+}
+
+// postGotrueFactorVerify checks a TOTP code against a challenge and upgrades the session to aal2.
+// Verifying the first factor of an account also returns its recovery codes
+func (a *Api) postGotrueFactorVerify(c *gin.Context) {
+	account := currentAccount(c)
+	claims := currentClaims(c)
+
+	var body GotrueFactorVerify
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if !a.checkLockout(c, mfaKey(account.ID)) {
+		return
+	}
+
+	tx, err := a.pgPool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+	queries := a.queries.WithTx(tx)
+
+	factor, err := queries.GetMfaFactorForUpdate(ctx, database.GetMfaFactorForUpdateParams{
+		ID:        c.Param("id"),
+		AccountID: account.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(404, gin.H{"error": "Factor not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	challenge, err := queries.GetMfaChallengeForUpdate(ctx, database.GetMfaChallengeForUpdateParams{
+		ID:       body.ChallengeID,
+		FactorID: factor.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(404, gin.H{"error": "Challenge not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if challenge.VerifiedAt.Valid || time.Since(challenge.CreatedAt.Time) > mfaChallengeTtl {
+		c.JSON(422, gin.H{"error": "Challenge has expired, request a new one"})
+		return
+	}
+
+	secret, err := a.decryptFactorSecret(factor)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to verify factor %s: %v", factor.ID, err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	step, ok := utils.ValidateTOTP(secret, body.Code, time.Now())
+	if !ok || totpReplayed(factor, step) {
+		a.recordFailedAttempt(ctx, mfaKey(account.ID), c.ClientIP())
+		c.JSON(422, gin.H{"error": "Invalid TOTP code entered"})
+		return
+	}
+
+	if err := queries.VerifyMfaChallenge(ctx, challenge.ID); err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	err = queries.VerifyMfaFactor(ctx, database.VerifyMfaFactorParams{
+		ID:           factor.ID,
+		LastUsedStep: pgtype.Int8{Int64: step, Valid: true},
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	var extra gin.H
+	if unused, err := queries.CountUnusedMfaRecoveryCodes(ctx, account.ID); err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	} else if unused == 0 {
+		codes, err := newRecoveryCodes(ctx, queries, account.ID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal Server Error"})
+			return
+		}
+		extra = gin.H{"recovery_codes": codes}
+	}
+
+	session, refreshToken, err := a.upgradeSession(ctx, queries, c, account, claims, "totp")
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(401, gin.H{"error": "Unauthorized"})
+		return
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to upgrade session: %v", err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	a.clearFailedAttempts(ctx, mfaKey(account.ID))
+	a.respondWithSession(c, *account, session, refreshToken, extra)
+}
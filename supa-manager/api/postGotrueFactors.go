@@ -0,0 +1,85 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/utils"
+)
+
+type GotrueFactorEnroll struct {
+	FactorType   string `json:"factor_type"`
+	FriendlyName string `json:"friendly_name"`
+	Issuer       string `json:"issuer"`
+}
+
+// postGotrueFactors enrolls an unverified TOTP factor, it becomes usable once verified
+func (a *Api) postGotrueFactors(c *gin.Context) {
+	account := currentAccount(c)
+	claims := currentClaims(c)
+
+	var body GotrueFactorEnroll
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if body.FactorType != "totp" {
+		c.JSON(422, gin.H{"error": "Invalid request body", "details": "factor_type must be totp"})
+		return
+	}
+
+	factors, err := a.queries.GetMfaFactors(c.Request.Context(), account.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if len(factors) >= maxMfaFactors {
+		c.JSON(422, gin.H{"error": "Maximum number of enrolled factors reached"})
+		return
+	}
+	// Adding a factor to an account protected by MFA needs the existing second factor
+	for _, factor := range factors {
+		if factor.Status == "verified" && claims.AAL != aal2 {
+			c.JSON(403, gin.H{"error": "insufficient_aal", "message": "Verify an existing factor before enrolling another one"})
+			return
+		}
+	}
+
+	issuer := body.Issuer
+	if issuer == "" {
+		issuer = a.config.Auth.MfaIssuer
+	}
+	secret, err := utils.NewTOTPSecret()
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	encrypted, err := utils.Encrypt(a.config.EncryptionSecret, []byte(secret))
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	factor, err := a.queries.CreateMfaFactor(c.Request.Context(), database.CreateMfaFactorParams{
+		AccountID:       account.ID,
+		FriendlyName:    pgtype.Text{String: body.FriendlyName, Valid: body.FriendlyName != ""},
+		FactorType:      body.FactorType,
+		SecretEncrypted: encrypted,
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"id":            factor.ID,
+		"type":          factor.FactorType,
+		"friendly_name": body.FriendlyName,
+		"totp": gin.H{
+			// No QR encoder is bundled, authenticator apps accept the URI or the secret
+			"qr_code": "",
+			"secret":  secret,
+			"uri":     utils.TOTPURI(issuer, account.Email, secret),
+		},
+	})
+}
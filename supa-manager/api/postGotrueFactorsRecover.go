@@ -0,0 +1,71 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"supamanager.io/supa-manager/database"
+)
+
+type GotrueFactorsRecover struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// postGotrueFactorsRecover spends a recovery code in place of a TOTP code when the authenticator is lost
+func (a *Api) postGotrueFactorsRecover(c *gin.Context) {
+	account := currentAccount(c)
+	claims := currentClaims(c)
+
+	var body GotrueFactorsRecover
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if !a.checkLockout(c, mfaKey(account.ID)) {
+		return
+	}
+
+	tx, err := a.pgPool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+	queries := a.queries.WithTx(tx)
+
+	used, err := queries.UseMfaRecoveryCode(ctx, database.UseMfaRecoveryCodeParams{
+		AccountID: account.ID,
+		CodeHash:  hashRecoveryCode(body.Code),
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if used == 0 {
+		a.recordFailedAttempt(ctx, mfaKey(account.ID), c.ClientIP())
+		c.JSON(422, gin.H{"error": "Invalid recovery code"})
+		return
+	}
+
+	session, refreshToken, err := a.upgradeSession(ctx, queries, c, account, claims, "recovery")
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(401, gin.H{"error": "Unauthorized"})
+		return
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to upgrade session: %v", err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	a.clearFailedAttempts(ctx, mfaKey(account.ID))
+	a.logger.Warn("Recovery code used", "account", account.ID)
+	a.respondWithSession(c, *account, session, refreshToken, nil)
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// postGotrueFactorsRecoveryCodes replaces the recovery codes of an account protected by MFA
+func (a *Api) postGotrueFactorsRecoveryCodes(c *gin.Context) {
+	account := currentAccount(c)
+	claims := currentClaims(c)
+	if claims.AAL != aal2 {
+		c.JSON(403, gin.H{"error": "insufficient_aal", "message": "Verify a factor before generating recovery codes"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tx, err := a.pgPool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	codes, err := newRecoveryCodes(ctx, a.queries.WithTx(tx), account.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	c.JSON(200, gin.H{"recovery_codes": codes})
+}
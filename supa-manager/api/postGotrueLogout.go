@@ -0,0 +1,44 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"supamanager.io/supa-manager/database"
+)
+
+// postGotrueLogout revokes sessions like GoTrue's /logout, scope is local (default), others or global
+func (a *Api) postGotrueLogout(c *gin.Context) {
+	account := currentAccount(c)
+	claims := currentClaims(c)
+
+	var err error
+	switch c.DefaultQuery("scope", "local") {
+	case "local":
+		if claims.SessionID != "" {
+			err = a.queries.RevokeSessionFamily(c.Request.Context(), database.RevokeSessionFamilyParams{
+				FamilyID:      claims.SessionID,
+				AccountID:     account.ID,
+				RevokedReason: revokedReason(sessionRevokedLogout),
+			})
+		}
+	case "others":
+		err = a.queries.RevokeOtherSessions(c.Request.Context(), database.RevokeOtherSessionsParams{
+			AccountID:     account.ID,
+			FamilyID:      claims.SessionID,
+			RevokedReason: revokedReason(sessionRevokedSignOutOthers),
+		})
+	case "global":
+		err = a.queries.RevokeAllSessions(c.Request.Context(), database.RevokeAllSessionsParams{
+			AccountID:     account.ID,
+			RevokedReason: revokedReason(sessionRevokedLogout),
+		})
+	default:
+		c.JSON(400, gin.H{"error": "Invalid request", "details": "scope must be one of local, others or global"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	c.Status(204)
+}
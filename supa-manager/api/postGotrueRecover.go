@@ -0,0 +1,48 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type GotrueRecover struct {
+	Email      string `json:"email" binding:"required"`
+	RedirectTo string `json:"redirect_to"`
+}
+
+// postGotrueRecover mails a password reset link. The response never reveals whether the account exists
+func (a *Api) postGotrueRecover(c *gin.Context) {
+	var body GotrueRecover
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	redirectTo := c.DefaultQuery("redirect_to", body.RedirectTo)
+
+	// Accounts of SSO domains have no password to reset
+	if a.passwordLoginDisabled(body.Email) {
+		c.JSON(200, gin.H{})
+		return
+	}
+
+	account, err := a.queries.GetAccountByEmail(c.Request.Context(), body.Email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(200, gin.H{})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	err = a.sendAccountToken(c.Request.Context(), account, tokenPurposeRecovery, account.Email, redirectTo)
+	if err != nil && !errors.Is(err, errAccountTokenThrottled) {
+		a.logger.Error(fmt.Sprintf("Failed to send recovery email: %v", err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	c.JSON(200, gin.H{})
+}
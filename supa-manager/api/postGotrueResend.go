@@ -0,0 +1,51 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type GotrueResend struct {
+	Type       string `json:"type" binding:"required"`
+	Email      string `json:"email" binding:"required"`
+	RedirectTo string `json:"redirect_to"`
+}
+
+// postGotrueResend sends the signup confirmation email again
+func (a *Api) postGotrueResend(c *gin.Context) {
+	var body GotrueResend
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if body.Type != tokenPurposeSignup {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": "type must be signup"})
+		return
+	}
+	redirectTo := c.DefaultQuery("redirect_to", body.RedirectTo)
+
+	account, err := a.queries.GetAccountByEmail(c.Request.Context(), body.Email)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && account.EmailConfirmedAt.Valid) {
+		c.JSON(200, gin.H{})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	err = a.sendAccountToken(c.Request.Context(), account, tokenPurposeSignup, account.Email, redirectTo)
+	if errors.Is(err, errAccountTokenThrottled) {
+		c.JSON(429, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to send confirmation email: %v", err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	c.JSON(200, gin.H{})
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+type GotrueSso struct {
+	Domain           string `json:"domain"`
+	ProviderId       string `json:"provider_id"`
+	RedirectTo       string `json:"redirect_to"`
+	SkipHttpRedirect bool   `json:"skip_http_redirect"`
+}
+
+// postGotrueSso is GoTrue's signInWithSSO. There is a single identity provider, so the domain is only
+// checked against the domains that must use it when that list is configured
+func (a *Api) postGotrueSso(c *gin.Context) {
+	if a.sso == nil {
+		c.JSON(404, gin.H{"error": "Single sign-on is not enabled"})
+		return
+	}
+
+	var body GotrueSso
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if body.Domain != "" && len(a.config.Sso.PasswordDisabledDomains) > 0 && !a.passwordLoginDisabled("@"+strings.TrimPrefix(body.Domain, "@")) {
+		c.JSON(404, gin.H{"error": "sso_provider_not_found", "message": "No SSO provider assigned for this domain"})
+		return
+	}
+
+	target, err := a.ssoAuthorizeURL(c.Request.Context(), body.RedirectTo)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to start sso login: %v", err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if body.SkipHttpRedirect {
+		c.JSON(200, gin.H{"url": target})
+		return
+	}
+	c.Redirect(http.StatusSeeOther, target)
+}
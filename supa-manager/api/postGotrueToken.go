@@ -1,15 +1,22 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/matthewhartstonge/argon2"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/utils"
 	"time"
 )
 
 type GotrueToken struct {
 	Email              string `json:"email"`
 	Password           string `json:"password"`
+	RefreshToken       string `json:"refresh_token"`
 	GotrueMetaSecurity struct {
 		CaptchaToken string `json:"captcha_token"`
 	} `json:"gotrue_meta_security"`
@@ -22,46 +29,220 @@ func (a *Api) postGotrueToken(c *gin.Context) {
 		return
 	}
 
+	switch grantType := c.DefaultQuery("grant_type", "password"); grantType {
+	case "password":
+		a.passwordGrant(c, body)
+	case "refresh_token":
+		a.refreshTokenGrant(c, body)
+	default:
+		c.JSON(400, gin.H{"error": "unsupported_grant_type", "error_description": fmt.Sprintf("Unsupported grant type %q", grantType)})
+	}
+}
+
+func (a *Api) passwordGrant(c *gin.Context, body GotrueToken) {
+	if a.passwordLoginDisabled(body.Email) {
+		invalidGrant(c, errPasswordLoginDisabled.Error())
+		return
+	}
+
+	// Refreshes aren't counted, every open Studio tab refreshes its session
+	if !a.allowAuthAttempt(c) {
+		return
+	}
+
+	// Lockouts are keyed on the email, existing or not, so they don't reveal which accounts exist
+	key := loginKey(body.Email)
+	if !a.checkLockout(c, key) {
+		return
+	}
+
 	account, err := a.queries.GetAccountByEmail(c.Request.Context(), body.Email)
+	passwordHash := dummyPasswordHash()
+	if err == nil {
+		passwordHash = []byte(account.PasswordHash)
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		a.logger.Error(fmt.Sprintf("Failed to look up account: %v", err))
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	// Unknown emails and wrong passwords get the same response after the same work
+	verified, verifyErr := argon2.VerifyEncoded([]byte(body.Password), passwordHash)
+	if err != nil || verifyErr != nil || !verified {
+		a.recordFailedAttempt(c.Request.Context(), key, c.ClientIP())
+		invalidGrant(c, "Invalid login credentials")
+		return
+	}
+	a.clearFailedAttempts(c.Request.Context(), key)
+
+	if !account.EmailConfirmedAt.Valid {
+		invalidGrant(c, "Email not confirmed")
+		return
+	}
+
+	sessionID := utils.NewUUID()
+	session, refreshToken, err := a.createSession(c.Request.Context(), a.queries, c, account.ID, sessionID, pgtype.Text{}, SessionGrant{AAL: aal1, AMR: []string{"password"}})
 	if err != nil {
-		c.JSON(404, gin.H{"error": "Account not found"})
+		a.logger.Error(fmt.Sprintf("Failed to create session: %v", err))
+		c.JSON(500, gin.H{"error": "Internal server error"})
 		return
 	}
+	if err := a.queries.DeleteExpiredSessions(c.Request.Context(), account.ID); err != nil {
+		a.logger.Warn(fmt.Sprintf("Failed to delete expired sessions: %v", err))
+	}
+	if err := a.clearSessionSuccessors(c.Request.Context(), a.queries, account.ID); err != nil {
+		a.logger.Warn(fmt.Sprintf("Failed to clear session successors: %v", err))
+	}
 
-	if verified, err := argon2.VerifyEncoded([]byte(body.Password), []byte(account.PasswordHash)); err != nil || !verified {
-		c.JSON(401, gin.H{"error": "Invalid password"})
+	a.respondWithSession(c, account, session, refreshToken, nil)
+}
+
+// refreshTokenGrant rotates a refresh token. Presenting a token that was already rotated means it
+// leaked or was replayed, so the whole session family is revoked
+func (a *Api) refreshTokenGrant(c *gin.Context, body GotrueToken) {
+	ctx := c.Request.Context()
+	if body.RefreshToken == "" {
+		invalidGrant(c, "Invalid Refresh Token: Refresh Token Not Found")
 		return
 	}
 
-	claims := jwt.RegisteredClaims{
-		Issuer:    "supamanager.io",
-		Subject:   account.GotrueID,
-		Audience:  []string{"supamanager.io"},
-		ExpiresAt: &jwt.NumericDate{Time: time.Now().AddDate(0, 0, 1)},
-		NotBefore: &jwt.NumericDate{Time: time.Now()},
-		IssuedAt:  &jwt.NumericDate{Time: time.Now()},
+	tx, err := a.pgPool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+	queries := a.queries.WithTx(tx)
+
+	session, err := queries.GetSessionByTokenHashForUpdate(ctx, utils.HashToken(body.RefreshToken))
+	if errors.Is(err, pgx.ErrNoRows) {
+		invalidGrant(c, "Invalid Refresh Token: Refresh Token Not Found")
+		return
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	// TODO: use a real secret
-	signedJwt, err := token.SignedString([]byte(a.config.JwtSecret))
 	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to look up session: %v", err))
 		c.JSON(500, gin.H{"error": "Internal server error"})
 		return
 	}
 
-	c.JSON(200, gin.H{
-		"access_token": signedJwt,
-		"token_type":   "Bearer",
-		"expires_in":   86400,
-		// TODO: support refresh tokens
-		"refresh_token": signedJwt,
-		// TODO: look at GoTrue code for more fields
-		"user": gin.H{
-			"id":    account.ID,
-			"email": account.Email,
-			"app_metadata": gin.H{
-				"provider": "email",
-			},
-		},
+	if session.RevokedAt.Valid {
+		invalidGrant(c, "Invalid Refresh Token: Session Revoked")
+		return
+	}
+	if session.RotatedAt.Valid && time.Since(session.RotatedAt.Time) <= a.config.Auth.RefreshTokenReuseInterval {
+		// A concurrent refresh or a retry, the client gets the session the token was rotated to
+		successor, refreshToken, err := a.sessionSuccessor(ctx, queries, session)
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Failed to load successor of session %s: %v", session.ID, err))
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+		if refreshToken == "" {
+			invalidGrant(c, "Invalid Refresh Token: Already Used")
+			return
+		}
+		account, err := queries.GetAccountByID(ctx, session.AccountID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+		a.respondWithSession(c, account, successor, refreshToken, nil)
+		return
+	}
+	if session.RotatedAt.Valid {
+		err := queries.RevokeSessionFamily(ctx, database.RevokeSessionFamilyParams{
+			FamilyID:      session.FamilyID,
+			AccountID:     session.AccountID,
+			RevokedReason: revokedReason(sessionRevokedReuse),
+		})
+		if err == nil {
+			err = tx.Commit(ctx)
+		}
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Failed to revoke session %s after refresh token reuse: %v", session.FamilyID, err))
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+		a.logger.Warn("Refresh token reused, session revoked", "session", session.FamilyID, "account", session.AccountID, "ip", c.ClientIP())
+		invalidGrant(c, "Invalid Refresh Token: Already Used")
+		return
+	}
+	if session.ExpiresAt.Time.Before(time.Now()) {
+		invalidGrant(c, "Invalid Refresh Token: Session Expired")
+		return
+	}
+
+	account, err := queries.GetAccountByID(ctx, session.AccountID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	if err := queries.RotateSession(ctx, session.ID); err != nil {
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return
+	}
+	rotated, refreshToken, err := a.createSession(ctx, queries, c, account.ID, session.FamilyID, pgtype.Text{String: session.ID, Valid: true}, SessionGrant{AAL: session.Aal, AMR: session.Amr})
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to rotate session: %v", err))
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return
+	}
+	successor, err := utils.Encrypt(a.config.EncryptionSecret, []byte(refreshToken))
+	if err == nil {
+		err = queries.SetSessionSuccessor(ctx, database.SetSessionSuccessorParams{
+			ID:                      session.ID,
+			SuccessorTokenEncrypted: pgtype.Text{String: successor, Valid: true},
+		})
+	}
+	if err == nil {
+		err = a.clearSessionSuccessors(ctx, queries, account.ID)
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to record successor of session %s: %v", session.ID, err))
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	a.respondWithSession(c, account, rotated, refreshToken, nil)
+}
+
+// clearSessionSuccessors forgets the successor tokens of the account's sessions that were rotated
+// longer than the reuse interval ago, they are only kept for retries within it
+func (a *Api) clearSessionSuccessors(ctx context.Context, queries *database.Queries, accountID int32) error {
+	return queries.ClearSessionSuccessors(ctx, database.ClearSessionSuccessorsParams{
+		AccountID:     accountID,
+		RotatedBefore: pgtype.Timestamptz{Time: time.Now().Add(-a.config.Auth.RefreshTokenReuseInterval), Valid: true},
 	})
 }
+
+// sessionSuccessor returns the session a rotated one was refreshed into with its refresh token. The
+// token is empty when there's none still active, like when it was rotated again or by an MFA step up
+func (a *Api) sessionSuccessor(ctx context.Context, queries *database.Queries, session database.Session) (database.Session, string, error) {
+	if !session.SuccessorTokenEncrypted.Valid {
+		return database.Session{}, "", nil
+	}
+	raw, err := utils.Decrypt(a.config.EncryptionSecret, session.SuccessorTokenEncrypted.String)
+	if err != nil {
+		return database.Session{}, "", err
+	}
+	successor, err := queries.GetSessionByTokenHashForUpdate(ctx, utils.HashToken(string(raw)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return database.Session{}, "", nil
+	}
+	if err != nil {
+		return database.Session{}, "", err
+	}
+	if successor.RevokedAt.Valid || successor.RotatedAt.Valid || successor.ExpiresAt.Time.Before(time.Now()) {
+		return database.Session{}, "", nil
+	}
+	return successor, string(raw), nil
+}
+
+func invalidGrant(c *gin.Context, description string) {
+	c.JSON(400, gin.H{"error": "invalid_grant", "error_description": description})
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+)
+
+type GotrueVerify struct {
+	Type  string `json:"type" binding:"required"`
+	Token string `json:"token" binding:"required"`
+}
+
+// postGotrueVerify consumes an emailed token and returns a session
+func (a *Api) postGotrueVerify(c *gin.Context) {
+	var body GotrueVerify
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	account, session, refreshToken, err := a.useAccountToken(c.Request.Context(), c, body.Type, body.Token)
+	if errors.Is(err, errInvalidAccountToken) {
+		c.JSON(403, gin.H{"error": "access_denied", "error_description": err.Error()})
+		return
+	}
+	if errors.Is(err, errEmailInUse) {
+		c.JSON(409, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to verify %s token: %v", body.Type, err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	a.respondWithSession(c, account, session, refreshToken, nil)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+	"strings"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/permisions"
+	"supamanager.io/supa-manager/utils"
+)
+
+// postOrganizationInvitationByToken accepts an invitation. Only the invited, confirmed email
+// address can use the link, and only once
+func (a *Api) postOrganizationInvitationByToken(c *gin.Context) {
+	account := currentAccount(c)
+	ctx := c.Request.Context()
+
+	org, err := a.queries.GetOrganizationById(ctx, c.Param("slug"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(404, gin.H{"error": errInvitationInvalid.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	tx, err := a.pgPool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+	queries := a.queries.WithTx(tx)
+
+	invitation, err := queries.AcceptOrganizationInvitation(ctx, database.AcceptOrganizationInvitationParams{
+		TokenHash:  utils.HashToken(c.Param("token")),
+		AcceptedBy: pgtype.Int4{Int32: account.ID, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && invitation.OrganizationID != org.ID) {
+		c.JSON(404, gin.H{"error": errInvitationInvalid.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	// Rolling back leaves the invitation usable by the right account
+	if !strings.EqualFold(invitation.InvitedEmail, account.Email) || !account.EmailConfirmedAt.Valid {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden", "message": "This invitation was sent to a different email address"})
+		return
+	}
+
+	// Existing members keep their role
+	err = queries.EnsureOrganizationMembership(ctx, database.EnsureOrganizationMembershipParams{
+		OrganizationID: org.ID,
+		AccountID:      account.ID,
+		Role:           invitation.Role,
+	})
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to add member: %v", err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	a.logger.Info("Invitation accepted", "organization", org.Slug, "account", account.ID, "role", invitation.Role)
+	c.JSON(http.StatusCreated, gin.H{"organization_slug": org.Slug, "role_id": permisions.RoleID(invitation.Role)})
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"strings"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/permisions"
+)
+
+type CreateOrganizationInvitation struct {
+	Email  string `json:"email" binding:"required,email"`
+	RoleID int32  `json:"role_id" binding:"required"`
+}
+
+// postOrganizationInvitations invites an email address with a role and mails it the link to join
+func (a *Api) postOrganizationInvitations(c *gin.Context) {
+	org := currentOrganization(c)
+
+	var body CreateOrganizationInvitation
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	role, ok := permisions.RoleByID(body.RoleID)
+	if !ok {
+		c.JSON(422, gin.H{"error": fmt.Sprintf("Unknown role %d", body.RoleID)})
+		return
+	}
+	email := strings.ToLower(strings.TrimSpace(body.Email))
+
+	allowed, err := a.allowedOn(c, actionInvitationCreate, roleAttributes(role))
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden", "message": fmt.Sprintf("Your role does not allow inviting members as %s", permisions.RoleName(role))})
+		return
+	}
+
+	ctx := c.Request.Context()
+	existing, err := a.queries.GetAccountByEmail(ctx, email)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if err == nil {
+		_, err := a.queries.GetOrganizationMemberRole(ctx, database.GetOrganizationMemberRoleParams{
+			OrganizationID: org.ID,
+			AccountID:      existing.ID,
+		})
+		if err == nil {
+			c.JSON(409, gin.H{"error": "This user is already a member of the organization"})
+			return
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(500, gin.H{"error": "Internal Server Error"})
+			return
+		}
+	}
+
+	invitation, err := a.sendInvitation(ctx, c, *org, email, role)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to invite %s to %s: %v", email, org.Slug, err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	a.logger.Info("Invited member", "organization", org.Slug, "email", email, "role", role)
+	c.JSON(http.StatusCreated, organizationInvitationResponse(invitation))
+}
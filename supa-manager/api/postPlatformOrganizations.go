@@ -1,44 +1,79 @@
 package api
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"strings"
 	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/permisions"
 )
 
 type CreateOrgParams struct {
-	Name string `json:"name"`
+	Name string `json:"name" binding:"required"`
 	Kind string `json:"kind"`
 	Size string `json:"size"`
 	Tier string `json:"tier"`
 }
 
-// TODO: use tx
+// postPlatformOrganizations creates an organization owned by the caller, the organization and
+// its first membership are written together
 func (a *Api) postPlatformOrganizations(c *gin.Context) {
-	account, err := a.GetAccountFromRequest(c)
-	if err != nil {
-		c.JSON(401, gin.H{"error": "Unauthorized"})
-		return
-	}
+	account := currentAccount(c)
 
 	var params CreateOrgParams
 	if err := c.ShouldBindJSON(&params); err != nil {
-		c.JSON(400, gin.H{"error": "Bad Request"})
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Name == "" || len(params.Name) > 100 {
+		c.JSON(422, gin.H{"error": "Name must be between 1 and 100 characters"})
+		return
+	}
+	plan, ok := planFromTier(params.Tier)
+	if !ok {
+		c.JSON(422, gin.H{"error": fmt.Sprintf("Unknown tier %q", params.Tier)})
 		return
 	}
 
-	org, err := a.queries.CreateOrganization(c.Request.Context(), params.Name)
+	ctx := c.Request.Context()
+	tx, err := a.pgPool.Begin(ctx)
 	if err != nil {
-		println(err.Error())
 		c.JSON(500, gin.H{"error": "Internal Server Error"})
 		return
 	}
+	defer tx.Rollback(ctx)
+	queries := a.queries.WithTx(tx)
 
-	_, err = a.queries.CreateOrganizationMembership(c.Request.Context(), database.CreateOrganizationMembershipParams{
+	org, err := queries.CreateOrganization(ctx, database.CreateOrganizationParams{
+		Name:         params.Name,
+		Kind:         textOrEmpty(params.Kind),
+		Size:         textOrEmpty(params.Size),
+		Plan:         plan,
+		BillingEmail: textOrEmpty(account.Email),
+	})
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to create organization: %v", err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	_, err = queries.CreateOrganizationMembership(ctx, database.CreateOrganizationMembershipParams{
 		OrganizationID: org.ID,
 		AccountID:      account.ID,
-		Role:           "OWNER",
+		Role:           permisions.RoleOwner,
 	})
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to add organization owner: %v", err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
 
-	c.AbortWithStatus(http.StatusCreated)
+	setAuditScope(c, org.ID, "")
+	a.logger.Info("Created organization", "organization", org.Slug, "plan", plan, "account", account.ID)
+	c.JSON(http.StatusCreated, organizationResponse(org, true))
 }
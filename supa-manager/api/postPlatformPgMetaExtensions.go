@@ -0,0 +1,29 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"supamanager.io/supa-manager/pgmeta"
+)
+
+func (a *Api) postPlatformPgMetaExtensions(c *gin.Context) {
+	var req pgmeta.ExtensionCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	pool, ok := a.projectPoolFromRequest(c)
+	if !ok {
+		return
+	}
+
+	extension, err := pgmeta.CreateExtension(c.Request.Context(), pool, req)
+	if err != nil {
+		a.respondQueryError(c, err)
+		return
+	}
+
+	a.logger.Info("Created extension", "project", c.Param("ref"), "extension", req.Name)
+	c.JSON(http.StatusOK, extension)
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"supamanager.io/supa-manager/pgmeta"
+)
+
+func (a *Api) postPlatformPgMetaFunctions(c *gin.Context) {
+	var req pgmeta.FunctionCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	pool, ok := a.projectPoolFromRequest(c)
+	if !ok {
+		return
+	}
+
+	function, err := pgmeta.CreateFunction(c.Request.Context(), pool, req)
+	if err != nil {
+		a.respondQueryError(c, err)
+		return
+	}
+
+	a.logger.Info("Created function", "project", c.Param("ref"), "function", req.Name)
+	c.JSON(http.StatusOK, function)
+}
@@ -1,8 +1,15 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
 	"net/http"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/pgmeta"
+	"time"
 )
 
 type PgMetaQueryRequest struct {
@@ -10,15 +17,7 @@ type PgMetaQueryRequest struct {
 }
 
 func (a *Api) postPlatformPgMetaQuery(c *gin.Context) {
-	_, err := a.GetAccountFromRequest(c)
-	if err != nil {
-		println(err.Error())
-		c.JSON(401, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	projectRef := c.Param("ref")
-	key := c.Query("key")
+	account := currentAccount(c)
 
 	var req PgMetaQueryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -26,41 +25,60 @@ func (a *Api) postPlatformPgMetaQuery(c *gin.Context) {
 		return
 	}
 
-	// For now, return mock data based on the query key
-	// TODO: In Phase 3, connect to actual project's PostgreSQL database
-	switch key {
-	case "schemas-list":
-		// Return mock schema list
-		c.JSON(http.StatusOK, []gin.H{
-			{
-				"id":    1,
-				"name":  "public",
-				"owner": "postgres",
-			},
-		})
-	case "tables-list":
-		// Return empty tables list for now
-		c.JSON(http.StatusOK, []interface{}{})
-	case "columns-list":
-		// Return empty columns list
-		c.JSON(http.StatusOK, []interface{}{})
-	case "functions-list":
-		// Return empty functions list
-		c.JSON(http.StatusOK, []interface{}{})
-	case "types-list":
-		// Return empty types list
-		c.JSON(http.StatusOK, []interface{}{})
-	default:
-		// For unknown keys, return empty result with proper format
-		// executeSql wraps the response in { result: data }, so we just return the array
-		a.logger.Info("Unknown pg-meta query key", "key", key, "project", projectRef)
-		c.JSON(http.StatusOK, []gin.H{
-			{
-				"data": gin.H{
-					"entities": []interface{}{},
-					"count":    0,
-				},
-			},
-		})
+	project := *currentProject(c)
+	pool, ok := a.projectPoolOrRespond(c, project)
+	if !ok {
+		return
+	}
+
+	// The "key" query param is only Studio's cache key, the SQL itself is executed as-is
+	started := time.Now()
+	result, err := pgmeta.Execute(c.Request.Context(), pool, req.Query, a.config.PgMeta.MaxRows)
+
+	// Studio's own metadata queries always carry a cache key, queries typed into the
+	// SQL editor do not, and only those belong in the user's history
+	if c.Query("key") == "" {
+		a.recordQueryHistory(project, account, req.Query, time.Since(started), result, err)
+	}
+
+	if err != nil {
+		a.respondQueryError(c, err)
+		return
+	}
+
+	if result.Truncated {
+		a.logger.Warn("pg-meta query result truncated",
+			"project", c.Param("ref"),
+			"key", c.Query("key"),
+			"max_rows", a.config.PgMeta.MaxRows)
+		c.Header("X-Result-Truncated", "true")
+	}
+
+	c.JSON(http.StatusOK, result.Rows)
+}
+
+func (a *Api) recordQueryHistory(project database.Project, account *database.Account, query string, duration time.Duration, result *pgmeta.Result, queryErr error) {
+	params := database.CreateQueryHistoryParams{
+		ProjectID:  project.ID,
+		AccountID:  account.ID,
+		Query:      query,
+		Success:    queryErr == nil,
+		DurationMs: int32(duration.Milliseconds()),
+	}
+	if result != nil {
+		params.RowCount = int32(len(result.Rows))
+	}
+	if queryErr != nil {
+		message := queryErr.Error()
+		var pgErr *pgmeta.QueryError
+		if errors.As(queryErr, &pgErr) {
+			message = pgErr.Message
+		}
+		params.ErrorMessage = pgtype.Text{String: message, Valid: true}
+	}
+
+	// History is best effort and must not fail the query, nor be cancelled with the request
+	if err := a.queries.CreateQueryHistory(context.Background(), params); err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to record query history for project %s: %v", project.ProjectRef, err))
 	}
 }
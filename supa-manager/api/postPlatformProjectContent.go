@@ -0,0 +1,23 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func (a *Api) postPlatformProjectContent(c *gin.Context) {
+	account := currentAccount(c)
+
+	var req UserContentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	project := *currentProject(c)
+
+	a.createUserContent(c, account, project, req)
+}
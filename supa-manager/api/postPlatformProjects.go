@@ -2,12 +2,15 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 	"net/http"
 	"strings"
 	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/dnshook"
 	"supamanager.io/supa-manager/utils"
 )
 
@@ -48,31 +51,82 @@ type ProjectCreationResponse struct {
 }
 
 func (a *Api) postPlatformProjects(c *gin.Context) {
-	_, err := a.GetAccountFromRequest(c)
+	var createProject ProjectCreationBody
+	if err := c.BindJSON(&createProject); err != nil {
+		c.JSON(400, gin.H{"error": "Bad Request"})
+		return
+	}
+
+	allowed, err := a.can(c.Request.Context(), currentAccount(c), actionProjectCreate, authzResource{OrganizationID: createProject.OrgId})
+	if errors.Is(err, errNotMember) {
+		c.JSON(404, gin.H{"error": "Organization not found"})
+		return
+	}
 	if err != nil {
-		c.JSON(401, gin.H{"error": "Unauthorized"})
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if !allowed {
+		c.JSON(403, gin.H{"error": "Forbidden", "message": "Your role does not allow creating projects"})
 		return
 	}
 
-	var createProject ProjectCreationBody
-	if err := c.BindJSON(&createProject); err != nil {
-		c.JSON(400, gin.H{"error": "Bad Request"})
+	dbPassword, err := utils.Encrypt(a.config.EncryptionSecret, []byte(createProject.DbPass))
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
 		return
 	}
 
-	proj, err := a.queries.CreateProject(c.Request.Context(), database.CreateProjectParams{
-		ProjectRef:     utils.GenerateProjectRef(createProject.Name),
-		ProjectName:    createProject.Name,
-		OrganizationID: createProject.OrgId,
-		JwtSecret:      uuid.New().String(),
-		CloudProvider:  strings.ToUpper(createProject.CloudProvider),
-		Region:         strings.ToUpper(createProject.DbRegion),
+	ctx := c.Request.Context()
+	tx, err := a.pgPool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+	queries := a.queries.WithTx(tx)
+
+	// The lock keeps concurrent creations and transfers from going over the plan's project limit
+	org, err := queries.LockOrganization(ctx, createProject.OrgId)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if limit, ok := planProjectLimits[org.Plan]; ok {
+		count, err := queries.CountProjectsForOrganization(ctx, org.ID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal Server Error"})
+			return
+		}
+		if count >= limit {
+			c.JSON(422, gin.H{"error": fmt.Sprintf("The %s plan allows at most %d projects per organization", organizationPlanNames[org.Plan], limit)})
+			return
+		}
+	}
+
+	proj, err := queries.CreateProject(ctx, database.CreateProjectParams{
+		ProjectRef:          utils.GenerateProjectRef(createProject.Name),
+		ProjectName:         createProject.Name,
+		OrganizationID:      createProject.OrgId,
+		JwtSecret:           uuid.New().String(),
+		CloudProvider:       strings.ToUpper(createProject.CloudProvider),
+		Region:              strings.ToUpper(createProject.DbRegion),
+		DbPasswordEncrypted: pgtype.Text{String: dbPassword, Valid: true},
 	})
 
 	if err != nil {
 		c.JSON(500, gin.H{"error": "Internal Server Error"})
 		return
 	}
+	if err := a.enqueueDnsUpdate(ctx, queries, a.projectDnsUpdate(dnshook.UpdateCreate, proj.ProjectRef)); err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	setAuditScope(c, proj.OrganizationID, proj.ProjectRef)
 
 	// Trigger async provisioning if enabled
 	if a.provisioner != nil {
@@ -1,9 +1,17 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"strings"
 	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/mailer"
+	"time"
 )
 
 type PlatformSignupBody struct {
@@ -25,33 +33,84 @@ func (a *Api) postPlatformSignup(c *gin.Context) {
 		return
 	}
 
-	_, err := a.queries.GetAccountByEmail(c.Request.Context(), body.Email)
-	if err == nil {
-		c.JSON(409, gin.H{"error": "Email already in use"})
+	if a.passwordLoginDisabled(body.Email) {
+		c.JSON(403, gin.H{"error": errPasswordLoginDisabled.Error()})
 		return
 	}
 
-	if err != pgx.ErrNoRows {
-		c.JSON(500, gin.H{"error": "Internal server error"})
+	if err := validatePassword(body.Password); err != nil {
+		c.JSON(422, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Signing up with a taken address answers like a new signup, the address's owner is mailed
+	// instead so the response doesn't tell which addresses have accounts. Both hash the password
+	// and mail in the background so they take the same time too
 	hash, err := a.argon.HashEncoded([]byte(body.Password))
 	if err != nil {
 		c.JSON(500, gin.H{"error": "Internal server error"})
 		return
 	}
+	// The request context ends with the response, the mails are sent after it
+	ctx := context.WithoutCancel(c.Request.Context())
 
-	_, err = a.queries.CreateAccount(c.Request.Context(), database.CreateAccountParams{
-		Email:        body.Email,
-		PasswordHash: string(hash),
-		Username:     "idekman",
-	})
+	existing, err := a.queries.GetAccountByEmail(c.Request.Context(), body.Email)
+	if err == nil {
+		go a.notifyExistingSignup(ctx, existing, body.RedirectTo)
+		c.JSON(200, gin.H{"status": "CREATED"})
+		return
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return
+	}
 
+	account, err := a.queries.CreateAccount(c.Request.Context(), database.CreateAccountParams{
+		Email:            body.Email,
+		PasswordHash:     string(hash),
+		Username:         usernameFromEmail(body.Email),
+		EmailConfirmedAt: pgtype.Timestamptz{Time: time.Now(), Valid: a.config.Auth.Autoconfirm},
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		// A concurrent signup with the same address won
+		c.JSON(200, gin.H{"status": "CREATED"})
+		return
+	}
 	if err != nil {
 		c.JSON(500, gin.H{"error": "Internal server error"})
 		return
 	}
 
+	if !a.config.Auth.Autoconfirm {
+		go func() {
+			if err := a.sendAccountToken(ctx, account, tokenPurposeSignup, account.Email, body.RedirectTo); err != nil {
+				a.logger.Error(fmt.Sprintf("Failed to send confirmation email: %v", err))
+			}
+		}()
+	}
+
 	c.JSON(200, gin.H{"status": "CREATED"})
 }
+
+// notifyExistingSignup resends the confirmation link of an unconfirmed account, a confirmed one is
+// told somebody signed up with its address and given a password reset link
+func (a *Api) notifyExistingSignup(ctx context.Context, account database.Account, redirectTo string) {
+	var err error
+	if account.EmailConfirmedAt.Valid {
+		err = a.sendAccountTokenMessage(ctx, account, tokenPurposeRecovery, account.Email, redirectTo, mailer.AccountExistsMessage)
+	} else {
+		err = a.sendAccountToken(ctx, account, tokenPurposeSignup, account.Email, redirectTo)
+	}
+	if err != nil && !errors.Is(err, errAccountTokenThrottled) {
+		a.logger.Error(fmt.Sprintf("Failed to notify account %d of a signup: %v", account.ID, err))
+	}
+}
+
+// usernameFromEmail derives the default username from the local part of the address
+func usernameFromEmail(email string) string {
+	if at := strings.LastIndex(email, "@"); at > 0 {
+		return email[:at]
+	}
+	return email
+}
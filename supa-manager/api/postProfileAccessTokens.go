@@ -0,0 +1,121 @@
+package api
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"strings"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/utils"
+	"time"
+)
+
+type CreatePersonalAccessToken struct {
+	Name             string     `json:"name" binding:"required"`
+	ReadOnly         bool       `json:"read_only"`
+	OrganizationSlug string     `json:"organization_slug"`
+	ProjectRef       string     `json:"project_ref"`
+	ExpiresAt        *time.Time `json:"expires_at"`
+}
+
+// postProfileAccessTokens creates a personal access token, the token is only ever returned here
+func (a *Api) postProfileAccessTokens(c *gin.Context) {
+	account := currentAccount(c)
+	claims := currentClaims(c)
+
+	var body CreatePersonalAccessToken
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	body.Name = strings.TrimSpace(body.Name)
+	if body.Name == "" || len(body.Name) > 100 {
+		c.JSON(422, gin.H{"error": "Name must be between 1 and 100 characters"})
+		return
+	}
+	if body.OrganizationSlug != "" && body.ProjectRef != "" {
+		c.JSON(422, gin.H{"error": "A token can be scoped to an organization or a project, not both"})
+		return
+	}
+	if body.ExpiresAt != nil && !body.ExpiresAt.After(time.Now()) {
+		c.JSON(422, gin.H{"error": "Expiry must be in the future"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	count, err := a.queries.CountPersonalAccessTokens(ctx, account.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if count >= maxPersonalAccessTokens {
+		c.JSON(422, gin.H{"error": fmt.Sprintf("An account can have at most %d access tokens", maxPersonalAccessTokens)})
+		return
+	}
+
+	// Tokens can only be scoped to resources the account is a member of
+	var organizationID pgtype.Int4
+	var organizationSlug *string
+	if body.OrganizationSlug != "" {
+		organizations, err := a.queries.GetOrganizationsForAccountId(ctx, account.ID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal Server Error"})
+			return
+		}
+		for _, org := range organizations {
+			if org.Slug == body.OrganizationSlug {
+				organizationID = pgtype.Int4{Int32: org.ID, Valid: true}
+				organizationSlug = &body.OrganizationSlug
+			}
+		}
+		if !organizationID.Valid {
+			c.JSON(404, gin.H{"error": "Organization not found"})
+			return
+		}
+	}
+	if body.ProjectRef != "" {
+		projects, err := a.queries.GetProjectsForAccountId(ctx, account.ID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal Server Error"})
+			return
+		}
+		found := false
+		for _, project := range projects {
+			found = found || project.ProjectRef == body.ProjectRef
+		}
+		if !found {
+			c.JSON(404, gin.H{"error": "Project not found"})
+			return
+		}
+	}
+
+	plain, alias, err := newPersonalAccessToken()
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	expiresAt := pgtype.Timestamptz{}
+	if body.ExpiresAt != nil {
+		expiresAt = pgtype.Timestamptz{Time: *body.ExpiresAt, Valid: true}
+	}
+	token, err := a.queries.CreatePersonalAccessToken(ctx, database.CreatePersonalAccessTokenParams{
+		AccountID:      account.ID,
+		Name:           body.Name,
+		TokenHash:      utils.HashToken(plain),
+		TokenAlias:     alias,
+		ReadOnly:       body.ReadOnly,
+		OrganizationID: organizationID,
+		ProjectRef:     pgtype.Text{String: body.ProjectRef, Valid: body.ProjectRef != ""},
+		Aal:            claims.AAL,
+		ExpiresAt:      expiresAt,
+	})
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to create personal access token: %v", err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	response := personalAccessTokenResponse(token, organizationSlug)
+	response.Token = plain
+	c.JSON(201, response)
+}
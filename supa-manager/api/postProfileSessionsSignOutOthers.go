@@ -0,0 +1,24 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"supamanager.io/supa-manager/database"
+)
+
+// postProfileSessionsSignOutOthers revokes every session of the account except the calling one
+func (a *Api) postProfileSessionsSignOutOthers(c *gin.Context) {
+	account := currentAccount(c)
+	claims := currentClaims(c)
+
+	err := a.queries.RevokeOtherSessions(c.Request.Context(), database.RevokeOtherSessionsParams{
+		AccountID:     account.ID,
+		FamilyID:      claims.SessionID,
+		RevokedReason: revokedReason(sessionRevokedSignOutOthers),
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "OK"})
+}
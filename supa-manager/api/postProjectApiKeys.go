@@ -0,0 +1,95 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"supamanager.io/supa-manager/database"
+	"time"
+)
+
+type CreateProjectApiKey struct {
+	Name      string     `json:"name" binding:"required"`
+	Role      string     `json:"role" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// postProjectApiKeys creates a named API key, the key is only ever returned here
+func (a *Api) postProjectApiKeys(c *gin.Context) {
+	account := currentAccount(c)
+	project := currentProject(c)
+
+	var body CreateProjectApiKey
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if !validApiKeyName.MatchString(body.Name) {
+		c.JSON(422, gin.H{"error": "Name must be up to 63 lowercase letters, digits, dashes and underscores"})
+		return
+	}
+	if !validApiKeyRole.MatchString(body.Role) {
+		c.JSON(422, gin.H{"error": "Role must be a database role name"})
+		return
+	}
+	if body.ExpiresAt != nil && !body.ExpiresAt.After(time.Now()) {
+		c.JSON(422, gin.H{"error": "Expiry must be in the future"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	count, err := a.queries.CountProjectApiKeys(ctx, project.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if count >= maxProjectApiKeys {
+		c.JSON(422, gin.H{"error": fmt.Sprintf("A project can have at most %d API keys", maxProjectApiKeys)})
+		return
+	}
+
+	expiresAt := pgtype.Timestamptz{}
+	if body.ExpiresAt != nil {
+		expiresAt = pgtype.Timestamptz{Time: *body.ExpiresAt, Valid: true}
+	}
+	var key database.ProjectApiKey
+	var plain string
+	err = a.changeProjectApiKeys(ctx, project, func(queries *database.Queries, locked database.Project) error {
+		var alias, encrypted string
+		var err error
+		plain, alias, encrypted, err = a.mintProjectApiKey(locked.JwtSecret, body.Role, expiresAt)
+		if err != nil {
+			return err
+		}
+		key, err = queries.CreateProjectApiKey(ctx, database.CreateProjectApiKeyParams{
+			ProjectID:    project.ID,
+			Name:         body.Name,
+			Role:         body.Role,
+			KeyEncrypted: encrypted,
+			KeyAlias:     alias,
+			CreatedBy:    pgtype.Int4{Int32: account.ID, Valid: true},
+			ExpiresAt:    expiresAt,
+		})
+		return err
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		c.JSON(409, gin.H{"error": fmt.Sprintf("An API key named %s already exists", body.Name)})
+		return
+	}
+	if errors.Is(err, errJwtSecretRotating) {
+		c.JSON(409, gin.H{"error": "API keys can't be changed while the JWT secret is being rotated"})
+		return
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to create API key for project %s: %v", project.ProjectRef, err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	response := projectApiKeyResponse(key)
+	response.ApiKey = plain
+	c.JSON(201, response)
+}
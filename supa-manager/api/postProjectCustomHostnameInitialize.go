@@ -0,0 +1,85 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/utils"
+)
+
+type InitializeCustomHostname struct {
+	CustomHostname string `json:"custom_hostname" binding:"required"`
+}
+
+// postProjectCustomHostnameInitialize registers the hostname a project is served on besides its
+// own subdomain. Registering the same hostname again returns it unchanged. A hostname is only taken
+// once a project verified it, until then any project can register it
+func (a *Api) postProjectCustomHostnameInitialize(c *gin.Context) {
+	project := currentProject(c)
+	if a.certs == nil {
+		c.JSON(422, gin.H{"error": "Custom hostnames need ACME to be enabled to issue their certificates"})
+		return
+	}
+
+	var body InitializeCustomHostname
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	hostname, err := a.normalizeCustomHostname(body.CustomHostname)
+	if err != nil {
+		c.JSON(422, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	custom, err := a.queries.GetCustomHostnameByProject(ctx, project.ID)
+	if err == nil {
+		if custom.Hostname != hostname {
+			c.JSON(409, gin.H{"error": fmt.Sprintf("The project already uses %s, delete it first", custom.Hostname)})
+			return
+		}
+		c.JSON(200, a.customHostnameResponse(ctx, project.ProjectRef, custom))
+		return
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	claimed, err := a.queries.IsCustomHostnameClaimed(ctx, hostname)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if claimed {
+		c.JSON(409, gin.H{"error": fmt.Sprintf("%s is already used by a project", hostname)})
+		return
+	}
+
+	token, err := utils.RandomToken(24)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	custom, err = a.queries.CreateCustomHostname(ctx, database.CreateCustomHostnameParams{
+		ProjectID:         project.ID,
+		Hostname:          hostname,
+		VerificationToken: "supamanager-verification=" + token,
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		c.JSON(409, gin.H{"error": "The project already has a custom hostname"})
+		return
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to create custom hostname for project %s: %v", project.ProjectRef, err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	c.JSON(201, a.customHostnameResponse(ctx, project.ProjectRef, custom))
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// postProjectCustomHostnameReverify checks the DNS records of the custom hostname now instead of
+// waiting for the background check
+func (a *Api) postProjectCustomHostnameReverify(c *gin.Context) {
+	project := currentProject(c)
+
+	ctx := c.Request.Context()
+	custom, err := a.queries.GetCustomHostnameByProject(ctx, project.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(404, gin.H{"error": "Custom hostname not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	custom, err = a.checkCustomHostname(ctx, project.ProjectRef, custom)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to check custom hostname %s: %v", custom.Hostname, err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	c.JSON(200, a.customHostnameResponse(ctx, project.ProjectRef, custom))
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+)
+
+// postProjectDatabaseBackups backs the project's database up now. The backup is catalogued with
+// its SHA-256, which the verification job checks before restoring it
+func (a *Api) postProjectDatabaseBackups(c *gin.Context) {
+	project := currentProject(c)
+
+	if a.provisioner == nil || !project.ProvisionedAt.Valid {
+		c.JSON(409, gin.H{"error": "Project is not provisioned"})
+		return
+	}
+
+	backup, err := a.createProjectBackup(c.Request.Context(), *project)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to back up project %s: %v", project.ProjectRef, err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	c.JSON(201, projectBackupResponse(backup))
+}
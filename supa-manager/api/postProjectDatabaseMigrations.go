@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/pgmeta"
+	"time"
+)
+
+// MigrationRequest describes a migration either as a single query or as a list of statements
+type MigrationRequest struct {
+	Version    string   `json:"version"`
+	Name       string   `json:"name"`
+	Query      string   `json:"query"`
+	Statements []string `json:"statements"`
+}
+
+func (r MigrationRequest) migration() pgmeta.Migration {
+	statements := r.Statements
+	if len(statements) == 0 && r.Query != "" {
+		statements = []string{r.Query}
+	}
+	return pgmeta.Migration{Version: r.Version, Name: r.Name, Statements: statements}
+}
+
+func (a *Api) postProjectDatabaseMigrations(c *gin.Context) {
+	account := currentAccount(c)
+
+	var req MigrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	migration := req.migration()
+	if migration.Version == "" {
+		migration.Version = pgmeta.NewMigrationVersion(time.Now())
+	}
+	if err := pgmeta.ValidateMigration(migration); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, err := a.queries.GetProjectByRef(c.Request.Context(), c.Param("ref"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+			return
+		}
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	pool, ok := a.projectPoolOrRespond(c, project)
+	if !ok {
+		return
+	}
+
+	// The project reports RUNNING_MIGRATIONS while the transaction runs and goes back to its
+	// previous status afterwards, whatever the outcome. Both changes only apply when nothing else
+	// changed the status in between: an overlapping migration leaves it to the first one, and a
+	// pause or removal meanwhile isn't overwritten. ApplyMigration serializes the migrations
+	if a.swapProjectStatus(c.Request.Context(), project.ProjectRef, project.Status, StatusRunningMigrations) {
+		defer a.swapProjectStatus(context.Background(), project.ProjectRef, StatusRunningMigrations, project.Status)
+	}
+
+	applied, err := pgmeta.ApplyMigration(c.Request.Context(), pool, migration, account.Email)
+	if err != nil {
+		var queryErr *pgmeta.QueryError
+		switch {
+		case errors.Is(err, pgmeta.ErrMigrationExists):
+			c.JSON(http.StatusConflict, gin.H{"status": StatusMigrationsFailed, "error": fmt.Sprintf("Migration %s has already been applied", migration.Version)})
+		case errors.As(err, &queryErr):
+			a.logger.Info("Migration failed", "project", project.ProjectRef, "version", migration.Version, "error", queryErr.Message)
+			c.JSON(http.StatusBadRequest, gin.H{"status": StatusMigrationsFailed, "error": queryErr})
+		default:
+			a.logger.Error(fmt.Sprintf("Failed to apply migration %s to project %s: %v", migration.Version, project.ProjectRef, err))
+			c.JSON(http.StatusInternalServerError, gin.H{"status": StatusMigrationsFailed, "error": "Internal Server Error"})
+		}
+		return
+	}
+
+	a.logger.Info("Migration applied", "project", project.ProjectRef, "version", applied.Version, "by", account.Email)
+	c.JSON(http.StatusCreated, gin.H{"status": StatusMigrationsPassed, "migration": applied})
+}
+
+// swapProjectStatus sets the status of a project if it's still the expected one and reports
+// whether it did
+func (a *Api) swapProjectStatus(ctx context.Context, ref string, expected Status, status Status) bool {
+	if expected == status {
+		return false
+	}
+	swapped, err := a.queries.SwapProjectStatus(ctx, database.SwapProjectStatusParams{
+		Status:         status,
+		ProjectRef:     ref,
+		ExpectedStatus: expected,
+	})
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to set project %s status to %s: %v", ref, status, err))
+		return false
+	}
+	if swapped == 0 {
+		return false
+	}
+	a.invalidateEdgeRoute(ref)
+	return true
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"supamanager.io/supa-manager/pgmeta"
+)
+
+type MigrationDiffRequest struct {
+	Migrations []MigrationRequest `json:"migrations" binding:"required"`
+}
+
+func (a *Api) postProjectDatabaseMigrationsDiff(c *gin.Context) {
+	var req MigrationDiffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	target := make([]pgmeta.Migration, len(req.Migrations))
+	for i, m := range req.Migrations {
+		target[i] = m.migration()
+		if err := pgmeta.ValidateMigration(target[i]); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "version": m.Version})
+			return
+		}
+	}
+
+	pool, ok := a.projectPoolFromRequest(c)
+	if !ok {
+		return
+	}
+
+	diff, err := pgmeta.DiffMigrations(c.Request.Context(), pool, target)
+	if err != nil {
+		a.respondQueryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
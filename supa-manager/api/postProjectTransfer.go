@@ -0,0 +1,70 @@
+package api
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"supamanager.io/supa-manager/database"
+)
+
+// postProjectTransfer moves a project to another organization after the same checks as the preview
+func (a *Api) postProjectTransfer(c *gin.Context) {
+	project := currentProject(c)
+
+	var body ProjectTransferBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	source, target, ok := a.projectTransferOrganizations(c, body)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	tx, err := a.pgPool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+	queries := a.queries.WithTx(tx)
+
+	// Locking the target serializes transfers and project creation against its project limit
+	target, err = queries.LockOrganization(ctx, target.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	// The status checked is the one the project keeps until the move commits
+	locked, err := queries.GetProjectForUpdate(ctx, project.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	preview, err := previewProjectTransfer(ctx, queries, locked, source, target)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if !preview.Valid {
+		c.JSON(422, gin.H{"error": preview.Errors[0].Message, "errors": preview.Errors})
+		return
+	}
+
+	if _, err := queries.UpdateProjectOrganization(ctx, database.UpdateProjectOrganizationParams{
+		ProjectRef:     project.ProjectRef,
+		OrganizationID: target.ID,
+	}); err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to transfer project %s: %v", project.ProjectRef, err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	a.logger.Info("Transferred project", "project", project.ProjectRef, "from", source.Slug, "to", target.Slug, "account", currentAccount(c).ID)
+	c.JSON(http.StatusOK, gin.H{"ref": project.ProjectRef, "organization_id": target.ID, "organization_slug": target.Slug})
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func (a *Api) postProjectTransferPreview(c *gin.Context) {
+	var body ProjectTransferBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	source, target, ok := a.projectTransferOrganizations(c, body)
+	if !ok {
+		return
+	}
+
+	preview, err := previewProjectTransfer(c.Request.Context(), a.queries, *currentProject(c), source, target)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
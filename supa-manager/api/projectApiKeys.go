@@ -0,0 +1,177 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"regexp"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/provisioner"
+	"supamanager.io/supa-manager/utils"
+	"time"
+)
+
+const maxProjectApiKeys = 50
+
+// Key names are shown in Studio and logs, roles must be plain Postgres role names since PostgREST
+// switches to them
+var (
+	validApiKeyName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+	validApiKeyRole = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+)
+
+var errJwtSecretRotating = errors.New("the JWT secret is being rotated")
+
+// ProjectApiKey is a named key as listed, the key itself is only returned on creation
+type ProjectApiKey struct {
+	Id        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Role      string     `json:"role"`
+	KeyAlias  string     `json:"key_alias"`
+	ApiKey    string     `json:"api_key,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	Expired   bool       `json:"expired"`
+}
+
+func projectApiKeyResponse(key database.ProjectApiKey) ProjectApiKey {
+	return ProjectApiKey{
+		Id:        key.ID,
+		Name:      key.Name,
+		Role:      key.Role,
+		KeyAlias:  key.KeyAlias,
+		CreatedAt: key.CreatedAt.Time,
+		ExpiresAt: utils.PgTimestamptzToPointer(key.ExpiresAt),
+		Expired:   key.ExpiresAt.Valid && !key.ExpiresAt.Time.After(time.Now()),
+	}
+}
+
+// apiKeyConsumer is the Kong consumer of a key, ids can't collide with anon and service_role
+func apiKeyConsumer(id int64) string {
+	return fmt.Sprintf("apikey-%d", id)
+}
+
+// apiKeyGroup maps a role to its Kong ACL group, service_role keys are admins like the service key
+func apiKeyGroup(role string) string {
+	if role == "service_role" {
+		return "admin"
+	}
+	return role
+}
+
+// mintProjectApiKey signs a key for role with the project secret. Keys without an expiry last as
+// long as the anon key, the jti keeps keys minted in the same second apart
+func (a *Api) mintProjectApiKey(secret string, role string, expiresAt pgtype.Timestamptz) (key string, alias string, encrypted string, err error) {
+	expiry := time.Now().AddDate(10, 0, 0)
+	if expiresAt.Valid {
+		expiry = expiresAt.Time
+	}
+	key, err = a.signProjectJWT(secret, role, expiry, jwt.MapClaims{"jti": utils.NewUUID()})
+	if err != nil {
+		return "", "", "", err
+	}
+	encrypted, err = utils.Encrypt(a.config.EncryptionSecret, []byte(key))
+	if err != nil {
+		return "", "", "", err
+	}
+	return key, "..." + key[len(key)-6:], encrypted, nil
+}
+
+// projectGatewayKeys returns the keys Kong should accept besides anon and service_role
+func (a *Api) projectGatewayKeys(ctx context.Context, queries *database.Queries, projectID int32) ([]provisioner.APIKey, error) {
+	keys, err := queries.GetActiveProjectApiKeys(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	consumers := make([]provisioner.APIKey, len(keys))
+	for i, key := range keys {
+		plain, err := utils.Decrypt(a.config.EncryptionSecret, key.KeyEncrypted)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt API key %d: %w", key.ID, err)
+		}
+		consumers[i] = provisioner.APIKey{Consumer: apiKeyConsumer(key.ID), Key: string(plain), Group: apiKeyGroup(key.Role)}
+	}
+	return consumers, nil
+}
+
+// jwtSecretRotating reports whether a rotation of the project's secret is running, keys changed
+// meanwhile would be signed with the secret being replaced
+func (a *Api) jwtSecretRotating(ctx context.Context, queries *database.Queries, projectID int32) (bool, error) {
+	rotation, err := queries.GetLatestJwtSecretRotation(ctx, projectID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return rotation.Status == jwtRotationPending || rotation.Status == jwtRotationInProgress, nil
+}
+
+// changeProjectApiKeys applies change to the project's keys and regenerates the Kong configuration
+// from the result. The change is only committed once Kong runs with it, so listed keys are the
+// keys Kong accepts. Changes of a project are serialized by locking its row, rotations of its JWT
+// secret start under the same lock. change is passed the project as locked, keys must be signed
+// with its secret
+func (a *Api) changeProjectApiKeys(ctx context.Context, project *database.Project, change func(queries *database.Queries, project database.Project) error) error {
+	tx, err := a.pgPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	queries := a.queries.WithTx(tx)
+
+	if err := queries.LockProject(ctx, project.ID); err != nil {
+		return err
+	}
+	rotating, err := a.jwtSecretRotating(ctx, queries, project.ID)
+	if err != nil {
+		return err
+	}
+	if rotating {
+		return errJwtSecretRotating
+	}
+	locked, err := queries.GetProjectByID(ctx, project.ID)
+	if err != nil {
+		return err
+	}
+	if err := change(queries, locked); err != nil {
+		return err
+	}
+	if err := a.reloadProjectGateway(ctx, queries, &locked); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// reloadProjectGateway renders the project's files with its current keys and reloads Kong. Projects
+// that aren't provisioned pick the keys up when they are rendered
+func (a *Api) reloadProjectGateway(ctx context.Context, queries *database.Queries, project *database.Project) error {
+	if a.provisioner == nil || !project.ProvisionedAt.Valid {
+		return nil
+	}
+	config, err := a.provisioner.LoadProjectConfig(ctx, project.ProjectRef)
+	if errors.Is(err, provisioner.ErrProjectNotRendered) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	next := *config
+	if next.APIKeys, err = a.projectGatewayKeys(ctx, queries, project.ID); err != nil {
+		return err
+	}
+	if err := a.provisioner.RenderProject(ctx, &next); err != nil {
+		return err
+	}
+	if err := a.provisioner.ReloadGateway(ctx, project.ProjectRef); err != nil {
+		// The change is rolled back, Kong must not pick it up when it restarts
+		if err := a.provisioner.RenderProject(ctx, config); err != nil {
+			a.logger.Error(fmt.Sprintf("Failed to restore gateway configuration of project %s: %v", project.ProjectRef, err))
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/utils"
+	"time"
+)
+
+type ProjectBackup struct {
+	Id                 string     `json:"id"`
+	BackupType         string     `json:"backup_type"`
+	Status             string     `json:"status"`
+	SizeBytes          int64      `json:"size_bytes"`
+	ChecksumSha256     *string    `json:"checksum_sha256"`
+	VerificationStatus string     `json:"verification_status"`
+	VerificationError  *string    `json:"verification_error"`
+	VerifiedAt         *time.Time `json:"verified_at"`
+	InsertedAt         time.Time  `json:"inserted_at"`
+	CompletedAt        *time.Time `json:"completed_at"`
+}
+
+func projectBackupResponse(backup database.Backup) ProjectBackup {
+	return ProjectBackup{
+		Id:                 backup.ID,
+		BackupType:         backup.BackupType,
+		Status:             backup.Status,
+		SizeBytes:          backup.SizeBytes,
+		ChecksumSha256:     utils.PgTextToPointer(backup.ChecksumSha256),
+		VerificationStatus: backup.VerificationStatus,
+		VerificationError:  utils.PgTextToPointer(backup.VerificationError),
+		VerifiedAt:         utils.PgTimestamptzToPointer(backup.VerifiedAt),
+		InsertedAt:         backup.CreatedAt.Time,
+		CompletedAt:        utils.PgTimestamptzToPointer(backup.CompletedAt),
+	}
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"net/http"
+	"strconv"
+	"strings"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/pgmeta"
+	"supamanager.io/supa-manager/utils"
+)
+
+var errProjectDatabaseUnavailable = errors.New("project database is not available")
+
+// projectPool returns the cached connection pool for a project's Postgres
+func (a *Api) projectPool(c *gin.Context, proj database.Project) (*pgxpool.Pool, error) {
+	if !proj.PostgresPort.Valid || !proj.DbPasswordEncrypted.Valid {
+		return nil, errProjectDatabaseUnavailable
+	}
+
+	password, err := utils.Decrypt(a.config.EncryptionSecret, proj.DbPasswordEncrypted.String)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt database password: %w", err)
+	}
+
+	return a.pgMetaPools.Get(c.Request.Context(), pgmeta.Target{
+		ProjectRef: proj.ProjectRef,
+		Host:       a.config.Provisioning.DatabaseHost,
+		Port:       int(proj.PostgresPort.Int32),
+		User:       proj.DbUser,
+		Password:   string(password),
+		Database:   "postgres",
+	})
+}
+
+// projectPoolFromRequest returns the connection pool of the :ref project loaded by loadProject,
+// writing the error response itself when that is not possible
+func (a *Api) projectPoolFromRequest(c *gin.Context) (*pgxpool.Pool, bool) {
+	return a.projectPoolOrRespond(c, *currentProject(c))
+}
+
+// projectPoolOrRespond is projectPoolFromRequest for handlers that already loaded the project
+func (a *Api) projectPoolOrRespond(c *gin.Context, proj database.Project) (*pgxpool.Pool, bool) {
+	pool, err := a.projectPool(c, proj)
+	if err != nil {
+		if errors.Is(err, errProjectDatabaseUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Project database is not available"})
+			return nil, false
+		}
+		a.logger.Error(fmt.Sprintf("Failed to connect to project %s database: %v", proj.ProjectRef, err))
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to connect to project database"})
+		return nil, false
+	}
+
+	return pool, true
+}
+
+// pgMetaListOptions reads the filters shared by the pg-meta list endpoints from the query string
+func pgMetaListOptions(c *gin.Context) pgmeta.ListOptions {
+	opts := pgmeta.ListOptions{
+		IncludeSystemSchemas: c.Query("include_system_schemas") == "true",
+		IncludedSchemas:      splitQueryList(c.Query("included_schemas")),
+		ExcludedSchemas:      splitQueryList(c.Query("excluded_schemas")),
+	}
+	opts.Limit, _ = strconv.Atoi(c.Query("limit"))
+	opts.Offset, _ = strconv.Atoi(c.Query("offset"))
+	return opts
+}
+
+func splitQueryList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// pgMetaID reads the numeric id query parameter used to address catalog objects
+func pgMetaID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Query("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing or invalid query parameter: id"})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondPgMetaError reports a missing catalog object the way pg-meta does, anything else as a query error
+func (a *Api) respondPgMetaError(c *gin.Context, kind string, id interface{}, err error) {
+	if errors.Is(err, pgmeta.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Cannot find a %s with ID %v", kind, id)})
+		return
+	}
+	a.respondQueryError(c, err)
+}
+
+// respondQueryError translates a failed project query into a pg-meta style response
+func (a *Api) respondQueryError(c *gin.Context, err error) {
+	var queryErr *pgmeta.QueryError
+	if errors.As(err, &queryErr) {
+		c.JSON(http.StatusBadRequest, queryErr)
+		return
+	}
+	if errors.Is(err, pgmeta.ErrInvalidRequest) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	a.logger.Error(fmt.Sprintf("Project query failed: %v", err))
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+}
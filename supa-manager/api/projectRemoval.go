@@ -0,0 +1,51 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/dnshook"
+)
+
+// removeProject tears down a project's containers and deletes it. The status is set first so
+// Studio shows the project going down while the provisioner works
+func (a *Api) removeProject(ctx context.Context, project database.Project) error {
+	if _, err := a.queries.UpdateProjectStatus(ctx, database.UpdateProjectStatusParams{
+		ProjectRef: project.ProjectRef,
+		Status:     StatusGoingDown,
+	}); err != nil {
+		return err
+	}
+	a.invalidateEdgeRoute(project.ProjectRef)
+	if a.provisioner != nil {
+		if err := a.provisioner.DeleteProject(ctx, project.ProjectRef); err != nil {
+			return fmt.Errorf("failed to deprovision project %s: %w", project.ProjectRef, err)
+		}
+	}
+	a.pgMetaPools.Evict(project.ProjectRef)
+	custom, err := a.queries.GetCustomHostnameByProject(ctx, project.ID)
+	if err == nil {
+		err = a.removeCustomHostname(ctx, project.ProjectRef, custom)
+	}
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to remove custom hostname of project %s: %w", project.ProjectRef, err)
+	}
+	defer a.invalidateEdgeRoute(project.ProjectRef)
+
+	tx, err := a.pgPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	queries := a.queries.WithTx(tx)
+
+	if err := queries.DeleteProject(ctx, project.ProjectRef); err != nil {
+		return err
+	}
+	if err := a.enqueueDnsUpdate(ctx, queries, a.projectDnsUpdate(dnshook.UpdateDelete, project.ProjectRef)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"supamanager.io/supa-manager/database"
+)
+
+type ProjectTransferBody struct {
+	TargetOrganizationSlug string `json:"target_organization_slug" binding:"required"`
+}
+
+type ProjectTransferIssue struct {
+	Key     string `json:"key"`
+	Message string `json:"message"`
+}
+
+// ProjectTransferPreview is what Studio shows before moving a project, billing amounts are always zero
+type ProjectTransferPreview struct {
+	Valid                            bool                   `json:"valid"`
+	Warnings                         []ProjectTransferIssue `json:"warnings"`
+	Errors                           []ProjectTransferIssue `json:"errors"`
+	MembersExceedingFreeProjectLimit []interface{}          `json:"members_exceeding_free_project_limit"`
+	SourceSubscriptionPlan           string                 `json:"source_subscription_plan"`
+	TargetSubscriptionPlan           string                 `json:"target_subscription_plan"`
+	CreditsOnSourceOrganization      int                    `json:"credits_on_source_organization"`
+	CostsOnTargetOrganization        int                    `json:"costs_on_target_organization"`
+	ChargeOnTargetOrganization       int                    `json:"charge_on_target_organization"`
+}
+
+// projectBusyStatuses are transitions a project can't be moved in the middle of
+var projectBusyStatuses = map[string]bool{
+	"PROVISIONING":  true,
+	StatusComingUp:  true,
+	StatusGoingDown: true,
+	StatusPausing:   true,
+	StatusRestoring: true,
+	StatusUpgrading: true,
+}
+
+// previewProjectTransfer checks whether project can move to target. Run it inside the transaction
+// holding the target organization's lock so the project count can't change before the move, with
+// the project read under its lock so its status can't either
+func previewProjectTransfer(ctx context.Context, queries *database.Queries, project database.Project, source database.Organization, target database.Organization) (ProjectTransferPreview, error) {
+	preview := ProjectTransferPreview{
+		Warnings:                         []ProjectTransferIssue{},
+		Errors:                           []ProjectTransferIssue{},
+		MembersExceedingFreeProjectLimit: []interface{}{},
+		SourceSubscriptionPlan:           source.Plan,
+		TargetSubscriptionPlan:           target.Plan,
+	}
+
+	if target.ID == source.ID {
+		preview.Errors = append(preview.Errors, ProjectTransferIssue{
+			Key:     "same_organization",
+			Message: "The project already belongs to this organization",
+		})
+	}
+	if projectBusyStatuses[project.Status] {
+		preview.Errors = append(preview.Errors, ProjectTransferIssue{
+			Key:     "project_busy",
+			Message: fmt.Sprintf("The project can't be transferred while it is %s", project.Status),
+		})
+	}
+
+	if limit, ok := planProjectLimits[target.Plan]; ok {
+		count, err := queries.CountProjectsForOrganization(ctx, target.ID)
+		if err != nil {
+			return ProjectTransferPreview{}, err
+		}
+		if count >= limit {
+			preview.Errors = append(preview.Errors, ProjectTransferIssue{
+				Key:     "project_limit",
+				Message: fmt.Sprintf("%s has reached the limit of %d projects of the %s plan", target.Name, limit, organizationPlanNames[target.Plan]),
+			})
+		}
+	}
+
+	preview.Valid = len(preview.Errors) == 0
+	return preview, nil
+}
+
+// projectTransferOrganizations resolves the project's organization and the target organization,
+// the caller must be allowed to transfer projects out of one and into the other. It writes the
+// error response itself when that fails
+func (a *Api) projectTransferOrganizations(c *gin.Context, body ProjectTransferBody) (database.Organization, database.Organization, bool) {
+	project := currentProject(c)
+	ctx := c.Request.Context()
+
+	target, err := a.queries.GetOrganizationById(ctx, body.TargetOrganizationSlug)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(404, gin.H{"error": "Organization not found"})
+		return database.Organization{}, database.Organization{}, false
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return database.Organization{}, database.Organization{}, false
+	}
+
+	allowedSource, err := a.allowed(c, actionProjectTransfer)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return database.Organization{}, database.Organization{}, false
+	}
+	allowedTarget, err := a.can(ctx, currentAccount(c), actionProjectTransfer, authzResource{OrganizationID: target.ID})
+	if errors.Is(err, errNotMember) {
+		c.JSON(404, gin.H{"error": "Organization not found"})
+		return database.Organization{}, database.Organization{}, false
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return database.Organization{}, database.Organization{}, false
+	}
+	if !allowedSource || !allowedTarget {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden", "message": "Only owners of both organizations can transfer a project"})
+		return database.Organization{}, database.Organization{}, false
+	}
+
+	source, err := a.queries.GetOrganization(ctx, project.OrganizationID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return database.Organization{}, database.Organization{}, false
+	}
+	return source, target, true
+}
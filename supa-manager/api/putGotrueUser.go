@@ -0,0 +1,105 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"strings"
+	"supamanager.io/supa-manager/database"
+)
+
+type GotrueUserUpdate struct {
+	Email    *string `json:"email"`
+	Password *string `json:"password"`
+}
+
+// putGotrueUser changes the password, or starts an email change confirmed from the new address
+func (a *Api) putGotrueUser(c *gin.Context) {
+	account := currentAccount(c)
+	claims := currentClaims(c)
+
+	var body GotrueUserUpdate
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if claims.AAL != aal2 {
+		required, err := a.mfaRequired(ctx, account)
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal Server Error"})
+			return
+		}
+		if required {
+			c.JSON(403, gin.H{"error": "insufficient_aal", "message": "Verify a factor before changing your credentials"})
+			return
+		}
+	}
+
+	if body.Password != nil {
+		if err := validatePassword(*body.Password); err != nil {
+			c.JSON(422, gin.H{"error": "weak_password", "message": err.Error()})
+			return
+		}
+		hash, err := a.argon.HashEncoded([]byte(*body.Password))
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal Server Error"})
+			return
+		}
+		if err := a.queries.SetAccountPassword(ctx, database.SetAccountPasswordParams{ID: account.ID, PasswordHash: string(hash)}); err != nil {
+			c.JSON(500, gin.H{"error": "Internal Server Error"})
+			return
+		}
+		// Whoever knew the old password must not keep a session
+		err = a.queries.RevokeOtherSessions(ctx, database.RevokeOtherSessionsParams{
+			AccountID:     account.ID,
+			FamilyID:      claims.SessionID,
+			RevokedReason: revokedReason(sessionRevokedPasswordChange),
+		})
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal Server Error"})
+			return
+		}
+	}
+
+	var newEmail *string
+	if body.Email != nil && !strings.EqualFold(*body.Email, account.Email) {
+		_, err := a.queries.GetAccountByEmail(ctx, *body.Email)
+		if err == nil {
+			c.JSON(422, gin.H{"error": "email_exists", "message": errEmailInUse.Error()})
+			return
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(500, gin.H{"error": "Internal Server Error"})
+			return
+		}
+		err = a.sendAccountToken(ctx, *account, tokenPurposeEmailChange, *body.Email, c.Query("redirect_to"))
+		if errors.Is(err, errAccountTokenThrottled) {
+			c.JSON(429, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Failed to send email change confirmation: %v", err))
+			c.JSON(500, gin.H{"error": "Internal Server Error"})
+			return
+		}
+		newEmail = body.Email
+	}
+
+	updated, err := a.queries.GetAccountByID(ctx, account.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	factors, err := a.queries.GetMfaFactors(ctx, account.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	user := gotrueUser(updated, factors)
+	user["new_email"] = newEmail
+	c.JSON(200, user)
+}
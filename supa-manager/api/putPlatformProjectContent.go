@@ -0,0 +1,49 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"supamanager.io/supa-manager/database"
+)
+
+// putPlatformProjectContent upserts content by id, which is how Studio autosaves snippets
+func (a *Api) putPlatformProjectContent(c *gin.Context) {
+	account := currentAccount(c)
+
+	var req UserContentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	project := *currentProject(c)
+
+	if req.ID == "" {
+		a.createUserContent(c, account, project, req)
+		return
+	}
+
+	existing, err := a.queries.GetUserContentByID(c.Request.Context(), database.GetUserContentByIDParams{
+		ID:        req.ID,
+		ProjectID: project.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		a.createUserContent(c, account, project, req)
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if !canReadUserContent(account, existing) {
+		c.JSON(404, gin.H{"error": "Content not found"})
+		return
+	}
+
+	a.updateUserContent(c, account, project, existing, req)
+}
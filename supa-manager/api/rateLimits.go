@@ -0,0 +1,190 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/matthewhartstonge/argon2"
+	"math"
+	"strconv"
+	"strings"
+	"supamanager.io/supa-manager/conf"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/ratelimit"
+	"sync"
+	"time"
+)
+
+// rateLimitPruneInterval is how often limiter state nobody touched in a while is dropped
+const rateLimitPruneInterval = 10 * time.Minute
+
+// dummyPasswordHash is verified against when the account doesn't exist, so an unknown email takes
+// as long to reject as a wrong password
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	config := argon2.DefaultConfig()
+	hash, err := config.HashEncoded([]byte("not the password of any account"))
+	if err != nil {
+		return nil
+	}
+	return hash
+})
+
+func newRateLimiter(settings conf.RateLimitSettings, queries *database.Queries) (*ratelimit.Limiter, error) {
+	switch settings.Store {
+	case "postgres":
+		return ratelimit.New(ratelimit.NewPostgresStore(queries)), nil
+	case "memory":
+		return ratelimit.New(ratelimit.NewMemoryStore()), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", settings.Store)
+	}
+}
+
+// Limiter keys: attempts per client IP on the auth endpoints, failures per login email and per
+// account for second factors, and requests per token
+func authIPKey(ip string) string {
+	return "auth:ip:" + ip
+}
+
+func loginKey(email string) string {
+	return "auth:login:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func mfaKey(accountID int32) string {
+	return fmt.Sprintf("auth:mfa:%d", accountID)
+}
+
+func tokenKey(claims *AccessTokenClaims) string {
+	if claims.PersonalAccessToken != nil {
+		return fmt.Sprintf("api:pat:%d", claims.PersonalAccessToken.ID)
+	}
+	if claims.SessionID != "" {
+		return "api:session:" + claims.SessionID
+	}
+	return "api:subject:" + claims.Subject
+}
+
+func (a *Api) lockout() ratelimit.Lockout {
+	return ratelimit.Lockout{
+		Threshold: a.config.RateLimit.LockoutThreshold,
+		Base:      a.config.RateLimit.LockoutBase,
+		Max:       a.config.RateLimit.LockoutMax,
+		Window:    a.config.RateLimit.LockoutWindow,
+	}
+}
+
+// limitAuthAttempts limits how often a client IP can call an auth endpoint, whatever the outcome
+func (a *Api) limitAuthAttempts(c *gin.Context) {
+	if !a.allowAuthAttempt(c) {
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+// allowAuthAttempt counts an auth attempt of the client IP. It writes the error response itself
+func (a *Api) allowAuthAttempt(c *gin.Context) bool {
+	result, err := a.limiter.Allow(c.Request.Context(), authIPKey(c.ClientIP()), ratelimit.Limit{
+		Requests: a.config.RateLimit.AuthIpLimit,
+		Window:   a.config.RateLimit.AuthIpWindow,
+	})
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to check rate limit: %v", err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return false
+	}
+	if !result.Allowed {
+		a.logger.Warn("Auth rate limit exceeded", "ip", c.ClientIP(), "path", c.FullPath())
+		tooManyRequests(c, result.RetryAfter)
+		return false
+	}
+	return true
+}
+
+// limitTokenRequests limits the requests of every access token and personal access token when
+// a token limit is configured. It runs after authenticate, public routes aren't limited here
+func (a *Api) limitTokenRequests(c *gin.Context) {
+	value, ok := c.Get(contextClaimsKey)
+	if !ok || a.config.RateLimit.TokenLimit <= 0 {
+		c.Next()
+		return
+	}
+
+	result, err := a.limiter.Allow(c.Request.Context(), tokenKey(value.(*AccessTokenClaims)), ratelimit.Limit{
+		Requests: a.config.RateLimit.TokenLimit,
+		Window:   a.config.RateLimit.TokenWindow,
+	})
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to check rate limit: %v", err))
+		c.AbortWithStatusJSON(500, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	if !result.Allowed {
+		tooManyRequests(c, result.RetryAfter)
+		return
+	}
+	c.Next()
+}
+
+// checkLockout refuses the attempt while key is locked out. It writes the error response itself
+func (a *Api) checkLockout(c *gin.Context, key string) bool {
+	remaining, err := a.limiter.Locked(c.Request.Context(), key)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to check lockout: %v", err))
+		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		return false
+	}
+	if remaining > 0 {
+		tooManyRequests(c, remaining)
+		return false
+	}
+	return true
+}
+
+// recordFailedAttempt counts a failed attempt for key, locking it out after too many in a row.
+// It only logs errors, the attempt failed either way
+func (a *Api) recordFailedAttempt(ctx context.Context, key string, ip string) {
+	locked, err := a.limiter.Fail(ctx, key, a.lockout())
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to record failed attempt: %v", err))
+		return
+	}
+	if locked > 0 {
+		a.logger.Warn("Locked out after repeated failed attempts", "key", key, "ip", ip, "duration", locked)
+	}
+}
+
+// clearFailedAttempts forgets the failures of key after a successful attempt
+func (a *Api) clearFailedAttempts(ctx context.Context, key string) {
+	if err := a.limiter.Succeed(ctx, key); err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to clear failed attempts: %v", err))
+	}
+}
+
+// runRateLimitPruneJob drops limiter state that outlived every window it could still count in
+func (a *Api) runRateLimitPruneJob(ctx context.Context) {
+	settings := a.config.RateLimit
+	age := max(settings.AuthIpWindow, settings.LockoutWindow, settings.TokenWindow)
+
+	ticker := time.NewTicker(rateLimitPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := a.limiter.Prune(ctx, age); err != nil {
+			a.logger.Error(fmt.Sprintf("Failed to prune rate limits: %v", err))
+		}
+	}
+}
+
+func tooManyRequests(c *gin.Context, retryAfter time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	c.AbortWithStatusJSON(429, gin.H{"error": "over_request_rate_limit", "error_description": "Too many requests, try again later"})
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"supamanager.io/supa-manager/conf"
+	"supamanager.io/supa-manager/ratelimit"
+	"testing"
+	"time"
+)
+
+func newRateLimitTestRouter(t *testing.T, trustedProxies []string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	a := &Api{
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		config: &conf.Config{
+			TrustedProxies: trustedProxies,
+			RateLimit:      conf.RateLimitSettings{AuthIpLimit: 2, AuthIpWindow: time.Minute},
+		},
+		limiter: ratelimit.New(ratelimit.NewMemoryStore()),
+	}
+	r, err := a.newEngine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.POST("/auth/token", a.limitAuthAttempts, func(c *gin.Context) {
+		c.String(200, c.ClientIP())
+	})
+	return r
+}
+
+func authAttempt(r *gin.Engine, remoteAddr string, forwardedFor string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/auth/token", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthRateLimitIgnoresSpoofedForwardedFor(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+	}{
+		{"no trusted proxies", nil, "203.0.113.7:4000"},
+		{"client isn't a trusted proxy", []string{"10.0.0.0/8"}, "203.0.113.7:4000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRateLimitTestRouter(t, tt.trustedProxies)
+			forwarded := []string{"198.51.100.1", "198.51.100.2", "198.51.100.3"}
+			codes := make([]int, 0, len(forwarded))
+			for _, ip := range forwarded {
+				w := authAttempt(r, tt.remoteAddr, ip)
+				codes = append(codes, w.Code)
+				if w.Code == 200 && w.Body.String() != "203.0.113.7" {
+					t.Errorf("client address = %q, want the connection's address", w.Body.String())
+				}
+			}
+			if codes[2] != 429 {
+				t.Errorf("statuses = %v, a new X-Forwarded-For on each attempt got past the limit", codes)
+			}
+		})
+	}
+}
+
+func TestAuthRateLimitKeysOnClientBehindTrustedProxy(t *testing.T) {
+	r := newRateLimitTestRouter(t, []string{"10.0.0.0/8"})
+	proxy := "10.0.0.5:4000"
+
+	for i := 0; i < 2; i++ {
+		if w := authAttempt(r, proxy, "198.51.100.1"); w.Code != 200 || w.Body.String() != "198.51.100.1" {
+			t.Fatalf("attempt %d: status %d, client %q", i+1, w.Code, w.Body.String())
+		}
+	}
+	if w := authAttempt(r, proxy, "198.51.100.1"); w.Code != 429 {
+		t.Fatalf("third attempt of a client: status %d, want 429", w.Code)
+	}
+	if w := authAttempt(r, proxy, "198.51.100.2"); w.Code != 200 {
+		t.Fatalf("another client behind the same proxy: status %d, want 200", w.Code)
+	}
+}
+
+func TestRouterRejectsInvalidTrustedProxies(t *testing.T) {
+	a := &Api{config: &conf.Config{TrustedProxies: []string{"not an address"}}}
+	if _, err := a.newEngine(); err == nil {
+		t.Fatal("expected an error for an invalid trusted proxy")
+	}
+}
+
+func TestTooManyRequestsRoundsRetryAfterUp(t *testing.T) {
+	tests := []struct {
+		retryAfter time.Duration
+		want       string
+	}{
+		{time.Second, "1"},
+		{1500 * time.Millisecond, "2"},
+		{time.Minute, "60"},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		tooManyRequests(c, tt.retryAfter)
+		if w.Code != 429 {
+			t.Errorf("status = %d, want 429", w.Code)
+		}
+		if got := w.Header().Get("Retry-After"); got != tt.want {
+			t.Errorf("Retry-After for %s = %q, want %q", tt.retryAfter, got, tt.want)
+		}
+	}
+}
+
+func TestLoginKeyNormalizesEmail(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"user@example.com", "User@Example.com"},
+		{"user@example.com", "  user@example.com "},
+	}
+	for _, tt := range tests {
+		if loginKey(tt.a) != loginKey(tt.b) {
+			t.Errorf("loginKey(%q) != loginKey(%q), the lockout could be dodged by changing case", tt.a, tt.b)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/utils"
+	"time"
+)
+
+const refreshTokenSize = 32
+
+// Reasons recorded when a session family is revoked
+const (
+	sessionRevokedLogout         = "logout"
+	sessionRevokedSignOutOthers  = "sign_out_others"
+	sessionRevokedReuse          = "refresh_token_reuse"
+	sessionRevokedPasswordChange = "password_change"
+)
+
+// Authenticator assurance levels, aal2 means the session passed a second factor
+const (
+	aal1 = "aal1"
+	aal2 = "aal2"
+)
+
+// AccessTokenClaims are the claims of the access tokens issued by /auth/token
+type AccessTokenClaims struct {
+	jwt.RegisteredClaims
+	Email     string     `json:"email"`
+	SessionID string     `json:"session_id"`
+	AAL       string     `json:"aal"`
+	AMR       []AMREntry `json:"amr"`
+
+	// PersonalAccessToken is set when the request authenticated with a personal access token
+	PersonalAccessToken *database.PersonalAccessToken `json:"-"`
+}
+
+// AMREntry is an authentication method used by a session, as in GoTrue's amr claim
+type AMREntry struct {
+	Method    string `json:"method"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// SessionGrant describes how a session was authenticated
+type SessionGrant struct {
+	AAL string
+	AMR []string
+}
+
+// createSession stores a new hashed refresh token in a session family and returns it with the plain token
+func (a *Api) createSession(ctx context.Context, queries *database.Queries, c *gin.Context, accountID int32, familyID string, parentID pgtype.Text, grant SessionGrant) (database.Session, string, error) {
+	refreshToken, err := utils.RandomToken(refreshTokenSize)
+	if err != nil {
+		return database.Session{}, "", err
+	}
+
+	session, err := queries.CreateSession(ctx, database.CreateSessionParams{
+		FamilyID:  familyID,
+		ParentID:  parentID,
+		AccountID: accountID,
+		TokenHash: utils.HashToken(refreshToken),
+		UserAgent: pgtype.Text{String: c.Request.UserAgent(), Valid: c.Request.UserAgent() != ""},
+		Ip:        pgtype.Text{String: c.ClientIP(), Valid: c.ClientIP() != ""},
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(a.config.Auth.RefreshTokenTtl), Valid: true},
+		Aal:       grant.AAL,
+		Amr:       grant.AMR,
+	})
+	if err != nil {
+		return database.Session{}, "", err
+	}
+	return session, refreshToken, nil
+}
+
+// signAccessToken mints a short-lived access token bound to a session family
+func (a *Api) signAccessToken(account database.Account, session database.Session, now time.Time) (string, error) {
+	amr := make([]AMREntry, len(session.Amr))
+	for i, method := range session.Amr {
+		amr[i] = AMREntry{Method: method, Timestamp: session.CreatedAt.Time.Unix()}
+	}
+
+	claims := AccessTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    accessTokenIssuer,
+			Subject:   account.GotrueID,
+			Audience:  []string{accessTokenAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(a.config.Auth.AccessTokenTtl)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		Email:     account.Email,
+		SessionID: session.FamilyID,
+		AAL:       session.Aal,
+		AMR:       amr,
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(a.config.JwtSecret))
+}
+
+// respondWithSession writes a GoTrue token response
+func (a *Api) respondWithSession(c *gin.Context, account database.Account, session database.Session, refreshToken string, extra gin.H) {
+	response, err := a.sessionResponse(c.Request.Context(), account, session, refreshToken)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return
+	}
+	for k, v := range extra {
+		response[k] = v
+	}
+	c.JSON(200, response)
+}
+
+func (a *Api) sessionResponse(ctx context.Context, account database.Account, session database.Session, refreshToken string) (gin.H, error) {
+	now := time.Now()
+	accessToken, err := a.signAccessToken(account, session, now)
+	if err != nil {
+		return nil, err
+	}
+	factors, err := a.queries.GetMfaFactors(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return gin.H{
+		"access_token":  accessToken,
+		"token_type":    "bearer",
+		"expires_in":    int(a.config.Auth.AccessTokenTtl.Seconds()),
+		"expires_at":    now.Add(a.config.Auth.AccessTokenTtl).Unix(),
+		"refresh_token": refreshToken,
+		"user":          gotrueUser(account, factors),
+	}, nil
+}
+
+func gotrueUser(account database.Account, factors []database.MfaFactor) gin.H {
+	return gin.H{
+		"id":                 account.GotrueID,
+		"aud":                "authenticated",
+		"role":               "authenticated",
+		"email":              account.Email,
+		"email_confirmed_at": utils.PgTimestamptzToPointer(account.EmailConfirmedAt),
+		"app_metadata": gin.H{
+			"provider":  "email",
+			"providers": []string{"email"},
+		},
+		"user_metadata": gin.H{},
+		"factors":       gotrueFactors(factors),
+		"created_at":    account.CreatedAt.Time,
+		"updated_at":    account.UpdatedAt.Time,
+	}
+}
+
+func revokedReason(reason string) pgtype.Text {
+	return pgtype.Text{String: reason, Valid: true}
+}
@@ -0,0 +1,263 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"strings"
+	"supamanager.io/supa-manager/conf"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/permisions"
+	"supamanager.io/supa-manager/sso"
+	"supamanager.io/supa-manager/utils"
+	"time"
+)
+
+// ssoStateTtl is how long a user has to complete the login at the identity provider
+const ssoStateTtl = 10 * time.Minute
+
+// defaultSsoRole is the organization role granted by a group mapping without a role
+const defaultSsoRole = permisions.RoleDeveloper
+
+var (
+	errSsoStateInvalid   = errors.New("single sign-on request is invalid or has expired")
+	errSsoAccountMissing = errors.New("no account exists for this identity")
+	errSsoEmailUnlinked  = errors.New("an account with this email already exists, its email must be verified by the identity provider to link it")
+	errSsoEmailMissing   = errors.New("the identity provider did not return an email address")
+
+	errPasswordLoginDisabled = errors.New("password login is disabled for this email domain, sign in with SSO")
+)
+
+// ssoGroupMapping grants membership of an organization to members of an identity provider group
+type ssoGroupMapping struct {
+	Group        string
+	Organization string
+	Role         string
+}
+
+// parseSsoGroupMappings parses <group>=<organization slug>[:<role>] entries
+func parseSsoGroupMappings(entries []string) ([]ssoGroupMapping, error) {
+	mappings := make([]ssoGroupMapping, 0, len(entries))
+	for _, entry := range entries {
+		group, target, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || group == "" || target == "" {
+			return nil, fmt.Errorf("invalid sso group mapping %q, expected <group>=<organization>[:<role>]", entry)
+		}
+		organization, role, _ := strings.Cut(target, ":")
+		if role == "" {
+			role = defaultSsoRole
+		}
+		role = strings.ToUpper(role)
+		if !permisions.ValidRole(role) {
+			return nil, fmt.Errorf("invalid sso group mapping %q, role must be one of %s", entry, strings.Join(permisions.Roles, ", "))
+		}
+		mappings = append(mappings, ssoGroupMapping{Group: group, Organization: organization, Role: role})
+	}
+	return mappings, nil
+}
+
+func newSsoProvider(settings conf.SsoSettings) (*sso.Provider, []ssoGroupMapping, error) {
+	if !settings.Enabled {
+		return nil, nil, nil
+	}
+	if settings.Issuer == "" || settings.ClientId == "" {
+		return nil, nil, errors.New("SSO_ISSUER and SSO_CLIENT_ID are required when SSO is enabled")
+	}
+	mappings, err := parseSsoGroupMappings(settings.GroupMappings)
+	if err != nil {
+		return nil, nil, err
+	}
+	provider := sso.NewProvider(sso.Config{
+		Issuer:       settings.Issuer,
+		ClientID:     settings.ClientId,
+		ClientSecret: settings.ClientSecret,
+		Scopes:       settings.Scopes,
+	})
+	return provider, mappings, nil
+}
+
+func (a *Api) ssoRedirectURI() string {
+	return strings.TrimRight(a.config.Auth.ExternalUrl, "/") + "/auth/sso/callback"
+}
+
+// passwordLoginDisabled reports whether the email's domain must sign in through SSO
+func (a *Api) passwordLoginDisabled(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+	for _, disabled := range a.config.Sso.PasswordDisabledDomains {
+		if strings.ToLower(strings.TrimSpace(disabled)) == domain {
+			return true
+		}
+	}
+	return false
+}
+
+// ssoAuthorizeURL starts a login, remembering the PKCE verifier and nonce under the hashed state
+func (a *Api) ssoAuthorizeURL(ctx context.Context, redirectTo string) (string, error) {
+	state, err := sso.NewState()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := sso.NewState()
+	if err != nil {
+		return "", err
+	}
+	verifier, challenge, err := sso.NewPKCE()
+	if err != nil {
+		return "", err
+	}
+
+	if err := a.queries.DeleteExpiredSsoStates(ctx); err != nil {
+		a.logger.Warn(fmt.Sprintf("Failed to delete expired sso states: %v", err))
+	}
+	err = a.queries.CreateSsoState(ctx, database.CreateSsoStateParams{
+		StateHash:    utils.HashToken(state),
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		RedirectTo:   a.redirectURL(redirectTo),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return a.sso.AuthCodeURL(ctx, a.ssoRedirectURI(), state, nonce, challenge)
+}
+
+// ssoCallback completes a login: it exchanges the code, verifies the ID token and signs the account in
+func (a *Api) ssoCallback(ctx context.Context, state string, code string) (database.SsoState, sso.Claims, error) {
+	stored, err := a.queries.ConsumeSsoState(ctx, utils.HashToken(state))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return database.SsoState{}, nil, errSsoStateInvalid
+	}
+	if err != nil {
+		return database.SsoState{}, nil, err
+	}
+	if time.Since(stored.CreatedAt.Time) > ssoStateTtl {
+		return stored, nil, errSsoStateInvalid
+	}
+
+	tokens, err := a.sso.Exchange(ctx, code, stored.CodeVerifier, a.ssoRedirectURI())
+	if err != nil {
+		return stored, nil, err
+	}
+	claims, err := a.sso.VerifyIDToken(ctx, tokens.IDToken, stored.Nonce)
+	if err != nil {
+		return stored, nil, err
+	}
+	claims, err = a.sso.UserInfo(ctx, tokens.AccessToken, claims)
+	if err != nil {
+		return stored, nil, err
+	}
+	return stored, claims, nil
+}
+
+// ssoAccount finds, links or provisions the account of an identity and applies the group mappings
+func (a *Api) ssoAccount(ctx context.Context, queries *database.Queries, claims sso.Claims) (database.Account, error) {
+	settings := a.config.Sso
+	subject := claims.String("sub")
+	email := strings.ToLower(claims.String(settings.EmailClaim))
+	emailVerified := settings.TrustEmail || claims.EmailVerified()
+
+	var account database.Account
+	identity, err := queries.GetAccountIdentity(ctx, database.GetAccountIdentityParams{Provider: settings.Issuer, Subject: subject})
+	switch {
+	case err == nil:
+		account, err = queries.GetAccountByID(ctx, identity.AccountID)
+		if err != nil {
+			return database.Account{}, err
+		}
+	case !errors.Is(err, pgx.ErrNoRows):
+		return database.Account{}, err
+	case email == "":
+		return database.Account{}, errSsoEmailMissing
+	default:
+		account, err = queries.GetAccountByEmail(ctx, email)
+		if errors.Is(err, pgx.ErrNoRows) {
+			if !settings.AutoProvision {
+				return database.Account{}, errSsoAccountMissing
+			}
+			account, err = a.provisionSsoAccount(ctx, queries, email, emailVerified, claims.String(settings.NameClaim))
+		} else if err == nil && !emailVerified {
+			return database.Account{}, errSsoEmailUnlinked
+		}
+		if err != nil {
+			return database.Account{}, err
+		}
+	}
+
+	rawClaims, err := json.Marshal(claims)
+	if err != nil {
+		return database.Account{}, err
+	}
+	err = queries.UpsertAccountIdentity(ctx, database.UpsertAccountIdentityParams{
+		Provider:  settings.Issuer,
+		Subject:   subject,
+		AccountID: account.ID,
+		Email:     pgtype.Text{String: email, Valid: email != ""},
+		Claims:    rawClaims,
+	})
+	if err != nil {
+		return database.Account{}, err
+	}
+
+	groups := map[string]bool{}
+	for _, group := range claims.Strings(settings.GroupsClaim) {
+		groups[group] = true
+	}
+	for _, mapping := range a.ssoGroupMappings {
+		if !groups[mapping.Group] {
+			continue
+		}
+		org, err := queries.GetOrganizationById(ctx, mapping.Organization)
+		if errors.Is(err, pgx.ErrNoRows) {
+			a.logger.Warn("SSO group mapping references an unknown organization", "group", mapping.Group, "organization", mapping.Organization)
+			continue
+		}
+		if err != nil {
+			return database.Account{}, err
+		}
+		// Existing members keep their role, mappings only add members
+		err = queries.EnsureOrganizationMembership(ctx, database.EnsureOrganizationMembershipParams{
+			OrganizationID: org.ID,
+			AccountID:      account.ID,
+			Role:           mapping.Role,
+		})
+		if err != nil {
+			return database.Account{}, err
+		}
+	}
+
+	return account, nil
+}
+
+// provisionSsoAccount creates an account without a usable password
+func (a *Api) provisionSsoAccount(ctx context.Context, queries *database.Queries, email string, emailVerified bool, name string) (database.Account, error) {
+	account, err := queries.CreateAccount(ctx, database.CreateAccountParams{
+		Email:            email,
+		PasswordHash:     "",
+		Username:         usernameFromEmail(email),
+		EmailConfirmedAt: pgtype.Timestamptz{Time: time.Now(), Valid: emailVerified},
+	})
+	if err != nil {
+		return database.Account{}, err
+	}
+
+	if name != "" {
+		first, last, _ := strings.Cut(name, " ")
+		err = queries.SetAccountName(ctx, database.SetAccountNameParams{
+			ID:        account.ID,
+			FirstName: pgtype.Text{String: first, Valid: true},
+			LastName:  pgtype.Text{String: last, Valid: last != ""},
+		})
+		if err != nil {
+			return database.Account{}, err
+		}
+	}
+	return account, nil
+}
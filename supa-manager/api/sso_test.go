@@ -0,0 +1,58 @@
+package api
+
+import (
+	"reflect"
+	"supamanager.io/supa-manager/conf"
+	"testing"
+)
+
+func TestParseSsoGroupMappings(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    []ssoGroupMapping
+		wantErr bool
+	}{
+		{"none", nil, []ssoGroupMapping{}, false},
+		{"default role", []string{"devs=acme"}, []ssoGroupMapping{{Group: "devs", Organization: "acme", Role: "DEVELOPER"}}, false},
+		{"role", []string{" admins=acme:owner "}, []ssoGroupMapping{{Group: "admins", Organization: "acme", Role: "OWNER"}}, false},
+		{"read only", []string{"viewers=acme:read_only"}, []ssoGroupMapping{{Group: "viewers", Organization: "acme", Role: "READ_ONLY"}}, false},
+		{"unknown role", []string{"admins=acme:superuser"}, nil, true},
+		{"missing organization", []string{"admins="}, nil, true},
+		{"missing group", []string{"=acme"}, nil, true},
+		{"missing separator", []string{"admins"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSsoGroupMappings(tt.entries)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSsoGroupMappings() error = %v, want error: %t", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSsoGroupMappings() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordLoginDisabled(t *testing.T) {
+	a := &Api{config: &conf.Config{Sso: conf.SsoSettings{PasswordDisabledDomains: []string{"Example.com", " corp.io "}}}}
+
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{"user@example.com", true},
+		{"user@EXAMPLE.COM", true},
+		{"user@corp.io", true},
+		{"user@sub.example.com", false},
+		{"user@example.com.evil.io", false},
+		{"user@other.com", false},
+		{"not an email", false},
+	}
+	for _, tt := range tests {
+		if got := a.passwordLoginDisabled(tt.email); got != tt.want {
+			t.Errorf("passwordLoginDisabled(%q) = %t, want %t", tt.email, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/utils"
+	"time"
+)
+
+var userContentTypes = map[string]bool{"sql": true, "report": true, "log_sql": true}
+var userContentVisibilities = map[string]bool{"user": true, "project": true, "org": true, "public": true}
+
+// UserContentRequest is the body Studio sends when saving a snippet, report or log query
+type UserContentRequest struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Description *string         `json:"description"`
+	Type        string          `json:"type"`
+	Visibility  string          `json:"visibility"`
+	Content     json.RawMessage `json:"content"`
+	OwnerID     *int32          `json:"owner_id"`
+	Favorite    *bool           `json:"favorite"`
+	FolderID    *string         `json:"folder_id"`
+}
+
+type UserContent struct {
+	ID            string          `json:"id"`
+	Name          string          `json:"name"`
+	Description   *string         `json:"description"`
+	Type          string          `json:"type"`
+	Visibility    string          `json:"visibility"`
+	Content       json.RawMessage `json:"content"`
+	OwnerID       int32           `json:"owner_id"`
+	LastUpdatedBy *int32          `json:"last_updated_by"`
+	ProjectID     int32           `json:"project_id"`
+	Favorite      bool            `json:"favorite"`
+	FolderID      *string         `json:"folder_id"`
+	InsertedAt    time.Time       `json:"inserted_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+func userContentFromRow(row database.UserContent) UserContent {
+	content := UserContent{
+		ID:         row.ID,
+		Name:       row.Name,
+		Type:       row.Type,
+		Visibility: row.Visibility,
+		Content:    json.RawMessage(row.Content),
+		OwnerID:    row.OwnerID,
+		ProjectID:  row.ProjectID,
+		Favorite:   row.Favorite,
+		InsertedAt: row.InsertedAt.Time,
+		UpdatedAt:  row.UpdatedAt.Time,
+	}
+	if row.Description.Valid {
+		content.Description = &row.Description.String
+	}
+	if row.LastUpdatedBy.Valid {
+		content.LastUpdatedBy = &row.LastUpdatedBy.Int32
+	}
+	if row.FolderID.Valid {
+		content.FolderID = &row.FolderID.String
+	}
+	return content
+}
+
+// validate fills in defaults and checks the enumerated fields
+func (r *UserContentRequest) validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if !userContentTypes[r.Type] {
+		return fmt.Errorf("unknown content type %q", r.Type)
+	}
+	if r.Visibility == "" {
+		r.Visibility = "user"
+	}
+	if !userContentVisibilities[r.Visibility] {
+		return fmt.Errorf("unknown visibility %q", r.Visibility)
+	}
+	if len(r.Content) == 0 {
+		r.Content = json.RawMessage("{}")
+	}
+	if !json.Valid(r.Content) {
+		return fmt.Errorf("content must be valid JSON")
+	}
+	return nil
+}
+
+// canOnUserContent evaluates the user_content permissions Studio also receives, so the
+// backend enforces exactly what the dashboard shows
+func (a *Api) canOnUserContent(ctx context.Context, account *database.Account, project database.Project, action string, ownerID int32, contentType, visibility string) (bool, error) {
+	return a.can(ctx, account, authzAction{Action: action, Resource: "user_content"}, authzResource{
+		OrganizationID: project.OrganizationID,
+		ProjectID:      project.ID,
+		Attributes: map[string]interface{}{
+			"owner_id":   ownerID,
+			"type":       contentType,
+			"visibility": visibility,
+		},
+	})
+}
+
+// canReadUserContent mirrors the listing query: private content is only visible to its owner
+func canReadUserContent(account *database.Account, row database.UserContent) bool {
+	return row.Visibility != "user" || row.OwnerID == account.ID
+}
+
+func textOrNull(s *string) pgtype.Text {
+	if s == nil {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: *s, Valid: true}
+}
+
+// createUserContent stores new content owned by the account and writes the response
+func (a *Api) createUserContent(c *gin.Context, account *database.Account, project database.Project, req UserContentRequest) {
+	ownerID := account.ID
+	if req.OwnerID != nil {
+		ownerID = *req.OwnerID
+	}
+	allowed, err := a.canOnUserContent(c.Request.Context(), account, project, "write:Create", ownerID, req.Type, req.Visibility)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to check content permission: %v", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to create this content"})
+		return
+	}
+
+	id := req.ID
+	if id == "" {
+		id = utils.NewUUID()
+	}
+	row, err := a.queries.CreateUserContent(c.Request.Context(), database.CreateUserContentParams{
+		ID:            id,
+		ProjectID:     project.ID,
+		OwnerID:       ownerID,
+		LastUpdatedBy: pgtype.Int4{Int32: account.ID, Valid: true},
+		Type:          req.Type,
+		Visibility:    req.Visibility,
+		Name:          req.Name,
+		Description:   textOrNull(req.Description),
+		Content:       req.Content,
+		Favorite:      req.Favorite != nil && *req.Favorite,
+		FolderID:      textOrNull(req.FolderID),
+	})
+	// Client supplied ids are unique across projects, the id may be taken by content this
+	// project can't see
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Content with id %s already exists", id)})
+		return
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to create content for project %s: %v", project.ProjectRef, err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, userContentFromRow(row))
+}
+
+// updateUserContent replaces existing content after checking the update permission against
+// both the stored and the requested visibility, so nobody can take over private content by
+// making it shared in the same request
+func (a *Api) updateUserContent(c *gin.Context, account *database.Account, project database.Project, existing database.UserContent, req UserContentRequest) {
+	for _, target := range []UserContentRequest{{Type: existing.Type, Visibility: existing.Visibility}, req} {
+		allowed, err := a.canOnUserContent(c.Request.Context(), account, project, "write:Update", existing.OwnerID, target.Type, target.Visibility)
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Failed to check content permission: %v", err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to update this content"})
+			return
+		}
+	}
+
+	favorite := existing.Favorite
+	if req.Favorite != nil {
+		favorite = *req.Favorite
+	}
+	row, err := a.queries.UpdateUserContent(c.Request.Context(), database.UpdateUserContentParams{
+		ID:            existing.ID,
+		Type:          req.Type,
+		Visibility:    req.Visibility,
+		Name:          req.Name,
+		Description:   textOrNull(req.Description),
+		Content:       req.Content,
+		Favorite:      favorite,
+		FolderID:      textOrNull(req.FolderID),
+		LastUpdatedBy: pgtype.Int4{Int32: account.ID, Valid: true},
+	})
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to update content %s: %v", existing.ID, err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, userContentFromRow(row))
+}
+
+// userContentForAccount loads content of the project that the account may see, writing the
+// error response itself when that fails
+func (a *Api) userContentForAccount(c *gin.Context, account *database.Account, project database.Project, id string) (database.UserContent, bool) {
+	row, err := a.queries.GetUserContentByID(c.Request.Context(), database.GetUserContentByIDParams{
+		ID:        id,
+		ProjectID: project.ID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Content not found"})
+			return database.UserContent{}, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+		return database.UserContent{}, false
+	}
+	if !canReadUserContent(account, row) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Content not found"})
+		return database.UserContent{}, false
+	}
+	return row, true
+}
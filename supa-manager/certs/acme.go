@@ -0,0 +1,295 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/crypto/acme"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/utils"
+)
+
+// propagationPollInterval is how often a dns-01 record is looked up until it's visible
+const propagationPollInterval = 5 * time.Second
+
+type issuedCertificate struct {
+	certPEM   []byte
+	keyPEM    []byte
+	notBefore time.Time
+	notAfter  time.Time
+}
+
+// newHTTPClient trusts the system roots and those in caFile, if set
+func newHTTPClient(caFile string) (*http.Client, error) {
+	if caFile == "" {
+		return &http.Client{Timeout: 30 * time.Second}, nil
+	}
+	raw, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ACME CA file: %w", err)
+	}
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	if !roots.AppendCertsFromPEM(raw) {
+		return nil, fmt.Errorf("no certificates in ACME CA file %s", caFile)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: roots}
+	return &http.Client{Timeout: 30 * time.Second, Transport: transport}, nil
+}
+
+// acmeClient returns a client for the account of the directory, registering it the first time.
+// Replicas registering together keep whichever account was stored first
+func (m *Manager) acmeClient(ctx context.Context) (*acme.Client, error) {
+	m.clientMu.Lock()
+	defer m.clientMu.Unlock()
+	if m.client != nil {
+		return m.client, nil
+	}
+
+	account, err := m.queries.GetAcmeAccount(ctx, m.settings.DirectoryURL)
+	if errors.Is(err, pgx.ErrNoRows) {
+		account, err = m.register(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := utils.Decrypt(m.secret, account.KeyEncrypted)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("ACME account key is not PEM encoded")
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	m.client = m.newClient(key)
+	return m.client, nil
+}
+
+func (m *Manager) register(ctx context.Context) (database.AcmeAccount, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return database.AcmeAccount{}, err
+	}
+	var contact []string
+	if m.settings.Email != "" {
+		contact = []string{"mailto:" + m.settings.Email}
+	}
+	registered, err := m.newClient(key).Register(ctx, &acme.Account{Contact: contact}, acme.AcceptTOS)
+	if err != nil && !errors.Is(err, acme.ErrAccountAlreadyExists) {
+		return database.AcmeAccount{}, fmt.Errorf("failed to register ACME account: %w", err)
+	}
+
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return database.AcmeAccount{}, err
+	}
+	encrypted, err := utils.Encrypt(m.secret, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		return database.AcmeAccount{}, err
+	}
+	var accountURL pgtype.Text
+	if registered != nil {
+		accountURL = pgtype.Text{String: registered.URI, Valid: true}
+	}
+	err = m.queries.CreateAcmeAccount(ctx, database.CreateAcmeAccountParams{
+		DirectoryUrl: m.settings.DirectoryURL,
+		KeyEncrypted: encrypted,
+		AccountUrl:   accountURL,
+	})
+	if err != nil {
+		return database.AcmeAccount{}, err
+	}
+	m.logger.Info("Registered ACME account", "directory", m.settings.DirectoryURL)
+	return m.queries.GetAcmeAccount(ctx, m.settings.DirectoryURL)
+}
+
+func (m *Manager) newClient(key *ecdsa.PrivateKey) *acme.Client {
+	return &acme.Client{
+		Key:          key,
+		DirectoryURL: m.settings.DirectoryURL,
+		HTTPClient:   m.http,
+		UserAgent:    "supa-manager",
+	}
+}
+
+// obtain runs an ACME order for the certificate's domains with a new key
+func (m *Manager) obtain(ctx context.Context, cert database.Certificate) (*issuedCertificate, error) {
+	client, err := m.acmeClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs(cert.Domains...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create order: %w", err)
+	}
+	for _, authzURL := range order.AuthzURLs {
+		if err := m.authorize(ctx, client, authzURL, cert.Challenge); err != nil {
+			return nil, err
+		}
+	}
+	if order, err = client.WaitOrder(ctx, order.URI); err != nil {
+		return nil, fmt.Errorf("order did not become ready: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{DNSNames: cert.Domains}, key)
+	if err != nil {
+		return nil, err
+	}
+	chain, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to finalize order: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		return nil, err
+	}
+
+	issued := &issuedCertificate{notBefore: leaf.NotBefore, notAfter: leaf.NotAfter}
+	for _, der := range chain {
+		issued.certPEM = append(issued.certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	issued.keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	return issued, nil
+}
+
+// authorize completes one authorization of an order with the challenge type of the certificate.
+// The challenge record or token is removed whatever the outcome
+func (m *Manager) authorize(ctx context.Context, client *acme.Client, authzURL string, challengeType string) error {
+	authz, err := client.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return err
+	}
+	if authz.Status == acme.StatusValid {
+		return nil
+	}
+	domain := authz.Identifier.Value
+	index := slices.IndexFunc(authz.Challenges, func(c *acme.Challenge) bool { return c.Type == challengeType })
+	if index < 0 {
+		return fmt.Errorf("CA offers no %s challenge for %s", challengeType, domain)
+	}
+	challenge := authz.Challenges[index]
+
+	cleanup, err := m.present(ctx, client, domain, challenge)
+	if err != nil {
+		return fmt.Errorf("failed to present %s challenge for %s: %w", challengeType, domain, err)
+	}
+	defer cleanup()
+
+	if _, err := client.Accept(ctx, challenge); err != nil {
+		return fmt.Errorf("failed to accept %s challenge for %s: %w", challengeType, domain, err)
+	}
+	if _, err := client.WaitAuthorization(ctx, authz.URI); err != nil {
+		return fmt.Errorf("authorization of %s failed: %w", domain, err)
+	}
+	return nil
+}
+
+// present publishes what the CA checks for a challenge and returns how to remove it
+func (m *Manager) present(ctx context.Context, client *acme.Client, domain string, challenge *acme.Challenge) (func(), error) {
+	switch challenge.Type {
+	case ChallengeDNS01:
+		value, err := client.DNS01ChallengeRecord(challenge.Token)
+		if err != nil {
+			return nil, err
+		}
+		name := "_acme-challenge." + domain
+		if err := m.dns.SetTXT(ctx, name, value); err != nil {
+			return nil, err
+		}
+		cleanup := func() {
+			if err := m.dns.ClearTXT(context.WithoutCancel(ctx), name, value); err != nil {
+				m.logger.Warn(fmt.Sprintf("Failed to remove challenge record %s: %v", name, err))
+			}
+		}
+		if err := m.waitForTXT(ctx, name, value); err != nil {
+			cleanup()
+			return nil, err
+		}
+		return cleanup, nil
+
+	case ChallengeHTTP01:
+		keyAuthorization, err := client.HTTP01ChallengeResponse(challenge.Token)
+		if err != nil {
+			return nil, err
+		}
+		err = m.queries.CreateAcmeChallenge(ctx, database.CreateAcmeChallengeParams{
+			Token:            challenge.Token,
+			KeyAuthorization: keyAuthorization,
+			ExpiresAt:        pgtype.Timestamptz{Time: time.Now().Add(issueTimeout), Valid: true},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return func() {
+			if err := m.queries.DeleteAcmeChallenge(context.WithoutCancel(ctx), challenge.Token); err != nil {
+				m.logger.Warn(fmt.Sprintf("Failed to remove challenge token for %s: %v", domain, err))
+			}
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported challenge type %s", challenge.Type)
+	}
+}
+
+// waitForTXT polls the resolver until the record is visible, the CA fails a challenge it can't see
+func (m *Manager) waitForTXT(ctx context.Context, name string, value string) error {
+	resolver := net.DefaultResolver
+	if m.settings.DNSResolver != "" {
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, m.settings.DNSResolver)
+			},
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.settings.PropagationTimeout)
+	defer cancel()
+	ticker := time.NewTicker(propagationPollInterval)
+	defer ticker.Stop()
+	for {
+		records, err := resolver.LookupTXT(ctx, name)
+		if err == nil && slices.Contains(records, value) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("record %s did not propagate: %w", strings.TrimSuffix(name, "."), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package certs
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const http01Prefix = "/.well-known/acme-challenge/"
+
+// HTTPHandler answers HTTP-01 challenges from the tokens any replica stored and passes every other
+// request to next. It must be served on port 80 of the hostnames being validated
+func (m *Manager) HTTPHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.URL.Path, http01Prefix)
+		if !ok || r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		challenge, err := m.queries.GetAcmeChallenge(r.Context(), token)
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			m.logger.Error("Failed to load ACME challenge", "error", err.Error())
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(challenge.KeyAuthorization))
+	})
+}
@@ -0,0 +1,238 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/crypto/acme"
+	"supamanager.io/supa-manager/database"
+	"supamanager.io/supa-manager/utils"
+)
+
+// Challenge types a certificate is validated with. Wildcards can only use dns-01
+const (
+	ChallengeDNS01  = "dns-01"
+	ChallengeHTTP01 = "http-01"
+)
+
+// issueTimeout bounds one issuance, the certificate stays claimed by this replica as long
+const issueTimeout = 10 * time.Minute
+
+// DNSProvider publishes the TXT records of dns-01 challenges
+type DNSProvider interface {
+	SetTXT(ctx context.Context, name string, value string) error
+	ClearTXT(ctx context.Context, name string, value string) error
+}
+
+type Settings struct {
+	DirectoryURL string
+	Email        string
+	// CAFile holds extra roots trusted for the directory, like the one a local Pebble serves with
+	CAFile string
+	// RenewBefore is how long before expiry certificates are renewed
+	RenewBefore time.Duration
+	// RetryAfter is how long failed issuances wait before the next attempt
+	RetryAfter time.Duration
+	// DNSResolver is the "host:port" checked for challenge records, the system resolver when empty
+	DNSResolver        string
+	PropagationTimeout time.Duration
+}
+
+// Manager obtains certificates from an ACME CA and serves them for TLS handshakes. Certificates,
+// the account and HTTP-01 tokens live in Postgres so every replica serves the same ones and can
+// answer any challenge
+type Manager struct {
+	queries  *database.Queries
+	secret   string
+	dns      DNSProvider
+	settings Settings
+	logger   *slog.Logger
+	http     *http.Client
+
+	clientMu sync.Mutex
+	client   *acme.Client
+
+	mu    sync.RWMutex
+	certs map[string]*tls.Certificate
+}
+
+// New creates a manager, certificates are served once Reload loaded them
+func New(queries *database.Queries, encryptionSecret string, dns DNSProvider, settings Settings, logger *slog.Logger) (*Manager, error) {
+	client, err := newHTTPClient(settings.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{
+		queries:  queries,
+		secret:   encryptionSecret,
+		dns:      dns,
+		settings: settings,
+		logger:   logger,
+		http:     client,
+		certs:    make(map[string]*tls.Certificate),
+	}, nil
+}
+
+// GetCertificate picks the certificate of the requested server name, falling back to the wildcard
+// of its parent domain. It's meant for tls.Config
+func (m *Manager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	name := strings.TrimSuffix(strings.ToLower(hello.ServerName), ".")
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if cert, ok := m.certs[name]; ok {
+		return cert, nil
+	}
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		if cert, ok := m.certs["*"+name[i:]]; ok {
+			return cert, nil
+		}
+	}
+	return nil, fmt.Errorf("no certificate for %q", hello.ServerName)
+}
+
+// Request asks for a certificate named name covering domains, it's issued by the next RenewDue
+// on any replica. Requesting an existing certificate changes nothing until it's renewed
+func (m *Manager) Request(ctx context.Context, name string, domains []string, challenge string) (database.Certificate, error) {
+	return m.queries.RequestCertificate(ctx, database.RequestCertificateParams{
+		Name:      name,
+		Domains:   domains,
+		Challenge: challenge,
+	})
+}
+
+// Status returns the certificate named name, pgx.ErrNoRows when it was never requested
+func (m *Manager) Status(ctx context.Context, name string) (database.Certificate, error) {
+	return m.queries.GetCertificate(ctx, name)
+}
+
+// Remove deletes a certificate, other replicas stop serving it on their next Reload
+func (m *Manager) Remove(ctx context.Context, name string) error {
+	if err := m.queries.DeleteCertificate(ctx, name); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.certs, name)
+	return nil
+}
+
+// Reload replaces the served certificates with those stored. Certificates that fail to load are
+// skipped, a broken one must not take the others down
+func (m *Manager) Reload(ctx context.Context) error {
+	stored, err := m.queries.GetIssuedCertificates(ctx)
+	if err != nil {
+		return err
+	}
+
+	certs := make(map[string]*tls.Certificate, len(stored))
+	for _, cert := range stored {
+		loaded, err := m.load(cert)
+		if err != nil {
+			m.logger.Error(fmt.Sprintf("Failed to load certificate %s: %v", cert.Name, err))
+			continue
+		}
+		certs[cert.Name] = loaded
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.certs = certs
+	return nil
+}
+
+// RenewDue issues the certificates that were requested, failed a while ago or expire soon. Each is
+// claimed first, so replicas running this together don't issue a certificate twice
+func (m *Manager) RenewDue(ctx context.Context) error {
+	now := time.Now()
+	due, err := m.queries.GetDueCertificates(ctx, database.GetDueCertificatesParams{
+		RetryBefore: pgtype.Timestamptz{Time: now.Add(-m.settings.RetryAfter), Valid: true},
+		RenewBefore: pgtype.Timestamptz{Time: now.Add(m.settings.RenewBefore), Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, cert := range due {
+		claimed, err := m.queries.ClaimCertificate(ctx, database.ClaimCertificateParams{
+			ID:          cert.ID,
+			LockedUntil: pgtype.Timestamptz{Time: time.Now().Add(issueTimeout), Valid: true},
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		m.issue(ctx, claimed)
+	}
+	return nil
+}
+
+func (m *Manager) issue(ctx context.Context, cert database.Certificate) {
+	ctx, cancel := context.WithTimeout(ctx, issueTimeout)
+	defer cancel()
+
+	issued, err := m.obtain(ctx, cert)
+	if err == nil {
+		err = m.save(ctx, cert, issued)
+	}
+	if err != nil {
+		m.logger.Error(fmt.Sprintf("Failed to issue certificate %s: %v", cert.Name, err))
+		if err := m.queries.FailCertificate(context.WithoutCancel(ctx), database.FailCertificateParams{
+			ID:    cert.ID,
+			Error: pgtype.Text{String: err.Error(), Valid: true},
+		}); err != nil {
+			m.logger.Error(fmt.Sprintf("Failed to record failed certificate %s: %v", cert.Name, err))
+		}
+		return
+	}
+	m.logger.Info("Issued certificate", "name", cert.Name, "not_after", issued.notAfter)
+}
+
+func (m *Manager) save(ctx context.Context, cert database.Certificate, issued *issuedCertificate) error {
+	loaded, err := tls.X509KeyPair(issued.certPEM, issued.keyPEM)
+	if err != nil {
+		return err
+	}
+	encrypted, err := utils.Encrypt(m.secret, issued.keyPEM)
+	if err != nil {
+		return err
+	}
+	err = m.queries.SaveIssuedCertificate(ctx, database.SaveIssuedCertificateParams{
+		ID:           cert.ID,
+		CertPem:      pgtype.Text{String: string(issued.certPEM), Valid: true},
+		KeyEncrypted: pgtype.Text{String: encrypted, Valid: true},
+		NotBefore:    pgtype.Timestamptz{Time: issued.notBefore, Valid: true},
+		NotAfter:     pgtype.Timestamptz{Time: issued.notAfter, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.certs[cert.Name] = &loaded
+	return nil
+}
+
+func (m *Manager) load(cert database.Certificate) (*tls.Certificate, error) {
+	key, err := utils.Decrypt(m.secret, cert.KeyEncrypted.String)
+	if err != nil {
+		return nil, err
+	}
+	loaded, err := tls.X509KeyPair([]byte(cert.CertPem.String), key)
+	if err != nil {
+		return nil, err
+	}
+	return &loaded, nil
+}
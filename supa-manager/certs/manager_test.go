@@ -0,0 +1,147 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func selfSigned(t *testing.T, names ...string) *tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		DNSNames:     names,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestGetCertificate(t *testing.T) {
+	wildcard := selfSigned(t, "*.supamanager.io")
+	exact := selfSigned(t, "api.supamanager.io")
+	custom := selfSigned(t, "api.example.com")
+	m := &Manager{certs: map[string]*tls.Certificate{
+		"*.supamanager.io":   wildcard,
+		"api.supamanager.io": exact,
+		"api.example.com":    custom,
+	}}
+
+	tests := []struct {
+		name       string
+		serverName string
+		want       *tls.Certificate
+	}{
+		{"exact name wins over the wildcard", "api.supamanager.io", exact},
+		{"project subdomain", "abcdefghijklmnop.supamanager.io", wildcard},
+		{"upper case", "ABCDEFGHIJKLMNOP.SupaManager.io", wildcard},
+		{"trailing dot", "abcdefghijklmnop.supamanager.io.", wildcard},
+		{"custom hostname", "api.example.com", custom},
+		// a wildcard only covers one label
+		{"nested subdomain", "a.b.supamanager.io", nil},
+		{"the base itself", "supamanager.io", nil},
+		{"unknown", "www.example.com", nil},
+		{"other subdomain of a custom hostname", "www.api.example.com", nil},
+		{"no server name", "", nil},
+		{"leading dot", ".supamanager.io", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: tt.serverName})
+			if got != tt.want {
+				t.Errorf("GetCertificate(%q) returned the wrong certificate", tt.serverName)
+			}
+			if (err != nil) != (tt.want == nil) {
+				t.Errorf("GetCertificate(%q) error = %v", tt.serverName, err)
+			}
+		})
+	}
+}
+
+func TestHTTPHandlerPassesOtherRequests(t *testing.T) {
+	// the challenge store isn't touched for anything but a GET of a challenge path
+	m := &Manager{}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/"},
+		{http.MethodGet, "/.well-known/other"},
+		{http.MethodGet, "/prefix/.well-known/acme-challenge/token"},
+		{http.MethodPost, "/.well-known/acme-challenge/token"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		m.HTTPHandler(next).ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != http.StatusTeapot {
+			t.Errorf("%s %s: status %d, want it passed on", tt.method, tt.path, w.Code)
+		}
+	}
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	emptyFile := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(emptyFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		caFile      string
+		wantErr     bool
+		wantTrusted bool
+	}{
+		{"system roots", "", false, false},
+		{"extra root", caFile, false, true},
+		{"missing file", filepath.Join(dir, "missing.pem"), true, false},
+		{"file without certificates", emptyFile, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := newHTTPClient(tt.caFile)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newHTTPClient() error = %v, want error: %t", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			resp, err := client.Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if trusted := err == nil; trusted != tt.wantTrusted {
+				t.Errorf("request to a server signed by the extra root: %v, want trusted: %t", err, tt.wantTrusted)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
 	"os"
+	"time"
 )
 
 type PostgresSettings struct {
@@ -17,6 +18,10 @@ type DomainSettings struct {
 	Base       string  `json:"base_url" required:"true"`
 	DnsHookUrl *string `json:"dns_hook_url" split_words:"true"`
 	DnsHookKey *string `json:"dns_hook_key" split_words:"true"`
+
+	// DnsHookMaxAttempts is how often an update is sent before it's given up on and stops holding
+	// back the later updates of its hostname
+	DnsHookMaxAttempts int32 `json:"dns_hook_max_attempts" split_words:"true" default:"10"`
 }
 
 type ProvisioningSettings struct {
@@ -25,6 +30,96 @@ type ProvisioningSettings struct {
 	ProjectsDir      string `json:"projects_dir" split_words:"true" default:"./projects"`
 	BasePostgresPort int    `json:"base_postgres_port" split_words:"true" default:"5433"`
 	BaseKongHTTPPort int    `json:"base_kong_http_port" split_words:"true" default:"54321"`
+	DatabaseHost     string `json:"database_host" split_words:"true" default:"localhost"`
+}
+
+type EdgeSettings struct {
+	Enabled      bool          `json:"enabled" default:"false"`
+	HttpAddress  string        `json:"http_address" split_words:"true" default:":80"`
+	HttpsAddress string        `json:"https_address" split_words:"true" default:":443"`
+	TlsCertFile  string        `json:"tls_cert_file" split_words:"true"`
+	TlsKeyFile   string        `json:"tls_key_file" split_words:"true"`
+	UpstreamHost string        `json:"upstream_host" split_words:"true" default:"localhost"`
+	CacheTtl     time.Duration `json:"cache_ttl" split_words:"true" default:"30s"`
+}
+
+type AcmeSettings struct {
+	Enabled            bool          `json:"enabled" default:"false"`
+	DirectoryUrl       string        `json:"directory_url" split_words:"true" default:"https://acme-v02.api.letsencrypt.org/directory"`
+	Email              string        `json:"email"`
+	CaFile             string        `json:"ca_file" split_words:"true"`
+	RenewBefore        time.Duration `json:"renew_before" split_words:"true" default:"720h"`
+	RetryAfter         time.Duration `json:"retry_after" split_words:"true" default:"1h"`
+	DnsResolver        string        `json:"dns_resolver" split_words:"true"`
+	PropagationTimeout time.Duration `json:"propagation_timeout" split_words:"true" default:"5m"`
+}
+
+type PgMetaSettings struct {
+	QueryTimeout    time.Duration `json:"query_timeout" split_words:"true" default:"60s"`
+	MaxRows         int           `json:"max_rows" split_words:"true" default:"50000"`
+	MaxConns        int32         `json:"max_conns" split_words:"true" default:"4"`
+	PoolIdleTimeout time.Duration `json:"pool_idle_timeout" split_words:"true" default:"10m"`
+}
+
+type AuthSettings struct {
+	AccessTokenTtl  time.Duration `json:"access_token_ttl" split_words:"true" default:"1h"`
+	RefreshTokenTtl time.Duration `json:"refresh_token_ttl" split_words:"true" default:"720h"`
+	MfaRequired     bool          `json:"mfa_required" split_words:"true" default:"false"`
+	MfaIssuer       string        `json:"mfa_issuer" split_words:"true" default:"Supa Manager"`
+	ExternalUrl     string        `json:"external_url" split_words:"true" default:"http://localhost:8080"`
+	Autoconfirm     bool          `json:"autoconfirm" default:"false"`
+
+	// RefreshTokenReuseInterval is how long a rotated refresh token still returns the session it
+	// was rotated to, concurrent refreshes and retries would otherwise revoke the session
+	RefreshTokenReuseInterval time.Duration `json:"refresh_token_reuse_interval" split_words:"true" default:"10s"`
+}
+
+type SsoSettings struct {
+	Enabled                 bool     `json:"enabled" default:"false"`
+	Issuer                  string   `json:"issuer"`
+	ClientId                string   `json:"client_id" split_words:"true"`
+	ClientSecret            string   `json:"client_secret" split_words:"true"`
+	Scopes                  []string `json:"scopes" default:"openid,email,profile"`
+	EmailClaim              string   `json:"email_claim" split_words:"true" default:"email"`
+	NameClaim               string   `json:"name_claim" split_words:"true" default:"name"`
+	GroupsClaim             string   `json:"groups_claim" split_words:"true" default:"groups"`
+	TrustEmail              bool     `json:"trust_email" split_words:"true" default:"false"`
+	AutoProvision           bool     `json:"auto_provision" split_words:"true" default:"true"`
+	GroupMappings           []string `json:"group_mappings" split_words:"true"`
+	PasswordDisabledDomains []string `json:"password_disabled_domains" split_words:"true"`
+}
+
+type MailerSettings struct {
+	Driver       string        `json:"driver" default:"log"`
+	From         string        `json:"from" default:"Supa Manager <noreply@supamanager.io>"`
+	SmtpHost     string        `json:"smtp_host" split_words:"true"`
+	SmtpPort     int           `json:"smtp_port" split_words:"true" default:"587"`
+	SmtpUsername string        `json:"smtp_username" split_words:"true"`
+	SmtpPassword string        `json:"smtp_password" split_words:"true"`
+	SmtpSecurity string        `json:"smtp_security" split_words:"true" default:"starttls"`
+	SmtpTimeout  time.Duration `json:"smtp_timeout" split_words:"true" default:"30s"`
+	FileDir      string        `json:"file_dir" split_words:"true" default:"./mail"`
+}
+
+type BackupSettings struct {
+	Dir             string        `json:"dir" default:"./backups"`
+	VerifyEnabled   bool          `json:"verify_enabled" split_words:"true" default:"false"`
+	VerifyInterval  time.Duration `json:"verify_interval" split_words:"true" default:"24h"`
+	VerifyTimeout   time.Duration `json:"verify_timeout" split_words:"true" default:"30m"`
+	VerifyBatchSize int           `json:"verify_batch_size" split_words:"true" default:"5"`
+	AlertWebhookUrl *string       `json:"alert_webhook_url" split_words:"true"`
+}
+
+type RateLimitSettings struct {
+	Store            string        `json:"store" default:"postgres"`
+	AuthIpLimit      int           `json:"auth_ip_limit" split_words:"true" default:"30"`
+	AuthIpWindow     time.Duration `json:"auth_ip_window" split_words:"true" default:"15m"`
+	LockoutThreshold int           `json:"lockout_threshold" split_words:"true" default:"5"`
+	LockoutBase      time.Duration `json:"lockout_base" split_words:"true" default:"30s"`
+	LockoutMax       time.Duration `json:"lockout_max" split_words:"true" default:"1h"`
+	LockoutWindow    time.Duration `json:"lockout_window" split_words:"true" default:"1h"`
+	TokenLimit       int           `json:"token_limit" split_words:"true" default:"0"`
+	TokenWindow      time.Duration `json:"token_window" split_words:"true" default:"1m"`
 }
 
 type Config struct {
@@ -33,10 +128,19 @@ type Config struct {
 	EncryptionSecret  string               `json:"encryption_secret" split_words:"true" required:"true"`
 	JwtSecret         string               `json:"jwt_secret" split_words:"true" required:"true"`
 	AllowSignup       bool                 `json:"allow_signup" split_words:"true" default:"false"`
+	TrustedProxies    []string             `json:"trusted_proxies" split_words:"true"`
 	ServiceVersionUrl string               `json:"service_version_url" split_words:"true" required:"true" default:"https://supamanager.io/updates"`
 	Domain            DomainSettings       `json:"domain" required:"true"`
+	Auth              AuthSettings         `json:"auth"`
+	RateLimit         RateLimitSettings    `json:"rate_limit" split_words:"true"`
+	Mailer            MailerSettings       `json:"mailer"`
+	Sso               SsoSettings          `json:"sso"`
 	Postgres          PostgresSettings     `json:"postgres" required:"true"`
 	Provisioning      ProvisioningSettings `json:"provisioning"`
+	Edge              EdgeSettings         `json:"edge"`
+	Acme              AcmeSettings         `json:"acme"`
+	Backups           BackupSettings       `json:"backups"`
+	PgMeta            PgMetaSettings       `json:"pg_meta" split_words:"true"`
 }
 
 func LoadConfig(filename string) (*Config, error) {
@@ -11,20 +11,38 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const confirmAccountEmail = `-- name: ConfirmAccountEmail :exec
+UPDATE public.accounts
+SET email_confirmed_at = coalesce(email_confirmed_at, now()),
+    updated_at         = now()
+WHERE id = $1
+`
+
+func (q *Queries) ConfirmAccountEmail(ctx context.Context, id int32) error {
+	_, err := q.db.Exec(ctx, confirmAccountEmail, id)
+	return err
+}
+
 const createAccount = `-- name: CreateAccount :one
-INSERT INTO public.accounts (email, password_hash, username)
-VALUES ($1, $2, $3)
-RETURNING id, gotrue_id, email, password_hash, username, first_name, last_name, created_at, updated_at
+INSERT INTO public.accounts (email, password_hash, username, email_confirmed_at)
+VALUES ($1, $2, $3, $4)
+RETURNING id, gotrue_id, email, password_hash, username, first_name, last_name, created_at, updated_at, email_confirmed_at
 `
 
 type CreateAccountParams struct {
-	Email        string
-	PasswordHash string
-	Username     string
+	Email            string
+	PasswordHash     string
+	Username         string
+	EmailConfirmedAt pgtype.Timestamptz
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRow(ctx, createAccount, arg.Email, arg.PasswordHash, arg.Username)
+	row := q.db.QueryRow(ctx, createAccount,
+		arg.Email,
+		arg.PasswordHash,
+		arg.Username,
+		arg.EmailConfirmedAt,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -36,12 +54,13 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.LastName,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailConfirmedAt,
 	)
 	return i, err
 }
 
 const getAccountByEmail = `-- name: GetAccountByEmail :one
-SELECT id, gotrue_id, email, password_hash, username, first_name, last_name, created_at, updated_at FROM public.accounts WHERE email = $1
+SELECT id, gotrue_id, email, password_hash, username, first_name, last_name, created_at, updated_at, email_confirmed_at FROM public.accounts WHERE email = $1
 `
 
 func (q *Queries) GetAccountByEmail(ctx context.Context, email string) (Account, error) {
@@ -57,12 +76,13 @@ func (q *Queries) GetAccountByEmail(ctx context.Context, email string) (Account,
 		&i.LastName,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailConfirmedAt,
 	)
 	return i, err
 }
 
 const getAccountByGoTrueID = `-- name: GetAccountByGoTrueID :one
-SELECT id, gotrue_id, email, password_hash, username, first_name, last_name, created_at, updated_at FROM public.accounts WHERE gotrue_id = $1
+SELECT id, gotrue_id, email, password_hash, username, first_name, last_name, created_at, updated_at, email_confirmed_at FROM public.accounts WHERE gotrue_id = $1
 `
 
 func (q *Queries) GetAccountByGoTrueID(ctx context.Context, gotrueID string) (Account, error) {
@@ -78,12 +98,13 @@ func (q *Queries) GetAccountByGoTrueID(ctx context.Context, gotrueID string) (Ac
 		&i.LastName,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailConfirmedAt,
 	)
 	return i, err
 }
 
 const getAccountByID = `-- name: GetAccountByID :one
-SELECT id, gotrue_id, email, password_hash, username, first_name, last_name, created_at, updated_at FROM public.accounts WHERE id = $1
+SELECT id, gotrue_id, email, password_hash, username, first_name, last_name, created_at, updated_at, email_confirmed_at FROM public.accounts WHERE id = $1
 `
 
 func (q *Queries) GetAccountByID(ctx context.Context, id int32) (Account, error) {
@@ -99,10 +120,29 @@ func (q *Queries) GetAccountByID(ctx context.Context, id int32) (Account, error)
 		&i.LastName,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailConfirmedAt,
 	)
 	return i, err
 }
 
+const setAccountEmail = `-- name: SetAccountEmail :exec
+UPDATE public.accounts
+SET email              = $2,
+    email_confirmed_at = now(),
+    updated_at         = now()
+WHERE id = $1
+`
+
+type SetAccountEmailParams struct {
+	ID    int32
+	Email string
+}
+
+func (q *Queries) SetAccountEmail(ctx context.Context, arg SetAccountEmailParams) error {
+	_, err := q.db.Exec(ctx, setAccountEmail, arg.ID, arg.Email)
+	return err
+}
+
 const setAccountName = `-- name: SetAccountName :exec
 UPDATE public.accounts
 SET first_name = $2,
@@ -120,3 +160,20 @@ func (q *Queries) SetAccountName(ctx context.Context, arg SetAccountNameParams)
 	_, err := q.db.Exec(ctx, setAccountName, arg.ID, arg.FirstName, arg.LastName)
 	return err
 }
+
+const setAccountPassword = `-- name: SetAccountPassword :exec
+UPDATE public.accounts
+SET password_hash = $2,
+    updated_at    = now()
+WHERE id = $1
+`
+
+type SetAccountPasswordParams struct {
+	ID           int32
+	PasswordHash string
+}
+
+func (q *Queries) SetAccountPassword(ctx context.Context, arg SetAccountPasswordParams) error {
+	_, err := q.db.Exec(ctx, setAccountPassword, arg.ID, arg.PasswordHash)
+	return err
+}
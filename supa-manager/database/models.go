@@ -9,17 +9,178 @@ import (
 )
 
 type Account struct {
-	ID           int32
-	GotrueID     string
-	Email        string
-	PasswordHash string
-	Username     string
-	FirstName    pgtype.Text
-	LastName     pgtype.Text
+	ID               int32
+	GotrueID         string
+	Email            string
+	PasswordHash     string
+	Username         string
+	FirstName        pgtype.Text
+	LastName         pgtype.Text
+	CreatedAt        pgtype.Timestamptz
+	UpdatedAt        pgtype.Timestamptz
+	EmailConfirmedAt pgtype.Timestamptz
+}
+
+type AccountIdentity struct {
+	Provider     string
+	Subject      string
+	AccountID    int32
+	Email        pgtype.Text
+	Claims       []byte
+	CreatedAt    pgtype.Timestamptz
+	LastSignInAt pgtype.Timestamptz
+}
+
+type AccountToken struct {
+	ID        int64
+	AccountID int32
+	Purpose   string
+	TokenHash string
+	Email     string
+	CreatedAt pgtype.Timestamptz
+	ExpiresAt pgtype.Timestamptz
+	UsedAt    pgtype.Timestamptz
+}
+
+type AcmeAccount struct {
+	DirectoryUrl string
+	KeyEncrypted string
+	AccountUrl   pgtype.Text
+	CreatedAt    pgtype.Timestamptz
+}
+
+type AcmeChallenge struct {
+	Token            string
+	KeyAuthorization string
+	ExpiresAt        pgtype.Timestamptz
+}
+
+type AuditEvent struct {
+	ID             int64
+	OccurredAt     pgtype.Timestamptz
+	ActorID        pgtype.Int4
+	ActorEmail     pgtype.Text
+	TokenID        pgtype.Int8
+	OrganizationID pgtype.Int4
+	ProjectRef     pgtype.Text
+	Action         string
+	Target         string
+	Status         pgtype.Int4
+	Error          pgtype.Text
+	Ip             pgtype.Text
+	UserAgent      pgtype.Text
+	RequestID      pgtype.Text
+	Payload        []byte
+}
+
+type Backup struct {
+	ID                    string
+	ProjectRef            string
+	BackupType            string
+	Status                string
+	FilePath              string
+	S3Key                 pgtype.Text
+	SizeBytes             int64
+	Compressed            bool
+	Encrypted             bool
+	ChecksumSha256        pgtype.Text
+	VerificationStatus    string
+	VerificationError     pgtype.Text
+	VerifiedAt            pgtype.Timestamptz
+	CreatedAt             pgtype.Timestamptz
+	CompletedAt           pgtype.Timestamptz
+	ExpiresAt             pgtype.Timestamptz
+	VerificationStartedAt pgtype.Timestamptz
+}
+
+type Certificate struct {
+	ID           int64
+	Name         string
+	Domains      []string
+	Challenge    string
+	Status       string
+	CertPem      pgtype.Text
+	KeyEncrypted pgtype.Text
+	NotBefore    pgtype.Timestamptz
+	NotAfter     pgtype.Timestamptz
+	Error        pgtype.Text
+	LockedUntil  pgtype.Timestamptz
 	CreatedAt    pgtype.Timestamptz
 	UpdatedAt    pgtype.Timestamptz
 }
 
+type CustomHostname struct {
+	ID                int64
+	ProjectID         int32
+	Hostname          string
+	Status            string
+	VerificationToken string
+	Error             pgtype.Text
+	LastCheckedAt     pgtype.Timestamptz
+	VerifiedAt        pgtype.Timestamptz
+	ActivatedAt       pgtype.Timestamptz
+	CreatedAt         pgtype.Timestamptz
+	UpdatedAt         pgtype.Timestamptz
+}
+
+type DnsHookOutbox struct {
+	ID            int64
+	Type          string
+	Hostname      string
+	ProjectRef    pgtype.Text
+	RecordType    pgtype.Text
+	Value         pgtype.Text
+	Attempts      int32
+	NextAttemptAt pgtype.Timestamptz
+	LockedUntil   pgtype.Timestamptz
+	LastError     pgtype.Text
+	CreatedAt     pgtype.Timestamptz
+	FailedAt      pgtype.Timestamptz
+}
+
+type JwtSecretRotation struct {
+	ID               int64
+	ProjectID        int32
+	ChangeTrackingID string
+	Status           string
+	Step             string
+	Progress         int32
+	Error            pgtype.Text
+	RolledBack       bool
+	RequestedBy      pgtype.Int4
+	CreatedAt        pgtype.Timestamptz
+	UpdatedAt        pgtype.Timestamptz
+	CompletedAt      pgtype.Timestamptz
+}
+
+type MfaChallenge struct {
+	ID         string
+	FactorID   string
+	Ip         pgtype.Text
+	CreatedAt  pgtype.Timestamptz
+	VerifiedAt pgtype.Timestamptz
+}
+
+type MfaFactor struct {
+	ID              string
+	AccountID       int32
+	FriendlyName    pgtype.Text
+	FactorType      string
+	Status          string
+	SecretEncrypted string
+	LastUsedStep    pgtype.Int8
+	CreatedAt       pgtype.Timestamptz
+	UpdatedAt       pgtype.Timestamptz
+}
+
+type MfaRecoveryCode struct {
+	ID        int64
+	AccountID int32
+	CodeHash  string
+	UsedAt    pgtype.Timestamptz
+	CreatedAt pgtype.Timestamptz
+}
+
 type Migration struct {
 	ID        string
 	Note      pgtype.Text
@@ -27,11 +188,29 @@ type Migration struct {
 }
 
 type Organization struct {
-	ID        int32
-	Slug      string
-	Name      string
-	CreatedAt pgtype.Timestamptz
-	UpdatedAt pgtype.Timestamptz
+	ID           int32
+	Slug         string
+	Name         string
+	CreatedAt    pgtype.Timestamptz
+	UpdatedAt    pgtype.Timestamptz
+	Kind         pgtype.Text
+	Size         pgtype.Text
+	Plan         string
+	BillingEmail pgtype.Text
+	OptInTags    []string
+}
+
+type OrganizationInvitation struct {
+	ID             int64
+	OrganizationID int32
+	InvitedEmail   string
+	Role           string
+	TokenHash      string
+	InvitedBy      pgtype.Int4
+	CreatedAt      pgtype.Timestamptz
+	ExpiresAt      pgtype.Timestamptz
+	AcceptedAt     pgtype.Timestamptz
+	AcceptedBy     pgtype.Int4
 }
 
 type OrganizationMembership struct {
@@ -42,23 +221,118 @@ type OrganizationMembership struct {
 	UpdatedAt      pgtype.Timestamptz
 }
 
+type PersonalAccessToken struct {
+	ID             int64
+	AccountID      int32
+	Name           string
+	TokenHash      string
+	TokenAlias     string
+	ReadOnly       bool
+	OrganizationID pgtype.Int4
+	ProjectRef     pgtype.Text
+	Aal            string
+	CreatedAt      pgtype.Timestamptz
+	ExpiresAt      pgtype.Timestamptz
+	LastUsedAt     pgtype.Timestamptz
+	LastUsedIp     pgtype.Text
+	RevokedAt      pgtype.Timestamptz
+}
+
 type Project struct {
-	ID                int32
-	ProjectRef        string
-	ProjectName       string
-	OrganizationID    int32
-	Status            string
-	CloudProvider     string
-	Region            string
-	JwtSecret         string
-	CreatedAt         pgtype.Timestamptz
-	UpdatedAt         pgtype.Timestamptz
-	DockerComposePath pgtype.Text
-	DockerNetworkName pgtype.Text
-	PostgresPort      pgtype.Int4
-	KongHttpPort      pgtype.Int4
-	KongHttpsPort     pgtype.Int4
-	AnonKey           pgtype.Text
-	ServiceRoleKey    pgtype.Text
-	ProvisionedAt     pgtype.Timestamptz
+	ID                  int32
+	ProjectRef          string
+	ProjectName         string
+	OrganizationID      int32
+	Status              string
+	CloudProvider       string
+	Region              string
+	JwtSecret           string
+	CreatedAt           pgtype.Timestamptz
+	UpdatedAt           pgtype.Timestamptz
+	DockerComposePath   pgtype.Text
+	DockerNetworkName   pgtype.Text
+	PostgresPort        pgtype.Int4
+	KongHttpPort        pgtype.Int4
+	KongHttpsPort       pgtype.Int4
+	AnonKey             pgtype.Text
+	ServiceRoleKey      pgtype.Text
+	ProvisionedAt       pgtype.Timestamptz
+	DbUser              string
+	DbPasswordEncrypted pgtype.Text
+}
+
+type ProjectApiKey struct {
+	ID           int64
+	ProjectID    int32
+	Name         string
+	Role         string
+	KeyEncrypted string
+	KeyAlias     string
+	CreatedBy    pgtype.Int4
+	CreatedAt    pgtype.Timestamptz
+	ExpiresAt    pgtype.Timestamptz
+	RevokedAt    pgtype.Timestamptz
+}
+
+type QueryHistory struct {
+	ID           int64
+	ProjectID    int32
+	AccountID    int32
+	Query        string
+	Success      bool
+	ErrorMessage pgtype.Text
+	RowCount     int32
+	DurationMs   int32
+	ExecutedAt   pgtype.Timestamptz
+}
+
+type RateLimit struct {
+	Key         string
+	Hits        int32
+	WindowStart pgtype.Timestamptz
+	Failures    int32
+	LockedUntil pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type Session struct {
+	ID                      string
+	FamilyID                string
+	ParentID                pgtype.Text
+	AccountID               int32
+	TokenHash               string
+	UserAgent               pgtype.Text
+	Ip                      pgtype.Text
+	CreatedAt               pgtype.Timestamptz
+	ExpiresAt               pgtype.Timestamptz
+	RotatedAt               pgtype.Timestamptz
+	RevokedAt               pgtype.Timestamptz
+	RevokedReason           pgtype.Text
+	Aal                     string
+	Amr                     []string
+	SuccessorTokenEncrypted pgtype.Text
+}
+
+type SsoState struct {
+	StateHash    string
+	CodeVerifier string
+	Nonce        string
+	RedirectTo   string
+	CreatedAt    pgtype.Timestamptz
+}
+
+type UserContent struct {
+	ID            string
+	ProjectID     int32
+	OwnerID       int32
+	LastUpdatedBy pgtype.Int4
+	Type          string
+	Visibility    string
+	Name          string
+	Description   pgtype.Text
+	Content       []byte
+	Favorite      bool
+	FolderID      pgtype.Text
+	InsertedAt    pgtype.Timestamptz
+	UpdatedAt     pgtype.Timestamptz
 }
@@ -33,3 +33,182 @@ func (q *Queries) CreateOrganizationMembership(ctx context.Context, arg CreateOr
 	)
 	return i, err
 }
+
+const deleteOrganizationMembership = `-- name: DeleteOrganizationMembership :execrows
+DELETE FROM organization_membership
+WHERE organization_id = $1
+  AND account_id = $2
+`
+
+type DeleteOrganizationMembershipParams struct {
+	OrganizationID int32
+	AccountID      int32
+}
+
+func (q *Queries) DeleteOrganizationMembership(ctx context.Context, arg DeleteOrganizationMembershipParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteOrganizationMembership, arg.OrganizationID, arg.AccountID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const ensureOrganizationMembership = `-- name: EnsureOrganizationMembership :exec
+INSERT INTO organization_membership (organization_id, account_id, role)
+VALUES ($1, $2, $3)
+ON CONFLICT (organization_id, account_id) DO NOTHING
+`
+
+type EnsureOrganizationMembershipParams struct {
+	OrganizationID int32
+	AccountID      int32
+	Role           string
+}
+
+func (q *Queries) EnsureOrganizationMembership(ctx context.Context, arg EnsureOrganizationMembershipParams) error {
+	_, err := q.db.Exec(ctx, ensureOrganizationMembership, arg.OrganizationID, arg.AccountID, arg.Role)
+	return err
+}
+
+const getOrganizationMemberByGotrueId = `-- name: GetOrganizationMemberByGotrueId :one
+SELECT a.id, om.role
+FROM organization_membership om
+         JOIN accounts a on a.id = om.account_id
+WHERE om.organization_id = $1
+  AND a.gotrue_id = $2
+`
+
+type GetOrganizationMemberByGotrueIdParams struct {
+	OrganizationID int32
+	GotrueID       string
+}
+
+type GetOrganizationMemberByGotrueIdRow struct {
+	ID   int32
+	Role string
+}
+
+func (q *Queries) GetOrganizationMemberByGotrueId(ctx context.Context, arg GetOrganizationMemberByGotrueIdParams) (GetOrganizationMemberByGotrueIdRow, error) {
+	row := q.db.QueryRow(ctx, getOrganizationMemberByGotrueId, arg.OrganizationID, arg.GotrueID)
+	var i GetOrganizationMemberByGotrueIdRow
+	err := row.Scan(&i.ID, &i.Role)
+	return i, err
+}
+
+const getOrganizationMemberRole = `-- name: GetOrganizationMemberRole :one
+SELECT role
+FROM organization_membership
+WHERE organization_id = $1
+  AND account_id = $2
+`
+
+type GetOrganizationMemberRoleParams struct {
+	OrganizationID int32
+	AccountID      int32
+}
+
+func (q *Queries) GetOrganizationMemberRole(ctx context.Context, arg GetOrganizationMemberRoleParams) (string, error) {
+	row := q.db.QueryRow(ctx, getOrganizationMemberRole, arg.OrganizationID, arg.AccountID)
+	var role string
+	err := row.Scan(&role)
+	return role, err
+}
+
+const getOrganizationMembers = `-- name: GetOrganizationMembers :many
+SELECT a.id,
+       a.gotrue_id,
+       a.email,
+       a.username,
+       om.role,
+       EXISTS (SELECT 1 FROM public.mfa_factors f WHERE f.account_id = a.id AND f.status = 'verified') AS mfa_enabled,
+       EXISTS (SELECT 1 FROM public.account_identities i WHERE i.account_id = a.id) AS is_sso_user
+FROM organization_membership om
+         JOIN accounts a on a.id = om.account_id
+WHERE om.organization_id = $1
+ORDER BY om.created_at
+`
+
+type GetOrganizationMembersRow struct {
+	ID         int32
+	GotrueID   string
+	Email      string
+	Username   string
+	Role       string
+	MfaEnabled bool
+	IsSsoUser  bool
+}
+
+func (q *Queries) GetOrganizationMembers(ctx context.Context, organizationID int32) ([]GetOrganizationMembersRow, error) {
+	rows, err := q.db.Query(ctx, getOrganizationMembers, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrganizationMembersRow
+	for rows.Next() {
+		var i GetOrganizationMembersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.GotrueID,
+			&i.Email,
+			&i.Username,
+			&i.Role,
+			&i.MfaEnabled,
+			&i.IsSsoUser,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockOrganizationOwners = `-- name: LockOrganizationOwners :many
+SELECT account_id
+FROM organization_membership
+WHERE organization_id = $1
+  AND role = 'OWNER'
+FOR UPDATE
+`
+
+func (q *Queries) LockOrganizationOwners(ctx context.Context, organizationID int32) ([]int32, error) {
+	rows, err := q.db.Query(ctx, lockOrganizationOwners, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var accountID int32
+		if err := rows.Scan(&accountID); err != nil {
+			return nil, err
+		}
+		items = append(items, accountID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateOrganizationMemberRole = `-- name: UpdateOrganizationMemberRole :exec
+UPDATE organization_membership
+SET role       = $3,
+    updated_at = now()
+WHERE organization_id = $1
+  AND account_id = $2
+`
+
+type UpdateOrganizationMemberRoleParams struct {
+	OrganizationID int32
+	AccountID      int32
+	Role           string
+}
+
+func (q *Queries) UpdateOrganizationMemberRole(ctx context.Context, arg UpdateOrganizationMemberRoleParams) error {
+	_, err := q.db.Exec(ctx, updateOrganizationMemberRole, arg.OrganizationID, arg.AccountID, arg.Role)
+	return err
+}
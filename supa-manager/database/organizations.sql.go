@@ -12,13 +12,27 @@ import (
 )
 
 const createOrganization = `-- name: CreateOrganization :one
-INSERT INTO public.organizations (name, created_at, updated_at)
-VALUES ($1, now(), now())
-RETURNING id, slug, name, created_at, updated_at
+INSERT INTO public.organizations (name, kind, size, plan, billing_email, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, now(), now())
+RETURNING id, slug, name, created_at, updated_at, kind, size, plan, billing_email, opt_in_tags
 `
 
-func (q *Queries) CreateOrganization(ctx context.Context, name string) (Organization, error) {
-	row := q.db.QueryRow(ctx, createOrganization, name)
+type CreateOrganizationParams struct {
+	Name         string
+	Kind         pgtype.Text
+	Size         pgtype.Text
+	Plan         string
+	BillingEmail pgtype.Text
+}
+
+func (q *Queries) CreateOrganization(ctx context.Context, arg CreateOrganizationParams) (Organization, error) {
+	row := q.db.QueryRow(ctx, createOrganization,
+		arg.Name,
+		arg.Kind,
+		arg.Size,
+		arg.Plan,
+		arg.BillingEmail,
+	)
 	var i Organization
 	err := row.Scan(
 		&i.ID,
@@ -26,12 +40,49 @@ func (q *Queries) CreateOrganization(ctx context.Context, name string) (Organiza
 		&i.Name,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Kind,
+		&i.Size,
+		&i.Plan,
+		&i.BillingEmail,
+		&i.OptInTags,
+	)
+	return i, err
+}
+
+const deleteOrganization = `-- name: DeleteOrganization :exec
+DELETE FROM public.organizations
+WHERE id = $1
+`
+
+func (q *Queries) DeleteOrganization(ctx context.Context, id int32) error {
+	_, err := q.db.Exec(ctx, deleteOrganization, id)
+	return err
+}
+
+const getOrganization = `-- name: GetOrganization :one
+SELECT id, slug, name, created_at, updated_at, kind, size, plan, billing_email, opt_in_tags FROM public.organizations WHERE id = $1
+`
+
+func (q *Queries) GetOrganization(ctx context.Context, id int32) (Organization, error) {
+	row := q.db.QueryRow(ctx, getOrganization, id)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Slug,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Kind,
+		&i.Size,
+		&i.Plan,
+		&i.BillingEmail,
+		&i.OptInTags,
 	)
 	return i, err
 }
 
 const getOrganizationById = `-- name: GetOrganizationById :one
-SELECT id, slug, name, created_at, updated_at FROM public.organizations WHERE slug = $1
+SELECT id, slug, name, created_at, updated_at, kind, size, plan, billing_email, opt_in_tags FROM public.organizations WHERE slug = $1
 `
 
 func (q *Queries) GetOrganizationById(ctx context.Context, id string) (Organization, error) {
@@ -43,6 +94,11 @@ func (q *Queries) GetOrganizationById(ctx context.Context, id string) (Organizat
 		&i.Name,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Kind,
+		&i.Size,
+		&i.Plan,
+		&i.BillingEmail,
+		&i.OptInTags,
 	)
 	return i, err
 }
@@ -75,19 +131,24 @@ func (q *Queries) GetOrganizationIdsForAccountId(ctx context.Context, accountID
 }
 
 const getOrganizationsForAccountId = `-- name: GetOrganizationsForAccountId :many
-SELECT o.id, o.slug, o.name, o.created_at, o.updated_at, om.role as member_role
+SELECT o.id, o.slug, o.name, o.created_at, o.updated_at, o.kind, o.size, o.plan, o.billing_email, o.opt_in_tags, om.role as member_role
 FROM organization_membership om
          JOIN organizations o on o.id = om.organization_id
 WHERE account_id = $1
 `
 
 type GetOrganizationsForAccountIdRow struct {
-	ID         int32
-	Slug       string
-	Name       string
-	CreatedAt  pgtype.Timestamptz
-	UpdatedAt  pgtype.Timestamptz
-	MemberRole string
+	ID           int32
+	Slug         string
+	Name         string
+	CreatedAt    pgtype.Timestamptz
+	UpdatedAt    pgtype.Timestamptz
+	Kind         pgtype.Text
+	Size         pgtype.Text
+	Plan         string
+	BillingEmail pgtype.Text
+	OptInTags    []string
+	MemberRole   string
 }
 
 func (q *Queries) GetOrganizationsForAccountId(ctx context.Context, accountID int32) ([]GetOrganizationsForAccountIdRow, error) {
@@ -105,6 +166,11 @@ func (q *Queries) GetOrganizationsForAccountId(ctx context.Context, accountID in
 			&i.Name,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Kind,
+			&i.Size,
+			&i.Plan,
+			&i.BillingEmail,
+			&i.OptInTags,
 			&i.MemberRole,
 		); err != nil {
 			return nil, err
@@ -116,3 +182,68 @@ func (q *Queries) GetOrganizationsForAccountId(ctx context.Context, accountID in
 	}
 	return items, nil
 }
+
+const lockOrganization = `-- name: LockOrganization :one
+SELECT id, slug, name, created_at, updated_at, kind, size, plan, billing_email, opt_in_tags
+FROM public.organizations
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) LockOrganization(ctx context.Context, id int32) (Organization, error) {
+	row := q.db.QueryRow(ctx, lockOrganization, id)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Slug,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Kind,
+		&i.Size,
+		&i.Plan,
+		&i.BillingEmail,
+		&i.OptInTags,
+	)
+	return i, err
+}
+
+const updateOrganization = `-- name: UpdateOrganization :one
+UPDATE public.organizations
+SET name          = $2,
+    billing_email = $3,
+    opt_in_tags   = $4,
+    updated_at    = now()
+WHERE id = $1
+RETURNING id, slug, name, created_at, updated_at, kind, size, plan, billing_email, opt_in_tags
+`
+
+type UpdateOrganizationParams struct {
+	ID           int32
+	Name         string
+	BillingEmail pgtype.Text
+	OptInTags    []string
+}
+
+func (q *Queries) UpdateOrganization(ctx context.Context, arg UpdateOrganizationParams) (Organization, error) {
+	row := q.db.QueryRow(ctx, updateOrganization,
+		arg.ID,
+		arg.Name,
+		arg.BillingEmail,
+		arg.OptInTags,
+	)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Slug,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Kind,
+		&i.Size,
+		&i.Plan,
+		&i.BillingEmail,
+		&i.OptInTags,
+	)
+	return i, err
+}
@@ -11,19 +11,33 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countProjectsForOrganization = `-- name: CountProjectsForOrganization :one
+SELECT count(*)
+FROM project
+WHERE organization_id = $1
+`
+
+func (q *Queries) CountProjectsForOrganization(ctx context.Context, organizationID int32) (int64, error) {
+	row := q.db.QueryRow(ctx, countProjectsForOrganization, organizationID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createProject = `-- name: CreateProject :one
-INSERT INTO project (project_ref, project_name, organization_id, status, jwt_secret, cloud_provider, region)
-VALUES ($1, $2, $3, 'PROVISIONING', $4, $5, $6)
-RETURNING id, project_ref, project_name, organization_id, status, cloud_provider, region, jwt_secret, created_at, updated_at, docker_compose_path, docker_network_name, postgres_port, kong_http_port, kong_https_port, anon_key, service_role_key, provisioned_at
+INSERT INTO project (project_ref, project_name, organization_id, status, jwt_secret, cloud_provider, region, db_password_encrypted)
+VALUES ($1, $2, $3, 'PROVISIONING', $4, $5, $6, $7)
+RETURNING id, project_ref, project_name, organization_id, status, cloud_provider, region, jwt_secret, created_at, updated_at, docker_compose_path, docker_network_name, postgres_port, kong_http_port, kong_https_port, anon_key, service_role_key, provisioned_at, db_user, db_password_encrypted
 `
 
 type CreateProjectParams struct {
-	ProjectRef     string
-	ProjectName    string
-	OrganizationID int32
-	JwtSecret      string
-	CloudProvider  string
-	Region         string
+	ProjectRef          string
+	ProjectName         string
+	OrganizationID      int32
+	JwtSecret           string
+	CloudProvider       string
+	Region              string
+	DbPasswordEncrypted pgtype.Text
 }
 
 func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) (Project, error) {
@@ -34,6 +48,7 @@ func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) (P
 		arg.JwtSecret,
 		arg.CloudProvider,
 		arg.Region,
+		arg.DbPasswordEncrypted,
 	)
 	var i Project
 	err := row.Scan(
@@ -55,6 +70,8 @@ func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) (P
 		&i.AnonKey,
 		&i.ServiceRoleKey,
 		&i.ProvisionedAt,
+		&i.DbUser,
+		&i.DbPasswordEncrypted,
 	)
 	return i, err
 }
@@ -69,8 +86,42 @@ func (q *Queries) DeleteProject(ctx context.Context, projectRef string) error {
 	return err
 }
 
+const getProjectByID = `-- name: GetProjectByID :one
+SELECT id, project_ref, project_name, organization_id, status, cloud_provider, region, jwt_secret, created_at, updated_at, docker_compose_path, docker_network_name, postgres_port, kong_http_port, kong_https_port, anon_key, service_role_key, provisioned_at, db_user, db_password_encrypted
+FROM project
+WHERE id = $1
+`
+
+func (q *Queries) GetProjectByID(ctx context.Context, id int32) (Project, error) {
+	row := q.db.QueryRow(ctx, getProjectByID, id)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectRef,
+		&i.ProjectName,
+		&i.OrganizationID,
+		&i.Status,
+		&i.CloudProvider,
+		&i.Region,
+		&i.JwtSecret,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DockerComposePath,
+		&i.DockerNetworkName,
+		&i.PostgresPort,
+		&i.KongHttpPort,
+		&i.KongHttpsPort,
+		&i.AnonKey,
+		&i.ServiceRoleKey,
+		&i.ProvisionedAt,
+		&i.DbUser,
+		&i.DbPasswordEncrypted,
+	)
+	return i, err
+}
+
 const getProjectByRef = `-- name: GetProjectByRef :one
-SELECT id, project_ref, project_name, organization_id, status, cloud_provider, region, jwt_secret, created_at, updated_at, docker_compose_path, docker_network_name, postgres_port, kong_http_port, kong_https_port, anon_key, service_role_key, provisioned_at
+SELECT id, project_ref, project_name, organization_id, status, cloud_provider, region, jwt_secret, created_at, updated_at, docker_compose_path, docker_network_name, postgres_port, kong_http_port, kong_https_port, anon_key, service_role_key, provisioned_at, db_user, db_password_encrypted
 FROM project
 WHERE project_ref = $1
 `
@@ -97,12 +148,49 @@ func (q *Queries) GetProjectByRef(ctx context.Context, projectRef string) (Proje
 		&i.AnonKey,
 		&i.ServiceRoleKey,
 		&i.ProvisionedAt,
+		&i.DbUser,
+		&i.DbPasswordEncrypted,
+	)
+	return i, err
+}
+
+const getProjectForUpdate = `-- name: GetProjectForUpdate :one
+SELECT id, project_ref, project_name, organization_id, status, cloud_provider, region, jwt_secret, created_at, updated_at, docker_compose_path, docker_network_name, postgres_port, kong_http_port, kong_https_port, anon_key, service_role_key, provisioned_at, db_user, db_password_encrypted
+FROM project
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetProjectForUpdate(ctx context.Context, id int32) (Project, error) {
+	row := q.db.QueryRow(ctx, getProjectForUpdate, id)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectRef,
+		&i.ProjectName,
+		&i.OrganizationID,
+		&i.Status,
+		&i.CloudProvider,
+		&i.Region,
+		&i.JwtSecret,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DockerComposePath,
+		&i.DockerNetworkName,
+		&i.PostgresPort,
+		&i.KongHttpPort,
+		&i.KongHttpsPort,
+		&i.AnonKey,
+		&i.ServiceRoleKey,
+		&i.ProvisionedAt,
+		&i.DbUser,
+		&i.DbPasswordEncrypted,
 	)
 	return i, err
 }
 
 const getProjectsByStatus = `-- name: GetProjectsByStatus :many
-SELECT id, project_ref, project_name, organization_id, status, cloud_provider, region, jwt_secret, created_at, updated_at, docker_compose_path, docker_network_name, postgres_port, kong_http_port, kong_https_port, anon_key, service_role_key, provisioned_at
+SELECT id, project_ref, project_name, organization_id, status, cloud_provider, region, jwt_secret, created_at, updated_at, docker_compose_path, docker_network_name, postgres_port, kong_http_port, kong_https_port, anon_key, service_role_key, provisioned_at, db_user, db_password_encrypted
 FROM project
 WHERE status = $1
 ORDER BY created_at DESC
@@ -136,6 +224,8 @@ func (q *Queries) GetProjectsByStatus(ctx context.Context, status string) ([]Pro
 			&i.AnonKey,
 			&i.ServiceRoleKey,
 			&i.ProvisionedAt,
+			&i.DbUser,
+			&i.DbPasswordEncrypted,
 		); err != nil {
 			return nil, err
 		}
@@ -148,7 +238,7 @@ func (q *Queries) GetProjectsByStatus(ctx context.Context, status string) ([]Pro
 }
 
 const getProjectsForAccountId = `-- name: GetProjectsForAccountId :many
-SELECT p.id, p.project_ref, p.project_name, p.organization_id, p.status, p.cloud_provider, p.region, p.jwt_secret, p.created_at, p.updated_at, p.docker_compose_path, p.docker_network_name, p.postgres_port, p.kong_http_port, p.kong_https_port, p.anon_key, p.service_role_key, p.provisioned_at
+SELECT p.id, p.project_ref, p.project_name, p.organization_id, p.status, p.cloud_provider, p.region, p.jwt_secret, p.created_at, p.updated_at, p.docker_compose_path, p.docker_network_name, p.postgres_port, p.kong_http_port, p.kong_https_port, p.anon_key, p.service_role_key, p.provisioned_at, p.db_user, p.db_password_encrypted
 FROM organization_membership om
          JOIN project p on om.organization_id = p.organization_id
 WHERE account_id = $1
@@ -182,6 +272,56 @@ func (q *Queries) GetProjectsForAccountId(ctx context.Context, accountID int32)
 			&i.AnonKey,
 			&i.ServiceRoleKey,
 			&i.ProvisionedAt,
+			&i.DbUser,
+			&i.DbPasswordEncrypted,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getProjectsForOrganization = `-- name: GetProjectsForOrganization :many
+SELECT id, project_ref, project_name, organization_id, status, cloud_provider, region, jwt_secret, created_at, updated_at, docker_compose_path, docker_network_name, postgres_port, kong_http_port, kong_https_port, anon_key, service_role_key, provisioned_at, db_user, db_password_encrypted
+FROM project
+WHERE organization_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) GetProjectsForOrganization(ctx context.Context, organizationID int32) ([]Project, error) {
+	rows, err := q.db.Query(ctx, getProjectsForOrganization, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Project
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectRef,
+			&i.ProjectName,
+			&i.OrganizationID,
+			&i.Status,
+			&i.CloudProvider,
+			&i.Region,
+			&i.JwtSecret,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DockerComposePath,
+			&i.DockerNetworkName,
+			&i.PostgresPort,
+			&i.KongHttpPort,
+			&i.KongHttpsPort,
+			&i.AnonKey,
+			&i.ServiceRoleKey,
+			&i.ProvisionedAt,
+			&i.DbUser,
+			&i.DbPasswordEncrypted,
 		); err != nil {
 			return nil, err
 		}
@@ -193,6 +333,38 @@ func (q *Queries) GetProjectsForAccountId(ctx context.Context, accountID int32)
 	return items, nil
 }
 
+const lockProject = `-- name: LockProject :exec
+SELECT id
+FROM project
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) LockProject(ctx context.Context, id int32) error {
+	_, err := q.db.Exec(ctx, lockProject, id)
+	return err
+}
+
+const swapProjectStatus = `-- name: SwapProjectStatus :execrows
+UPDATE project
+SET status = $1, updated_at = now()
+WHERE project_ref = $2 AND status = $3
+`
+
+type SwapProjectStatusParams struct {
+	Status         string
+	ProjectRef     string
+	ExpectedStatus string
+}
+
+func (q *Queries) SwapProjectStatus(ctx context.Context, arg SwapProjectStatusParams) (int64, error) {
+	result, err := q.db.Exec(ctx, swapProjectStatus, arg.Status, arg.ProjectRef, arg.ExpectedStatus)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateProjectInfrastructure = `-- name: UpdateProjectInfrastructure :one
 UPDATE project
 SET docker_compose_path = $2,
@@ -205,7 +377,7 @@ SET docker_compose_path = $2,
     provisioned_at = now(),
     updated_at = now()
 WHERE project_ref = $1
-RETURNING id, project_ref, project_name, organization_id, status, cloud_provider, region, jwt_secret, created_at, updated_at, docker_compose_path, docker_network_name, postgres_port, kong_http_port, kong_https_port, anon_key, service_role_key, provisioned_at
+RETURNING id, project_ref, project_name, organization_id, status, cloud_provider, region, jwt_secret, created_at, updated_at, docker_compose_path, docker_network_name, postgres_port, kong_http_port, kong_https_port, anon_key, service_role_key, provisioned_at, db_user, db_password_encrypted
 `
 
 type UpdateProjectInfrastructureParams struct {
@@ -250,6 +422,98 @@ func (q *Queries) UpdateProjectInfrastructure(ctx context.Context, arg UpdatePro
 		&i.AnonKey,
 		&i.ServiceRoleKey,
 		&i.ProvisionedAt,
+		&i.DbUser,
+		&i.DbPasswordEncrypted,
+	)
+	return i, err
+}
+
+const updateProjectJwtSecret = `-- name: UpdateProjectJwtSecret :one
+UPDATE project
+SET jwt_secret = $2,
+    anon_key = $3,
+    service_role_key = $4,
+    updated_at = now()
+WHERE project_ref = $1
+RETURNING id, project_ref, project_name, organization_id, status, cloud_provider, region, jwt_secret, created_at, updated_at, docker_compose_path, docker_network_name, postgres_port, kong_http_port, kong_https_port, anon_key, service_role_key, provisioned_at, db_user, db_password_encrypted
+`
+
+type UpdateProjectJwtSecretParams struct {
+	ProjectRef     string
+	JwtSecret      string
+	AnonKey        pgtype.Text
+	ServiceRoleKey pgtype.Text
+}
+
+func (q *Queries) UpdateProjectJwtSecret(ctx context.Context, arg UpdateProjectJwtSecretParams) (Project, error) {
+	row := q.db.QueryRow(ctx, updateProjectJwtSecret,
+		arg.ProjectRef,
+		arg.JwtSecret,
+		arg.AnonKey,
+		arg.ServiceRoleKey,
+	)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectRef,
+		&i.ProjectName,
+		&i.OrganizationID,
+		&i.Status,
+		&i.CloudProvider,
+		&i.Region,
+		&i.JwtSecret,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DockerComposePath,
+		&i.DockerNetworkName,
+		&i.PostgresPort,
+		&i.KongHttpPort,
+		&i.KongHttpsPort,
+		&i.AnonKey,
+		&i.ServiceRoleKey,
+		&i.ProvisionedAt,
+		&i.DbUser,
+		&i.DbPasswordEncrypted,
+	)
+	return i, err
+}
+
+const updateProjectOrganization = `-- name: UpdateProjectOrganization :one
+UPDATE project
+SET organization_id = $2, updated_at = now()
+WHERE project_ref = $1
+RETURNING id, project_ref, project_name, organization_id, status, cloud_provider, region, jwt_secret, created_at, updated_at, docker_compose_path, docker_network_name, postgres_port, kong_http_port, kong_https_port, anon_key, service_role_key, provisioned_at, db_user, db_password_encrypted
+`
+
+type UpdateProjectOrganizationParams struct {
+	ProjectRef     string
+	OrganizationID int32
+}
+
+func (q *Queries) UpdateProjectOrganization(ctx context.Context, arg UpdateProjectOrganizationParams) (Project, error) {
+	row := q.db.QueryRow(ctx, updateProjectOrganization, arg.ProjectRef, arg.OrganizationID)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectRef,
+		&i.ProjectName,
+		&i.OrganizationID,
+		&i.Status,
+		&i.CloudProvider,
+		&i.Region,
+		&i.JwtSecret,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DockerComposePath,
+		&i.DockerNetworkName,
+		&i.PostgresPort,
+		&i.KongHttpPort,
+		&i.KongHttpsPort,
+		&i.AnonKey,
+		&i.ServiceRoleKey,
+		&i.ProvisionedAt,
+		&i.DbUser,
+		&i.DbPasswordEncrypted,
 	)
 	return i, err
 }
@@ -258,7 +522,7 @@ const updateProjectStatus = `-- name: UpdateProjectStatus :one
 UPDATE project
 SET status = $2, updated_at = now()
 WHERE project_ref = $1
-RETURNING id, project_ref, project_name, organization_id, status, cloud_provider, region, jwt_secret, created_at, updated_at, docker_compose_path, docker_network_name, postgres_port, kong_http_port, kong_https_port, anon_key, service_role_key, provisioned_at
+RETURNING id, project_ref, project_name, organization_id, status, cloud_provider, region, jwt_secret, created_at, updated_at, docker_compose_path, docker_network_name, postgres_port, kong_http_port, kong_https_port, anon_key, service_role_key, provisioned_at, db_user, db_password_encrypted
 `
 
 type UpdateProjectStatusParams struct {
@@ -288,6 +552,8 @@ func (q *Queries) UpdateProjectStatus(ctx context.Context, arg UpdateProjectStat
 		&i.AnonKey,
 		&i.ServiceRoleKey,
 		&i.ProvisionedAt,
+		&i.DbUser,
+		&i.DbPasswordEncrypted,
 	)
 	return i, err
 }
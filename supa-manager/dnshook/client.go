@@ -0,0 +1,106 @@
+package dnshook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Requests are signed with an HMAC-SHA256 of "<timestamp>.<body>" keyed with DOMAIN_DNS_HOOK_KEY.
+// The timestamp is in Unix seconds, receivers should reject those too far from their clock so a
+// captured request can't be replayed later
+const (
+	TimestampHeader = "X-Supamanager-Timestamp"
+	SignatureHeader = "X-Supamanager-Signature"
+)
+
+// UpdateType is what the DNS service should do with a record
+type UpdateType string
+
+const (
+	UpdateCreate UpdateType = "CREATE"
+	UpdateDelete UpdateType = "DELETE"
+)
+
+// Update is the body sent to the DNS hook. Updates without a record type are about the record that
+// points a project's hostname at the edge, the DNS service picks its type
+type Update struct {
+	Type       UpdateType `json:"type"`
+	Hostname   string     `json:"hostname"`
+	ProjectRef string     `json:"project_ref,omitempty"`
+	RecordType string     `json:"record_type,omitempty"` // like TXT for ACME challenges
+	Value      string     `json:"value,omitempty"`
+}
+
+// StatusError is returned when the DNS hook answers with a status other than 2xx
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("DNS hook returned status %d", e.StatusCode)
+}
+
+// Retryable reports whether sending the update again may succeed. Other 4xx mean the hook won't
+// ever accept it, like a payload it can't handle or a signature it doesn't trust
+func (e *StatusError) Retryable() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusTooManyRequests
+}
+
+// Client sends updates to the DNS hook service configured with DOMAIN_DNS_HOOK_URL
+type Client struct {
+	url    string
+	key    string
+	client *http.Client
+}
+
+func NewClient(url string, key string) *Client {
+	return &Client{
+		url:    url,
+		key:    key,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Send delivers an update, the DNS service accepts it with any 2xx status
+func (c *Client) Send(ctx context.Context, update Update) error {
+	payload, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(c.key, timestamp, payload))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver DNS update: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+// Sign returns the signature of a request body sent at timestamp, as carried by SignatureHeader
+func Sign(key string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
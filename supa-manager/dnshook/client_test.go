@@ -0,0 +1,103 @@
+package dnshook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// signatureVector is also checked by the dns-example-service tests, so both ends agree on the format
+const (
+	vectorKey       = "mysecretkey"
+	vectorTimestamp = "1700000000"
+	vectorBody      = `{"type":"CREATE","hostname":"abc.supamanager.io"}`
+	vectorSignature = "v1=45a61464fa45925486ceb6957fc84302abd2433ff203d3f97e4df0ad4350f21e"
+)
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		name      string
+		key       string
+		timestamp string
+		body      string
+		want      bool
+	}{
+		{"known vector", vectorKey, vectorTimestamp, vectorBody, true},
+		{"other key", "otherkey", vectorTimestamp, vectorBody, false},
+		{"other timestamp", vectorKey, "1700000001", vectorBody, false},
+		{"other body", vectorKey, vectorTimestamp, `{"type":"DELETE","hostname":"abc.supamanager.io"}`, false},
+		// the separator keeps the timestamp from running into the body
+		{"timestamp moved into body", vectorKey, "170000000", "0." + vectorBody, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sign(tt.key, tt.timestamp, []byte(tt.body))
+			if (got == vectorSignature) != tt.want {
+				t.Errorf("Sign = %s, matches the vector: %t, want %t", got, got == vectorSignature, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendSignsTheBody(t *testing.T) {
+	update := Update{Type: UpdateCreate, Hostname: "abc.supamanager.io", ProjectRef: "abc"}
+
+	var received Update
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp := r.Header.Get(TimestampHeader)
+		if r.Header.Get(SignatureHeader) != Sign("key", timestamp, body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		seconds, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil || time.Since(time.Unix(seconds, 0)).Abs() > time.Minute {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	if err := NewClient(server.URL, "key").Send(context.Background(), update); err != nil {
+		t.Fatal(err)
+	}
+	if received != update {
+		t.Errorf("received %+v, want %+v", received, update)
+	}
+
+	var statusErr *StatusError
+	err := NewClient(server.URL, "wrong key").Send(context.Background(), update)
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Send with the wrong key = %v, want status 401", err)
+	}
+}
+
+func TestStatusErrorRetryable(t *testing.T) {
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{http.StatusBadRequest, false},
+		{http.StatusUnauthorized, false},
+		{http.StatusNotFound, false},
+		{http.StatusUnprocessableEntity, false},
+		{http.StatusRequestTimeout, true},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, true},
+		{http.StatusBadGateway, true},
+		{http.StatusServiceUnavailable, true},
+	}
+	for _, tt := range tests {
+		if got := (&StatusError{StatusCode: tt.status}).Retryable(); got != tt.want {
+			t.Errorf("Retryable() for %d = %t, want %t", tt.status, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package edge
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrNotFound is returned by a LookupFunc for hosts without a project
+var ErrNotFound = errors.New("project not found")
+
+// maxCacheEntries bounds the cache, lookups of made up subdomains are cached too
+const maxCacheEntries = 10000
+
+// State is what the edge does with a project's requests
+type State int
+
+const (
+	// StateActive projects are proxied to their gateway
+	StateActive State = iota
+	// StatePaused projects are stopped until someone restores them
+	StatePaused
+	// StateUnavailable projects are being created, restored or changed
+	StateUnavailable
+)
+
+// Route is where the requests of a project go
+type Route struct {
+	Ref   string
+	Port  int32 // Gateway port on the upstream host, zero while the project isn't provisioned
+	State State
+}
+
+// LookupFunc loads the route of a project ref
+type LookupFunc func(ctx context.Context, ref string) (Route, error)
+
+type cacheEntry struct {
+	route   Route
+	err     error
+	expires time.Time
+}
+
+// Cache keeps routes for ttl so proxied requests don't each hit the database. Concurrent misses
+// for a ref share one lookup, unknown refs are remembered like known ones
+type Cache struct {
+	lookup LookupFunc
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	group   singleflight.Group
+}
+
+// NewCache creates a route cache in front of lookup
+func NewCache(lookup LookupFunc, ttl time.Duration) *Cache {
+	return &Cache{
+		lookup:  lookup,
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// Get returns the route of ref, or ErrNotFound
+func (c *Cache) Get(ctx context.Context, ref string) (Route, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[ref]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.route, entry.err
+	}
+
+	result, err, _ := c.group.Do(ref, func() (interface{}, error) {
+		// The lookup is shared, it must not end with the request that started it
+		route, err := c.lookup(context.WithoutCancel(ctx), ref)
+		if err == nil || errors.Is(err, ErrNotFound) {
+			c.store(ref, cacheEntry{route: route, err: err, expires: time.Now().Add(c.ttl)})
+		}
+		return route, err
+	})
+	return result.(Route), err
+}
+
+// Invalidate drops the cached route of ref, the next request looks it up again
+func (c *Cache) Invalidate(ref string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, ref)
+}
+
+// InvalidateRoutesOf drops every cached route to the project ref, whatever host it was looked up by
+func (c *Cache) InvalidateRoutesOf(ref string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, cached := range c.entries {
+		if cached.route.Ref == ref {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (c *Cache) store(ref string, entry cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= maxCacheEntries {
+		now := time.Now()
+		for key, cached := range c.entries {
+			if now.After(cached.expires) {
+				delete(c.entries, key)
+			}
+		}
+		if len(c.entries) >= maxCacheEntries {
+			c.entries = make(map[string]cacheEntry)
+		}
+	}
+	c.entries[ref] = entry
+}
@@ -0,0 +1,121 @@
+package edge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type targetKey struct{}
+
+// Proxy routes requests for <ref>.<base> to the gateway of project ref, and those for a custom
+// hostname to the gateway of its project. Requests for other hosts go to the fallback handler.
+// WebSocket upgrades, which Realtime uses, are proxied like any request
+type Proxy struct {
+	base         string
+	upstreamHost string
+	routes       *Cache
+	hosts        *Cache
+	fallback     http.Handler
+	logger       *slog.Logger
+	proxy        *httputil.ReverseProxy
+}
+
+// New creates a proxy for the subdomains of base, routes are looked up by project ref and hosts by
+// custom hostname. Gateways are reached on upstreamHost, the host their ports are published on
+func New(base string, upstreamHost string, routes *Cache, hosts *Cache, fallback http.Handler, logger *slog.Logger) *Proxy {
+	p := &Proxy{
+		base:         "." + strings.Trim(strings.ToLower(base), "."),
+		upstreamHost: upstreamHost,
+		routes:       routes,
+		hosts:        hosts,
+		fallback:     fallback,
+		logger:       logger,
+	}
+	p.proxy = &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(r.In.Context().Value(targetKey{}).(*url.URL))
+			r.SetXForwarded()
+			// The services build their external URLs from the host the client used
+			r.Out.Host = r.In.Host
+		},
+		ErrorHandler: p.upstreamError,
+	}
+	return p
+}
+
+// hostname normalizes the Host of a request
+func hostname(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// ProjectRef returns the project ref of a host name, if it's a project subdomain
+func (p *Proxy) ProjectRef(host string) (string, bool) {
+	ref, found := strings.CutSuffix(hostname(host), p.base)
+	if !found || ref == "" || strings.Contains(ref, ".") {
+		return "", false
+	}
+	return ref, true
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var route Route
+	var err error
+	if ref, ok := p.ProjectRef(r.Host); ok {
+		route, err = p.routes.Get(r.Context(), ref)
+		if errors.Is(err, ErrNotFound) {
+			writeError(w, http.StatusNotFound, "project_not_found", "No project is served at this address")
+			return
+		}
+	} else {
+		// Hosts that aren't custom hostnames are the API's
+		route, err = p.hosts.Get(r.Context(), hostname(r.Host))
+		if errors.Is(err, ErrNotFound) {
+			p.fallback.ServeHTTP(w, r)
+			return
+		}
+	}
+	if err != nil {
+		p.logger.Error(fmt.Sprintf("Failed to look up project of %s: %v", r.Host, err))
+		writeError(w, http.StatusBadGateway, "lookup_failed", "The project could not be reached, try again later")
+		return
+	}
+
+	switch {
+	case route.State == StatePaused:
+		writeError(w, http.StatusServiceUnavailable, "project_paused", "This project is paused. Its owner can restore it from the dashboard")
+		return
+	case route.State == StateUnavailable || route.Port == 0:
+		w.Header().Set("Retry-After", "30")
+		writeError(w, http.StatusServiceUnavailable, "project_unavailable", "This project is starting or being changed, try again shortly")
+		return
+	}
+
+	target := &url.URL{Scheme: "http", Host: net.JoinHostPort(p.upstreamHost, strconv.Itoa(int(route.Port)))}
+	p.proxy.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), targetKey{}, target)))
+}
+
+func (p *Proxy) upstreamError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	p.logger.Warn(fmt.Sprintf("Failed to proxy request for %s: %v", r.Host, err))
+	writeError(w, http.StatusBadGateway, "gateway_unreachable", "The project could not be reached, try again later")
+}
+
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": code, "message": message})
+}
@@ -0,0 +1,37 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9@._-]+`)
+
+// FileMailer writes every email as an .eml file into a directory, useful for tests
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer creates a mailer writing into dir, the directory is created if needed
+func NewFileMailer(dir string, from string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+func (f *FileMailer) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+	raw, err := render(f.from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000"), unsafeFileChars.ReplaceAllString(msg.To, "_"))
+	return os.WriteFile(filepath.Join(f.dir, name), raw, 0o640)
+}
@@ -0,0 +1,25 @@
+package mailer
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogMailer writes emails to the structured logger instead of sending them, links can be
+// copied from the logs during local development
+type LogMailer struct {
+	logger *slog.Logger
+}
+
+// NewLogMailer creates a mailer that only logs
+func NewLogMailer(logger *slog.Logger) *LogMailer {
+	return &LogMailer{logger: logger}
+}
+
+func (l *LogMailer) Send(ctx context.Context, msg Message) error {
+	l.logger.Info("Email not sent, the log mailer is configured",
+		"to", msg.To,
+		"subject", msg.Subject,
+		"body", msg.Text)
+	return nil
+}
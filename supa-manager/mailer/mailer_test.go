@@ -0,0 +1,88 @@
+package mailer
+
+import (
+	"mime"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		msg     Message
+		wantErr bool
+	}{
+		{"valid", "Supa Manager <noreply@supamanager.io>", Message{To: "user@example.com", Subject: "Hi", Text: "Hello"}, false},
+		{"invalid sender", "noreply", Message{To: "user@example.com", Text: "Hello"}, true},
+		{"invalid recipient", "noreply@supamanager.io", Message{To: "user", Text: "Hello"}, true},
+		{"recipient injecting a header", "noreply@supamanager.io", Message{To: "user@example.com\r\nBcc: other@example.com", Text: "Hello"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := render(tt.from, tt.msg, time.Now())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("render() error = %v, want error: %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderEncodesSubject(t *testing.T) {
+	raw, err := render("noreply@supamanager.io", Message{
+		To:      "user@example.com",
+		Subject: "Hello\r\nBcc: other@example.com",
+		Text:    "Hello",
+	}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bcc := parsed.Header.Get("Bcc"); bcc != "" {
+		t.Fatalf("subject injected a Bcc header: %q", bcc)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != "Hello\r\nBcc: other@example.com" {
+		t.Errorf("Subject = %q, %v", subject, err)
+	}
+}
+
+func TestTemplatesEscapeHTML(t *testing.T) {
+	tests := []struct {
+		name    string
+		message func(to string, link string) (Message, error)
+	}{
+		{"confirmation", ConfirmationMessage},
+		{"recovery", RecoveryMessage},
+		{"account exists", AccountExistsMessage},
+		{"email change", EmailChangeMessage},
+		{"invitation", func(to string, link string) (Message, error) {
+			return InvitationMessage(to, link, "<b>Acme</b>")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := tt.message(`"<script>"@example.com`, "javascript:alert(1)")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(msg.HTML, "<script>") || strings.Contains(msg.HTML, "<b>") {
+				t.Errorf("HTML body isn't escaped: %s", msg.HTML)
+			}
+			if strings.Contains(msg.HTML, "javascript:") {
+				t.Errorf("HTML body links to a script: %s", msg.HTML)
+			}
+			if !strings.Contains(msg.Text, "javascript:alert(1)") {
+				t.Errorf("text body doesn't carry the link as is: %s", msg.Text)
+			}
+			if msg.To != `"<script>"@example.com` || msg.Subject == "" {
+				t.Errorf("message addressed to %q with subject %q", msg.To, msg.Subject)
+			}
+		})
+	}
+}
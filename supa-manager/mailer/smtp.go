@@ -0,0 +1,125 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTP transport security modes
+const (
+	SMTPStartTLS = "starttls" // Plain connection upgraded with STARTTLS, which is required
+	SMTPTLS      = "tls"      // Implicit TLS, usually port 465
+	SMTPNone     = "none"     // No encryption, only for local fake SMTP servers
+)
+
+// SMTPSettings configures an SMTPMailer
+type SMTPSettings struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	Security string
+	From     string
+	Timeout  time.Duration
+}
+
+// SMTPMailer delivers emails through an SMTP relay
+type SMTPMailer struct {
+	settings SMTPSettings
+}
+
+// NewSMTPMailer creates a mailer for the given relay
+func NewSMTPMailer(settings SMTPSettings) (*SMTPMailer, error) {
+	if settings.Host == "" {
+		return nil, fmt.Errorf("smtp host is required")
+	}
+	switch settings.Security {
+	case SMTPStartTLS, SMTPTLS, SMTPNone:
+	default:
+		return nil, fmt.Errorf("unknown smtp security mode %q", settings.Security)
+	}
+	if _, err := mail.ParseAddress(settings.From); err != nil {
+		return nil, fmt.Errorf("invalid sender address: %w", err)
+	}
+	if settings.Timeout == 0 {
+		settings.Timeout = 30 * time.Second
+	}
+	return &SMTPMailer{settings: settings}, nil
+}
+
+func (s *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	raw, err := render(s.settings.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	from, _ := mail.ParseAddress(s.settings.From)
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.settings.Timeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(s.settings.Host, strconv.Itoa(s.settings.Port))
+	tlsConfig := &tls.Config{ServerName: s.settings.Host}
+	var conn net.Conn
+	if s.settings.Security == SMTPTLS {
+		dialer := &tls.Dialer{Config: tlsConfig}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		dialer := &net.Dialer{}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.settings.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if s.settings.Security == SMTPStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("smtp server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+	if s.settings.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.settings.Username, s.settings.Password, s.settings.Host)); err != nil {
+			return fmt.Errorf("smtp authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(raw); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
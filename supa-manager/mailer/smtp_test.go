@@ -0,0 +1,280 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTP is an SMTP server good enough for net/smtp, it records what it was sent
+type fakeSMTP struct {
+	listener   net.Listener
+	extensions []string
+	rejectRcpt bool
+
+	mu   sync.Mutex
+	auth string
+	from string
+	rcpt []string
+	data string
+}
+
+func newFakeSMTP(t *testing.T, extensions ...string) *fakeSMTP {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeSMTP{listener: listener, extensions: extensions}
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+	return server
+}
+
+func (f *fakeSMTP) port() int {
+	return f.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (f *fakeSMTP) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.session(conn)
+	}
+}
+
+func (f *fakeSMTP) session(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 localhost fake SMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		f.mu.Lock()
+		switch verb {
+		case "EHLO", "HELO":
+			lines := append([]string{"localhost"}, f.extensions...)
+			for i, ext := range lines {
+				if i == len(lines)-1 {
+					reply("250 " + ext)
+				} else {
+					reply("250-" + ext)
+				}
+			}
+		case "AUTH":
+			f.auth = strings.TrimPrefix(line, "AUTH PLAIN ")
+			reply("235 Authenticated")
+		case "MAIL":
+			f.from = line
+			reply("250 OK")
+		case "RCPT":
+			if f.rejectRcpt {
+				reply("550 No such user")
+				break
+			}
+			f.rcpt = append(f.rcpt, line)
+			reply("250 OK")
+		case "DATA":
+			reply("354 Go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					f.mu.Unlock()
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			f.data = data.String()
+			reply("250 Queued")
+		case "QUIT":
+			reply("221 Bye")
+			f.mu.Unlock()
+			return
+		default:
+			reply("502 Not implemented")
+		}
+		f.mu.Unlock()
+	}
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	server := newFakeSMTP(t, "8BITMIME", "AUTH PLAIN")
+	mailer, err := NewSMTPMailer(SMTPSettings{
+		Host:     "127.0.0.1",
+		Port:     server.port(),
+		Username: "user",
+		Password: "pass",
+		Security: SMTPNone,
+		From:     "Supa Manager <noreply@supamanager.io>",
+		Timeout:  5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := RecoveryMessage("user@example.com", "https://studio.example.com/reset?token=abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mailer.Send(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if want := base64.StdEncoding.EncodeToString([]byte("\x00user\x00pass")); server.auth != want {
+		t.Errorf("AUTH PLAIN %q, want %q", server.auth, want)
+	}
+	if server.from != "MAIL FROM:<noreply@supamanager.io> BODY=8BITMIME" {
+		t.Errorf("sender %q", server.from)
+	}
+	if len(server.rcpt) != 1 || server.rcpt[0] != "RCPT TO:<user@example.com>" {
+		t.Errorf("recipients %q", server.rcpt)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(server.data))
+	if err != nil {
+		t.Fatalf("invalid message: %v", err)
+	}
+	if got := parsed.Header.Get("Subject"); got != "Reset your password" {
+		t.Errorf("Subject = %q", got)
+	}
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q", parsed.Header.Get("Content-Type"))
+	}
+	parts := multipart.NewReader(parsed.Body, params["boundary"])
+	var types []string
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(part)
+		if !strings.Contains(string(body), "https://studio.example.com/reset?token=abc") {
+			t.Errorf("%s part doesn't carry the link", part.Header.Get("Content-Type"))
+		}
+		types = append(types, part.Header.Get("Content-Type"))
+	}
+	if strings.Join(types, ",") != "text/plain; charset=utf-8,text/html; charset=utf-8" {
+		t.Errorf("parts %q", types)
+	}
+}
+
+func TestSMTPMailerSendFailures(t *testing.T) {
+	msg := Message{To: "user@example.com", Subject: "Hello", Text: "Hello"}
+
+	tests := []struct {
+		name       string
+		extensions []string
+		security   string
+		rejectRcpt bool
+		wantErr    string
+	}{
+		// a relay that doesn't offer STARTTLS must not get the message, or credentials, in plain text
+		{"STARTTLS not offered", nil, SMTPStartTLS, false, "does not support STARTTLS"},
+		{"recipient rejected", nil, SMTPNone, true, "550"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeSMTP(t, tt.extensions...)
+			server.rejectRcpt = tt.rejectRcpt
+			mailer, err := NewSMTPMailer(SMTPSettings{
+				Host: "127.0.0.1", Port: server.port(), Security: tt.security,
+				From: "noreply@supamanager.io", Timeout: 5 * time.Second,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = mailer.Send(context.Background(), msg)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Send() error = %v, want one containing %q", err, tt.wantErr)
+			}
+			server.mu.Lock()
+			defer server.mu.Unlock()
+			if server.data != "" {
+				t.Error("message was sent anyway")
+			}
+		})
+	}
+}
+
+func TestSMTPMailerSendTimesOut(t *testing.T) {
+	// accepts connections but never greets
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	mailer, err := NewSMTPMailer(SMTPSettings{
+		Host: "127.0.0.1", Port: listener.Addr().(*net.TCPAddr).Port, Security: SMTPNone,
+		From: "noreply@supamanager.io", Timeout: 100 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if err := mailer.Send(context.Background(), Message{To: "user@example.com", Text: "Hello"}); err == nil {
+		t.Fatal("Send() succeeded against a server that never answered")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Send() took %s, the timeout is 100ms", elapsed)
+	}
+}
+
+func TestNewSMTPMailer(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings SMTPSettings
+		wantErr  bool
+	}{
+		{"valid", SMTPSettings{Host: "smtp.example.com", Security: SMTPStartTLS, From: "noreply@example.com"}, false},
+		{"implicit TLS", SMTPSettings{Host: "smtp.example.com", Security: SMTPTLS, From: "noreply@example.com"}, false},
+		{"missing host", SMTPSettings{Security: SMTPStartTLS, From: "noreply@example.com"}, true},
+		{"unknown security", SMTPSettings{Host: "smtp.example.com", Security: "ssl", From: "noreply@example.com"}, true},
+		{"empty security", SMTPSettings{Host: "smtp.example.com", From: "noreply@example.com"}, true},
+		{"invalid sender", SMTPSettings{Host: "smtp.example.com", Security: SMTPStartTLS, From: "not an address"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSMTPMailer(tt.settings); (err != nil) != tt.wantErr {
+				t.Errorf("NewSMTPMailer() error = %v, want error: %t", err, tt.wantErr)
+			}
+		})
+	}
+}
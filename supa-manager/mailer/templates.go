@@ -0,0 +1,89 @@
+package mailer
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"text/template"
+)
+
+type linkTemplate struct {
+	subject string
+	text    *template.Template
+	html    *htmltemplate.Template
+}
+
+type linkData struct {
+	Email        string
+	Link         string
+	Organization string
+}
+
+func newLinkTemplate(subject string, intro string, action string) linkTemplate {
+	return linkTemplate{
+		subject: subject,
+		text: template.Must(template.New("text").Parse(intro + "\n\n" + action + ":\n{{.Link}}\n\n" +
+			"If you did not request this email you can ignore it.\n")),
+		html: htmltemplate.Must(htmltemplate.New("html").Parse("<p>" + intro + "</p>\n" +
+			`<p><a href="{{.Link}}">` + action + "</a></p>\n" +
+			"<p>If you did not request this email you can ignore it.</p>\n")),
+	}
+}
+
+var (
+	confirmationTemplate = newLinkTemplate("Confirm your email address",
+		"Thanks for signing up to Supa Manager with {{.Email}}.",
+		"Confirm your email address")
+	recoveryTemplate = newLinkTemplate("Reset your password",
+		"Somebody asked to reset the password of the Supa Manager account {{.Email}}.",
+		"Choose a new password")
+	emailChangeTemplate = newLinkTemplate("Confirm your new email address",
+		"Somebody asked to change the email address of a Supa Manager account to {{.Email}}.",
+		"Confirm the change")
+	accountExistsTemplate = newLinkTemplate("You already have an account",
+		"Somebody tried to sign up to Supa Manager with {{.Email}}, which already has an account. If that was you, sign in or reset your password.",
+		"Reset your password")
+	invitationTemplate = newLinkTemplate("You have been invited to join an organization",
+		"You have been invited to join the {{.Organization}} organization on Supa Manager as {{.Email}}.",
+		"Accept the invitation")
+)
+
+func (t linkTemplate) message(to string, link string) (Message, error) {
+	return t.render(linkData{Email: to, Link: link})
+}
+
+func (t linkTemplate) render(data linkData) (Message, error) {
+	var text, html bytes.Buffer
+	if err := t.text.Execute(&text, data); err != nil {
+		return Message{}, err
+	}
+	if err := t.html.Execute(&html, data); err != nil {
+		return Message{}, err
+	}
+	return Message{To: data.Email, Subject: t.subject, Text: text.String(), HTML: html.String()}, nil
+}
+
+// ConfirmationMessage asks a new account to confirm its email address
+func ConfirmationMessage(to string, link string) (Message, error) {
+	return confirmationTemplate.message(to, link)
+}
+
+// RecoveryMessage carries a password reset link
+func RecoveryMessage(to string, link string) (Message, error) {
+	return recoveryTemplate.message(to, link)
+}
+
+// AccountExistsMessage answers a signup for an address that already has an account, with a
+// password reset link
+func AccountExistsMessage(to string, link string) (Message, error) {
+	return accountExistsTemplate.message(to, link)
+}
+
+// EmailChangeMessage is sent to the new address of an email change
+func EmailChangeMessage(to string, link string) (Message, error) {
+	return emailChangeTemplate.message(to, link)
+}
+
+// InvitationMessage invites an email address to join an organization
+func InvitationMessage(to string, link string, organization string) (Message, error) {
+	return invitationTemplate.render(linkData{Email: to, Link: link, Organization: organization})
+}
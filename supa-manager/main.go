@@ -17,9 +17,22 @@ func main() {
 	}
 	apiInstance, err := api.CreateApi(logger, config)
 	if err != nil {
-		logger.Error("Failed to start API state. ", err.Error())
+		logger.Error("Failed to start API state.", "error", err.Error())
 		return
 	}
 
-	apiInstance.Router().Run(apiInstance.ListenAddress())
+	router, err := apiInstance.Router()
+	if err != nil {
+		logger.Error("Failed to set up routes.", "error", err.Error())
+		return
+	}
+	if config.Edge.Enabled {
+		go func() {
+			if err := apiInstance.RunEdge(router); err != nil {
+				logger.Error("Edge proxy stopped.", "error", err.Error())
+			}
+		}()
+	}
+
+	router.Run(apiInstance.ListenAddress())
 }
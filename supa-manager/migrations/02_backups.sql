@@ -0,0 +1,30 @@
+-- Backup catalog: one row per backup artifact, including integrity metadata
+CREATE TABLE IF NOT EXISTS public.backups
+(
+    id                  text        not null,
+    project_ref         text        not null,
+
+    backup_type         text        not null, -- FULL, DATABASE, STORAGE, INCREMENTAL
+    status              text        not null, -- CREATING, COMPLETED, FAILED
+
+    file_path           text        not null,
+    s3_key              text,
+    size_bytes          bigint      not null default 0,
+    compressed          boolean     not null default false,
+    encrypted           boolean     not null default false,
+
+    checksum_sha256     text,
+
+    verification_status text        not null default 'UNVERIFIED', -- UNVERIFIED, VERIFYING, VERIFIED, CORRUPT
+    verification_error  text,
+    verified_at         timestamptz,
+
+    created_at          timestamptz not null default now(),
+    completed_at        timestamptz,
+    expires_at          timestamptz,
+
+    primary key (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_backups_project_ref ON public.backups (project_ref);
+CREATE INDEX IF NOT EXISTS idx_backups_verification ON public.backups (verification_status, verified_at);
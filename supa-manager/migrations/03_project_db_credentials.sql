@@ -0,0 +1,4 @@
+-- Credentials used by supa-manager to reach a project's Postgres (pg-meta, migrations)
+-- The password is encrypted with ENCRYPTION_SECRET, see utils.Encrypt
+ALTER TABLE project ADD COLUMN IF NOT EXISTS db_user TEXT NOT NULL DEFAULT 'postgres';
+ALTER TABLE project ADD COLUMN IF NOT EXISTS db_password_encrypted TEXT;
@@ -0,0 +1,58 @@
+-- Studio content (SQL snippets, reports, log queries) saved per project and owner
+CREATE TABLE IF NOT EXISTS public.user_content
+(
+    id              text        not null default gen_random_uuid()::text,
+
+    project_id      int         not null,
+    owner_id        int         not null,
+    last_updated_by int,
+
+    type            text        not null, -- sql, report, log_sql
+    visibility      text        not null default 'user', -- user, project, org, public
+
+    name            text        not null,
+    description     text,
+    content         jsonb       not null default '{}',
+    favorite        boolean     not null default false,
+    folder_id       text,
+
+    inserted_at     timestamptz not null default now(),
+    updated_at      timestamptz not null default now(),
+
+    primary key (id)
+);
+
+ALTER TABLE public.user_content
+    ADD CONSTRAINT fk_user_content_project FOREIGN KEY (project_id) REFERENCES project (id) ON DELETE CASCADE;
+
+ALTER TABLE public.user_content
+    ADD CONSTRAINT fk_user_content_owner FOREIGN KEY (owner_id) REFERENCES accounts (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_user_content_project ON public.user_content (project_id, type);
+
+-- Queries executed through the SQL editor, per user and project
+CREATE TABLE IF NOT EXISTS public.query_history
+(
+    id            bigserial   not null,
+
+    project_id    int         not null,
+    account_id    int         not null,
+
+    query         text        not null,
+    success       boolean     not null,
+    error_message text,
+    row_count     int         not null default 0,
+    duration_ms   int         not null default 0,
+
+    executed_at   timestamptz not null default now(),
+
+    primary key (id)
+);
+
+ALTER TABLE public.query_history
+    ADD CONSTRAINT fk_query_history_project FOREIGN KEY (project_id) REFERENCES project (id) ON DELETE CASCADE;
+
+ALTER TABLE public.query_history
+    ADD CONSTRAINT fk_query_history_account FOREIGN KEY (account_id) REFERENCES accounts (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_query_history_account ON public.query_history (project_id, account_id, executed_at DESC);
@@ -0,0 +1,29 @@
+-- Refresh tokens of platform logins. Every rotation inserts a new row in the same family,
+-- the family id is the session id carried by access tokens
+CREATE TABLE IF NOT EXISTS public.sessions
+(
+    id             text        not null default gen_random_uuid()::text,
+    family_id      text        not null,
+    parent_id      text,
+
+    account_id     int         not null,
+    token_hash     text        not null,
+
+    user_agent     text,
+    ip             text,
+
+    created_at     timestamptz not null default now(),
+    expires_at     timestamptz not null,
+    rotated_at     timestamptz,
+    revoked_at     timestamptz,
+    revoked_reason text,
+
+    primary key (id)
+);
+
+ALTER TABLE public.sessions
+    ADD CONSTRAINT fk_sessions_account FOREIGN KEY (account_id) REFERENCES accounts (id) ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sessions_token_hash ON public.sessions (token_hash);
+CREATE INDEX IF NOT EXISTS idx_sessions_family ON public.sessions (family_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_account ON public.sessions (account_id);
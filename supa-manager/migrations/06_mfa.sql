@@ -0,0 +1,59 @@
+-- Authenticator assurance level and authentication methods of a session, copied on every rotation
+ALTER TABLE public.sessions ADD COLUMN IF NOT EXISTS aal TEXT NOT NULL DEFAULT 'aal1';
+ALTER TABLE public.sessions ADD COLUMN IF NOT EXISTS amr TEXT[] NOT NULL DEFAULT '{password}';
+
+-- TOTP factors, secrets are encrypted with the encryption secret
+CREATE TABLE IF NOT EXISTS public.mfa_factors
+(
+    id               text        not null default gen_random_uuid()::text,
+    account_id       int         not null,
+
+    friendly_name    text,
+    factor_type      text        not null default 'totp',
+    status           text        not null default 'unverified', -- unverified, verified
+    secret_encrypted text        not null,
+    last_used_step   bigint,
+
+    created_at       timestamptz not null default now(),
+    updated_at       timestamptz not null default now(),
+
+    primary key (id)
+);
+
+ALTER TABLE public.mfa_factors
+    ADD CONSTRAINT fk_mfa_factors_account FOREIGN KEY (account_id) REFERENCES accounts (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_mfa_factors_account ON public.mfa_factors (account_id);
+
+CREATE TABLE IF NOT EXISTS public.mfa_challenges
+(
+    id          text        not null default gen_random_uuid()::text,
+    factor_id   text        not null,
+
+    ip          text,
+    created_at  timestamptz not null default now(),
+    verified_at timestamptz,
+
+    primary key (id)
+);
+
+ALTER TABLE public.mfa_challenges
+    ADD CONSTRAINT fk_mfa_challenges_factor FOREIGN KEY (factor_id) REFERENCES mfa_factors (id) ON DELETE CASCADE;
+
+-- Single-use recovery codes, stored hashed
+CREATE TABLE IF NOT EXISTS public.mfa_recovery_codes
+(
+    id         bigserial   not null,
+    account_id int         not null,
+
+    code_hash  text        not null,
+    used_at    timestamptz,
+    created_at timestamptz not null default now(),
+
+    primary key (id)
+);
+
+ALTER TABLE public.mfa_recovery_codes
+    ADD CONSTRAINT fk_mfa_recovery_codes_account FOREIGN KEY (account_id) REFERENCES accounts (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_account ON public.mfa_recovery_codes (account_id, code_hash);
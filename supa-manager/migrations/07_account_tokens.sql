@@ -0,0 +1,26 @@
+-- Accounts must confirm their email address, accounts created before verification existed are trusted
+ALTER TABLE public.accounts ADD COLUMN IF NOT EXISTS email_confirmed_at TIMESTAMPTZ;
+UPDATE public.accounts SET email_confirmed_at = created_at WHERE email_confirmed_at IS NULL;
+
+-- Single-use links mailed for signup confirmation, password recovery and email changes, stored hashed
+CREATE TABLE IF NOT EXISTS public.account_tokens
+(
+    id         bigserial   not null,
+    account_id int         not null,
+
+    purpose    text        not null, -- signup, recovery, email_change
+    token_hash text        not null,
+    email      text        not null, -- address the link was sent to, the new address for email_change
+
+    created_at timestamptz not null default now(),
+    expires_at timestamptz not null,
+    used_at    timestamptz,
+
+    primary key (id)
+);
+
+ALTER TABLE public.account_tokens
+    ADD CONSTRAINT fk_account_tokens_account FOREIGN KEY (account_id) REFERENCES accounts (id) ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_account_tokens_token_hash ON public.account_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_account_tokens_account ON public.account_tokens (account_id, purpose);
@@ -0,0 +1,34 @@
+-- In-flight OIDC logins, keyed by the hashed state parameter and consumed by the callback
+CREATE TABLE IF NOT EXISTS public.sso_states
+(
+    state_hash    text        not null,
+
+    code_verifier text        not null,
+    nonce         text        not null,
+    redirect_to   text        not null,
+
+    created_at    timestamptz not null default now(),
+
+    primary key (state_hash)
+);
+
+-- Identity provider subjects linked to accounts
+CREATE TABLE IF NOT EXISTS public.account_identities
+(
+    provider        text        not null, -- issuer URL
+    subject         text        not null,
+    account_id      int         not null,
+
+    email           text,
+    claims          jsonb       not null default '{}',
+
+    created_at      timestamptz not null default now(),
+    last_sign_in_at timestamptz not null default now(),
+
+    primary key (provider, subject)
+);
+
+ALTER TABLE public.account_identities
+    ADD CONSTRAINT fk_account_identities_account FOREIGN KEY (account_id) REFERENCES accounts (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_account_identities_account ON public.account_identities (account_id);
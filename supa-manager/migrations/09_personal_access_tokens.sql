@@ -0,0 +1,33 @@
+-- Long-lived tokens for the API and CLI, stored hashed. A token is limited to one organization or
+-- one project when either is set, and to safe methods when read_only is set
+CREATE TABLE IF NOT EXISTS public.personal_access_tokens
+(
+    id              bigserial   not null,
+    account_id      int         not null,
+
+    name            text        not null,
+    token_hash      text        not null,
+    token_alias     text        not null, -- prefix and last characters, shown in lists
+
+    read_only       boolean     not null default false,
+    organization_id int,
+    project_ref     text,
+    aal             text        not null default 'aal1', -- level of the session that created the token
+
+    created_at      timestamptz not null default now(),
+    expires_at      timestamptz,
+    last_used_at    timestamptz,
+    last_used_ip    text,
+    revoked_at      timestamptz,
+
+    primary key (id)
+);
+
+ALTER TABLE public.personal_access_tokens
+    ADD CONSTRAINT fk_personal_access_tokens_account FOREIGN KEY (account_id) REFERENCES accounts (id) ON DELETE CASCADE;
+
+ALTER TABLE public.personal_access_tokens
+    ADD CONSTRAINT fk_personal_access_tokens_org FOREIGN KEY (organization_id) REFERENCES organizations (id) ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_personal_access_tokens_token_hash ON public.personal_access_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_personal_access_tokens_account ON public.personal_access_tokens (account_id);
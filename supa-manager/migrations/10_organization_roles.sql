@@ -0,0 +1,6 @@
+-- Members have one of four roles, like Supabase organizations. Only OWNER was written before
+UPDATE public.organization_membership SET role = upper(role);
+UPDATE public.organization_membership SET role = 'READ_ONLY' WHERE role NOT IN ('OWNER', 'ADMIN', 'DEVELOPER', 'READ_ONLY');
+
+ALTER TABLE public.organization_membership
+    ADD CONSTRAINT chk_membership_role CHECK (role IN ('OWNER', 'ADMIN', 'DEVELOPER', 'READ_ONLY'));
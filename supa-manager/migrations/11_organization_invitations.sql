@@ -0,0 +1,33 @@
+-- Pending invitations to join an organization, the emailed link carries a token stored hashed
+CREATE TABLE IF NOT EXISTS public.organization_invitations
+(
+    id              bigserial   not null,
+    organization_id int         not null,
+
+    invited_email   text        not null,
+    role            text        not null,
+    token_hash      text        not null,
+    invited_by      int,
+
+    created_at      timestamptz not null default now(),
+    expires_at      timestamptz not null,
+    accepted_at     timestamptz,
+    accepted_by     int,
+
+    primary key (id)
+);
+
+ALTER TABLE public.organization_invitations
+    ADD CONSTRAINT fk_organization_invitations_org FOREIGN KEY (organization_id) REFERENCES organizations (id) ON DELETE CASCADE;
+
+ALTER TABLE public.organization_invitations
+    ADD CONSTRAINT fk_organization_invitations_invited_by FOREIGN KEY (invited_by) REFERENCES accounts (id) ON DELETE SET NULL;
+
+ALTER TABLE public.organization_invitations
+    ADD CONSTRAINT fk_organization_invitations_accepted_by FOREIGN KEY (accepted_by) REFERENCES accounts (id) ON DELETE SET NULL;
+
+ALTER TABLE public.organization_invitations
+    ADD CONSTRAINT chk_organization_invitations_role CHECK (role IN ('OWNER', 'ADMIN', 'DEVELOPER', 'READ_ONLY'));
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organization_invitations_token_hash ON public.organization_invitations (token_hash);
+CREATE INDEX IF NOT EXISTS idx_organization_invitations_org ON public.organization_invitations (organization_id);
@@ -0,0 +1,19 @@
+-- What Studio asks for when creating an organization, and what owners can change later
+ALTER TABLE public.organizations ADD COLUMN IF NOT EXISTS kind TEXT;
+ALTER TABLE public.organizations ADD COLUMN IF NOT EXISTS size TEXT;
+ALTER TABLE public.organizations ADD COLUMN IF NOT EXISTS plan TEXT NOT NULL DEFAULT 'free';
+ALTER TABLE public.organizations ADD COLUMN IF NOT EXISTS billing_email TEXT;
+ALTER TABLE public.organizations ADD COLUMN IF NOT EXISTS opt_in_tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- Organizations created before plans existed were served as enterprise, keep them unlimited
+UPDATE public.organizations SET plan = 'enterprise';
+
+ALTER TABLE public.organizations
+    ADD CONSTRAINT chk_organizations_plan CHECK (plan IN ('free', 'pro', 'team', 'enterprise'));
+
+-- Memberships go with their organization, projects must be deprovisioned first
+ALTER TABLE public.organization_membership DROP CONSTRAINT IF EXISTS fk_membership_org;
+ALTER TABLE public.organization_membership
+    ADD CONSTRAINT fk_membership_org FOREIGN KEY (organization_id) REFERENCES organizations (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_project_organization ON public.project (organization_id);
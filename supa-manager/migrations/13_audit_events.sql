@@ -0,0 +1,42 @@
+-- Append-only record of mutating requests and provisioner operations. There are no foreign keys
+-- on purpose, events outlive the accounts, organizations and projects they mention
+CREATE TABLE IF NOT EXISTS public.audit_events
+(
+    id              bigserial   not null,
+    occurred_at     timestamptz not null default now(),
+
+    actor_id        int,    -- null for unauthenticated requests and background operations
+    actor_email     text,
+    token_id        bigint, -- personal access token the request was made with
+    organization_id int,
+    project_ref     text,
+
+    action          text        not null, -- route pattern like "DELETE /platform/projects/:ref", or provisioner.<operation>
+    target          text        not null, -- request path or project ref
+    status          int,                  -- response status, null for provisioner operations
+    error           text,
+    ip              text,
+    user_agent      text,
+    request_id      text,
+    payload         jsonb,                -- request body and query with secrets redacted
+
+    primary key (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_org ON public.audit_events (organization_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_project ON public.audit_events (project_ref, id);
+
+CREATE OR REPLACE FUNCTION public.audit_events_append_only() RETURNS trigger AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_audit_events_append_only
+    BEFORE UPDATE OR DELETE ON public.audit_events
+    FOR EACH ROW EXECUTE FUNCTION public.audit_events_append_only();
+
+CREATE TRIGGER trg_audit_events_no_truncate
+    BEFORE TRUNCATE ON public.audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION public.audit_events_append_only();
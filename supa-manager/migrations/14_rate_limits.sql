@@ -0,0 +1,15 @@
+-- Limiter state shared by the supa-manager replicas. Losing it on a crash only lifts limits early,
+-- so the table skips the write-ahead log
+CREATE UNLOGGED TABLE IF NOT EXISTS public.rate_limits
+(
+    key          text        not null, -- like "login:ip:203.0.113.7" or "login:account:jane@example.com"
+    hits         int         not null default 0,
+    window_start timestamptz not null default now(),
+    failures     int         not null default 0,
+    locked_until timestamptz,
+    updated_at   timestamptz not null default now(),
+
+    primary key (key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limits_updated_at ON public.rate_limits (updated_at);
@@ -0,0 +1,30 @@
+-- Progress of project JWT secret rotations, read by the jwt-secret-update-status endpoints
+CREATE TABLE IF NOT EXISTS public.jwt_secret_rotations
+(
+    id                 bigserial   not null,
+    project_id         int         not null,
+    change_tracking_id text        not null,
+    status             text        not null default 'pending',
+    step               text        not null default 'pending', -- like generating_keys or recreating_auth
+    progress           int         not null default 0,         -- percent
+    error              text,
+    rolled_back        bool        not null default false,
+    requested_by       int,
+    created_at         timestamptz not null default now(),
+    updated_at         timestamptz not null default now(),
+    completed_at       timestamptz,
+
+    primary key (id)
+);
+
+ALTER TABLE public.jwt_secret_rotations
+    ADD CONSTRAINT fk_jwt_secret_rotations_project FOREIGN KEY (project_id) REFERENCES project (id) ON DELETE CASCADE;
+ALTER TABLE public.jwt_secret_rotations
+    ADD CONSTRAINT fk_jwt_secret_rotations_account FOREIGN KEY (requested_by) REFERENCES accounts (id) ON DELETE SET NULL;
+ALTER TABLE public.jwt_secret_rotations
+    ADD CONSTRAINT chk_jwt_secret_rotations_status CHECK (status IN ('pending', 'in_progress', 'completed', 'failed'));
+
+-- A project rotates one secret at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jwt_secret_rotations_active ON public.jwt_secret_rotations (project_id)
+    WHERE status IN ('pending', 'in_progress');
+CREATE INDEX IF NOT EXISTS idx_jwt_secret_rotations_project ON public.jwt_secret_rotations (project_id, id);
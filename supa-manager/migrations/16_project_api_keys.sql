@@ -0,0 +1,27 @@
+-- Named API keys of a project besides anon and service_role. Keys are JWTs signed with the
+-- project secret, stored encrypted because Kong's key-auth needs them in plain text
+CREATE TABLE IF NOT EXISTS public.project_api_keys
+(
+    id            bigserial   not null,
+    project_id    int         not null,
+
+    name          text        not null,
+    role          text        not null, -- database role the key's requests run as
+    key_encrypted text        not null,
+    key_alias     text        not null, -- last characters, shown in lists
+
+    created_by    int,
+    created_at    timestamptz not null default now(),
+    expires_at    timestamptz,
+    revoked_at    timestamptz,
+
+    primary key (id)
+);
+
+ALTER TABLE public.project_api_keys
+    ADD CONSTRAINT fk_project_api_keys_project FOREIGN KEY (project_id) REFERENCES project (id) ON DELETE CASCADE;
+ALTER TABLE public.project_api_keys
+    ADD CONSTRAINT fk_project_api_keys_account FOREIGN KEY (created_by) REFERENCES accounts (id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_project_api_keys_name ON public.project_api_keys (project_id, name)
+    WHERE revoked_at IS NULL;
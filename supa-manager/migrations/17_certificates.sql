@@ -0,0 +1,47 @@
+-- ACME state shared by the supa-manager replicas: the account, the certificates they serve and the
+-- HTTP-01 tokens the CA may ask any of them for. Private keys are encrypted with the encryption secret
+CREATE TABLE IF NOT EXISTS public.acme_accounts
+(
+    directory_url text        not null,
+    key_encrypted text        not null,
+    account_url   text,
+    created_at    timestamptz not null default now(),
+
+    primary key (directory_url)
+);
+
+CREATE TABLE IF NOT EXISTS public.certificates
+(
+    id            bigserial   not null,
+    name          text        not null, -- like "*.supamanager.io" or a custom hostname
+    domains       text[]      not null,
+    challenge     text        not null,
+    status        text        not null default 'pending',
+    cert_pem      text,                 -- leaf first, then the chain
+    key_encrypted text,
+    not_before    timestamptz,
+    not_after     timestamptz,
+    error         text,
+    locked_until  timestamptz,          -- a replica is issuing it until then
+    created_at    timestamptz not null default now(),
+    updated_at    timestamptz not null default now(),
+
+    primary key (id)
+);
+
+ALTER TABLE public.certificates
+    ADD CONSTRAINT chk_certificates_challenge CHECK (challenge IN ('dns-01', 'http-01'));
+ALTER TABLE public.certificates
+    ADD CONSTRAINT chk_certificates_status CHECK (status IN ('pending', 'issued', 'failed'));
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_certificates_name ON public.certificates (name);
+CREATE INDEX IF NOT EXISTS idx_certificates_not_after ON public.certificates (not_after);
+
+CREATE UNLOGGED TABLE IF NOT EXISTS public.acme_challenges
+(
+    token             text        not null,
+    key_authorization text        not null,
+    expires_at        timestamptz not null,
+
+    primary key (token)
+);
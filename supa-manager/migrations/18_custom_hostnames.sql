@@ -0,0 +1,27 @@
+-- Hostnames customers point at a project. Ownership is proven with a TXT record holding the
+-- verification token, routing with a CNAME to the project's own hostname
+CREATE TABLE IF NOT EXISTS public.custom_hostnames
+(
+    id                 bigserial   not null,
+    project_id         int         not null,
+    hostname           text        not null,
+    status             text        not null default 'pending_verification',
+    verification_token text        not null,
+    error              text,       -- why the last check didn't move it on
+    last_checked_at    timestamptz,
+    verified_at        timestamptz,
+    activated_at       timestamptz,
+    created_at         timestamptz not null default now(),
+    updated_at         timestamptz not null default now(),
+
+    primary key (id)
+);
+
+ALTER TABLE public.custom_hostnames
+    ADD CONSTRAINT fk_custom_hostnames_project FOREIGN KEY (project_id) REFERENCES project (id) ON DELETE CASCADE;
+ALTER TABLE public.custom_hostnames
+    ADD CONSTRAINT chk_custom_hostnames_status CHECK (status IN ('pending_verification', 'verified', 'active'));
+
+-- A project has one custom hostname, a hostname belongs to one project
+CREATE UNIQUE INDEX IF NOT EXISTS idx_custom_hostnames_project ON public.custom_hostnames (project_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_custom_hostnames_hostname ON public.custom_hostnames (hostname);
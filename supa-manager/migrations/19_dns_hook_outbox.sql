@@ -0,0 +1,23 @@
+-- DNS hook updates waiting to be delivered. They're written with the change they describe and
+-- retried with backoff until the DNS service accepts them, in order for each hostname
+CREATE TABLE IF NOT EXISTS public.dns_hook_outbox
+(
+    id              bigserial   not null,
+    type            text        not null,
+    hostname        text        not null,
+    project_ref     text,
+    record_type     text,
+    value           text,
+    attempts        int         not null default 0,
+    next_attempt_at timestamptz not null default now(),
+    locked_until    timestamptz,          -- a replica is delivering it until then
+    last_error      text,
+    created_at      timestamptz not null default now(),
+
+    primary key (id)
+);
+
+ALTER TABLE public.dns_hook_outbox
+    ADD CONSTRAINT chk_dns_hook_outbox_type CHECK (type IN ('CREATE', 'DELETE'));
+
+CREATE INDEX IF NOT EXISTS idx_dns_hook_outbox_hostname ON public.dns_hook_outbox (hostname, id);
@@ -0,0 +1,3 @@
+-- When the running verification of a backup started, a VERIFYING backup whose verification started
+-- long ago belongs to a replica that died and is verified again
+ALTER TABLE public.backups ADD COLUMN IF NOT EXISTS verification_started_at TIMESTAMPTZ;
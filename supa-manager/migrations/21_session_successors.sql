@@ -0,0 +1,4 @@
+-- The refresh token a session was rotated to, encrypted with the encryption secret. A client that
+-- presents the old token again within the reuse interval, like a retry that lost the response, gets
+-- this one back instead of having its session revoked
+ALTER TABLE public.sessions ADD COLUMN IF NOT EXISTS successor_token_encrypted TEXT;
@@ -0,0 +1,5 @@
+-- A hostname only belongs to a project once its owner proved control of it. Any number of projects
+-- can have it pending, so one that never verifies can't keep the actual owner from claiming it
+DROP INDEX IF EXISTS public.idx_custom_hostnames_hostname;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_custom_hostnames_claimed_hostname ON public.custom_hostnames (hostname)
+    WHERE status IN ('verified', 'active');
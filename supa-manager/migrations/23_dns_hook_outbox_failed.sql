@@ -0,0 +1,3 @@
+-- When an update was given up on, after the hook rejected it or it ran out of attempts. Failed
+-- updates are kept for inspection but no longer hold back the later updates of their hostname
+ALTER TABLE public.dns_hook_outbox ADD COLUMN IF NOT EXISTS failed_at TIMESTAMPTZ;
@@ -0,0 +1,89 @@
+package permisions
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// Permission is one entry of the permission list served to Studio
+type Permission struct {
+	OrganizationID int32       `json:"organization_id"`
+	Resources      []string    `json:"resources"`
+	Actions        []string    `json:"actions"`
+	Condition      interface{} `json:"condition"`
+	Restrictive    bool        `json:"restrictive"`
+	ProjectIDs     []int32     `json:"project_ids"`
+}
+
+// Can evaluates permissions the way Studio's doPermissionsCheck does: permissions of the
+// organization (and project, when projectID is not zero) whose action and resource patterns
+// match are considered, a matching restrictive permission denies, and otherwise any matching
+// permission without a condition, or whose condition holds for data, allows.
+func Can(permissions []Permission, orgID int32, projectID int32, action string, resource string, data map[string]interface{}) bool {
+	input := normalize(data)
+	input["resource_name"] = resource
+
+	allowed := false
+	for _, p := range permissions {
+		if p.OrganizationID != orgID {
+			continue
+		}
+		if projectID != 0 && len(p.ProjectIDs) > 0 && !containsID(p.ProjectIDs, projectID) {
+			continue
+		}
+		if !matchesAny(p.Actions, action) || !matchesAny(p.Resources, resource) {
+			continue
+		}
+
+		holds := p.Condition == nil || Truthy(Apply(p.Condition, input))
+		if p.Restrictive && holds {
+			return false
+		}
+		if !p.Restrictive && holds {
+			allowed = true
+		}
+	}
+	return allowed
+}
+
+// normalize round-trips data through JSON so numbers and structs look like they do to json-logic-js
+func normalize(data map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	if data == nil {
+		return out
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return out
+	}
+	_ = json.Unmarshal(encoded, &out)
+	return out
+}
+
+func matchesAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if patternRegexp(pattern).MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// patternRegexp turns a permission pattern into a regexp, % matches anything
+func patternRegexp(pattern string) *regexp.Regexp {
+	parts := strings.Split(pattern, "%")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+}
+
+func containsID(ids []int32, id int32) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+package permisions
+
+import "testing"
+
+func TestCanByRole(t *testing.T) {
+	const orgID, projectID = 1, 10
+	subject := map[string]interface{}{"id": 7, "gotrue_id": "abc"}
+
+	tests := []struct {
+		name     string
+		action   string
+		resource string
+		data     map[string]interface{}
+		allowed  map[string]bool
+	}{
+		{
+			name: "read the organization", action: "read:Read", resource: "organizations",
+			allowed: map[string]bool{RoleOwner: true, RoleAdmin: true, RoleDeveloper: true, RoleReadOnly: true},
+		},
+		{
+			name: "update the organization", action: "write:Update", resource: "organizations",
+			allowed: map[string]bool{RoleOwner: true, RoleAdmin: true},
+		},
+		{
+			name: "delete the organization", action: "write:Delete", resource: "organizations",
+			allowed: map[string]bool{RoleOwner: true},
+		},
+		{
+			name: "billing", action: "billing:Write", resource: "stripe.subscriptions",
+			allowed: map[string]bool{RoleOwner: true},
+		},
+		{
+			name: "run SQL", action: "tenant:Sql:Query", resource: "%",
+			allowed: map[string]bool{RoleOwner: true, RoleAdmin: true, RoleDeveloper: true},
+		},
+		{
+			name: "read the service role key", action: "read:Read", resource: "service_api_keys.service_role_key",
+			allowed: map[string]bool{RoleOwner: true, RoleAdmin: true, RoleDeveloper: true},
+		},
+		{
+			name: "pause a project", action: "infra:Execute", resource: "queue_jobs.projects.pause",
+			allowed: map[string]bool{RoleOwner: true, RoleAdmin: true},
+		},
+		{
+			name: "run another infra job", action: "infra:Execute", resource: "queue_jobs.projects.restart",
+			allowed: map[string]bool{RoleOwner: true, RoleAdmin: true, RoleDeveloper: true},
+		},
+		{
+			name: "update a project", action: "write:Update", resource: "projects",
+			data:    map[string]interface{}{"resource": map[string]interface{}{"project_id": projectID}},
+			allowed: map[string]bool{RoleOwner: true, RoleAdmin: true},
+		},
+		{
+			name: "update a project without its id", action: "write:Update", resource: "projects",
+			allowed: map[string]bool{},
+		},
+		{
+			name: "invite an administrator", action: "write:Create", resource: "user_invites",
+			data:    map[string]interface{}{"resource": map[string]interface{}{"role_id": RoleID(RoleAdmin)}},
+			allowed: map[string]bool{RoleOwner: true, RoleAdmin: true},
+		},
+		{
+			name: "invite an owner", action: "write:Create", resource: "user_invites",
+			data:    map[string]interface{}{"resource": map[string]interface{}{"role_id": RoleID(RoleOwner)}},
+			allowed: map[string]bool{RoleOwner: true},
+		},
+		{
+			name: "leave the organization", action: "write:Delete", resource: "auth.subject_roles",
+			data:    map[string]interface{}{"subject": subject, "resource": map[string]interface{}{"subject_id": "abc"}},
+			allowed: map[string]bool{RoleOwner: true, RoleAdmin: true, RoleDeveloper: true, RoleReadOnly: true},
+		},
+		{
+			name: "remove another member", action: "write:Delete", resource: "auth.subject_roles",
+			data:    map[string]interface{}{"subject": subject, "resource": map[string]interface{}{"subject_id": "xyz", "role_id": RoleID(RoleDeveloper)}},
+			allowed: map[string]bool{RoleOwner: true, RoleAdmin: true},
+		},
+		{
+			name: "update an own snippet", action: "write:Update", resource: "user_content",
+			data:    map[string]interface{}{"subject": subject, "resource": map[string]interface{}{"owner_id": 7, "type": "sql", "visibility": "user"}},
+			allowed: map[string]bool{RoleOwner: true, RoleAdmin: true, RoleDeveloper: true, RoleReadOnly: true},
+		},
+		{
+			name: "update an own report", action: "write:Update", resource: "user_content",
+			data:    map[string]interface{}{"subject": subject, "resource": map[string]interface{}{"owner_id": 7, "type": "report", "visibility": "user"}},
+			allowed: map[string]bool{RoleOwner: true, RoleAdmin: true, RoleDeveloper: true},
+		},
+		{
+			name: "update someone's private snippet", action: "write:Update", resource: "user_content",
+			data:    map[string]interface{}{"subject": subject, "resource": map[string]interface{}{"owner_id": 8, "type": "sql", "visibility": "user"}},
+			allowed: map[string]bool{},
+		},
+		{
+			name: "update a shared snippet", action: "write:Update", resource: "user_content",
+			data:    map[string]interface{}{"subject": subject, "resource": map[string]interface{}{"owner_id": 8, "type": "sql", "visibility": "project"}},
+			allowed: map[string]bool{RoleOwner: true, RoleAdmin: true, RoleDeveloper: true},
+		},
+	}
+	for _, tt := range tests {
+		for _, role := range append(Roles, "UNKNOWN") {
+			t.Run(tt.name+"/"+role, func(t *testing.T) {
+				got := Can(ForRole(orgID, role, nil), orgID, projectID, tt.action, tt.resource, tt.data)
+				if got != tt.allowed[role] {
+					t.Errorf("Can(%s, %s) = %t, want %t", tt.action, tt.resource, got, tt.allowed[role])
+				}
+			})
+		}
+	}
+}
+
+func TestCanIsScopedToOrganizationAndProjects(t *testing.T) {
+	tests := []struct {
+		name        string
+		memberships []Membership
+		orgID       int32
+		projectID   int32
+		want        bool
+	}{
+		{"own organization", []Membership{{OrganizationID: 1, Role: RoleOwner}}, 1, 10, true},
+		{"other organization", []Membership{{OrganizationID: 1, Role: RoleOwner}}, 2, 10, false},
+		{"listed project", []Membership{{OrganizationID: 1, Role: RoleOwner, ProjectIDs: []int32{10}}}, 1, 10, true},
+		{"unlisted project", []Membership{{OrganizationID: 1, Role: RoleOwner, ProjectIDs: []int32{11}}}, 1, 10, false},
+		{"organization level check with project scope", []Membership{{OrganizationID: 1, Role: RoleOwner, ProjectIDs: []int32{11}}}, 1, 0, true},
+		{"role in another organization", []Membership{{OrganizationID: 1, Role: RoleReadOnly}, {OrganizationID: 2, Role: RoleOwner}}, 1, 10, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Can(ForMemberships(tt.memberships), tt.orgID, tt.projectID, "write:Update", "organizations", nil); got != tt.want {
+				t.Errorf("Can = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanRestrictivePermissionDenies(t *testing.T) {
+	permissions := []Permission{
+		{OrganizationID: 1, Resources: []string{"%"}, Actions: []string{"%"}},
+		{OrganizationID: 1, Resources: []string{"projects"}, Actions: []string{"write:Delete"}, Restrictive: true,
+			Condition: map[string]interface{}{"==": []interface{}{map[string]interface{}{"var": "resource.project_id"}, float64(10)}}},
+	}
+
+	tests := []struct {
+		name      string
+		action    string
+		projectID int
+		want      bool
+	}{
+		{"condition holds", "write:Delete", 10, false},
+		{"condition doesn't hold", "write:Delete", 11, true},
+		{"other action", "write:Update", 10, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := map[string]interface{}{"resource": map[string]interface{}{"project_id": tt.projectID}}
+			if got := Can(permissions, 1, 0, tt.action, "projects", data); got != tt.want {
+				t.Errorf("Can = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatternRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		value   string
+		want    bool
+	}{
+		{"%", "anything", true},
+		{"tenant:Sql:Write:%", "tenant:Sql:Write:Insert", true},
+		{"tenant:Sql:Write:%", "tenant:Sql:Read:Select", false},
+		{"projects", "projects.pgsodium_root_key_encrypted", false},
+		// regexp characters in patterns are literal
+		{"auth.roles", "auth_roles", false},
+		{"a+", "aa", false},
+	}
+	for _, tt := range tests {
+		if got := patternRegexp(tt.pattern).MatchString(tt.value); got != tt.want {
+			t.Errorf("pattern %q on %q = %t, want %t", tt.pattern, tt.value, got, tt.want)
+		}
+	}
+}
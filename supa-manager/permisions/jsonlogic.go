@@ -0,0 +1,287 @@
+package permisions
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Apply evaluates a JSON-logic rule against data with the semantics of json-logic-js, which
+// Studio uses for the same permission conditions. Data must be JSON shaped: maps, slices,
+// strings, float64, bool and nil. Only the operators needed by permission conditions are
+// supported, unknown operators evaluate to nil.
+func Apply(rule interface{}, data interface{}) interface{} {
+	switch r := rule.(type) {
+	case []interface{}:
+		out := make([]interface{}, len(r))
+		for i, item := range r {
+			out[i] = Apply(item, data)
+		}
+		return out
+	case map[string]interface{}:
+		if len(r) != 1 {
+			return r
+		}
+		for op, rawArgs := range r {
+			args, ok := rawArgs.([]interface{})
+			if !ok {
+				args = []interface{}{rawArgs}
+			}
+			return applyOperator(op, args, data)
+		}
+	}
+	return rule
+}
+
+func applyOperator(op string, args []interface{}, data interface{}) interface{} {
+	// Short-circuiting operators evaluate their arguments lazily
+	switch op {
+	case "and":
+		var last interface{} = true
+		for _, arg := range args {
+			last = Apply(arg, data)
+			if !Truthy(last) {
+				return last
+			}
+		}
+		return last
+	case "or":
+		var last interface{} = false
+		for _, arg := range args {
+			last = Apply(arg, data)
+			if Truthy(last) {
+				return last
+			}
+		}
+		return last
+	case "if", "?:":
+		for i := 0; i+1 < len(args); i += 2 {
+			if Truthy(Apply(args[i], data)) {
+				return Apply(args[i+1], data)
+			}
+		}
+		if len(args)%2 == 1 {
+			return Apply(args[len(args)-1], data)
+		}
+		return nil
+	}
+
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = Apply(arg, data)
+	}
+	arg := func(i int) interface{} {
+		if i < len(values) {
+			return values[i]
+		}
+		return nil
+	}
+
+	switch op {
+	case "var":
+		return lookupVar(data, arg(0), arg(1))
+	case "missing":
+		missing := []interface{}{}
+		for _, key := range values {
+			if v := lookupVar(data, key, nil); v == nil || v == "" {
+				missing = append(missing, key)
+			}
+		}
+		return missing
+	case "==":
+		return looseEquals(arg(0), arg(1))
+	case "!=":
+		return !looseEquals(arg(0), arg(1))
+	case "===":
+		return strictEquals(arg(0), arg(1))
+	case "!==":
+		return !strictEquals(arg(0), arg(1))
+	case "!":
+		return !Truthy(arg(0))
+	case "!!":
+		return Truthy(arg(0))
+	case "<", "<=", ">", ">=":
+		return compare(op, values)
+	case "in":
+		switch haystack := arg(1).(type) {
+		case string:
+			return strings.Contains(haystack, toString(arg(0)))
+		case []interface{}:
+			for _, item := range haystack {
+				if strictEquals(item, arg(0)) {
+					return true
+				}
+			}
+		}
+		return false
+	case "cat":
+		var b strings.Builder
+		for _, v := range values {
+			b.WriteString(toString(v))
+		}
+		return b.String()
+	}
+	return nil
+}
+
+// Truthy reports whether a value is truthy in JavaScript
+func Truthy(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return t
+	case float64:
+		return t != 0 && !math.IsNaN(t)
+	case string:
+		return t != ""
+	case []interface{}:
+		// json-logic treats empty arrays as falsy
+		return len(t) > 0
+	default:
+		return true
+	}
+}
+
+func lookupVar(data interface{}, key interface{}, fallback interface{}) interface{} {
+	path := toString(key)
+	if key == nil || path == "" {
+		return data
+	}
+	current := data
+	for _, part := range strings.Split(path, ".") {
+		switch c := current.(type) {
+		case map[string]interface{}:
+			v, ok := c[part]
+			if !ok {
+				return fallback
+			}
+			current = v
+		case []interface{}:
+			idx, err := strconv.Atoi(part)
+			if err != nil || idx < 0 || idx >= len(c) {
+				return fallback
+			}
+			current = c[idx]
+		default:
+			return fallback
+		}
+	}
+	if current == nil {
+		return fallback
+	}
+	return current
+}
+
+func strictEquals(a, b interface{}) bool {
+	switch a.(type) {
+	case nil, bool, float64, string:
+		return a == b
+	}
+	// Objects and arrays are only equal to themselves, which cannot happen across evaluations
+	return false
+}
+
+// looseEquals implements the subset of JavaScript's == that matters for JSON values
+func looseEquals(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if strictEquals(a, b) {
+		return true
+	}
+	an, aok := toNumber(a)
+	bn, bok := toNumber(b)
+	_, aIsString := a.(string)
+	_, bIsString := b.(string)
+	if aIsString && bIsString {
+		return false
+	}
+	return aok && bok && an == bn
+}
+
+func compare(op string, values []interface{}) bool {
+	if len(values) < 2 {
+		return false
+	}
+	for i := 0; i+1 < len(values); i++ {
+		a, b := values[i], values[i+1]
+		var result bool
+		as, aIsString := a.(string)
+		bs, bIsString := b.(string)
+		if aIsString && bIsString {
+			switch op {
+			case "<":
+				result = as < bs
+			case "<=":
+				result = as <= bs
+			case ">":
+				result = as > bs
+			case ">=":
+				result = as >= bs
+			}
+		} else {
+			an, aok := toNumber(a)
+			bn, bok := toNumber(b)
+			if !aok || !bok {
+				return false
+			}
+			switch op {
+			case "<":
+				result = an < bn
+			case "<=":
+				result = an <= bn
+			case ">":
+				result = an > bn
+			case ">=":
+				result = an >= bn
+			}
+		}
+		if !result {
+			return false
+		}
+		// Only "<" and "<=" accept a third operand (between), like json-logic-js
+		if op == ">" || op == ">=" {
+			break
+		}
+	}
+	return true
+}
+
+func toNumber(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return t, true
+	case bool:
+		if t {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		if strings.TrimSpace(t) == "" {
+			return 0, true
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(t), 64)
+		return f, err == nil
+	case nil:
+		return 0, true
+	}
+	return 0, false
+}
+
+func toString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	default:
+		encoded, _ := json.Marshal(t)
+		return string(encoded)
+	}
+}
@@ -0,0 +1,132 @@
+package permisions
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("invalid JSON %s: %v", s, err)
+	}
+	return v
+}
+
+// The expectations are what json-logic-js returns for the same rule and data
+func TestApply(t *testing.T) {
+	data := `{"subject": {"id": 7, "gotrue_id": "abc"}, "resource": {"owner_id": 7, "role_id": 1, "type": "sql", "tags": ["a", "b"], "empty": ""}}`
+
+	tests := []struct {
+		name string
+		rule string
+		want string
+	}{
+		{"literal", `"x"`, `"x"`},
+		{"var", `{"var": "subject.id"}`, `7`},
+		{"var without path returns data", `{"var": ""}`, data},
+		{"var missing uses default", `{"var": ["resource.project_id", 3]}`, `3`},
+		{"var missing is null", `{"var": "resource.project_id"}`, `null`},
+		{"var through a non object", `{"var": "subject.id.nested"}`, `null`},
+		{"var array index", `{"var": "resource.tags.1"}`, `"b"`},
+		{"var array index out of range", `{"var": "resource.tags.5"}`, `null`},
+		{"missing", `{"missing": ["subject.id", "resource.empty", "resource.nope"]}`, `["resource.empty", "resource.nope"]`},
+
+		{"== coerces numbers and strings", `{"==": [1, "1"]}`, `true`},
+		{"== compares strings exactly", `{"==": ["1.0", "1"]}`, `false`},
+		{"== null only equals null", `{"==": [null, 0]}`, `false`},
+		{"== null equals null", `{"==": [null, {"var": "nope"}]}`, `true`},
+		{"== booleans are numbers", `{"==": [true, 1]}`, `true`},
+		{"=== doesn't coerce", `{"===": [1, "1"]}`, `false`},
+		{"=== same number", `{"===": [{"var": "resource.owner_id"}, {"var": "subject.id"}]}`, `true`},
+		{"=== arrays are never equal", `{"===": [[1], [1]]}`, `false`},
+		{"!== negates", `{"!==": [{"var": "resource.role_id"}, 1]}`, `false`},
+		{"!= negates", `{"!=": ["1", 1]}`, `false`},
+
+		{"! of empty string", `{"!": [""]}`, `true`},
+		{"! single argument form", `{"!": {"var": "resource.type"}}`, `false`},
+		{"!! of empty array", `{"!!": [[]]}`, `false`},
+		{"!! of zero", `{"!!": [0]}`, `false`},
+		{"!! of string zero", `{"!!": ["0"]}`, `true`},
+
+		{"and returns the first falsy value", `{"and": [1, "", 2]}`, `""`},
+		{"and returns the last value", `{"and": [1, "x"]}`, `"x"`},
+		{"and without arguments", `{"and": []}`, `true`},
+		{"or returns the first truthy value", `{"or": [0, "", "y", "z"]}`, `"y"`},
+		{"or returns the last falsy value", `{"or": [0, null]}`, `null`},
+
+		{"if", `{"if": [false, "a", true, "b", "c"]}`, `"b"`},
+		{"if else", `{"if": [false, "a", "c"]}`, `"c"`},
+		{"if without else", `{"if": [false, "a"]}`, `null`},
+
+		{"<", `{"<": [1, 2]}`, `true`},
+		{"< between", `{"<": [1, 5, 3]}`, `false`},
+		{"<= between", `{"<=": [1, 1, 3]}`, `true`},
+		{"> ignores a third operand", `{">": [3, 2, 5]}`, `true`},
+		{"< compares strings lexically", `{"<": ["10", "9"]}`, `true`},
+		{"< coerces a number and a string", `{"<": [9, "10"]}`, `true`},
+		{"< with a non number", `{"<": [1, "x"]}`, `false`},
+		{"< with one operand", `{"<": [1]}`, `false`},
+
+		{"in array", `{"in": ["a", {"var": "resource.tags"}]}`, `true`},
+		{"in array is strict", `{"in": [1, ["1"]]}`, `false`},
+		{"in string", `{"in": ["ql", "sql"]}`, `true`},
+		{"in something else", `{"in": ["a", 5]}`, `false`},
+		{"cat", `{"cat": ["id:", {"var": "subject.id"}, true, null]}`, `"id:7true"`},
+
+		{"unknown operator", `{"nope": [1]}`, `null`},
+		{"objects with several keys are literals", `{"a": 1, "b": 2}`, `{"a": 1, "b": 2}`},
+		{"arrays are evaluated", `[{"var": "subject.id"}, 1]`, `[7, 1]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Apply(decode(t, tt.rule), decode(t, data))
+			if want := decode(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("Apply(%s) = %#v, want %#v", tt.rule, got, want)
+			}
+		})
+	}
+}
+
+func TestTruthy(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  bool
+	}{
+		{nil, false},
+		{false, false},
+		{true, true},
+		{float64(0), false},
+		{float64(-1), true},
+		{"", false},
+		{"false", true},
+		{[]interface{}{}, false},
+		{[]interface{}{false}, true},
+		{map[string]interface{}{}, true},
+	}
+	for _, tt := range tests {
+		if got := Truthy(tt.value); got != tt.want {
+			t.Errorf("Truthy(%#v) = %t, want %t", tt.value, got, tt.want)
+		}
+	}
+}
+
+// The builders in roles.go must produce rules that survive the JSON round trip to Studio unchanged
+func TestRuleBuildersAreJSONShaped(t *testing.T) {
+	for _, role := range Roles {
+		for _, rule := range roleRules(role) {
+			if rule.Condition == nil {
+				continue
+			}
+			encoded, err := json.Marshal(rule.Condition)
+			if err != nil {
+				t.Fatalf("%s: %v", role, err)
+			}
+			if decoded := decode(t, string(encoded)); !reflect.DeepEqual(decoded, rule.Condition) {
+				t.Errorf("%s: condition %s changes through JSON", role, encoded)
+			}
+		}
+	}
+}
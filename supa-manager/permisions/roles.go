@@ -0,0 +1,335 @@
+package permisions
+
+// Organization member roles
+const (
+	RoleOwner     = "OWNER"
+	RoleAdmin     = "ADMIN"
+	RoleDeveloper = "DEVELOPER"
+	RoleReadOnly  = "READ_ONLY"
+)
+
+// Roles lists the roles from most to least privileged
+var Roles = []string{RoleOwner, RoleAdmin, RoleDeveloper, RoleReadOnly}
+
+// roleIDs are the ids Studio knows the roles by, conditions on role_id compare against them
+var roleIDs = map[string]int32{
+	RoleOwner:     1,
+	RoleAdmin:     2,
+	RoleDeveloper: 3,
+	RoleReadOnly:  4,
+}
+
+// roleNames are the names Studio shows for the roles
+var roleNames = map[string]string{
+	RoleOwner:     "Owner",
+	RoleAdmin:     "Administrator",
+	RoleDeveloper: "Developer",
+	RoleReadOnly:  "Read-only",
+}
+
+// RoleID returns the id of a role, zero for unknown roles
+func RoleID(role string) int32 {
+	return roleIDs[role]
+}
+
+// RoleName returns the display name of a role
+func RoleName(role string) string {
+	return roleNames[role]
+}
+
+// RoleByID returns the role with the given id
+func RoleByID(id int32) (string, bool) {
+	for role, roleID := range roleIDs {
+		if roleID == id {
+			return role, true
+		}
+	}
+	return "", false
+}
+
+// ValidRole reports whether role is one of the organization roles
+func ValidRole(role string) bool {
+	_, ok := roleIDs[role]
+	return ok
+}
+
+// Rule grants actions on resources to a role, optionally only when a JSON-logic condition holds
+type Rule struct {
+	Resources   []string
+	Actions     []string
+	Condition   interface{}
+	Restrictive bool
+}
+
+// Membership is an account's role in an organization, limited to some projects when ProjectIDs is set
+type Membership struct {
+	OrganizationID int32
+	Role           string
+	ProjectIDs     []int32
+}
+
+// ForMemberships returns the permissions Studio receives for the memberships of an account
+func ForMemberships(memberships []Membership) []Permission {
+	permissions := []Permission{}
+	for _, m := range memberships {
+		permissions = append(permissions, ForRole(m.OrganizationID, m.Role, m.ProjectIDs)...)
+	}
+	return permissions
+}
+
+// ForRole returns the permissions of a role in an organization, unknown roles get none
+func ForRole(orgID int32, role string, projectIDs []int32) []Permission {
+	if projectIDs == nil {
+		projectIDs = []int32{}
+	}
+	permissions := []Permission{}
+	for _, rule := range roleRules(role) {
+		permissions = append(permissions, Permission{
+			OrganizationID: orgID,
+			Resources:      rule.Resources,
+			Actions:        rule.Actions,
+			Condition:      rule.Condition,
+			Restrictive:    rule.Restrictive,
+			ProjectIDs:     projectIDs,
+		})
+	}
+	return permissions
+}
+
+func roleRules(role string) []Rule {
+	switch role {
+	case RoleOwner:
+		return concat(memberRules, developerRules, adminRules, ownerRules)
+	case RoleAdmin:
+		return concat(memberRules, developerRules, adminRules, adminOnlyRules)
+	case RoleDeveloper:
+		return concat(memberRules, developerRules)
+	case RoleReadOnly:
+		return concat(memberRules, readOnlyRules)
+	default:
+		return nil
+	}
+}
+
+func concat(sets ...[]Rule) []Rule {
+	var rules []Rule
+	for _, set := range sets {
+		rules = append(rules, set...)
+	}
+	return rules
+}
+
+var (
+	write       = []string{"write:Create", "write:Update", "write:Delete"}
+	isOwnItem   = and(v("resource.owner_id"), v("subject.id"), eq(v("resource.owner_id"), v("subject.id")))
+	isNotReport = strictNeq(v("resource.type"), "report")
+)
+
+// memberRules are granted to every member: reading the organization, its projects and shared content
+var memberRules = []Rule{
+	{
+		Resources: []string{"members", "organizations", "auth.subject_roles", "users", "user_invites", "auth.permissions", "auth.roles"},
+		Actions:   []string{"read:Read"},
+	},
+	{
+		// Members can leave the organization
+		Resources: []string{"auth.subject_roles"},
+		Actions:   []string{"write:Delete"},
+		Condition: and(v("resource.subject_id"), v("subject.gotrue_id"), eq(v("resource.subject_id"), v("subject.gotrue_id"))),
+	},
+	{
+		Resources: []string{
+			"back_ups", "custom_config_gotrue", "custom_config_postgrest", "customers", "events", "gotrue_config",
+			"infrastructure", "invoices", "member_active_free_projects", "notifications", "organizations",
+			"owner_reassign", "physical_backups", "postgrest_config", "service_api_keys", "services",
+			"stats_daily_projects", "subscriptions", "subscription_items", "preview_branches",
+			"resource_exhaustion_notifications", "approved_oauth_apps", "third_party_auth",
+			"integrations.vercel_connections", "integrations.github_connections",
+		},
+		Actions: []string{"read:Read"},
+	},
+	{
+		Resources: []string{"projects", "third_party_auth"},
+		Actions:   []string{"read:Read"},
+		Condition: and(v("resource.project_id")),
+	},
+	{
+		Resources: []string{"user_content_folders"},
+		Actions:   append([]string{"read:Read"}, write...),
+		Condition: isOwnItem,
+	},
+	{
+		Resources: []string{"user_content"},
+		Actions:   []string{"read:Read"},
+		Condition: and(v("resource.visibility"), v("resource.owner_id"), v("subject.id"),
+			or(neq(v("resource.visibility"), "user"), eq(v("resource.owner_id"), v("subject.id")))),
+	},
+	{
+		Resources: []string{"%"},
+		Actions:   []string{"analytics:Read", "billing:Read", "functions:Read", "storage:Admin:Read", "tenant:Sql:Admin:Read", "tenant:Sql:Read:Select"},
+	},
+}
+
+// readOnlyRules let read-only members keep their own snippets, except reports
+var readOnlyRules = []Rule{
+	{
+		Resources: []string{"user_content"},
+		Actions:   []string{"write:Update", "write:Delete"},
+		Condition: and(v("resource.owner_id"), v("resource.type"), v("resource.visibility"), v("subject.id"),
+			and(isNotReport, strictEq(v("resource.owner_id"), v("subject.id")))),
+	},
+	{
+		Resources: []string{"user_content"},
+		Actions:   []string{"write:Create"},
+		Condition: and(v("resource.owner_id"), v("resource.type"), v("subject.id"),
+			and(isNotReport, strictEq(v("resource.owner_id"), v("subject.id")))),
+	},
+}
+
+// developerRules cover working inside projects: SQL, secrets, content and configuration
+var developerRules = []Rule{
+	{
+		Resources: []string{"preview_branches"},
+		Actions:   write,
+		Condition: not(v("resource.is_default")),
+	},
+	{
+		Resources: []string{"back_ups", "events"},
+		Actions:   []string{"write:Create"},
+	},
+	{
+		// Developers can't pause or restore projects
+		Resources: []string{"%"},
+		Actions:   []string{"infra:Execute"},
+		Condition: and(v("resource_name"), not(in(v("resource_name"), []interface{}{"queue_jobs.projects.initialize_or_resume", "queue_jobs.projects.pause"}))),
+	},
+	{
+		Resources: []string{"user_content"},
+		Actions:   []string{"write:Update", "write:Delete"},
+		Condition: and(v("resource.visibility"), v("resource.owner_id"), v("subject.id"),
+			or(neq(v("resource.visibility"), "user"), eq(v("resource.owner_id"), v("subject.id")))),
+	},
+	{
+		Resources: []string{"user_content"},
+		Actions:   []string{"write:Create"},
+		Condition: isOwnItem,
+	},
+	{
+		Resources: []string{"field.jwt_secret", "service_api_keys.service_role_key"},
+		Actions:   []string{"read:Read"},
+	},
+	{
+		Resources: []string{"%"},
+		Actions:   []string{"auth:Execute", "functions:Write", "storage:Admin:Write", "tenant:Sql:Admin:Write", "tenant:Sql:CreateTable", "tenant:Sql:Query", "tenant:Sql:Write:%"},
+	},
+	{
+		Resources: []string{"custom_config_gotrue", "custom_config_postgrest", "owner_reassign", "services"},
+		Actions:   []string{"write:Create", "write:Update"},
+	},
+}
+
+// adminRules manage the organization and its projects
+var adminRules = []Rule{
+	{
+		Resources: []string{"projects", "integrations.vercel_connections", "integrations.github_connections"},
+		Actions:   []string{"write:Create"},
+	},
+	{
+		Resources: []string{"projects"},
+		Actions:   []string{"write:Update", "write:Delete"},
+		Condition: and(v("resource.project_id")),
+	},
+	{
+		Resources: []string{"preview_branches", "approved_oauth_apps", "third_party_auth"},
+		Actions:   write,
+	},
+	{
+		Resources: []string{"projects.pgsodium_root_key_encrypted"},
+		Actions:   []string{"read:Read", "write:Update"},
+	},
+	{
+		Resources: []string{"notifications", "integrations.vercel_connections", "integrations.github_connections"},
+		Actions:   []string{"write:Update", "write:Delete"},
+	},
+	{
+		Resources: []string{"organizations"},
+		Actions:   []string{"write:Update"},
+	},
+	{
+		Resources: []string{"audit_logs"},
+		Actions:   []string{"read:Read"},
+	},
+	{
+		Resources: []string{"%"},
+		Actions:   []string{"infra:Execute"},
+	},
+}
+
+// adminOnlyRules let administrators manage members, but not owners
+var adminOnlyRules = []Rule{
+	{
+		Resources: []string{"auth.subject_roles", "user_invites"},
+		Actions:   write,
+		// Conditions compare against JSON numbers, hence float64
+		Condition: and(v("resource.role_id"), strictNeq(v("resource.role_id"), float64(RoleID(RoleOwner)))),
+	},
+}
+
+// ownerRules are reserved to owners: billing, deleting the organization and managing any member
+var ownerRules = []Rule{
+	{
+		Resources: []string{"%"},
+		Actions:   []string{"billing:Write"},
+	},
+	{
+		Resources: []string{"organizations"},
+		Actions:   []string{"write:Delete"},
+	},
+	{
+		// Moving a project changes which organization pays for it
+		Resources: []string{"projects"},
+		Actions:   []string{"write:Transfer"},
+	},
+	{
+		Resources: []string{"user_invites", "auth.permissions", "auth.roles", "auth.subject_roles"},
+		Actions:   write,
+	},
+}
+
+// JSON-logic builders, the rules they build are JSON shaped so Studio and Apply see the same thing
+
+func v(path string) interface{} {
+	return map[string]interface{}{"var": path}
+}
+
+func and(args ...interface{}) interface{} {
+	return map[string]interface{}{"and": args}
+}
+
+func or(args ...interface{}) interface{} {
+	return map[string]interface{}{"or": args}
+}
+
+func not(arg interface{}) interface{} {
+	return map[string]interface{}{"!": arg}
+}
+
+func eq(a, b interface{}) interface{} {
+	return map[string]interface{}{"==": []interface{}{a, b}}
+}
+
+func neq(a, b interface{}) interface{} {
+	return map[string]interface{}{"!=": []interface{}{a, b}}
+}
+
+func strictEq(a, b interface{}) interface{} {
+	return map[string]interface{}{"===": []interface{}{a, b}}
+}
+
+func strictNeq(a, b interface{}) interface{} {
+	return map[string]interface{}{"!==": []interface{}{a, b}}
+}
+
+func in(a, b interface{}) interface{} {
+	return map[string]interface{}{"in": []interface{}{a, b}}
+}
@@ -0,0 +1,374 @@
+package pgmeta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// columnsSQL lists table/view columns in the shape returned by postgres-meta
+const columnsSQL = `
+SELECT
+  c.oid :: int8 AS table_id,
+  nc.nspname AS schema,
+  c.relname AS table,
+  (c.oid || '.' || a.attnum) AS id,
+  a.attnum AS ordinal_position,
+  a.attname AS name,
+  CASE
+    WHEN a.atthasdef THEN pg_get_expr(ad.adbin, ad.adrelid)
+    ELSE NULL
+  END AS default_value,
+  CASE
+    WHEN t.typtype = 'd' THEN CASE
+      WHEN bt.typelem <> 0 :: oid AND bt.typlen = -1 THEN 'ARRAY'
+      WHEN nbt.nspname = 'pg_catalog' THEN format_type(t.typbasetype, NULL)
+      ELSE 'USER-DEFINED'
+    END
+    ELSE CASE
+      WHEN t.typelem <> 0 :: oid AND t.typlen = -1 THEN 'ARRAY'
+      WHEN nt.nspname = 'pg_catalog' THEN format_type(a.atttypid, NULL)
+      ELSE 'USER-DEFINED'
+    END
+  END AS data_type,
+  COALESCE(bt.typname, t.typname) AS format,
+  a.attidentity IN ('a', 'd') AS is_identity,
+  CASE a.attidentity
+    WHEN 'a' THEN 'ALWAYS'
+    WHEN 'd' THEN 'BY DEFAULT'
+    ELSE NULL
+  END AS identity_generation,
+  a.attgenerated IN ('s') AS is_generated,
+  NOT (a.attnotnull OR t.typtype = 'd' AND t.typnotnull) AS is_nullable,
+  (
+    c.relkind IN ('r', 'p')
+    OR c.relkind IN ('v', 'f') AND pg_column_is_updatable(c.oid, a.attnum, FALSE)
+  ) AS is_updatable,
+  uniques.table_id IS NOT NULL AS is_unique,
+  check_constraints.definition AS "check",
+  array_to_json(
+    array(
+      SELECT enumlabel
+      FROM pg_catalog.pg_enum enums
+      WHERE enums.enumtypid = coalesce(bt.oid, t.oid)
+        OR enums.enumtypid = coalesce(bt.typelem, t.typelem)
+      ORDER BY enums.enumsortorder
+    )
+  ) AS enums,
+  col_description(c.oid, a.attnum) AS comment
+FROM
+  pg_attribute a
+  LEFT JOIN pg_attrdef ad ON a.attrelid = ad.adrelid AND a.attnum = ad.adnum
+  JOIN (
+    pg_class c
+    JOIN pg_namespace nc ON c.relnamespace = nc.oid
+  ) ON a.attrelid = c.oid
+  JOIN (
+    pg_type t
+    JOIN pg_namespace nt ON t.typnamespace = nt.oid
+  ) ON a.atttypid = t.oid
+  LEFT JOIN (
+    pg_type bt
+    JOIN pg_namespace nbt ON bt.typnamespace = nbt.oid
+  ) ON t.typtype = 'd' AND t.typbasetype = bt.oid
+  LEFT JOIN (
+    SELECT DISTINCT ON (table_id, ordinal_position)
+      conrelid AS table_id,
+      conkey[1] AS ordinal_position
+    FROM pg_catalog.pg_constraint
+    WHERE contype = 'u' AND cardinality(conkey) = 1
+  ) AS uniques ON uniques.table_id = c.oid AND uniques.ordinal_position = a.attnum
+  LEFT JOIN (
+    -- Only the first single-column check constraint is reported
+    SELECT DISTINCT ON (table_id, ordinal_position)
+      conrelid AS table_id,
+      conkey[1] AS ordinal_position,
+      substring(
+        pg_get_constraintdef(pg_constraint.oid, true),
+        8,
+        length(pg_get_constraintdef(pg_constraint.oid, true)) - 8
+      ) AS "definition"
+    FROM pg_constraint
+    WHERE contype = 'c' AND cardinality(conkey) = 1
+    ORDER BY table_id, ordinal_position, oid ASC
+  ) AS check_constraints ON check_constraints.table_id = c.oid AND check_constraints.ordinal_position = a.attnum
+WHERE
+  NOT pg_is_other_temp_schema(nc.oid)
+  AND a.attnum > 0
+  AND NOT a.attisdropped
+  AND (c.relkind IN ('r', 'v', 'm', 'f', 'p'))
+  AND (
+    pg_has_role(c.relowner, 'USAGE')
+    OR has_column_privilege(c.oid, a.attnum, 'SELECT, INSERT, UPDATE, REFERENCES')
+  )
+`
+
+// ColumnCreate is the body of a pg-meta column create request
+type ColumnCreate struct {
+	TableID            int64       `json:"table_id" binding:"required"`
+	Name               string      `json:"name" binding:"required"`
+	Type               string      `json:"type" binding:"required"`
+	DefaultValue       interface{} `json:"default_value"`
+	DefaultValueFormat string      `json:"default_value_format"` // "literal" (default) or "expression"
+	IsIdentity         bool        `json:"is_identity"`
+	IdentityGeneration string      `json:"identity_generation"` // "BY DEFAULT" (default) or "ALWAYS"
+	IsNullable         *bool       `json:"is_nullable"`
+	IsPrimaryKey       bool        `json:"is_primary_key"`
+	IsUnique           bool        `json:"is_unique"`
+	Comment            *string     `json:"comment"`
+	Check              *string     `json:"check"`
+}
+
+// ColumnUpdate is the body of a pg-meta column update request, nil fields are left unchanged
+type ColumnUpdate struct {
+	Name               *string     `json:"name"`
+	Type               *string     `json:"type"`
+	DropDefault        bool        `json:"drop_default"`
+	DefaultValue       interface{} `json:"default_value"`
+	DefaultValueFormat string      `json:"default_value_format"`
+	IsIdentity         *bool       `json:"is_identity"`
+	IdentityGeneration *string     `json:"identity_generation"`
+	IsNullable         *bool       `json:"is_nullable"`
+	IsPrimaryKey       *bool       `json:"is_primary_key"`
+	IsUnique           *bool       `json:"is_unique"`
+	Comment            *string     `json:"comment"`
+	Check              *string     `json:"check"` // An empty check removes the existing one
+}
+
+// ColumnListOptions controls ListColumns
+type ColumnListOptions struct {
+	ListOptions
+	TableID int64 // Only list columns of this table when non-zero
+}
+
+// ListColumns returns columns matching the options
+func ListColumns(ctx context.Context, db Querier, opts ColumnListOptions) ([]Row, error) {
+	where := schemaFilter("schema", opts.ListOptions)
+	if opts.TableID != 0 {
+		where += fmt.Sprintf(" AND table_id = %d", opts.TableID)
+	}
+	sql := fmt.Sprintf("WITH columns AS (%s) SELECT * FROM columns WHERE %s ORDER BY table_id, ordinal_position", columnsSQL, where) + limitOffset(opts.ListOptions)
+	return Query(ctx, db, sql)
+}
+
+// RetrieveColumn returns a column by its pg-meta id, "<table oid>.<attnum>"
+func RetrieveColumn(ctx context.Context, db Querier, id string) (Row, error) {
+	return queryOne(ctx, db, fmt.Sprintf("WITH columns AS (%s) SELECT * FROM columns WHERE id = $1", columnsSQL), id)
+}
+
+func retrieveColumnByName(ctx context.Context, db Querier, tableID int64, name string) (Row, error) {
+	return queryOne(ctx, db, fmt.Sprintf("WITH columns AS (%s) SELECT * FROM columns WHERE table_id = $1 AND name = $2", columnsSQL), tableID, name)
+}
+
+// CreateColumn adds a column to a table and returns it
+func CreateColumn(ctx context.Context, pool *pgxpool.Pool, req ColumnCreate) (Row, error) {
+	table, err := RetrieveTable(ctx, pool, req.TableID)
+	if err != nil {
+		return Row{}, err
+	}
+	target := ident(table.String("schema"), table.String("name"))
+
+	clauses := []string{ident(req.Name), typeIdent(req.Type)}
+	if req.IsIdentity {
+		if req.DefaultValue != nil {
+			return Row{}, fmt.Errorf("%w: columns cannot both be identity and have a default value", ErrInvalidRequest)
+		}
+		generation, err := identityGeneration(req.IdentityGeneration)
+		if err != nil {
+			return Row{}, err
+		}
+		clauses = append(clauses, fmt.Sprintf("GENERATED %s AS IDENTITY", generation))
+	} else if req.DefaultValue != nil {
+		clauses = append(clauses, "DEFAULT "+defaultValue(req.DefaultValue, req.DefaultValueFormat))
+	}
+	if req.IsNullable != nil {
+		if *req.IsNullable {
+			clauses = append(clauses, "NULL")
+		} else {
+			clauses = append(clauses, "NOT NULL")
+		}
+	}
+	if req.IsPrimaryKey {
+		clauses = append(clauses, "PRIMARY KEY")
+	}
+	if req.IsUnique {
+		clauses = append(clauses, "UNIQUE")
+	}
+	if req.Check != nil && *req.Check != "" {
+		clauses = append(clauses, fmt.Sprintf("CHECK (%s)", *req.Check))
+	}
+
+	statements := []string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", target, strings.Join(clauses, " "))}
+	if req.Comment != nil {
+		statements = append(statements, commentOn("COLUMN", ident(table.String("schema"), table.String("name"), req.Name), *req.Comment))
+	}
+
+	return inTransaction(ctx, pool, statements, func(tx pgx.Tx) (Row, error) {
+		return retrieveColumnByName(ctx, tx, req.TableID, req.Name)
+	})
+}
+
+// UpdateColumn applies the requested changes in one transaction and returns the updated column
+func UpdateColumn(ctx context.Context, pool *pgxpool.Pool, id string, req ColumnUpdate) (Row, error) {
+	old, err := RetrieveColumn(ctx, pool, id)
+	if err != nil {
+		return Row{}, err
+	}
+	tableID, position := old.Int("table_id"), old.Int("ordinal_position")
+	alter := "ALTER TABLE " + ident(old.String("schema"), old.String("table"))
+	column := ident(old.String("name"))
+	alterColumn := alter + " ALTER COLUMN " + column
+
+	statements := []string{}
+	if req.IsNullable != nil {
+		if *req.IsNullable {
+			statements = append(statements, alterColumn+" DROP NOT NULL")
+		} else {
+			statements = append(statements, alterColumn+" SET NOT NULL")
+		}
+	}
+	if req.Type != nil {
+		// The explicit cast lets Postgres convert between types that have no assignment cast
+		statements = append(statements, fmt.Sprintf("%s SET DATA TYPE %s USING %s::%s", alterColumn, typeIdent(*req.Type), column, typeIdent(*req.Type)))
+	}
+	if req.DropDefault {
+		statements = append(statements, alterColumn+" DROP DEFAULT")
+	} else if req.DefaultValue != nil {
+		statements = append(statements, alterColumn+" SET DEFAULT "+defaultValue(req.DefaultValue, req.DefaultValueFormat))
+	}
+	if req.IsIdentity != nil && !*req.IsIdentity {
+		statements = append(statements, alterColumn+" DROP IDENTITY IF EXISTS")
+	} else if req.IsIdentity != nil || req.IdentityGeneration != nil {
+		generation := ""
+		if req.IdentityGeneration != nil {
+			generation = *req.IdentityGeneration
+		}
+		generation, err := identityGeneration(generation)
+		if err != nil {
+			return Row{}, err
+		}
+		if isIdentity, _ := old.Get("is_identity"); isIdentity == true {
+			statements = append(statements, fmt.Sprintf("%s SET GENERATED %s", alterColumn, generation))
+		} else {
+			statements = append(statements, fmt.Sprintf("%s ADD GENERATED %s AS IDENTITY", alterColumn, generation))
+		}
+	}
+	if req.IsUnique != nil {
+		isUnique, _ := old.Get("is_unique")
+		if isUnique == true && !*req.IsUnique {
+			statements = append(statements, dropColumnConstraints(tableID, position, "u"))
+		} else if isUnique != true && *req.IsUnique {
+			statements = append(statements, fmt.Sprintf("%s ADD UNIQUE (%s)", alter, column))
+		}
+	}
+	if req.IsPrimaryKey != nil {
+		var isPrimaryKey bool
+		if err := pool.QueryRow(ctx, primaryKeyColumnSQL, tableID, position).Scan(&isPrimaryKey); err != nil {
+			return Row{}, err
+		}
+		if isPrimaryKey && !*req.IsPrimaryKey {
+			statements = append(statements, dropColumnConstraints(tableID, position, "p"))
+		} else if !isPrimaryKey && *req.IsPrimaryKey {
+			statements = append(statements, fmt.Sprintf("%s ADD PRIMARY KEY (%s)", alter, column))
+		}
+	}
+	if req.Comment != nil {
+		statements = append(statements, commentOn("COLUMN", ident(old.String("schema"), old.String("table"), old.String("name")), *req.Comment))
+	}
+	if req.Check != nil {
+		statements = append(statements, dropColumnConstraints(tableID, position, "c"))
+		if *req.Check != "" {
+			statements = append(statements, fmt.Sprintf("%s ADD CHECK (%s)", alter, *req.Check))
+		}
+	}
+	// The rename goes last so the statements above can use the old name
+	if req.Name != nil && *req.Name != old.String("name") {
+		statements = append(statements, fmt.Sprintf("%s RENAME COLUMN %s TO %s", alter, column, ident(*req.Name)))
+	}
+
+	return inTransaction(ctx, pool, statements, func(tx pgx.Tx) (Row, error) {
+		return RetrieveColumn(ctx, tx, id)
+	})
+}
+
+// DeleteColumn drops a column and returns it as it was before the drop
+func DeleteColumn(ctx context.Context, pool *pgxpool.Pool, id string, cascade bool) (Row, error) {
+	old, err := RetrieveColumn(ctx, pool, id)
+	if err != nil {
+		return Row{}, err
+	}
+
+	stmt := fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s %s",
+		ident(old.String("schema"), old.String("table")), ident(old.String("name")), dropBehavior(cascade))
+	return inTransaction(ctx, pool, []string{stmt}, func(pgx.Tx) (Row, error) {
+		return old, nil
+	})
+}
+
+// primaryKeyColumnSQL tells whether a column is part of its table's primary key
+const primaryKeyColumnSQL = `SELECT EXISTS (SELECT 1 FROM pg_constraint WHERE contype = 'p' AND conrelid = $1 AND $2 = ANY (conkey))`
+
+// dropColumnConstraints drops the constraints of the given type that cover only this column,
+// or for primary keys any primary key that includes it
+func dropColumnConstraints(tableID, position int64, contype string) string {
+	match := "cardinality(conkey) = 1 AND conkey[1] = %d"
+	if contype == "p" {
+		match = "%d = ANY (conkey)"
+	}
+	return fmt.Sprintf(`DO $$
+DECLARE r record;
+BEGIN
+  FOR r IN
+    SELECT conname, conrelid::regclass AS rel FROM pg_constraint
+    WHERE contype = %s AND conrelid = %d AND `+match+`
+  LOOP
+    EXECUTE format('ALTER TABLE %%s DROP CONSTRAINT %%I', r.rel, r.conname);
+  END LOOP;
+END $$`, literal(contype), tableID, position)
+}
+
+// typeIdent quotes a type name the way pg-meta does: schema qualified and parameterised
+// types are used verbatim, arrays keep their [] suffix outside the quotes
+func typeIdent(typ string) string {
+	if strings.HasSuffix(typ, "[]") {
+		return typeIdent(strings.TrimSuffix(typ, "[]")) + "[]"
+	}
+	if strings.ContainsAny(typ, ".( ") {
+		return typ
+	}
+	return ident(typ)
+}
+
+func identityGeneration(generation string) (string, error) {
+	switch strings.ToUpper(generation) {
+	case "", "BY DEFAULT":
+		return "BY DEFAULT", nil
+	case "ALWAYS":
+		return "ALWAYS", nil
+	default:
+		return "", fmt.Errorf("%w: unknown identity_generation %q", ErrInvalidRequest, generation)
+	}
+}
+
+// defaultValue renders a default, expressions are used verbatim and everything else becomes a constant
+func defaultValue(value interface{}, format string) string {
+	if format == "expression" {
+		return fmt.Sprint(value)
+	}
+	switch v := value.(type) {
+	case string:
+		return literal(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		encoded, _ := json.Marshal(v)
+		return literal(string(encoded))
+	}
+}
@@ -0,0 +1,267 @@
+package pgmeta
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Type OIDs that get special treatment, mirroring the node-postgres type
+// parsers configured by postgres-meta
+const (
+	oidBool        = 16
+	oidBytea       = 17
+	oidInt8        = 20
+	oidInt2        = 21
+	oidInt4        = 23
+	oidOid         = 26
+	oidJSON        = 114
+	oidJSONArray   = 199
+	oidFloat4      = 700
+	oidFloat8      = 701
+	oidBoolArray   = 1000
+	oidByteaArray  = 1001
+	oidInt2Array   = 1005
+	oidInt4Array   = 1007
+	oidTextArray   = 1009
+	oidBpcharArray = 1014
+	oidVarcharArr  = 1015
+	oidInt8Array   = 1016
+	oidFloat4Array = 1021
+	oidFloat8Array = 1022
+	oidOidArray    = 1028
+	oidTsArray     = 1115
+	oidDateArray   = 1182
+	oidTimeArray   = 1183
+	oidTstzArray   = 1185
+	oidNumericArr  = 1231
+	oidTimetzArray = 1270
+	oidUUIDArray   = 2951
+	oidJSONB       = 3802
+	oidJSONBArray  = 3807
+)
+
+// maxSafeInteger is Number.MAX_SAFE_INTEGER, bigger int8 values stay strings
+const maxSafeInteger = 1<<53 - 1
+
+// decodeValue converts a text-format value into what pg-meta would put in its JSON response:
+// numbers for integer and float types (int8 only when it fits a JS number), booleans, parsed
+// JSON for json/jsonb, Buffer objects for bytea, arrays for the array types node-postgres knows,
+// and the raw Postgres text for everything else (numeric, dates, timestamps, intervals, ...).
+func decodeValue(oid uint32, raw []byte) interface{} {
+	if raw == nil {
+		return nil
+	}
+	text := string(raw)
+
+	if elem, ok := arrayElementParsers[oid]; ok {
+		parsed, err := parseArray(text)
+		if err != nil {
+			return text
+		}
+		return mapArray(parsed, elem)
+	}
+	return decodeScalar(oid, text)
+}
+
+func decodeScalar(oid uint32, text string) interface{} {
+	switch oid {
+	case oidBool:
+		return text == "t" || text == "true"
+	case oidInt2, oidInt4, oidOid:
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n
+		}
+		return text
+	case oidInt8:
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil && n <= maxSafeInteger && n >= -maxSafeInteger {
+			return n
+		}
+		return text
+	case oidFloat4, oidFloat8:
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			// NaN and Infinity serialise to null in JSON
+			return nil
+		}
+		return f
+	case oidJSON, oidJSONB:
+		if json.Valid([]byte(text)) {
+			return json.RawMessage(text)
+		}
+		return text
+	case oidBytea:
+		return decodeBytea(text)
+	default:
+		return text
+	}
+}
+
+// arrayElementParsers maps array OIDs to the decoder applied to each element
+var arrayElementParsers = map[uint32]func(string) interface{}{
+	oidBoolArray:   func(s string) interface{} { return decodeScalar(oidBool, s) },
+	oidInt2Array:   func(s string) interface{} { return decodeScalar(oidInt2, s) },
+	oidInt4Array:   func(s string) interface{} { return decodeScalar(oidInt4, s) },
+	oidOidArray:    func(s string) interface{} { return decodeScalar(oidOid, s) },
+	oidInt8Array:   func(s string) interface{} { return s }, // node-postgres keeps bigint array elements as strings
+	oidFloat4Array: func(s string) interface{} { return decodeScalar(oidFloat4, s) },
+	oidFloat8Array: func(s string) interface{} { return decodeScalar(oidFloat8, s) },
+	oidNumericArr:  func(s string) interface{} { return s },
+	oidTextArray:   func(s string) interface{} { return s },
+	oidBpcharArray: func(s string) interface{} { return s },
+	oidVarcharArr:  func(s string) interface{} { return s },
+	oidUUIDArray:   func(s string) interface{} { return s },
+	oidTsArray:     func(s string) interface{} { return s },
+	oidTstzArray:   func(s string) interface{} { return s },
+	oidDateArray:   func(s string) interface{} { return s },
+	oidTimeArray:   func(s string) interface{} { return s },
+	oidTimetzArray: func(s string) interface{} { return s },
+	oidJSONArray:   func(s string) interface{} { return decodeScalar(oidJSON, s) },
+	oidJSONBArray:  func(s string) interface{} { return decodeScalar(oidJSONB, s) },
+	oidByteaArray:  func(s string) interface{} { return decodeBytea(s) },
+}
+
+// nodeBuffer is how a Node.js Buffer serialises to JSON
+type nodeBuffer struct {
+	Type string `json:"type"`
+	Data []int  `json:"data"`
+}
+
+func decodeBytea(text string) interface{} {
+	if !strings.HasPrefix(text, `\x`) {
+		return text
+	}
+	hex := text[2:]
+	data := make([]int, 0, len(hex)/2)
+	for i := 0; i+1 < len(hex); i += 2 {
+		b, err := strconv.ParseUint(hex[i:i+2], 16, 8)
+		if err != nil {
+			return text
+		}
+		data = append(data, int(b))
+	}
+	return nodeBuffer{Type: "Buffer", Data: data}
+}
+
+func mapArray(v interface{}, elem func(string) interface{}) interface{} {
+	switch t := v.(type) {
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, e := range t {
+			out[i] = mapArray(e, elem)
+		}
+		return out
+	case *string:
+		if t == nil {
+			return nil
+		}
+		return elem(*t)
+	default:
+		return nil
+	}
+}
+
+// parseArray parses a Postgres array literal such as {1,2,NULL} or {{"a b",c},{d,e}}
+// into nested []interface{} whose leaves are *string (nil for NULL)
+func parseArray(text string) ([]interface{}, error) {
+	// Skip explicit bounds, e.g. [0:1]={a,b}
+	if strings.HasPrefix(text, "[") {
+		idx := strings.Index(text, "=")
+		if idx < 0 {
+			return nil, errors.New("invalid array bounds")
+		}
+		text = text[idx+1:]
+	}
+	p := &arrayParser{input: text}
+	result, err := p.parseLevel()
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+type arrayParser struct {
+	input string
+	pos   int
+}
+
+func (p *arrayParser) parseLevel() ([]interface{}, error) {
+	if p.pos >= len(p.input) || p.input[p.pos] != '{' {
+		return nil, errors.New("expected '{'")
+	}
+	p.pos++
+
+	items := []interface{}{}
+	if p.pos < len(p.input) && p.input[p.pos] == '}' {
+		p.pos++
+		return items, nil
+	}
+
+	for {
+		if p.pos >= len(p.input) {
+			return nil, errors.New("unterminated array")
+		}
+		switch p.input[p.pos] {
+		case '{':
+			nested, err := p.parseLevel()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, nested)
+		case '"':
+			value, err := p.parseQuoted()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, &value)
+		default:
+			start := p.pos
+			for p.pos < len(p.input) && p.input[p.pos] != ',' && p.input[p.pos] != '}' {
+				p.pos++
+			}
+			value := strings.TrimSpace(p.input[start:p.pos])
+			if strings.EqualFold(value, "NULL") {
+				items = append(items, (*string)(nil))
+			} else {
+				items = append(items, &value)
+			}
+		}
+
+		if p.pos >= len(p.input) {
+			return nil, errors.New("unterminated array")
+		}
+		switch p.input[p.pos] {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return items, nil
+		default:
+			return nil, errors.New("unexpected character in array")
+		}
+	}
+}
+
+func (p *arrayParser) parseQuoted() (string, error) {
+	p.pos++ // opening quote
+	var b strings.Builder
+	for p.pos < len(p.input) {
+		ch := p.input[p.pos]
+		switch ch {
+		case '\\':
+			p.pos++
+			if p.pos < len(p.input) {
+				b.WriteByte(p.input[p.pos])
+			}
+		case '"':
+			p.pos++
+			return b.String(), nil
+		default:
+			b.WriteByte(ch)
+		}
+		p.pos++
+	}
+	return "", errors.New("unterminated quoted element")
+}
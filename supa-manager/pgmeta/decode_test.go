@@ -0,0 +1,62 @@
+package pgmeta
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDecodeValue(t *testing.T) {
+	tests := []struct {
+		name string
+		oid  uint32
+		raw  []byte
+		want string // JSON of the decoded value
+	}{
+		{"null", oidInt4, nil, `null`},
+		{"bool true", oidBool, []byte("t"), `true`},
+		{"bool false", oidBool, []byte("f"), `false`},
+		{"int4", oidInt4, []byte("-42"), `-42`},
+		{"int8 that fits a number", oidInt8, []byte("9007199254740991"), `9007199254740991`},
+		{"int8 past a number", oidInt8, []byte("9007199254740992"), `"9007199254740992"`},
+		{"float8", oidFloat8, []byte("1.5"), `1.5`},
+		{"float NaN", oidFloat8, []byte("NaN"), `null`},
+		{"float Infinity", oidFloat4, []byte("Infinity"), `null`},
+		{"numeric stays text", 1700, []byte("1.10"), `"1.10"`},
+		{"timestamp stays text", 1184, []byte("2024-01-02 03:04:05+00"), `"2024-01-02 03:04:05+00"`},
+		{"jsonb", oidJSONB, []byte(`{"a": [1, 2]}`), `{"a":[1,2]}`},
+		{"invalid json", oidJSON, []byte(`{`), `"{"`},
+		{"bytea", oidBytea, []byte(`\x00ff`), `{"type":"Buffer","data":[0,255]}`},
+		{"bytea in escape format", oidBytea, []byte(`abc`), `"abc"`},
+		{"int4 array", oidInt4Array, []byte("{1,NULL,3}"), `[1,null,3]`},
+		{"int8 array stays text", oidInt8Array, []byte("{1,2}"), `["1","2"]`},
+		{"text array with quoting", oidTextArray, []byte(`{"a b","c\"d",NULL,"NULL"}`), `["a b","c\"d",null,"NULL"]`},
+		{"nested array", oidInt4Array, []byte("{{1,2},{3,4}}"), `[[1,2],[3,4]]`},
+		{"array with bounds", oidTextArray, []byte("[0:1]={a,b}"), `["a","b"]`},
+		{"empty array", oidBoolArray, []byte("{}"), `[]`},
+		{"jsonb array", oidJSONBArray, []byte(`{"{\"a\":1}","[]"}`), `[{"a":1},[]]`},
+		{"malformed array stays text", oidInt4Array, []byte("{1,2"), `"{1,2"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(decodeValue(tt.oid, tt.raw))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("decodeValue(%d, %q) = %s, want %s", tt.oid, tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRowMarshalJSON(t *testing.T) {
+	// duplicate columns keep their first position and take the last value, like a JS object
+	row := Row{columns: []string{"id", "name", "id"}, values: []interface{}{int64(1), "a", int64(2)}}
+	got, err := json.Marshal(row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != `{"id":2,"name":"a"}` {
+		t.Errorf("json.Marshal(row) = %s", got)
+	}
+}
@@ -0,0 +1,103 @@
+package pgmeta
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// extensionsSQL lists available extensions and whether they are installed, as postgres-meta does
+const extensionsSQL = `
+SELECT
+  e.name,
+  n.nspname AS schema,
+  e.default_version,
+  x.extversion AS installed_version,
+  e.comment
+FROM
+  pg_available_extensions() e(name, default_version, comment)
+  LEFT JOIN pg_extension x ON e.name = x.extname
+  LEFT JOIN pg_namespace n ON x.extnamespace = n.oid
+`
+
+// ExtensionCreate is the body of a pg-meta extension enable request
+type ExtensionCreate struct {
+	Name    string  `json:"name" binding:"required"`
+	Schema  *string `json:"schema"`
+	Version *string `json:"version"`
+	Cascade bool    `json:"cascade"`
+}
+
+// ExtensionUpdate is the body of a pg-meta extension update request
+type ExtensionUpdate struct {
+	Update  bool    `json:"update"` // Update to Version, or the default version when it is nil
+	Version *string `json:"version"`
+	Schema  *string `json:"schema"`
+}
+
+// ListExtensions returns every available extension
+func ListExtensions(ctx context.Context, db Querier, opts ListOptions) ([]Row, error) {
+	sql := fmt.Sprintf("WITH extensions AS (%s) SELECT * FROM extensions ORDER BY name", extensionsSQL) + limitOffset(opts)
+	return Query(ctx, db, sql)
+}
+
+// RetrieveExtension returns an extension by name
+func RetrieveExtension(ctx context.Context, db Querier, name string) (Row, error) {
+	return queryOne(ctx, db, fmt.Sprintf("WITH extensions AS (%s) SELECT * FROM extensions WHERE name = $1", extensionsSQL), name)
+}
+
+// CreateExtension enables an extension and returns it
+func CreateExtension(ctx context.Context, pool *pgxpool.Pool, req ExtensionCreate) (Row, error) {
+	stmt := "CREATE EXTENSION " + ident(req.Name)
+	if req.Schema != nil {
+		stmt += " SCHEMA " + ident(*req.Schema)
+	}
+	if req.Version != nil {
+		stmt += " VERSION " + literal(*req.Version)
+	}
+	if req.Cascade {
+		stmt += " CASCADE"
+	}
+
+	return inTransaction(ctx, pool, []string{stmt}, func(tx pgx.Tx) (Row, error) {
+		return RetrieveExtension(ctx, tx, req.Name)
+	})
+}
+
+// UpdateExtension updates or moves an installed extension and returns it
+func UpdateExtension(ctx context.Context, pool *pgxpool.Pool, name string, req ExtensionUpdate) (Row, error) {
+	if _, err := RetrieveExtension(ctx, pool, name); err != nil {
+		return Row{}, err
+	}
+
+	statements := []string{}
+	if req.Update {
+		stmt := fmt.Sprintf("ALTER EXTENSION %s UPDATE", ident(name))
+		if req.Version != nil {
+			stmt += " TO " + literal(*req.Version)
+		}
+		statements = append(statements, stmt)
+	}
+	if req.Schema != nil {
+		statements = append(statements, fmt.Sprintf("ALTER EXTENSION %s SET SCHEMA %s", ident(name), ident(*req.Schema)))
+	}
+
+	return inTransaction(ctx, pool, statements, func(tx pgx.Tx) (Row, error) {
+		return RetrieveExtension(ctx, tx, name)
+	})
+}
+
+// DeleteExtension disables an extension and returns it as it was before
+func DeleteExtension(ctx context.Context, pool *pgxpool.Pool, name string, cascade bool) (Row, error) {
+	old, err := RetrieveExtension(ctx, pool, name)
+	if err != nil {
+		return Row{}, err
+	}
+
+	stmt := fmt.Sprintf("DROP EXTENSION %s %s", ident(name), dropBehavior(cascade))
+	return inTransaction(ctx, pool, []string{stmt}, func(pgx.Tx) (Row, error) {
+		return old, nil
+	})
+}
@@ -0,0 +1,274 @@
+package pgmeta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// functionsSQL lists plain functions (not procedures or aggregates) in the shape returned by postgres-meta
+const functionsSQL = `
+WITH procs AS (
+  SELECT
+    p.*,
+    -- proargmodes is null when all arguments are IN
+    coalesce(
+      p.proargmodes :: text[],
+      array_fill('i' :: text, ARRAY[cardinality(coalesce(p.proallargtypes, p.proargtypes :: oid[]))])
+    ) AS arg_modes,
+    -- proargnames is null when all arguments are unnamed
+    coalesce(
+      p.proargnames,
+      array_fill('' :: text, ARRAY[cardinality(coalesce(p.proallargtypes, p.proargtypes :: oid[]))])
+    ) AS arg_names,
+    -- proallargtypes is null when all arguments are IN
+    coalesce(p.proallargtypes, p.proargtypes :: oid[]) AS arg_types,
+    array_cat(
+      array_fill(false, ARRAY[p.pronargs - p.pronargdefaults]),
+      array_fill(true, ARRAY[p.pronargdefaults])
+    ) AS arg_has_defaults
+  FROM pg_proc p
+  WHERE p.prokind = 'f'
+)
+SELECT
+  f.oid :: int8 AS id,
+  n.nspname AS schema,
+  f.proname AS name,
+  l.lanname AS language,
+  CASE WHEN l.lanname = 'internal' THEN '' ELSE f.prosrc END AS definition,
+  CASE WHEN l.lanname = 'internal' THEN f.prosrc ELSE pg_get_functiondef(f.oid) END AS complete_statement,
+  coalesce(f_args.args, '[]') AS args,
+  pg_get_function_arguments(f.oid) AS argument_types,
+  pg_get_function_identity_arguments(f.oid) AS identity_argument_types,
+  f.prorettype :: int8 AS return_type_id,
+  pg_get_function_result(f.oid) AS return_type,
+  nullif(rt.typrelid :: int8, 0) AS return_type_relation_id,
+  f.proretset AS is_set_returning_function,
+  CASE
+    WHEN f.provolatile = 'i' THEN 'IMMUTABLE'
+    WHEN f.provolatile = 's' THEN 'STABLE'
+    WHEN f.provolatile = 'v' THEN 'VOLATILE'
+  END AS behavior,
+  f.prosecdef AS security_definer,
+  f_config.config_params
+FROM
+  procs f
+  LEFT JOIN pg_namespace n ON f.pronamespace = n.oid
+  LEFT JOIN pg_language l ON f.prolang = l.oid
+  LEFT JOIN pg_type rt ON rt.oid = f.prorettype
+  LEFT JOIN (
+    SELECT
+      oid,
+      jsonb_object_agg(param, value) FILTER (WHERE param IS NOT NULL) AS config_params
+    FROM (
+      SELECT
+        oid,
+        (string_to_array(unnest(proconfig), '='))[1] AS param,
+        (string_to_array(unnest(proconfig), '='))[2] AS value
+      FROM procs
+    ) AS t
+    GROUP BY oid
+  ) f_config ON f_config.oid = f.oid
+  LEFT JOIN (
+    SELECT
+      t1.oid,
+      jsonb_agg(
+        jsonb_build_object('mode', t2.mode, 'name', t1.name, 'type_id', t1.type_id, 'has_default', t1.has_default)
+      ) AS args
+    FROM (
+      SELECT
+        oid,
+        unnest(arg_modes) AS mode,
+        unnest(arg_names) AS name,
+        unnest(arg_types) :: int8 AS type_id,
+        unnest(arg_has_defaults) AS has_default
+      FROM procs
+    ) AS t1,
+    LATERAL (
+      SELECT
+        CASE
+          WHEN t1.mode = 'i' THEN 'in'
+          WHEN t1.mode = 'o' THEN 'out'
+          WHEN t1.mode = 'b' THEN 'inout'
+          WHEN t1.mode = 'v' THEN 'variadic'
+          ELSE 'table'
+        END AS mode
+    ) AS t2
+    GROUP BY t1.oid
+  ) f_args ON f_args.oid = f.oid
+`
+
+// FunctionCreate is the body of a pg-meta function create request
+type FunctionCreate struct {
+	Name            string            `json:"name" binding:"required"`
+	Schema          string            `json:"schema"`
+	Args            []string          `json:"args"` // e.g. ["a int4", "b text"]
+	Definition      string            `json:"definition" binding:"required"`
+	ReturnType      string            `json:"return_type"` // void when empty
+	Language        string            `json:"language"`    // sql when empty
+	Behavior        string            `json:"behavior"`    // VOLATILE when empty
+	SecurityDefiner bool              `json:"security_definer"`
+	ConfigParams    map[string]string `json:"config_params"`
+}
+
+// FunctionUpdate is the body of a pg-meta function update request, nil fields are left unchanged
+type FunctionUpdate struct {
+	Name       *string `json:"name"`
+	Schema     *string `json:"schema"`
+	Definition *string `json:"definition"`
+}
+
+// ListFunctions returns functions matching the options
+func ListFunctions(ctx context.Context, db Querier, opts ListOptions) ([]Row, error) {
+	sql := fmt.Sprintf("WITH functions AS (%s) SELECT * FROM functions WHERE %s", functionsSQL, schemaFilter("schema", opts)) + limitOffset(opts)
+	return Query(ctx, db, sql)
+}
+
+// RetrieveFunction returns a function by oid
+func RetrieveFunction(ctx context.Context, db Querier, id int64) (Row, error) {
+	return queryOne(ctx, db, fmt.Sprintf("WITH functions AS (%s) SELECT * FROM functions WHERE id = $1", functionsSQL), id)
+}
+
+// retrieveNewestFunction finds a function that was just created, overloads share the
+// name so the one with the highest oid is taken
+func retrieveNewestFunction(ctx context.Context, db Querier, schema, name string) (Row, error) {
+	return queryOne(ctx, db, fmt.Sprintf("WITH functions AS (%s) SELECT * FROM functions WHERE schema = $1 AND name = $2 ORDER BY id DESC LIMIT 1", functionsSQL), schema, name)
+}
+
+// CreateFunction creates a function and returns it
+func CreateFunction(ctx context.Context, pool *pgxpool.Pool, req FunctionCreate) (Row, error) {
+	if req.Schema == "" {
+		req.Schema = "public"
+	}
+	if req.ReturnType == "" {
+		req.ReturnType = "void"
+	}
+	if req.Language == "" {
+		req.Language = "sql"
+	}
+	stmt, err := createFunctionSQL(req, false)
+	if err != nil {
+		return Row{}, err
+	}
+
+	return inTransaction(ctx, pool, []string{stmt}, func(tx pgx.Tx) (Row, error) {
+		return retrieveNewestFunction(ctx, tx, req.Schema, req.Name)
+	})
+}
+
+// UpdateFunction replaces the body, renames or moves a function and returns it
+func UpdateFunction(ctx context.Context, pool *pgxpool.Pool, id int64, req FunctionUpdate) (Row, error) {
+	old, err := RetrieveFunction(ctx, pool, id)
+	if err != nil {
+		return Row{}, err
+	}
+	signature := fmt.Sprintf("%s(%s)", ident(old.String("schema"), old.String("name")), old.String("identity_argument_types"))
+
+	statements := []string{}
+	if req.Definition != nil {
+		current := FunctionCreate{
+			Name:            old.String("name"),
+			Schema:          old.String("schema"),
+			Definition:      *req.Definition,
+			ReturnType:      old.String("return_type"),
+			Language:        old.String("language"),
+			Behavior:        old.String("behavior"),
+			SecurityDefiner: old.Bool("security_definer"),
+		}
+		if args := old.String("argument_types"); args != "" {
+			current.Args = []string{args}
+		}
+		if raw, ok := old.Get("config_params"); ok && raw != nil {
+			if encoded, ok := raw.(json.RawMessage); ok {
+				if err := json.Unmarshal(encoded, &current.ConfigParams); err != nil {
+					return Row{}, err
+				}
+			}
+		}
+		stmt, err := createFunctionSQL(current, true)
+		if err != nil {
+			return Row{}, err
+		}
+		statements = append(statements, stmt)
+	}
+	newSchema := old.String("schema")
+	if req.Schema != nil && *req.Schema != newSchema {
+		statements = append(statements, fmt.Sprintf("ALTER FUNCTION %s SET SCHEMA %s", signature, ident(*req.Schema)))
+		newSchema = *req.Schema
+		signature = fmt.Sprintf("%s(%s)", ident(newSchema, old.String("name")), old.String("identity_argument_types"))
+	}
+	if req.Name != nil && *req.Name != old.String("name") {
+		statements = append(statements, fmt.Sprintf("ALTER FUNCTION %s RENAME TO %s", signature, ident(*req.Name)))
+	}
+
+	return inTransaction(ctx, pool, statements, func(tx pgx.Tx) (Row, error) {
+		return RetrieveFunction(ctx, tx, id)
+	})
+}
+
+// DeleteFunction drops a function and returns it as it was before the drop
+func DeleteFunction(ctx context.Context, pool *pgxpool.Pool, id int64, cascade bool) (Row, error) {
+	old, err := RetrieveFunction(ctx, pool, id)
+	if err != nil {
+		return Row{}, err
+	}
+
+	stmt := fmt.Sprintf("DROP FUNCTION %s(%s) %s",
+		ident(old.String("schema"), old.String("name")), old.String("identity_argument_types"), dropBehavior(cascade))
+	return inTransaction(ctx, pool, []string{stmt}, func(pgx.Tx) (Row, error) {
+		return old, nil
+	})
+}
+
+func createFunctionSQL(req FunctionCreate, replace bool) (string, error) {
+	behavior := strings.ToUpper(req.Behavior)
+	switch behavior {
+	case "":
+		behavior = "VOLATILE"
+	case "IMMUTABLE", "STABLE", "VOLATILE":
+	default:
+		return "", fmt.Errorf("%w: unknown behavior %q", ErrInvalidRequest, req.Behavior)
+	}
+	security := "SECURITY INVOKER"
+	if req.SecurityDefiner {
+		security = "SECURITY DEFINER"
+	}
+
+	var b strings.Builder
+	b.WriteString("CREATE ")
+	if replace {
+		b.WriteString("OR REPLACE ")
+	}
+	fmt.Fprintf(&b, "FUNCTION %s(%s)\n", ident(req.Schema, req.Name), strings.Join(req.Args, ", "))
+	fmt.Fprintf(&b, "RETURNS %s\n", req.ReturnType)
+	fmt.Fprintf(&b, "LANGUAGE %s\n", ident(req.Language))
+	fmt.Fprintf(&b, "%s\n%s\n", behavior, security)
+
+	params := make([]string, 0, len(req.ConfigParams))
+	for param := range req.ConfigParams {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+	for _, param := range params {
+		b.WriteString(configClause(param, req.ConfigParams[param]) + "\n")
+	}
+	fmt.Fprintf(&b, "AS %s", literal(req.Definition))
+	return b.String(), nil
+}
+
+// configClause renders a SET clause, lists such as search_path are kept verbatim
+func configClause(param string, value string) string {
+	switch {
+	case strings.EqualFold(value, "FROM CURRENT"):
+		return fmt.Sprintf("SET %s FROM CURRENT", ident(param))
+	case strings.Contains(value, ","):
+		return fmt.Sprintf("SET %s TO %s", ident(param), value)
+	default:
+		return fmt.Sprintf("SET %s TO %s", ident(param), literal(value))
+	}
+}
@@ -0,0 +1,66 @@
+package pgmeta
+
+import (
+	"context"
+	"fmt"
+)
+
+// indexesSQL lists indexes in the shape returned by postgres-meta, which only exposes them read-only
+const indexesSQL = `
+SELECT
+  idx.indexrelid :: int8 AS id,
+  idx.indrelid :: int8 AS table_id,
+  n.nspname AS schema,
+  idx.indnatts AS number_of_attributes,
+  idx.indnkeyatts AS number_of_key_attributes,
+  idx.indisunique AS is_unique,
+  idx.indisprimary AS is_primary,
+  idx.indisexclusion AS is_exclusion,
+  idx.indimmediate AS is_immediate,
+  idx.indisclustered AS is_clustered,
+  idx.indisvalid AS is_valid,
+  idx.indcheckxmin AS check_xmin,
+  idx.indisready AS is_ready,
+  idx.indislive AS is_live,
+  idx.indisreplident AS is_replica_identity,
+  idx.indkey :: int2[] AS key_attributes,
+  idx.indcollation :: oid[] AS collation,
+  idx.indclass :: oid[] AS class,
+  idx.indoption :: int2[] AS options,
+  pg_get_expr(idx.indpred, idx.indrelid) AS index_predicate,
+  obj_description(idx.indexrelid, 'pg_class') AS comment,
+  ix.indexdef AS index_definition,
+  am.amname AS access_method,
+  coalesce(
+    (
+      SELECT jsonb_agg(
+        jsonb_build_object(
+          'attribute_number', a.attnum,
+          'attribute_name', a.attname,
+          'data_type', format_type(a.atttypid, a.atttypmod)
+        )
+        ORDER BY a.attnum
+      )
+      FROM pg_attribute a
+      WHERE a.attrelid = idx.indrelid AND a.attnum = ANY (idx.indkey)
+    ),
+    '[]'
+  ) AS index_attributes
+FROM
+  pg_index idx
+  JOIN pg_class c ON c.oid = idx.indexrelid
+  JOIN pg_namespace n ON c.relnamespace = n.oid
+  JOIN pg_indexes ix ON c.relname = ix.indexname AND n.nspname = ix.schemaname
+  JOIN pg_am am ON c.relam = am.oid
+`
+
+// ListIndexes returns indexes matching the options
+func ListIndexes(ctx context.Context, db Querier, opts ListOptions) ([]Row, error) {
+	sql := fmt.Sprintf("WITH indexes AS (%s) SELECT * FROM indexes WHERE %s", indexesSQL, schemaFilter("schema", opts)) + limitOffset(opts)
+	return Query(ctx, db, sql)
+}
+
+// RetrieveIndex returns an index by oid
+func RetrieveIndex(ctx context.Context, db Querier, id int64) (Row, error) {
+	return queryOne(ctx, db, fmt.Sprintf("WITH indexes AS (%s) SELECT * FROM indexes WHERE id = $1", indexesSQL), id)
+}
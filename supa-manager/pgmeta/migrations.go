@@ -0,0 +1,244 @@
+package pgmeta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrMigrationExists is returned when a migration with the same version was already applied
+var ErrMigrationExists = errors.New("migration already applied")
+
+// migrationsTableSQL creates the history table used by the Supabase CLI, adding our
+// bookkeeping columns to tables the CLI created earlier
+var migrationsTableSQL = []string{
+	"CREATE SCHEMA IF NOT EXISTS supabase_migrations",
+	"CREATE TABLE IF NOT EXISTS supabase_migrations.schema_migrations (version text NOT NULL PRIMARY KEY)",
+	"ALTER TABLE supabase_migrations.schema_migrations ADD COLUMN IF NOT EXISTS statements text[]",
+	"ALTER TABLE supabase_migrations.schema_migrations ADD COLUMN IF NOT EXISTS name text",
+	"ALTER TABLE supabase_migrations.schema_migrations ADD COLUMN IF NOT EXISTS created_by text",
+	"ALTER TABLE supabase_migrations.schema_migrations ADD COLUMN IF NOT EXISTS applied_at timestamptz DEFAULT now()",
+}
+
+// migrationsLockKey serialises migration runs on a project database
+const migrationsLockKey = "supabase_migrations"
+
+var migrationVersionPattern = regexp.MustCompile(`^[0-9]+$`)
+
+// Migration is a versioned set of statements, versions sort numerically like the CLI's timestamps
+type Migration struct {
+	Version    string   `json:"version"`
+	Name       string   `json:"name"`
+	Statements []string `json:"statements"`
+}
+
+// AppliedMigration is a row of supabase_migrations.schema_migrations
+type AppliedMigration struct {
+	Version    string     `json:"version"`
+	Name       *string    `json:"name"`
+	Statements []string   `json:"statements"`
+	CreatedBy  *string    `json:"created_by"`
+	AppliedAt  *time.Time `json:"applied_at"`
+}
+
+// MigrationMismatch is a version present on both sides with different statements
+type MigrationMismatch struct {
+	Version string           `json:"version"`
+	Target  Migration        `json:"target"`
+	Applied AppliedMigration `json:"applied"`
+}
+
+// MigrationDiff compares the applied history with a target list of migrations
+type MigrationDiff struct {
+	Pending    []Migration         `json:"pending"`      // In the target, not applied yet
+	Unknown    []AppliedMigration  `json:"unknown"`      // Applied, but not in the target
+	Mismatched []MigrationMismatch `json:"mismatched"`   // Applied with different statements
+	OutOfOrder []string            `json:"out_of_order"` // Pending versions older than the latest applied one
+}
+
+// NewMigrationVersion returns a version in the CLI's timestamp format
+func NewMigrationVersion(now time.Time) string {
+	return now.UTC().Format("20060102150405")
+}
+
+// ValidateMigration checks that a migration can be recorded
+func ValidateMigration(m Migration) error {
+	if !migrationVersionPattern.MatchString(m.Version) {
+		return fmt.Errorf("%w: version must only contain digits", ErrInvalidRequest)
+	}
+	if len(m.Statements) == 0 {
+		return fmt.Errorf("%w: a migration needs at least one statement", ErrInvalidRequest)
+	}
+	for _, stmt := range m.Statements {
+		if strings.TrimSpace(stmt) == "" {
+			return fmt.Errorf("%w: statements must not be empty", ErrInvalidRequest)
+		}
+	}
+	return nil
+}
+
+// ListMigrations returns the applied history ordered by version, empty when no migration was ever applied
+func ListMigrations(ctx context.Context, db Querier) ([]AppliedMigration, error) {
+	rows, err := db.Query(ctx, "SELECT to_regclass('supabase_migrations.schema_migrations') IS NOT NULL")
+	if err != nil {
+		return nil, wrapError(err, "")
+	}
+	exists, err := pgx.CollectExactlyOneRow(rows, pgx.RowTo[bool])
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return []AppliedMigration{}, nil
+	}
+
+	return queryMigrations(ctx, db)
+}
+
+func queryMigrations(ctx context.Context, db Querier) ([]AppliedMigration, error) {
+	// The CLI's own table may lack our bookkeeping columns, so read the row as JSON
+	sql := `SELECT to_jsonb(m) FROM supabase_migrations.schema_migrations m ORDER BY length(version), version`
+	rows, err := db.Query(ctx, sql)
+	if err != nil {
+		return nil, wrapError(err, sql)
+	}
+	migrations, err := pgx.CollectRows(rows, pgx.RowTo[AppliedMigration])
+	if err != nil {
+		return nil, err
+	}
+	return migrations, nil
+}
+
+// ApplyMigration runs the statements of a migration and records it in one transaction,
+// so a failing statement leaves neither schema changes nor a history entry behind
+func ApplyMigration(ctx context.Context, pool *pgxpool.Pool, m Migration, createdBy string) (AppliedMigration, error) {
+	if err := ValidateMigration(m); err != nil {
+		return AppliedMigration{}, err
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return AppliedMigration{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", migrationsLockKey); err != nil {
+		return AppliedMigration{}, wrapError(err, "")
+	}
+	for _, stmt := range migrationsTableSQL {
+		if _, err := tx.Exec(ctx, stmt); err != nil {
+			return AppliedMigration{}, wrapError(err, stmt)
+		}
+	}
+
+	var exists bool
+	if err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM supabase_migrations.schema_migrations WHERE version = $1)", m.Version).Scan(&exists); err != nil {
+		return AppliedMigration{}, wrapError(err, "")
+	}
+	if exists {
+		return AppliedMigration{}, ErrMigrationExists
+	}
+
+	for _, stmt := range m.Statements {
+		// Without arguments pgx uses the simple protocol, so a statement may contain several commands
+		if _, err := tx.Exec(ctx, stmt); err != nil {
+			return AppliedMigration{}, wrapError(err, stmt)
+		}
+	}
+
+	var applied AppliedMigration
+	err = tx.QueryRow(ctx, `
+INSERT INTO supabase_migrations.schema_migrations (version, name, statements, created_by)
+VALUES ($1, $2, $3, $4)
+RETURNING version, name, statements, created_by, applied_at`,
+		m.Version, m.Name, m.Statements, createdBy,
+	).Scan(&applied.Version, &applied.Name, &applied.Statements, &applied.CreatedBy, &applied.AppliedAt)
+	if err != nil {
+		return AppliedMigration{}, wrapError(err, "")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return AppliedMigration{}, wrapError(err, "COMMIT")
+	}
+	return applied, nil
+}
+
+// DiffMigrations compares the applied history with the target migrations
+func DiffMigrations(ctx context.Context, db Querier, target []Migration) (MigrationDiff, error) {
+	applied, err := ListMigrations(ctx, db)
+	if err != nil {
+		return MigrationDiff{}, err
+	}
+
+	diff := MigrationDiff{
+		Pending:    []Migration{},
+		Unknown:    []AppliedMigration{},
+		Mismatched: []MigrationMismatch{},
+		OutOfOrder: []string{},
+	}
+
+	appliedByVersion := make(map[string]AppliedMigration, len(applied))
+	latest := ""
+	for _, m := range applied {
+		appliedByVersion[m.Version] = m
+		if compareVersions(m.Version, latest) > 0 {
+			latest = m.Version
+		}
+	}
+
+	targetVersions := make(map[string]bool, len(target))
+	for _, m := range target {
+		targetVersions[m.Version] = true
+		existing, ok := appliedByVersion[m.Version]
+		if !ok {
+			diff.Pending = append(diff.Pending, m)
+			if compareVersions(m.Version, latest) < 0 {
+				diff.OutOfOrder = append(diff.OutOfOrder, m.Version)
+			}
+			continue
+		}
+		if !sameStatements(existing.Statements, m.Statements) {
+			diff.Mismatched = append(diff.Mismatched, MigrationMismatch{Version: m.Version, Target: m, Applied: existing})
+		}
+	}
+	for _, m := range applied {
+		if !targetVersions[m.Version] {
+			diff.Unknown = append(diff.Unknown, m)
+		}
+	}
+
+	sort.Slice(diff.Pending, func(i, j int) bool {
+		return compareVersions(diff.Pending[i].Version, diff.Pending[j].Version) < 0
+	})
+	return diff, nil
+}
+
+// compareVersions orders numeric versions of different lengths correctly
+func compareVersions(a, b string) int {
+	a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func sameStatements(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if strings.TrimSpace(a[i]) != strings.TrimSpace(b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,180 @@
+package pgmeta
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// policiesSQL lists row level security policies in the shape returned by postgres-meta
+const policiesSQL = `
+SELECT
+  pol.oid :: int8 AS id,
+  n.nspname AS schema,
+  c.relname AS table,
+  c.oid :: int8 AS table_id,
+  pol.polname AS name,
+  CASE
+    WHEN pol.polpermissive THEN 'PERMISSIVE' :: text
+    ELSE 'RESTRICTIVE' :: text
+  END AS action,
+  CASE
+    WHEN pol.polroles = '{0}' :: oid[] THEN array_to_json(string_to_array('public' :: text, '' :: text) :: name[])
+    ELSE array_to_json(
+      ARRAY(
+        SELECT pg_roles.rolname
+        FROM pg_roles
+        WHERE pg_roles.oid = ANY (pol.polroles)
+        ORDER BY pg_roles.rolname
+      )
+    )
+  END AS roles,
+  CASE pol.polcmd
+    WHEN 'r' THEN 'SELECT' :: text
+    WHEN 'a' THEN 'INSERT' :: text
+    WHEN 'w' THEN 'UPDATE' :: text
+    WHEN 'd' THEN 'DELETE' :: text
+    WHEN '*' THEN 'ALL' :: text
+    ELSE NULL :: text
+  END AS command,
+  pg_get_expr(pol.polqual, pol.polrelid) AS definition,
+  pg_get_expr(pol.polwithcheck, pol.polrelid) AS check
+FROM
+  pg_policy pol
+  JOIN pg_class c ON c.oid = pol.polrelid
+  LEFT JOIN pg_namespace n ON n.oid = c.relnamespace
+`
+
+// PolicyCreate is the body of a pg-meta policy create request
+type PolicyCreate struct {
+	Name       string   `json:"name" binding:"required"`
+	Table      string   `json:"table" binding:"required"`
+	Schema     string   `json:"schema"`
+	Definition *string  `json:"definition"`
+	Check      *string  `json:"check"`
+	Action     string   `json:"action"`  // PERMISSIVE (default) or RESTRICTIVE
+	Command    string   `json:"command"` // ALL (default), SELECT, INSERT, UPDATE or DELETE
+	Roles      []string `json:"roles"`
+}
+
+// PolicyUpdate is the body of a pg-meta policy update request, nil fields are left unchanged
+type PolicyUpdate struct {
+	Name       *string  `json:"name"`
+	Definition *string  `json:"definition"`
+	Check      *string  `json:"check"`
+	Roles      []string `json:"roles"`
+}
+
+// ListPolicies returns policies matching the options
+func ListPolicies(ctx context.Context, db Querier, opts ListOptions) ([]Row, error) {
+	sql := fmt.Sprintf("WITH policies AS (%s) SELECT * FROM policies WHERE %s", policiesSQL, schemaFilter("schema", opts)) + limitOffset(opts)
+	return Query(ctx, db, sql)
+}
+
+// RetrievePolicy returns a policy by oid
+func RetrievePolicy(ctx context.Context, db Querier, id int64) (Row, error) {
+	return queryOne(ctx, db, fmt.Sprintf("WITH policies AS (%s) SELECT * FROM policies WHERE id = $1", policiesSQL), id)
+}
+
+func retrievePolicyByName(ctx context.Context, db Querier, schema, table, name string) (Row, error) {
+	return queryOne(ctx, db, fmt.Sprintf("WITH policies AS (%s) SELECT * FROM policies WHERE schema = $1 AND \"table\" = $2 AND name = $3", policiesSQL), schema, table, name)
+}
+
+// CreatePolicy creates a row level security policy and returns it
+func CreatePolicy(ctx context.Context, pool *pgxpool.Pool, req PolicyCreate) (Row, error) {
+	schema := req.Schema
+	if schema == "" {
+		schema = "public"
+	}
+	action := strings.ToUpper(req.Action)
+	switch action {
+	case "":
+		action = "PERMISSIVE"
+	case "PERMISSIVE", "RESTRICTIVE":
+	default:
+		return Row{}, fmt.Errorf("%w: unknown action %q", ErrInvalidRequest, req.Action)
+	}
+	command := strings.ToUpper(req.Command)
+	switch command {
+	case "":
+		command = "ALL"
+	case "ALL", "SELECT", "INSERT", "UPDATE", "DELETE":
+	default:
+		return Row{}, fmt.Errorf("%w: unknown command %q", ErrInvalidRequest, req.Command)
+	}
+	roles := req.Roles
+	if len(roles) == 0 {
+		roles = []string{"public"}
+	}
+
+	stmt := fmt.Sprintf("CREATE POLICY %s ON %s AS %s FOR %s TO %s",
+		ident(req.Name), ident(schema, req.Table), action, command, roleList(roles))
+	if req.Definition != nil && *req.Definition != "" {
+		stmt += fmt.Sprintf(" USING (%s)", *req.Definition)
+	}
+	if req.Check != nil && *req.Check != "" {
+		stmt += fmt.Sprintf(" WITH CHECK (%s)", *req.Check)
+	}
+
+	return inTransaction(ctx, pool, []string{stmt}, func(tx pgx.Tx) (Row, error) {
+		return retrievePolicyByName(ctx, tx, schema, req.Table, req.Name)
+	})
+}
+
+// UpdatePolicy applies the requested changes in one transaction and returns the updated policy
+func UpdatePolicy(ctx context.Context, pool *pgxpool.Pool, id int64, req PolicyUpdate) (Row, error) {
+	old, err := RetrievePolicy(ctx, pool, id)
+	if err != nil {
+		return Row{}, err
+	}
+	table := ident(old.String("schema"), old.String("table"))
+	alter := fmt.Sprintf("ALTER POLICY %s ON %s", ident(old.String("name")), table)
+
+	statements := []string{}
+	if req.Definition != nil {
+		statements = append(statements, fmt.Sprintf("%s USING (%s)", alter, *req.Definition))
+	}
+	if req.Check != nil {
+		statements = append(statements, fmt.Sprintf("%s WITH CHECK (%s)", alter, *req.Check))
+	}
+	if req.Roles != nil {
+		statements = append(statements, fmt.Sprintf("%s TO %s", alter, roleList(req.Roles)))
+	}
+	if req.Name != nil && *req.Name != old.String("name") {
+		statements = append(statements, fmt.Sprintf("%s RENAME TO %s", alter, ident(*req.Name)))
+	}
+
+	return inTransaction(ctx, pool, statements, func(tx pgx.Tx) (Row, error) {
+		return RetrievePolicy(ctx, tx, id)
+	})
+}
+
+// DeletePolicy drops a policy and returns it as it was before the drop
+func DeletePolicy(ctx context.Context, pool *pgxpool.Pool, id int64) (Row, error) {
+	old, err := RetrievePolicy(ctx, pool, id)
+	if err != nil {
+		return Row{}, err
+	}
+
+	stmt := fmt.Sprintf("DROP POLICY %s ON %s", ident(old.String("name")), ident(old.String("schema"), old.String("table")))
+	return inTransaction(ctx, pool, []string{stmt}, func(pgx.Tx) (Row, error) {
+		return old, nil
+	})
+}
+
+// roleList renders role specifications, keeping the pseudo roles unquoted
+func roleList(roles []string) string {
+	specs := make([]string, len(roles))
+	for i, role := range roles {
+		switch strings.ToLower(role) {
+		case "public", "current_user", "current_role", "session_user":
+			specs[i] = strings.ToUpper(role)
+		default:
+			specs[i] = ident(role)
+		}
+	}
+	return strings.Join(specs, ", ")
+}
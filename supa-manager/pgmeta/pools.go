@@ -0,0 +1,137 @@
+package pgmeta
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Target describes how to reach a project's Postgres
+type Target struct {
+	ProjectRef string
+	Host       string
+	Port       int
+	User       string
+	Password   string
+	Database   string
+}
+
+func (t Target) connString() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=prefer application_name=supa-manager",
+		quoteConnValue(t.Host), t.Port, quoteConnValue(t.User), quoteConnValue(t.Password), quoteConnValue(t.Database))
+}
+
+// PoolSettings controls the per-project connection pools
+type PoolSettings struct {
+	MaxConns         int32
+	StatementTimeout time.Duration
+	IdleTimeout      time.Duration // Pools unused for this long are closed
+}
+
+type poolEntry struct {
+	pool     *pgxpool.Pool
+	connStr  string
+	lastUsed time.Time
+}
+
+// Pools caches one pgx pool per project so that Studio requests do not
+// pay the connection setup cost on every query
+type Pools struct {
+	settings PoolSettings
+
+	mu    sync.Mutex
+	pools map[string]*poolEntry
+}
+
+// NewPools creates an empty pool cache and starts the idle reaper
+func NewPools(settings PoolSettings) *Pools {
+	p := &Pools{
+		settings: settings,
+		pools:    make(map[string]*poolEntry),
+	}
+	if settings.IdleTimeout > 0 {
+		go p.reapIdle()
+	}
+	return p
+}
+
+// Get returns the pool for the target, creating it if needed.
+// A pool is recreated when the target's connection details change.
+func (p *Pools) Get(ctx context.Context, target Target) (*pgxpool.Pool, error) {
+	connStr := target.connString()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if entry, ok := p.pools[target.ProjectRef]; ok {
+		if entry.connStr == connStr {
+			entry.lastUsed = time.Now()
+			return entry.pool, nil
+		}
+		entry.pool.Close()
+		delete(p.pools, target.ProjectRef)
+	}
+
+	config, err := pgxpool.ParseConfig(connStr)
+	if err != nil {
+		return nil, err
+	}
+	config.MaxConns = p.settings.MaxConns
+	if p.settings.StatementTimeout > 0 {
+		config.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(p.settings.StatementTimeout.Milliseconds(), 10)
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	p.pools[target.ProjectRef] = &poolEntry{
+		pool:     pool,
+		connStr:  connStr,
+		lastUsed: time.Now(),
+	}
+	return pool, nil
+}
+
+// Evict closes and forgets the pool for a project, e.g. after its password changed or it was deleted
+func (p *Pools) Evict(projectRef string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if entry, ok := p.pools[projectRef]; ok {
+		entry.pool.Close()
+		delete(p.pools, projectRef)
+	}
+}
+
+func (p *Pools) reapIdle() {
+	ticker := time.NewTicker(p.settings.IdleTimeout / 2)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		p.mu.Lock()
+		for ref, entry := range p.pools {
+			if time.Since(entry.lastUsed) > p.settings.IdleTimeout {
+				entry.pool.Close()
+				delete(p.pools, ref)
+			}
+		}
+		p.mu.Unlock()
+	}
+}
+
+func quoteConnValue(v string) string {
+	escaped := ""
+	for _, r := range v {
+		if r == '\'' || r == '\\' {
+			escaped += "\\"
+		}
+		escaped += string(r)
+	}
+	return "'" + escaped + "'"
+}
@@ -0,0 +1,425 @@
+package pgmeta
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Row is a single result row that keeps the column order of the query when
+// encoded to JSON, like the objects returned by node-postgres
+type Row struct {
+	columns []string
+	values  []interface{}
+}
+
+// Get returns the value of the named column, the last one wins on duplicates
+func (r Row) Get(column string) (interface{}, bool) {
+	for i := len(r.columns) - 1; i >= 0; i-- {
+		if r.columns[i] == column {
+			return r.values[i], true
+		}
+	}
+	return nil, false
+}
+
+// String returns a text column, or "" when it is missing or NULL
+func (r Row) String(column string) string {
+	v, _ := r.Get(column)
+	s, _ := v.(string)
+	return s
+}
+
+// Int returns an integer column, or 0 when it is missing or NULL
+func (r Row) Int(column string) int64 {
+	v, _ := r.Get(column)
+	n, _ := v.(int64)
+	return n
+}
+
+// Bool returns a boolean column, or false when it is missing or NULL
+func (r Row) Bool(column string) bool {
+	v, _ := r.Get(column)
+	b, _ := v.(bool)
+	return b
+}
+
+func (r Row) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	written := 0
+	for i, column := range r.columns {
+		// Duplicate column names keep their first position but take the last value, as in a JS object
+		duplicate := false
+		for _, earlier := range r.columns[:i] {
+			if earlier == column {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		if written > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(column)
+		buf.Write(key)
+		buf.WriteByte(':')
+		last, _ := r.Get(column)
+		value, err := json.Marshal(last)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+		written++
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Result is the outcome of an executed query
+type Result struct {
+	Rows      []Row
+	Truncated bool // More rows were returned than the configured limit
+}
+
+// QueryError is a Postgres error enriched with the fields pg-meta returns to Studio
+type QueryError struct {
+	*pgconn.PgError
+	FormattedError string
+}
+
+func (e *QueryError) Error() string {
+	return e.PgError.Error()
+}
+
+// MarshalJSON renders the error the way postgres-meta does for failed queries
+func (e *QueryError) MarshalJSON() ([]byte, error) {
+	body := map[string]interface{}{
+		"message":        e.Message,
+		"error":          e.Message,
+		"formattedError": e.FormattedError,
+		"severity":       e.Severity,
+		"code":           e.Code,
+		"length":         len(e.Message),
+		"name":           "error",
+	}
+	optional := map[string]string{
+		"detail":     e.Detail,
+		"hint":       e.Hint,
+		"where":      e.Where,
+		"schema":     e.SchemaName,
+		"table":      e.TableName,
+		"column":     e.ColumnName,
+		"dataType":   e.DataTypeName,
+		"constraint": e.ConstraintName,
+		"file":       e.File,
+		"routine":    e.Routine,
+	}
+	for k, v := range optional {
+		if v != "" {
+			body[k] = v
+		}
+	}
+	if e.Position > 0 {
+		body["position"] = fmt.Sprintf("%d", e.Position)
+	}
+	if e.InternalPosition > 0 {
+		body["internalPosition"] = fmt.Sprintf("%d", e.InternalPosition)
+		body["internalQuery"] = e.InternalQuery
+	}
+	if e.Line > 0 {
+		body["line"] = fmt.Sprintf("%d", e.Line)
+	}
+	return json.Marshal(body)
+}
+
+// Execute runs sql (which may contain several statements) over the simple protocol
+// and returns the rows of the last statement that produced any, matching pg-meta.
+// At most maxRows rows are kept, maxRows <= 0 disables the limit. A single read-only
+// statement is cancelled once it reaches the limit so the server stops producing rows,
+// the rest of a batch still runs and the rows past the limit are discarded.
+func Execute(ctx context.Context, pool *pgxpool.Pool, sql string, maxRows int) (*Result, error) {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	pgConn := conn.Conn().PgConn()
+	cancellable := readOnlyStatement(sql)
+	cancelled := false
+	result := &Result{Rows: []Row{}}
+	mrr := pgConn.Exec(ctx, sql)
+	for mrr.NextResult() {
+		rr := mrr.ResultReader()
+		fields := rr.FieldDescriptions()
+		columns := make([]string, len(fields))
+		for i, f := range fields {
+			columns[i] = f.Name
+		}
+
+		rows := []Row{}
+		truncated := false
+		for rr.NextRow() {
+			if maxRows > 0 && len(rows) >= maxRows {
+				truncated = true
+				if !cancellable {
+					continue
+				}
+				// Cancelling rolls back nothing, the statement only reads. The rows already in
+				// flight are read until the server reports the cancellation
+				if !cancelled {
+					cancelled = true
+					if err := pgConn.CancelRequest(ctx); err != nil {
+						cancellable = false
+					}
+				}
+				continue
+			}
+			raw := rr.Values()
+			values := make([]interface{}, len(raw))
+			for i, v := range raw {
+				values[i] = decodeValue(fields[i].DataTypeOID, v)
+			}
+			rows = append(rows, Row{columns: columns, values: values})
+		}
+		if _, err := rr.Close(); err != nil && !(cancelled && isQueryCanceled(err)) {
+			mrr.Close()
+			return nil, wrapError(err, sql)
+		}
+
+		if len(rows) > 0 {
+			result.Rows = rows
+			result.Truncated = truncated
+		}
+	}
+	if err := mrr.Close(); err != nil && !(cancelled && isQueryCanceled(err)) {
+		return nil, wrapError(err, sql)
+	}
+	return result, nil
+}
+
+func isQueryCanceled(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "57014"
+}
+
+// readOnlyStatement reports whether sql is a single SELECT, TABLE or VALUES statement. Comments,
+// quoted identifiers, string constants and dollar quoting are skipped when looking for a second
+// statement
+func readOnlyStatement(sql string) bool {
+	rest := skipSpaceAndComments(sql)
+	end := strings.IndexFunc(rest, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+	})
+	if end < 0 {
+		end = len(rest)
+	}
+	switch strings.ToUpper(rest[:end]) {
+	case "SELECT", "TABLE", "VALUES":
+	default:
+		return false
+	}
+
+	for i := 0; i < len(rest); {
+		switch {
+		case rest[i] == ';':
+			return skipSpaceAndComments(strings.TrimLeft(rest[i+1:], ";")) == ""
+		case rest[i] == '\'' || rest[i] == '"':
+			escapes := rest[i] == '\'' && i > 0 && (rest[i-1] == 'E' || rest[i-1] == 'e') && (i == 1 || !identifierByte(rest[i-2]))
+			closing := quotedEnd(rest[i+1:], rest[i], escapes)
+			if closing < 0 {
+				return false
+			}
+			// Doubled quotes escape themselves and are read as two quoted strings
+			i += closing + 2
+		case rest[i] == '$':
+			tag, ok := dollarQuoteTag(rest[i:])
+			if !ok || (i > 0 && identifierByte(rest[i-1])) {
+				i++
+				continue
+			}
+			closing := strings.Index(rest[i+len(tag):], tag)
+			if closing < 0 {
+				return false
+			}
+			i += len(tag) + closing + len(tag)
+		case strings.HasPrefix(rest[i:], "--") || strings.HasPrefix(rest[i:], "/*"):
+			skipped := skipSpaceAndComments(rest[i:])
+			if len(skipped) == len(rest[i:]) {
+				return false
+			}
+			i = len(rest) - len(skipped)
+		default:
+			i++
+		}
+	}
+	return true
+}
+
+// quotedEnd returns the index of the quote closing a quoted string, backslashes escape the next
+// character in E'...' strings
+func quotedEnd(sql string, quote byte, escapes bool) int {
+	for i := 0; i < len(sql); i++ {
+		switch {
+		case escapes && sql[i] == '\\':
+			i++
+		case sql[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+func identifierByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// skipSpaceAndComments drops leading white space, line comments and block comments. An
+// unterminated block comment is kept
+func skipSpaceAndComments(sql string) string {
+	for {
+		sql = strings.TrimLeft(sql, " \t\r\n\f")
+		switch {
+		case strings.HasPrefix(sql, "--"):
+			end := strings.IndexByte(sql, '\n')
+			if end < 0 {
+				return ""
+			}
+			sql = sql[end+1:]
+		case strings.HasPrefix(sql, "/*"):
+			end := blockCommentEnd(sql)
+			if end < 0 {
+				return sql
+			}
+			sql = sql[end:]
+		default:
+			return sql
+		}
+	}
+}
+
+// blockCommentEnd returns the length of the block comment sql starts with, block comments nest
+func blockCommentEnd(sql string) int {
+	depth := 0
+	for i := 0; i+1 < len(sql); i++ {
+		switch sql[i : i+2] {
+		case "/*":
+			depth++
+			i++
+		case "*/":
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
+// dollarQuoteTag returns the $tag$ sql starts with, if it starts a dollar quoted string
+func dollarQuoteTag(sql string) (string, bool) {
+	for i := 1; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '$':
+			return sql[:i+1], true
+		case identifierByte(c) && (i > 1 || c < '0' || c > '9'):
+		default:
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// Querier is satisfied by both connection pools and transactions
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}
+
+// Query runs a single parameterised statement and returns rows decoded the same way as Execute
+func Query(ctx context.Context, db Querier, sql string, args ...interface{}) ([]Row, error) {
+	// Ask for text results so values decode exactly like the simple protocol path
+	rows, err := db.Query(ctx, sql, append([]interface{}{pgx.QueryResultFormats{pgx.TextFormatCode}}, args...)...)
+	if err != nil {
+		return nil, wrapError(err, sql)
+	}
+	defer rows.Close()
+
+	fields := rows.FieldDescriptions()
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = f.Name
+	}
+
+	result := []Row{}
+	for rows.Next() {
+		raw := rows.RawValues()
+		values := make([]interface{}, len(raw))
+		for i, v := range raw {
+			values[i] = decodeValue(fields[i].DataTypeOID, v)
+		}
+		result = append(result, Row{columns: columns, values: values})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrapError(err, sql)
+	}
+	return result, nil
+}
+
+func wrapError(err error, sql string) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	return &QueryError{PgError: pgErr, FormattedError: formatError(pgErr, sql)}
+}
+
+// formatError renders an error like psql does, with a caret under the failing position
+func formatError(pgErr *pgconn.PgError, sql string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s:  %s: %s\n", pgErr.Severity, pgErr.Code, pgErr.Message)
+
+	if pgErr.Position > 0 {
+		runes := []rune(sql)
+		pos := int(pgErr.Position) - 1
+		if pos > len(runes) {
+			pos = len(runes)
+		}
+		lineStart := 0
+		lineNo := 1
+		for i := 0; i < pos; i++ {
+			if runes[i] == '\n' {
+				lineStart = i + 1
+				lineNo++
+			}
+		}
+		lineEnd := len(runes)
+		for i := pos; i < len(runes); i++ {
+			if runes[i] == '\n' {
+				lineEnd = i
+				break
+			}
+		}
+		prefix := fmt.Sprintf("LINE %d: ", lineNo)
+		fmt.Fprintf(&b, "%s%s\n", prefix, string(runes[lineStart:lineEnd]))
+		fmt.Fprintf(&b, "%s^\n", strings.Repeat(" ", len(prefix)+pos-lineStart))
+	}
+	if pgErr.Detail != "" {
+		fmt.Fprintf(&b, "DETAIL:  %s\n", pgErr.Detail)
+	}
+	if pgErr.Hint != "" {
+		fmt.Fprintf(&b, "HINT:  %s\n", pgErr.Hint)
+	}
+	return b.String()
+}
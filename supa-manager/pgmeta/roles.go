@@ -0,0 +1,227 @@
+package pgmeta
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// rolesSQL lists database roles in the shape returned by postgres-meta, passwords are never exposed
+const rolesSQL = `
+SELECT
+  r.oid :: int8 AS id,
+  r.rolname AS name,
+  r.rolsuper AS is_superuser,
+  r.rolcreatedb AS can_create_db,
+  r.rolcreaterole AS can_create_role,
+  r.rolinherit AS inherit_role,
+  r.rolcanlogin AS can_login,
+  r.rolreplication AS is_replication_role,
+  r.rolbypassrls AS can_bypass_rls,
+  (
+    SELECT count(*)
+    FROM pg_stat_activity
+    WHERE r.rolname = pg_stat_activity.usename
+  ) AS active_connections,
+  CASE
+    WHEN r.rolconnlimit = -1 THEN current_setting('max_connections') :: int8
+    ELSE r.rolconnlimit
+  END AS connection_limit,
+  '********' AS password,
+  r.rolvaliduntil AS valid_until,
+  coalesce(
+    (
+      SELECT jsonb_object_agg(split_part(setting, '=', 1), substr(setting, strpos(setting, '=') + 1))
+      FROM unnest(r.rolconfig) AS setting
+    ),
+    '{}'
+  ) AS config
+FROM
+  pg_roles r
+`
+
+// RoleListOptions controls ListRoles
+type RoleListOptions struct {
+	ListOptions
+	IncludeDefaultRoles bool // Include the predefined pg_* roles
+}
+
+// RoleCreate is the body of a pg-meta role create request
+type RoleCreate struct {
+	Name              string            `json:"name" binding:"required"`
+	Password          *string           `json:"password"`
+	InheritRole       *bool             `json:"inherit_role"`
+	CanLogin          *bool             `json:"can_login"`
+	IsSuperuser       *bool             `json:"is_superuser"`
+	CanCreateDB       *bool             `json:"can_create_db"`
+	CanCreateRole     *bool             `json:"can_create_role"`
+	IsReplicationRole *bool             `json:"is_replication_role"`
+	CanBypassRLS      *bool             `json:"can_bypass_rls"`
+	ConnectionLimit   *int              `json:"connection_limit"`
+	ValidUntil        *string           `json:"valid_until"`
+	MemberOf          []string          `json:"member_of"`
+	Members           []string          `json:"members"`
+	Admins            []string          `json:"admins"`
+	Config            map[string]string `json:"config"`
+}
+
+// RoleUpdate is the body of a pg-meta role update request, nil fields are left unchanged
+type RoleUpdate struct {
+	Name              *string           `json:"name"`
+	Password          *string           `json:"password"`
+	InheritRole       *bool             `json:"inherit_role"`
+	CanLogin          *bool             `json:"can_login"`
+	IsSuperuser       *bool             `json:"is_superuser"`
+	CanCreateDB       *bool             `json:"can_create_db"`
+	CanCreateRole     *bool             `json:"can_create_role"`
+	IsReplicationRole *bool             `json:"is_replication_role"`
+	CanBypassRLS      *bool             `json:"can_bypass_rls"`
+	ConnectionLimit   *int              `json:"connection_limit"`
+	ValidUntil        *string           `json:"valid_until"`
+	Config            map[string]string `json:"config"` // An empty value resets the setting
+}
+
+// ListRoles returns roles matching the options
+func ListRoles(ctx context.Context, db Querier, opts RoleListOptions) ([]Row, error) {
+	where := "true"
+	if !opts.IncludeDefaultRoles {
+		where = "NOT starts_with(name, 'pg_')"
+	}
+	sql := fmt.Sprintf("WITH roles AS (%s) SELECT * FROM roles WHERE %s ORDER BY name", rolesSQL, where) + limitOffset(opts.ListOptions)
+	return Query(ctx, db, sql)
+}
+
+// RetrieveRole returns a role by oid
+func RetrieveRole(ctx context.Context, db Querier, id int64) (Row, error) {
+	return queryOne(ctx, db, fmt.Sprintf("WITH roles AS (%s) SELECT * FROM roles WHERE id = $1", rolesSQL), id)
+}
+
+func retrieveRoleByName(ctx context.Context, db Querier, name string) (Row, error) {
+	return queryOne(ctx, db, fmt.Sprintf("WITH roles AS (%s) SELECT * FROM roles WHERE name = $1", rolesSQL), name)
+}
+
+// CreateRole creates a role and returns it
+func CreateRole(ctx context.Context, pool *pgxpool.Pool, req RoleCreate) (Row, error) {
+	options := roleOptions(req.IsSuperuser, req.CanCreateDB, req.CanCreateRole, req.InheritRole, req.CanLogin,
+		req.IsReplicationRole, req.CanBypassRLS, req.ConnectionLimit, req.Password, req.ValidUntil)
+	if len(req.MemberOf) > 0 {
+		options = append(options, "IN ROLE "+identList(req.MemberOf))
+	}
+	if len(req.Members) > 0 {
+		options = append(options, "ROLE "+identList(req.Members))
+	}
+	if len(req.Admins) > 0 {
+		options = append(options, "ADMIN "+identList(req.Admins))
+	}
+
+	stmt := "CREATE ROLE " + ident(req.Name)
+	if len(options) > 0 {
+		stmt += " WITH " + strings.Join(options, " ")
+	}
+	statements := append([]string{stmt}, roleConfig(req.Name, req.Config)...)
+
+	return inTransaction(ctx, pool, statements, func(tx pgx.Tx) (Row, error) {
+		return retrieveRoleByName(ctx, tx, req.Name)
+	})
+}
+
+// UpdateRole applies the requested changes in one transaction and returns the updated role
+func UpdateRole(ctx context.Context, pool *pgxpool.Pool, id int64, req RoleUpdate) (Row, error) {
+	old, err := RetrieveRole(ctx, pool, id)
+	if err != nil {
+		return Row{}, err
+	}
+	name := old.String("name")
+
+	statements := []string{}
+	// Renaming clears an MD5 password, so it goes first and a new password in the same request survives
+	if req.Name != nil && *req.Name != name {
+		statements = append(statements, fmt.Sprintf("ALTER ROLE %s RENAME TO %s", ident(name), ident(*req.Name)))
+		name = *req.Name
+	}
+	options := roleOptions(req.IsSuperuser, req.CanCreateDB, req.CanCreateRole, req.InheritRole, req.CanLogin,
+		req.IsReplicationRole, req.CanBypassRLS, req.ConnectionLimit, req.Password, req.ValidUntil)
+	if len(options) > 0 {
+		statements = append(statements, fmt.Sprintf("ALTER ROLE %s WITH %s", ident(name), strings.Join(options, " ")))
+	}
+	statements = append(statements, roleConfig(name, req.Config)...)
+
+	return inTransaction(ctx, pool, statements, func(tx pgx.Tx) (Row, error) {
+		return RetrieveRole(ctx, tx, id)
+	})
+}
+
+// DeleteRole drops a role and returns it as it was before the drop
+func DeleteRole(ctx context.Context, pool *pgxpool.Pool, id int64) (Row, error) {
+	old, err := RetrieveRole(ctx, pool, id)
+	if err != nil {
+		return Row{}, err
+	}
+
+	stmt := "DROP ROLE " + ident(old.String("name"))
+	return inTransaction(ctx, pool, []string{stmt}, func(pgx.Tx) (Row, error) {
+		return old, nil
+	})
+}
+
+func roleOptions(superuser, createDB, createRole, inherit, login, replication, bypassRLS *bool, connLimit *int, password, validUntil *string) []string {
+	options := []string{}
+	flag := func(value *bool, on, off string) {
+		if value == nil {
+			return
+		}
+		if *value {
+			options = append(options, on)
+		} else {
+			options = append(options, off)
+		}
+	}
+	flag(superuser, "SUPERUSER", "NOSUPERUSER")
+	flag(createDB, "CREATEDB", "NOCREATEDB")
+	flag(createRole, "CREATEROLE", "NOCREATEROLE")
+	flag(inherit, "INHERIT", "NOINHERIT")
+	flag(login, "LOGIN", "NOLOGIN")
+	flag(replication, "REPLICATION", "NOREPLICATION")
+	flag(bypassRLS, "BYPASSRLS", "NOBYPASSRLS")
+	if connLimit != nil {
+		options = append(options, fmt.Sprintf("CONNECTION LIMIT %d", *connLimit))
+	}
+	if password != nil {
+		options = append(options, "PASSWORD "+literal(*password))
+	}
+	if validUntil != nil {
+		options = append(options, "VALID UNTIL "+literal(*validUntil))
+	}
+	return options
+}
+
+// roleConfig sets or resets per-role configuration parameters
+func roleConfig(role string, config map[string]string) []string {
+	params := make([]string, 0, len(config))
+	for param := range config {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+
+	statements := make([]string, 0, len(params))
+	for _, param := range params {
+		if config[param] == "" {
+			statements = append(statements, fmt.Sprintf("ALTER ROLE %s RESET %s", ident(role), ident(param)))
+			continue
+		}
+		statements = append(statements, fmt.Sprintf("ALTER ROLE %s %s", ident(role), configClause(param, config[param])))
+	}
+	return statements
+}
+
+func identList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = ident(name)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,114 @@
+package pgmeta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrNotFound is returned when a catalog object does not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrInvalidRequest is wrapped by errors about request bodies that cannot be turned into DDL
+var ErrInvalidRequest = errors.New("invalid request")
+
+// DefaultSystemSchemas are hidden unless include_system_schemas is requested, as in pg-meta
+var DefaultSystemSchemas = []string{"information_schema", "pg_catalog", "pg_toast"}
+
+// ListOptions are the filters shared by the pg-meta list endpoints
+type ListOptions struct {
+	IncludeSystemSchemas bool
+	IncludedSchemas      []string
+	ExcludedSchemas      []string
+	Limit                int
+	Offset               int
+}
+
+// ident quotes one or more identifier parts, e.g. ident("public", "users") -> "public"."users"
+func ident(parts ...string) string {
+	return pgx.Identifier(parts).Sanitize()
+}
+
+// literal quotes a string constant (standard_conforming_strings is on in every supported version)
+func literal(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// literalList renders values as a comma separated list of string constants
+func literalList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = literal(v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// schemaFilter builds the WHERE clause shared by the list queries for the given schema column
+func schemaFilter(column string, opts ListOptions) string {
+	conditions := []string{}
+	if len(opts.IncludedSchemas) > 0 {
+		conditions = append(conditions, fmt.Sprintf("%s IN (%s)", column, literalList(opts.IncludedSchemas)))
+	} else if !opts.IncludeSystemSchemas {
+		conditions = append(conditions, fmt.Sprintf("%s NOT IN (%s)", column, literalList(DefaultSystemSchemas)))
+	}
+	if len(opts.ExcludedSchemas) > 0 {
+		conditions = append(conditions, fmt.Sprintf("%s NOT IN (%s)", column, literalList(opts.ExcludedSchemas)))
+	}
+	if len(conditions) == 0 {
+		return "true"
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+func limitOffset(opts ListOptions) string {
+	clause := ""
+	if opts.Limit > 0 {
+		clause += fmt.Sprintf(" LIMIT %d", opts.Limit)
+	}
+	if opts.Offset > 0 {
+		clause += fmt.Sprintf(" OFFSET %d", opts.Offset)
+	}
+	return clause
+}
+
+// queryOne runs a query expected to return a single row
+func queryOne(ctx context.Context, db Querier, sql string, args ...interface{}) (Row, error) {
+	rows, err := Query(ctx, db, sql, args...)
+	if err != nil {
+		return Row{}, err
+	}
+	if len(rows) == 0 {
+		return Row{}, ErrNotFound
+	}
+	return rows[0], nil
+}
+
+// inTransaction runs the DDL statements in one transaction and then fetch, so the returned
+// object reflects the committed change
+func inTransaction(ctx context.Context, pool *pgxpool.Pool, statements []string, fetch func(tx pgx.Tx) (Row, error)) (Row, error) {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return Row{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(ctx, stmt); err != nil {
+			return Row{}, wrapError(err, stmt)
+		}
+	}
+
+	row, err := fetch(tx)
+	if err != nil {
+		return Row{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Row{}, wrapError(err, "COMMIT")
+	}
+	return row, nil
+}
@@ -0,0 +1,305 @@
+package pgmeta
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// tablesSQL lists ordinary and partitioned tables in the shape returned by postgres-meta
+const tablesSQL = `
+SELECT
+  c.oid :: int8 AS id,
+  nc.nspname AS schema,
+  c.relname AS name,
+  c.relrowsecurity AS rls_enabled,
+  c.relforcerowsecurity AS rls_forced,
+  CASE
+    WHEN c.relreplident = 'd' THEN 'DEFAULT'
+    WHEN c.relreplident = 'i' THEN 'INDEX'
+    WHEN c.relreplident = 'f' THEN 'FULL'
+    ELSE 'NOTHING'
+  END AS replica_identity,
+  pg_total_relation_size(format('%I.%I', nc.nspname, c.relname)) :: int8 AS bytes,
+  pg_size_pretty(pg_total_relation_size(format('%I.%I', nc.nspname, c.relname))) AS size,
+  pg_stat_get_live_tuples(c.oid) AS live_rows_estimate,
+  pg_stat_get_dead_tuples(c.oid) AS dead_rows_estimate,
+  obj_description(c.oid) AS comment,
+  coalesce(pk.primary_keys, '[]') AS primary_keys,
+  coalesce(
+    jsonb_agg(relationships) FILTER (WHERE relationships IS NOT NULL),
+    '[]'
+  ) AS relationships
+FROM
+  pg_namespace nc
+  JOIN pg_class c ON nc.oid = c.relnamespace
+  LEFT JOIN (
+    SELECT table_id, jsonb_agg(_pk.*) AS primary_keys
+    FROM (
+      SELECT
+        n.nspname AS schema,
+        c.relname AS table_name,
+        a.attname AS name,
+        c.oid :: int8 AS table_id
+      FROM pg_index i, pg_class c, pg_attribute a, pg_namespace n
+      WHERE i.indrelid = c.oid
+        AND c.relnamespace = n.oid
+        AND a.attrelid = c.oid
+        AND a.attnum = ANY (i.indkey)
+        AND i.indisprimary
+    ) AS _pk
+    GROUP BY table_id
+  ) AS pk ON pk.table_id = c.oid
+  LEFT JOIN (
+    SELECT
+      c.oid :: int8 AS id,
+      c.conname AS constraint_name,
+      nsa.nspname AS source_schema,
+      csa.relname AS source_table_name,
+      sa.attname AS source_column_name,
+      nta.nspname AS target_table_schema,
+      cta.relname AS target_table_name,
+      ta.attname AS target_column_name
+    FROM pg_constraint c
+    JOIN (
+      pg_attribute sa
+      JOIN pg_class csa ON sa.attrelid = csa.oid
+      JOIN pg_namespace nsa ON csa.relnamespace = nsa.oid
+    ) ON sa.attrelid = c.conrelid AND sa.attnum = ANY (c.conkey)
+    JOIN (
+      pg_attribute ta
+      JOIN pg_class cta ON ta.attrelid = cta.oid
+      JOIN pg_namespace nta ON cta.relnamespace = nta.oid
+    ) ON ta.attrelid = c.confrelid AND ta.attnum = ANY (c.confkey)
+    WHERE c.contype = 'f'
+  ) AS relationships
+    ON (relationships.source_schema = nc.nspname AND relationships.source_table_name = c.relname)
+    OR (relationships.target_table_schema = nc.nspname AND relationships.target_table_name = c.relname)
+WHERE
+  c.relkind IN ('r', 'p')
+  AND NOT pg_is_other_temp_schema(nc.oid)
+  AND (
+    pg_has_role(c.relowner, 'USAGE')
+    OR has_table_privilege(c.oid, 'SELECT, INSERT, UPDATE, DELETE, TRUNCATE, REFERENCES, TRIGGER')
+    OR has_any_column_privilege(c.oid, 'SELECT, INSERT, UPDATE, REFERENCES')
+  )
+GROUP BY
+  c.oid,
+  c.relname,
+  c.relrowsecurity,
+  c.relforcerowsecurity,
+  c.relreplident,
+  nc.nspname,
+  pk.primary_keys
+`
+
+// enrichedTablesSQL wraps tablesSQL, optionally attaching each table's columns
+func enrichedTablesSQL(includeColumns bool, where string) string {
+	if !includeColumns {
+		return fmt.Sprintf("WITH tables AS (%s) SELECT * FROM tables WHERE %s", tablesSQL, where)
+	}
+	return fmt.Sprintf(`WITH tables AS (%s), columns AS (%s)
+SELECT
+  *,
+  COALESCE(
+    (SELECT jsonb_agg(columns ORDER BY columns.ordinal_position) FROM columns WHERE columns.table_id = tables.id),
+    '[]'
+  ) AS columns
+FROM tables
+WHERE %s`, tablesSQL, columnsSQL, where)
+}
+
+// TableListOptions controls ListTables
+type TableListOptions struct {
+	ListOptions
+	IncludeColumns bool
+}
+
+// PrimaryKey names a column of a table's primary key
+type PrimaryKey struct {
+	Name string `json:"name"`
+}
+
+// TableCreate is the body of a pg-meta table create request
+type TableCreate struct {
+	Name    string  `json:"name" binding:"required"`
+	Schema  string  `json:"schema"`
+	Comment *string `json:"comment"`
+}
+
+// TableUpdate is the body of a pg-meta table update request, nil fields are left unchanged
+type TableUpdate struct {
+	Name                 *string      `json:"name"`
+	Schema               *string      `json:"schema"`
+	RLSEnabled           *bool        `json:"rls_enabled"`
+	RLSForced            *bool        `json:"rls_forced"`
+	ReplicaIdentity      *string      `json:"replica_identity"`
+	ReplicaIdentityIndex *string      `json:"replica_identity_index"`
+	PrimaryKeys          []PrimaryKey `json:"primary_keys"`
+	Comment              *string      `json:"comment"`
+}
+
+// ListTables returns tables matching the options
+func ListTables(ctx context.Context, db Querier, opts TableListOptions) ([]Row, error) {
+	sql := enrichedTablesSQL(opts.IncludeColumns, schemaFilter("schema", opts.ListOptions)) + limitOffset(opts.ListOptions)
+	return Query(ctx, db, sql)
+}
+
+// RetrieveTable returns a single table with its columns
+func RetrieveTable(ctx context.Context, db Querier, id int64) (Row, error) {
+	return queryOne(ctx, db, enrichedTablesSQL(true, "id = $1"), id)
+}
+
+func retrieveTableByName(ctx context.Context, db Querier, schema, name string) (Row, error) {
+	return queryOne(ctx, db, enrichedTablesSQL(true, "schema = $1 AND name = $2"), schema, name)
+}
+
+// CreateTable creates an empty table and returns it
+func CreateTable(ctx context.Context, pool *pgxpool.Pool, req TableCreate) (Row, error) {
+	schema := req.Schema
+	if schema == "" {
+		schema = "public"
+	}
+
+	statements := []string{fmt.Sprintf("CREATE TABLE %s ()", ident(schema, req.Name))}
+	if req.Comment != nil {
+		statements = append(statements, commentOn("TABLE", ident(schema, req.Name), *req.Comment))
+	}
+
+	return inTransaction(ctx, pool, statements, func(tx pgx.Tx) (Row, error) {
+		return retrieveTableByName(ctx, tx, schema, req.Name)
+	})
+}
+
+var validReplicaIdentities = map[string]bool{"DEFAULT": true, "FULL": true, "NOTHING": true, "INDEX": true}
+
+// primaryKeyColumnsSQL lists the primary key columns of a table in key order
+const primaryKeyColumnsSQL = `
+SELECT a.attname
+FROM pg_constraint c
+  CROSS JOIN unnest(c.conkey) WITH ORDINALITY AS k(attnum, position)
+  JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+WHERE c.contype = 'p' AND c.conrelid = $1
+ORDER BY k.position`
+
+// UpdateTable applies the requested changes in one transaction and returns the updated table
+func UpdateTable(ctx context.Context, pool *pgxpool.Pool, id int64, req TableUpdate) (Row, error) {
+	old, err := RetrieveTable(ctx, pool, id)
+	if err != nil {
+		return Row{}, err
+	}
+	oldSchema, oldName := old.String("schema"), old.String("name")
+	alter := "ALTER TABLE " + ident(oldSchema, oldName)
+
+	// The replica identity is a keyword, only the known ones may reach the statement
+	replicaIdentity := ""
+	if req.ReplicaIdentity != nil {
+		replicaIdentity = strings.ToUpper(strings.TrimSpace(*req.ReplicaIdentity))
+		if !validReplicaIdentities[replicaIdentity] {
+			return Row{}, fmt.Errorf("%w: replica_identity must be DEFAULT, FULL, NOTHING or INDEX", ErrInvalidRequest)
+		}
+		if replicaIdentity == "INDEX" && (req.ReplicaIdentityIndex == nil || *req.ReplicaIdentityIndex == "") {
+			return Row{}, fmt.Errorf("%w: replica_identity_index is required when replica_identity is INDEX", ErrInvalidRequest)
+		}
+	}
+
+	statements := []string{}
+	if req.RLSEnabled != nil {
+		if *req.RLSEnabled {
+			statements = append(statements, alter+" ENABLE ROW LEVEL SECURITY")
+		} else {
+			statements = append(statements, alter+" DISABLE ROW LEVEL SECURITY")
+		}
+	}
+	if req.RLSForced != nil {
+		if *req.RLSForced {
+			statements = append(statements, alter+" FORCE ROW LEVEL SECURITY")
+		} else {
+			statements = append(statements, alter+" NO FORCE ROW LEVEL SECURITY")
+		}
+	}
+	switch replicaIdentity {
+	case "":
+	case "INDEX":
+		statements = append(statements, fmt.Sprintf("%s REPLICA IDENTITY USING INDEX %s", alter, ident(*req.ReplicaIdentityIndex)))
+	default:
+		statements = append(statements, fmt.Sprintf("%s REPLICA IDENTITY %s", alter, replicaIdentity))
+	}
+	if req.PrimaryKeys != nil {
+		primaryKeys := make([]string, len(req.PrimaryKeys))
+		for i, pk := range req.PrimaryKeys {
+			primaryKeys[i] = pk.Name
+		}
+		// Studio sends the primary key with every edit. It's only replaced when it changed, dropping
+		// it fails while foreign keys reference it
+		rows, err := pool.Query(ctx, primaryKeyColumnsSQL, id)
+		if err != nil {
+			return Row{}, err
+		}
+		current, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return Row{}, err
+		}
+		if !slices.Equal(current, primaryKeys) {
+			statements = append(statements, fmt.Sprintf(`DO $$
+DECLARE pk name;
+BEGIN
+  SELECT conname INTO pk FROM pg_constraint WHERE contype = 'p' AND conrelid = %d;
+  IF pk IS NOT NULL THEN
+    EXECUTE format('ALTER TABLE %%I.%%I DROP CONSTRAINT %%I', %s, %s, pk);
+  END IF;
+END $$`, id, literal(oldSchema), literal(oldName)))
+			if len(primaryKeys) > 0 {
+				statements = append(statements, fmt.Sprintf("%s ADD PRIMARY KEY (%s)", alter, identList(primaryKeys)))
+			}
+		}
+	}
+	if req.Comment != nil {
+		statements = append(statements, commentOn("TABLE", ident(oldSchema, oldName), *req.Comment))
+	}
+	// Schema and name changes go last so the statements above can use the old name
+	newSchema := oldSchema
+	if req.Schema != nil && *req.Schema != oldSchema {
+		statements = append(statements, fmt.Sprintf("%s SET SCHEMA %s", alter, ident(*req.Schema)))
+		newSchema = *req.Schema
+	}
+	if req.Name != nil && *req.Name != oldName {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s RENAME TO %s", ident(newSchema, oldName), ident(*req.Name)))
+	}
+
+	return inTransaction(ctx, pool, statements, func(tx pgx.Tx) (Row, error) {
+		return RetrieveTable(ctx, tx, id)
+	})
+}
+
+// DeleteTable drops a table and returns it as it was before the drop
+func DeleteTable(ctx context.Context, pool *pgxpool.Pool, id int64, cascade bool) (Row, error) {
+	old, err := RetrieveTable(ctx, pool, id)
+	if err != nil {
+		return Row{}, err
+	}
+
+	stmt := fmt.Sprintf("DROP TABLE %s %s", ident(old.String("schema"), old.String("name")), dropBehavior(cascade))
+	return inTransaction(ctx, pool, []string{stmt}, func(pgx.Tx) (Row, error) {
+		return old, nil
+	})
+}
+
+// commentOn builds COMMENT ON, an empty comment removes it
+func commentOn(kind string, target string, comment string) string {
+	if comment == "" {
+		return fmt.Sprintf("COMMENT ON %s %s IS NULL", kind, target)
+	}
+	return fmt.Sprintf("COMMENT ON %s %s IS %s", kind, target, literal(comment))
+}
+
+func dropBehavior(cascade bool) string {
+	if cascade {
+		return "CASCADE"
+	}
+	return "RESTRICT"
+}
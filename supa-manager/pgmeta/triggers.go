@@ -0,0 +1,193 @@
+package pgmeta
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// triggersSQL lists table triggers in the shape returned by postgres-meta
+const triggersSQL = `
+SELECT
+  pg_t.oid :: int8 AS id,
+  pg_t.tgrelid :: int8 AS table_id,
+  CASE
+    WHEN pg_t.tgenabled = 'D' THEN 'DISABLED'
+    WHEN pg_t.tgenabled = 'O' THEN 'ORIGIN'
+    WHEN pg_t.tgenabled = 'R' THEN 'REPLICA'
+    WHEN pg_t.tgenabled = 'A' THEN 'ALWAYS'
+  END AS enabled_mode,
+  (string_to_array(encode(pg_t.tgargs, 'escape'), '\000'))[:pg_t.tgnargs] AS function_args,
+  is_t.trigger_name AS name,
+  is_t.event_object_table AS table,
+  is_t.event_object_schema AS schema,
+  is_t.action_condition AS condition,
+  is_t.action_orientation AS orientation,
+  is_t.action_timing AS activation,
+  array_agg(is_t.event_manipulation) :: text[] AS events,
+  pg_p.proname AS function_name,
+  pg_n.nspname AS function_schema
+FROM
+  pg_trigger AS pg_t
+  JOIN pg_class AS pg_c ON pg_t.tgrelid = pg_c.oid
+  JOIN pg_namespace AS table_ns ON pg_c.relnamespace = table_ns.oid
+  JOIN information_schema.triggers AS is_t
+    ON is_t.trigger_name = pg_t.tgname
+    AND pg_c.relname = is_t.event_object_table
+    AND table_ns.nspname = is_t.event_object_schema
+  JOIN pg_proc AS pg_p ON pg_t.tgfoid = pg_p.oid
+  JOIN pg_namespace AS pg_n ON pg_p.pronamespace = pg_n.oid
+GROUP BY
+  pg_t.oid,
+  pg_t.tgrelid,
+  pg_t.tgenabled,
+  pg_t.tgargs,
+  pg_t.tgnargs,
+  is_t.trigger_name,
+  is_t.event_object_table,
+  is_t.event_object_schema,
+  is_t.action_condition,
+  is_t.action_orientation,
+  is_t.action_timing,
+  pg_p.proname,
+  pg_n.nspname
+`
+
+// TriggerCreate is the body of a pg-meta trigger create request
+type TriggerCreate struct {
+	Name           string   `json:"name" binding:"required"`
+	Schema         string   `json:"schema"`
+	Table          string   `json:"table" binding:"required"`
+	FunctionSchema string   `json:"function_schema"`
+	FunctionName   string   `json:"function_name" binding:"required"`
+	FunctionArgs   []string `json:"function_args"`
+	Activation     string   `json:"activation" binding:"required"` // BEFORE, AFTER or INSTEAD OF
+	Events         []string `json:"events" binding:"required"`     // INSERT, UPDATE, DELETE, TRUNCATE
+	Orientation    string   `json:"orientation"`                   // STATEMENT (default) or ROW
+	Condition      *string  `json:"condition"`
+}
+
+// TriggerUpdate is the body of a pg-meta trigger update request, nil fields are left unchanged
+type TriggerUpdate struct {
+	Name        *string `json:"name"`
+	EnabledMode *string `json:"enabled_mode"` // ORIGIN, REPLICA, ALWAYS or DISABLED
+}
+
+// ListTriggers returns triggers matching the options
+func ListTriggers(ctx context.Context, db Querier, opts ListOptions) ([]Row, error) {
+	sql := fmt.Sprintf("WITH triggers AS (%s) SELECT * FROM triggers WHERE %s", triggersSQL, schemaFilter("schema", opts)) + limitOffset(opts)
+	return Query(ctx, db, sql)
+}
+
+// RetrieveTrigger returns a trigger by oid
+func RetrieveTrigger(ctx context.Context, db Querier, id int64) (Row, error) {
+	return queryOne(ctx, db, fmt.Sprintf("WITH triggers AS (%s) SELECT * FROM triggers WHERE id = $1", triggersSQL), id)
+}
+
+func retrieveTriggerByName(ctx context.Context, db Querier, schema, table, name string) (Row, error) {
+	return queryOne(ctx, db, fmt.Sprintf("WITH triggers AS (%s) SELECT * FROM triggers WHERE schema = $1 AND \"table\" = $2 AND name = $3", triggersSQL), schema, table, name)
+}
+
+// CreateTrigger creates a trigger and returns it
+func CreateTrigger(ctx context.Context, pool *pgxpool.Pool, req TriggerCreate) (Row, error) {
+	schema := req.Schema
+	if schema == "" {
+		schema = "public"
+	}
+	functionSchema := req.FunctionSchema
+	if functionSchema == "" {
+		functionSchema = "public"
+	}
+
+	activation := strings.ToUpper(req.Activation)
+	switch activation {
+	case "BEFORE", "AFTER", "INSTEAD OF":
+	default:
+		return Row{}, fmt.Errorf("%w: unknown activation %q", ErrInvalidRequest, req.Activation)
+	}
+	orientation := strings.ToUpper(req.Orientation)
+	switch orientation {
+	case "":
+		orientation = "STATEMENT"
+	case "ROW", "STATEMENT":
+	default:
+		return Row{}, fmt.Errorf("%w: unknown orientation %q", ErrInvalidRequest, req.Orientation)
+	}
+	if len(req.Events) == 0 {
+		return Row{}, fmt.Errorf("%w: at least one event is required", ErrInvalidRequest)
+	}
+	events := make([]string, len(req.Events))
+	for i, event := range req.Events {
+		events[i] = strings.ToUpper(event)
+		switch events[i] {
+		case "INSERT", "UPDATE", "DELETE", "TRUNCATE":
+		default:
+			return Row{}, fmt.Errorf("%w: unknown event %q", ErrInvalidRequest, event)
+		}
+	}
+	args := make([]string, len(req.FunctionArgs))
+	for i, arg := range req.FunctionArgs {
+		args[i] = literal(arg)
+	}
+
+	stmt := fmt.Sprintf("CREATE TRIGGER %s %s %s ON %s FOR EACH %s",
+		ident(req.Name), activation, strings.Join(events, " OR "), ident(schema, req.Table), orientation)
+	if req.Condition != nil && *req.Condition != "" {
+		stmt += fmt.Sprintf(" WHEN (%s)", *req.Condition)
+	}
+	stmt += fmt.Sprintf(" EXECUTE FUNCTION %s(%s)", ident(functionSchema, req.FunctionName), strings.Join(args, ", "))
+
+	return inTransaction(ctx, pool, []string{stmt}, func(tx pgx.Tx) (Row, error) {
+		return retrieveTriggerByName(ctx, tx, schema, req.Table, req.Name)
+	})
+}
+
+// UpdateTrigger renames or enables/disables a trigger and returns it
+func UpdateTrigger(ctx context.Context, pool *pgxpool.Pool, id int64, req TriggerUpdate) (Row, error) {
+	old, err := RetrieveTrigger(ctx, pool, id)
+	if err != nil {
+		return Row{}, err
+	}
+	table := ident(old.String("schema"), old.String("table"))
+	name := ident(old.String("name"))
+
+	statements := []string{}
+	if req.EnabledMode != nil {
+		switch strings.ToUpper(*req.EnabledMode) {
+		case "ORIGIN":
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ENABLE TRIGGER %s", table, name))
+		case "REPLICA":
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ENABLE REPLICA TRIGGER %s", table, name))
+		case "ALWAYS":
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ENABLE ALWAYS TRIGGER %s", table, name))
+		case "DISABLED":
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s DISABLE TRIGGER %s", table, name))
+		default:
+			return Row{}, fmt.Errorf("%w: unknown enabled_mode %q", ErrInvalidRequest, *req.EnabledMode)
+		}
+	}
+	if req.Name != nil && *req.Name != old.String("name") {
+		statements = append(statements, fmt.Sprintf("ALTER TRIGGER %s ON %s RENAME TO %s", name, table, ident(*req.Name)))
+	}
+
+	return inTransaction(ctx, pool, statements, func(tx pgx.Tx) (Row, error) {
+		return RetrieveTrigger(ctx, tx, id)
+	})
+}
+
+// DeleteTrigger drops a trigger and returns it as it was before the drop
+func DeleteTrigger(ctx context.Context, pool *pgxpool.Pool, id int64, cascade bool) (Row, error) {
+	old, err := RetrieveTrigger(ctx, pool, id)
+	if err != nil {
+		return Row{}, err
+	}
+
+	stmt := fmt.Sprintf("DROP TRIGGER %s ON %s %s",
+		ident(old.String("name")), ident(old.String("schema"), old.String("table")), dropBehavior(cascade))
+	return inTransaction(ctx, pool, []string{stmt}, func(pgx.Tx) (Row, error) {
+		return old, nil
+	})
+}
@@ -0,0 +1,102 @@
+package provisioner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// AlertSeverity indicates how urgently an alert needs attention
+type AlertSeverity string
+
+const (
+	AlertWarning  AlertSeverity = "WARNING"
+	AlertCritical AlertSeverity = "CRITICAL"
+)
+
+// Alert is an operator-facing notification raised by background jobs
+type Alert struct {
+	Severity  AlertSeverity
+	ProjectID string
+	Subject   string
+	Message   string
+	Time      time.Time
+}
+
+// Alerter delivers alerts to operators
+type Alerter interface {
+	Alert(ctx context.Context, alert Alert) error
+}
+
+// LogAlerter writes alerts to the structured logger
+type LogAlerter struct {
+	logger *slog.Logger
+}
+
+// NewLogAlerter creates an alerter that only logs
+func NewLogAlerter(logger *slog.Logger) *LogAlerter {
+	return &LogAlerter{logger: logger}
+}
+
+func (l *LogAlerter) Alert(ctx context.Context, alert Alert) error {
+	l.logger.Error(alert.Subject,
+		"severity", alert.Severity,
+		"project", alert.ProjectID,
+		"message", alert.Message)
+	return nil
+}
+
+// WebhookAlerter posts alerts as JSON to a webhook (Slack-compatible "text" payload)
+// and also forwards them to a fallback alerter so they are never lost
+type WebhookAlerter struct {
+	url      string
+	client   *http.Client
+	fallback Alerter
+}
+
+// NewWebhookAlerter creates an alerter that posts to the given URL
+func NewWebhookAlerter(url string, fallback Alerter) *WebhookAlerter {
+	return &WebhookAlerter{
+		url:      url,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		fallback: fallback,
+	}
+}
+
+func (w *WebhookAlerter) Alert(ctx context.Context, alert Alert) error {
+	if w.fallback != nil {
+		w.fallback.Alert(ctx, alert)
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"text":       fmt.Sprintf("[%s] %s: %s", alert.Severity, alert.Subject, alert.Message),
+		"severity":   alert.Severity,
+		"project_id": alert.ProjectID,
+		"subject":    alert.Subject,
+		"message":    alert.Message,
+		"time":       alert.Time.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver alert: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("alert webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
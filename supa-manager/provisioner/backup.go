@@ -15,6 +15,16 @@ const (
 	BackupTypeIncremental BackupType = "INCREMENTAL"  // Changes since last backup
 )
 
+// VerificationStatus records whether a backup has been proven restorable
+type VerificationStatus string
+
+const (
+	VerificationUnverified VerificationStatus = "UNVERIFIED" // Never checked
+	VerificationVerifying  VerificationStatus = "VERIFYING"  // Restore test in progress
+	VerificationVerified   VerificationStatus = "VERIFIED"   // Restored and passed sanity checks
+	VerificationCorrupt    VerificationStatus = "CORRUPT"    // Checksum mismatch or restore failed
+)
+
 // BackupConfig contains backup configuration
 type BackupConfig struct {
 	ProjectID      string
@@ -26,6 +36,7 @@ type BackupConfig struct {
 	S3Upload       bool          // Upload to S3
 	S3Bucket       string        // S3 bucket name
 	S3Prefix       string        // S3 key prefix
+	Dir            string        // Directory backup files are written to
 }
 
 // BackupInfo contains metadata about a backup
@@ -44,6 +55,12 @@ type BackupInfo struct {
 	CreatedAt      time.Time
 	CompletedAt    time.Time
 	ExpiresAt      time.Time     // Auto-deletion date
+
+	// Integrity
+	Checksum           string             // Hex-encoded SHA-256 of the backup file
+	VerificationStatus VerificationStatus // Result of the last restore test
+	VerificationError  string             // Why the last restore test failed
+	VerifiedAt         time.Time          // When the last restore test finished
 }
 
 // RestoreConfig contains restore configuration
@@ -82,10 +99,6 @@ type BackupSchedule struct {
 type BackupProvisioner interface {
 	Provisioner
 
-	// CreateBackup creates a new backup of a project
-	// Returns backup info with status CREATING, use GetBackupInfo to check completion
-	CreateBackup(ctx context.Context, config *BackupConfig) (*BackupInfo, error)
-
 	// GetBackupInfo retrieves information about a backup
 	GetBackupInfo(ctx context.Context, backupID string) (*BackupInfo, error)
 
@@ -1,14 +1,40 @@
 package provisioner
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"sync"
+	"text/template"
+	"time"
 
 	"github.com/docker/docker/client"
 )
 
+// Files of a rendered project, projectConfigFile keeps the configuration they were rendered with.
+// Kong's file is in a mounted directory, a file mount would keep the file replaced by a render
+const (
+	composeFile       = "docker-compose.yml"
+	kongFile          = "kong/kong.yml"
+	vectorFile        = "vector.yml"
+	projectConfigFile = "project.json"
+)
+
+// serviceStartTimeout bounds how long a recreated container may take to run and turn healthy
+const serviceStartTimeout = 2 * time.Minute
+
+// ErrProjectNotRendered is returned for projects without a rendered configuration
+var ErrProjectNotRendered = errors.New("project has no rendered configuration")
+
 // DockerProvisioner implements the Provisioner interface using Docker
 type DockerProvisioner struct {
 	client *client.Client
@@ -153,6 +179,185 @@ func (p *DockerProvisioner) ExecuteCommand(ctx context.Context, projectID string
 	return "", fmt.Errorf("not implemented yet - Phase 3")
 }
 
+// LoadProjectConfig reads the configuration saved by the last RenderProject
+func (p *DockerProvisioner) LoadProjectConfig(ctx context.Context, projectID string) (*ProjectConfig, error) {
+	raw, err := os.ReadFile(filepath.Join(p.getProjectDir(projectID), projectConfigFile))
+	if os.IsNotExist(err) {
+		return nil, ErrProjectNotRendered
+	}
+	if err != nil {
+		return nil, err
+	}
+	var config ProjectConfig
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil, fmt.Errorf("failed to read configuration of project %s: %w", projectID, err)
+	}
+	return &config, nil
+}
+
+// RenderProject renders the compose, Kong and Vector files of a project and saves the
+// configuration next to them. Every file is replaced atomically, they all hold secrets
+func (p *DockerProvisioner) RenderProject(ctx context.Context, config *ProjectConfig) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	dir := p.getProjectDir(config.ProjectID)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+
+	files := map[string]string{
+		"project-compose.tmpl.yml": composeFile,
+		"kong.tmpl.yml":            kongFile,
+		"vector.tmpl.yml":          vectorFile,
+	}
+	for tmpl, name := range files {
+		rendered, err := p.renderTemplate(filepath.Join(p.templateDir, tmpl), config)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			return err
+		}
+		if err := writeFileAtomic(path, []byte(rendered)); err != nil {
+			return err
+		}
+	}
+
+	raw, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dir, projectConfigFile), raw)
+}
+
+// RecreateServices recreates service containers in order with docker compose, which has no SDK.
+// Dependencies are left alone, only the named services are touched
+func (p *DockerProvisioner) RecreateServices(ctx context.Context, projectID string, services []string) error {
+	composePath := filepath.Join(p.getProjectDir(projectID), composeFile)
+	for _, service := range services {
+		cmd := exec.CommandContext(ctx, "docker", "compose",
+			"--project-name", projectID,
+			"--file", composePath,
+			"up", "--detach", "--no-deps", "--force-recreate", service)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return &ProvisionerError{
+				ProjectID: projectID,
+				Operation: "recreate " + service,
+				Err:       fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output))),
+			}
+		}
+		if err := p.waitForService(ctx, projectID, service); err != nil {
+			return &ProvisionerError{ProjectID: projectID, Operation: "recreate " + service, Err: err}
+		}
+	}
+	return nil
+}
+
+// ReloadGateway runs kong reload in the project's Kong container, which rereads the declarative
+// configuration and swaps it in without dropping connections
+func (p *DockerProvisioner) ReloadGateway(ctx context.Context, projectID string) error {
+	cmd := exec.CommandContext(ctx, "docker", "compose",
+		"--project-name", projectID,
+		"--file", filepath.Join(p.getProjectDir(projectID), composeFile),
+		"exec", "-T", "kong", "kong", "reload")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return &ProvisionerError{
+			ProjectID: projectID,
+			Operation: "reload gateway",
+			Err:       fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output))),
+		}
+	}
+	return nil
+}
+
+// CreateBackup dumps the project's database in pg_dump's custom format, which is compressed, into
+// config.Dir. The file is hashed while it's written and only appears under its name once complete
+func (p *DockerProvisioner) CreateBackup(ctx context.Context, config *BackupConfig) (*BackupInfo, error) {
+	started := time.Now().UTC()
+	info := &BackupInfo{
+		BackupID:   fmt.Sprintf("%s-%s", config.ProjectID, started.Format("20060102T150405Z")),
+		ProjectID:  config.ProjectID,
+		BackupType: BackupTypeDatabase,
+		Compressed: true,
+		Status:     "CREATING",
+		CreatedAt:  started,
+	}
+	fail := func(err error) (*BackupInfo, error) {
+		return nil, &ProvisionerError{ProjectID: config.ProjectID, Operation: "create backup", Err: err}
+	}
+
+	dir := filepath.Join(config.Dir, config.ProjectID)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fail(err)
+	}
+	info.FilePath = filepath.Join(dir, info.BackupID+".dump")
+	file, err := os.OpenFile(info.FilePath+".partial", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fail(err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	hash := sha256.New()
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", "compose",
+		"--project-name", config.ProjectID,
+		"--file", filepath.Join(p.getProjectDir(config.ProjectID), composeFile),
+		"exec", "-T", "db", "pg_dump", "-U", "postgres", "-d", "postgres", "--format=custom")
+	cmd.Stdout = io.MultiWriter(file, hash)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fail(fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String())))
+	}
+	if err := file.Sync(); err != nil {
+		return fail(err)
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		return fail(err)
+	}
+	if err := os.Rename(file.Name(), info.FilePath); err != nil {
+		return fail(err)
+	}
+
+	info.Size = stat.Size()
+	info.Checksum = hex.EncodeToString(hash.Sum(nil))
+	info.Status = "COMPLETED"
+	info.CompletedAt = time.Now().UTC()
+	if config.Retention > 0 {
+		info.ExpiresAt = started.AddDate(0, 0, config.Retention)
+	}
+	return info, nil
+}
+
+// waitForService polls a service container until it runs and, when it has a health check, is healthy
+func (p *DockerProvisioner) waitForService(ctx context.Context, projectID string, service string) error {
+	ctx, cancel := context.WithTimeout(ctx, serviceStartTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for {
+		inspected, err := p.client.ContainerInspect(ctx, projectID+"-"+service)
+		if err == nil && inspected.State != nil {
+			state := inspected.State
+			if state.Status == "exited" || state.Status == "dead" {
+				return fmt.Errorf("container %s-%s %s with code %d", projectID, service, state.Status, state.ExitCode)
+			}
+			if state.Running && (state.Health == nil || state.Health.Status == "healthy") {
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("container %s-%s did not become ready: %w", projectID, service, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
 // Helper functions for Phase 3 implementation
 
 // getProjectDir returns the directory path for a project
@@ -160,10 +365,39 @@ func (p *DockerProvisioner) getProjectDir(projectID string) string {
 	return filepath.Join(p.baseDir, projectID)
 }
 
-// renderTemplate renders a template file with project config
+// renderTemplate renders a template file with project config, unknown fields are an error
 func (p *DockerProvisioner) renderTemplate(templatePath string, config *ProjectConfig) (string, error) {
-	// Will implement template rendering using text/template
-	return "", fmt.Errorf("not implemented yet")
+	tmpl, err := template.New(filepath.Base(templatePath)).Option("missingkey=error").ParseFiles(templatePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %s: %w", templatePath, err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, config); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", templatePath, err)
+	}
+	return out.String(), nil
+}
+
+// writeFileAtomic replaces a file through a rename so readers never see a partial file
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
 
 // generateSecrets generates secure random secrets for a project
@@ -35,6 +35,7 @@ type ProjectConfig struct {
 	JWTSecret     string // JWT signing secret
 	AnonKey       string // Anonymous API key
 	ServiceKey    string // Service role API key
+	APIKeys       []APIKey // Additional API keys, rendered as Kong consumers
 
 	// Dashboard
 	DashboardUser string // Dashboard username
@@ -46,6 +47,13 @@ type ProjectConfig struct {
 	StorageLimit  string // Storage limit (e.g., "10GB")
 }
 
+// APIKey is a Kong consumer of a project besides anon and service_role
+type APIKey struct {
+	Consumer string // Kong consumer name, unique within the project
+	Key      string // Key the consumer authenticates with
+	Group    string // ACL group, only admin reaches pg-meta
+}
+
 // ProjectInfo contains runtime information about a provisioned project
 type ProjectInfo struct {
 	ProjectID     string
@@ -101,6 +109,25 @@ type Provisioner interface {
 	// ExecuteCommand runs a command in a specific service container
 	// Useful for database migrations, backups, etc.
 	ExecuteCommand(ctx context.Context, projectID string, service string, cmd []string) (string, error)
+
+	// LoadProjectConfig returns the configuration the project was last rendered with
+	LoadProjectConfig(ctx context.Context, projectID string) (*ProjectConfig, error)
+
+	// RenderProject writes the project's compose and Kong files from config
+	// Running containers keep their configuration until they are recreated
+	RenderProject(ctx context.Context, config *ProjectConfig) error
+
+	// RecreateServices recreates the containers of the given services one after the other,
+	// waiting for each to be running before the next, so they pick up the rendered configuration
+	RecreateServices(ctx context.Context, projectID string, services []string) error
+
+	// ReloadGateway makes the project's API gateway load its rendered configuration
+	// without restarting, open connections are kept
+	ReloadGateway(ctx context.Context, projectID string) error
+
+	// CreateBackup dumps a project's database into config.Dir and returns the completed backup
+	// with its SHA-256. The file is complete once this returns
+	CreateBackup(ctx context.Context, config *BackupConfig) (*BackupInfo, error)
 }
 
 // ProvisionerError represents an error that occurred during provisioning
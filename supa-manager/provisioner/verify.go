@@ -0,0 +1,356 @@
+package provisioner
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// VerificationResult contains the outcome of a backup restore test
+type VerificationResult struct {
+	BackupID   string
+	Status     VerificationStatus
+	Checksum   string // Checksum computed during verification
+	TableCount int    // User tables found after restore
+	Error      string // Populated when Status is CORRUPT
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// BackupVerifier restores backups into throwaway Postgres containers
+// to prove that they can actually be restored
+type BackupVerifier struct {
+	client *client.Client
+
+	// Postgres image used for the scratch database, e.g. "supabase/postgres:14.2"
+	image string
+
+	// Upper bound for a single verification (startup + restore + checks)
+	timeout time.Duration
+}
+
+// sanityChecks are run against the restored database, each must print a single value
+var sanityChecks = []struct {
+	name  string
+	query string
+	check func(value string) error
+}{
+	{
+		name:  "public schema present",
+		query: `SELECT count(*) FROM pg_catalog.pg_namespace WHERE nspname = 'public'`,
+		check: expectCount(func(n int) bool { return n == 1 }),
+	},
+	{
+		name:  "no invalid indexes",
+		query: `SELECT count(*) FROM pg_catalog.pg_index WHERE NOT indisvalid`,
+		check: expectCount(func(n int) bool { return n == 0 }),
+	},
+	{
+		name: "all user tables readable",
+		query: `DO $$
+DECLARE r record;
+BEGIN
+    FOR r IN SELECT schemaname, tablename FROM pg_catalog.pg_tables
+             WHERE schemaname NOT IN ('pg_catalog', 'information_schema')
+    LOOP
+        EXECUTE format('SELECT count(*) FROM %I.%I', r.schemaname, r.tablename);
+    END LOOP;
+END $$`,
+		check: func(string) error { return nil },
+	},
+}
+
+const tableCountQuery = `SELECT count(*) FROM pg_catalog.pg_tables WHERE schemaname NOT IN ('pg_catalog', 'information_schema')`
+
+// NewBackupVerifier creates a verifier that uses the given Postgres image for scratch databases
+func NewBackupVerifier(image string, timeout time.Duration) (*BackupVerifier, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Docker client: %w", err)
+	}
+
+	if _, err := cli.Ping(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to connect to Docker daemon: %w", err)
+	}
+
+	return &BackupVerifier{
+		client:  cli,
+		image:   image,
+		timeout: timeout,
+	}, nil
+}
+
+// ComputeChecksum returns the hex-encoded SHA-256 of a backup file
+func ComputeChecksum(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Verify checks the backup checksum, restores it into a scratch container and runs sanity queries.
+// A non-nil error means verification could not be performed; a failed verification is reported
+// through the result status instead.
+func (v *BackupVerifier) Verify(ctx context.Context, backup *BackupInfo) (*VerificationResult, error) {
+	result := &VerificationResult{
+		BackupID:  backup.BackupID,
+		StartedAt: time.Now(),
+	}
+	corrupt := func(format string, args ...interface{}) (*VerificationResult, error) {
+		result.Status = VerificationCorrupt
+		result.Error = fmt.Sprintf(format, args...)
+		result.FinishedAt = time.Now()
+		return result, nil
+	}
+
+	if backup.Encrypted {
+		return nil, fmt.Errorf("backup %s is encrypted and cannot be verified", backup.BackupID)
+	}
+
+	checksum, err := ComputeChecksum(backup.FilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return corrupt("backup file %s is missing", backup.FilePath)
+		}
+		return nil, fmt.Errorf("failed to checksum backup: %w", err)
+	}
+	result.Checksum = checksum
+	if backup.Checksum != "" && !strings.EqualFold(backup.Checksum, checksum) {
+		return corrupt("checksum mismatch: expected %s, got %s", backup.Checksum, checksum)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+
+	containerID, err := v.startScratchDatabase(ctx, backup)
+	if err != nil {
+		return nil, err
+	}
+	defer v.client.ContainerRemove(context.Background(), containerID, types.ContainerRemoveOptions{
+		Force:         true,
+		RemoveVolumes: true,
+	})
+
+	if err := v.waitForReady(ctx, containerID); err != nil {
+		return nil, err
+	}
+
+	if out, code, err := v.exec(ctx, containerID, []string{"createdb", "-U", "postgres", "verify"}); err != nil || code != 0 {
+		return nil, fmt.Errorf("failed to create scratch database: %s", describeExec(out, code, err))
+	}
+
+	out, code, err := v.exec(ctx, containerID, []string{"bash", "-c", restoreCommand(filepath.Base(backup.FilePath))})
+	if err != nil {
+		return nil, fmt.Errorf("failed to run restore: %w", err)
+	}
+	if code != 0 {
+		return corrupt("restore failed: %s", describeExec(out, code, nil))
+	}
+
+	for _, sc := range sanityChecks {
+		out, code, err := v.psql(ctx, containerID, sc.query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run sanity check %q: %w", sc.name, err)
+		}
+		if code != 0 {
+			return corrupt("sanity check %q failed: %s", sc.name, describeExec(out, code, nil))
+		}
+		if err := sc.check(out); err != nil {
+			return corrupt("sanity check %q failed: %v", sc.name, err)
+		}
+	}
+
+	out, code, err = v.psql(ctx, containerID, tableCountQuery)
+	if err != nil || code != 0 {
+		return corrupt("failed to count tables: %s", describeExec(out, code, err))
+	}
+	result.TableCount, _ = strconv.Atoi(strings.TrimSpace(out))
+	if result.TableCount == 0 {
+		// Every provisioned project has at least the auth and storage tables
+		return corrupt("restored database contains no tables")
+	}
+
+	result.Status = VerificationVerified
+	result.FinishedAt = time.Now()
+	return result, nil
+}
+
+// startScratchDatabase starts a throwaway Postgres container with the backup directory mounted read-only
+func (v *BackupVerifier) startScratchDatabase(ctx context.Context, backup *BackupInfo) (string, error) {
+	if err := v.ensureImage(ctx); err != nil {
+		return "", err
+	}
+
+	backupDir, err := filepath.Abs(filepath.Dir(backup.FilePath))
+	if err != nil {
+		return "", err
+	}
+
+	password := make([]byte, 16)
+	if _, err := rand.Read(password); err != nil {
+		return "", err
+	}
+
+	created, err := v.client.ContainerCreate(ctx,
+		&container.Config{
+			Image: v.image,
+			Env:   []string{"POSTGRES_PASSWORD=" + hex.EncodeToString(password)},
+			Labels: map[string]string{
+				"supamanager.io/purpose":   "backup-verification",
+				"supamanager.io/backup-id": backup.BackupID,
+			},
+		},
+		&container.HostConfig{
+			Binds: []string{backupDir + ":/backup:ro"},
+		},
+		nil, nil,
+		fmt.Sprintf("verify-%s-%d", backup.ProjectID, time.Now().Unix()),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create scratch container: %w", err)
+	}
+
+	if err := v.client.ContainerStart(ctx, created.ID, types.ContainerStartOptions{}); err != nil {
+		v.client.ContainerRemove(context.Background(), created.ID, types.ContainerRemoveOptions{Force: true})
+		return "", fmt.Errorf("failed to start scratch container: %w", err)
+	}
+	return created.ID, nil
+}
+
+// ensureImage pulls the scratch database image unless it's already present
+func (v *BackupVerifier) ensureImage(ctx context.Context) error {
+	if _, _, err := v.client.ImageInspectWithRaw(ctx, v.image); err == nil {
+		return nil
+	} else if !client.IsErrNotFound(err) {
+		return fmt.Errorf("failed to inspect %s: %w", v.image, err)
+	}
+
+	pull, err := v.client.ImagePull(ctx, v.image, types.ImagePullOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to pull %s: %w", v.image, err)
+	}
+	defer pull.Close()
+	if _, err := io.Copy(io.Discard, pull); err != nil {
+		return fmt.Errorf("failed to pull %s: %w", v.image, err)
+	}
+	return nil
+}
+
+// waitForReady polls pg_isready until the scratch database accepts connections
+func (v *BackupVerifier) waitForReady(ctx context.Context, containerID string) error {
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for {
+		if _, code, err := v.exec(ctx, containerID, []string{"pg_isready", "-U", "postgres"}); err == nil && code == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("scratch database did not become ready: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+func (v *BackupVerifier) psql(ctx context.Context, containerID string, query string) (string, int, error) {
+	return v.exec(ctx, containerID, []string{
+		"psql", "-U", "postgres", "-d", "verify", "-v", "ON_ERROR_STOP=1", "-tA", "-c", query,
+	})
+}
+
+// exec runs a command in the container and returns combined output and the exit code
+func (v *BackupVerifier) exec(ctx context.Context, containerID string, cmd []string) (string, int, error) {
+	created, err := v.client.ContainerExecCreate(ctx, containerID, types.ExecConfig{
+		Cmd:          cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return "", -1, err
+	}
+
+	attached, err := v.client.ContainerExecAttach(ctx, created.ID, types.ExecStartCheck{})
+	if err != nil {
+		return "", -1, err
+	}
+	defer attached.Close()
+
+	var stdout, stderr bytes.Buffer
+	if _, err := stdcopy.StdCopy(&stdout, &stderr, attached.Reader); err != nil {
+		return "", -1, err
+	}
+
+	inspect, err := v.client.ContainerExecInspect(ctx, created.ID)
+	if err != nil {
+		return "", -1, err
+	}
+	return stdout.String() + stderr.String(), inspect.ExitCode, nil
+}
+
+// restoreCommand builds the shell pipeline that loads a backup file into the scratch database.
+// Custom-format dumps go through pg_restore, plain SQL through psql, and full backup archives
+// are unpacked first so their database dump can be restored.
+func restoreCommand(fileName string) string {
+	src := "/backup/" + fileName
+	restore := "pg_restore -U postgres -d verify --no-owner --no-privileges --exit-on-error"
+	plain := "psql -U postgres -d verify -v ON_ERROR_STOP=1 -q"
+
+	switch {
+	case strings.HasSuffix(fileName, ".tar.gz"):
+		return fmt.Sprintf("set -e; mkdir -p /tmp/restore && tar xzf %q -C /tmp/restore && "+
+			"f=$(ls /tmp/restore/db/*.dump | head -n 1) && %s \"$f\"", src, restore)
+	case strings.HasSuffix(fileName, ".sql.gz"):
+		return fmt.Sprintf("set -e -o pipefail; gunzip -c %q | %s", src, plain)
+	case strings.HasSuffix(fileName, ".gz"):
+		return fmt.Sprintf("set -e -o pipefail; gunzip -c %q | %s", src, restore)
+	case strings.HasSuffix(fileName, ".sql"):
+		return fmt.Sprintf("%s -f %q", plain, src)
+	default:
+		return fmt.Sprintf("%s %q", restore, src)
+	}
+}
+
+func expectCount(ok func(n int) bool) func(string) error {
+	return func(value string) error {
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("unexpected output %q", value)
+		}
+		if !ok(n) {
+			return fmt.Errorf("unexpected count %d", n)
+		}
+		return nil
+	}
+}
+
+func describeExec(out string, code int, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	out = strings.TrimSpace(out)
+	if len(out) > 500 {
+		out = "..." + out[len(out)-500:]
+	}
+	return fmt.Sprintf("exit code %d: %s", code, out)
+}
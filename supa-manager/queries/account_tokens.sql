@@ -0,0 +1,27 @@
+-- name: CreateAccountToken :one
+INSERT INTO public.account_tokens (account_id, purpose, token_hash, email, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetLatestAccountToken :one
+SELECT *
+FROM public.account_tokens
+WHERE account_id = $1
+  AND purpose = $2
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: DeleteUnusedAccountTokens :exec
+DELETE FROM public.account_tokens
+WHERE account_id = $1
+  AND purpose = $2
+  AND used_at IS NULL;
+
+-- name: UseAccountToken :one
+UPDATE public.account_tokens
+SET used_at = now()
+WHERE token_hash = $1
+  AND purpose = $2
+  AND used_at IS NULL
+  AND expires_at > now()
+RETURNING *;
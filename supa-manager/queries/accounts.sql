@@ -1,6 +1,6 @@
 -- name: CreateAccount :one
-INSERT INTO public.accounts (email, password_hash, username)
-VALUES ($1, $2, $3)
+INSERT INTO public.accounts (email, password_hash, username, email_confirmed_at)
+VALUES ($1, $2, $3, $4)
 RETURNING *;
 
 -- name: SetAccountName :exec
@@ -16,4 +16,23 @@ SELECT * FROM public.accounts WHERE email = $1;
 SELECT * FROM public.accounts WHERE id = $1;
 
 -- name: GetAccountByGoTrueID :one
-SELECT * FROM public.accounts WHERE gotrue_id = $1;
\ No newline at end of file
+SELECT * FROM public.accounts WHERE gotrue_id = $1;
+
+-- name: ConfirmAccountEmail :exec
+UPDATE public.accounts
+SET email_confirmed_at = coalesce(email_confirmed_at, now()),
+    updated_at         = now()
+WHERE id = $1;
+
+-- name: SetAccountPassword :exec
+UPDATE public.accounts
+SET password_hash = $2,
+    updated_at    = now()
+WHERE id = $1;
+
+-- name: SetAccountEmail :exec
+UPDATE public.accounts
+SET email              = $2,
+    email_confirmed_at = now(),
+    updated_at         = now()
+WHERE id = $1;
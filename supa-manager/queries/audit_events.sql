@@ -0,0 +1,17 @@
+-- name: CreateAuditEvent :exec
+INSERT INTO public.audit_events (actor_id, actor_email, token_id, organization_id, project_ref, action, target, status,
+                                 error, ip, user_agent, request_id, payload)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13);
+
+-- name: GetAuditEvents :many
+SELECT *
+FROM public.audit_events
+WHERE organization_id = @organization_id
+  AND (sqlc.narg('actor_id')::int IS NULL OR actor_id = sqlc.narg('actor_id'))
+  AND (sqlc.narg('project_ref')::text IS NULL OR project_ref = sqlc.narg('project_ref'))
+  AND (sqlc.narg('action')::text IS NULL OR starts_with(action, sqlc.narg('action')))
+  AND (sqlc.narg('occurred_after')::timestamptz IS NULL OR occurred_at >= sqlc.narg('occurred_after'))
+  AND (sqlc.narg('occurred_before')::timestamptz IS NULL OR occurred_at < sqlc.narg('occurred_before'))
+  AND (sqlc.narg('before_id')::bigint IS NULL OR id < sqlc.narg('before_id'))
+ORDER BY id DESC
+LIMIT @max_events;
@@ -0,0 +1,53 @@
+-- name: CreateBackup :one
+INSERT INTO public.backups (id, project_ref, backup_type, status, file_path, size_bytes, compressed, encrypted, checksum_sha256, completed_at, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: GetBackupByID :one
+SELECT *
+FROM public.backups
+WHERE id = $1;
+
+-- name: GetBackupsForProject :many
+SELECT *
+FROM public.backups
+WHERE project_ref = $1
+ORDER BY created_at DESC;
+
+-- name: GetBackupsDueForVerification :many
+SELECT *
+FROM public.backups
+WHERE status = 'COMPLETED'
+  AND ((verification_status <> 'VERIFYING' AND (verified_at IS NULL OR verified_at < sqlc.arg('verified_before')))
+    OR (verification_status = 'VERIFYING' AND verification_started_at < sqlc.arg('stale_before')))
+ORDER BY verified_at NULLS FIRST, created_at
+LIMIT sqlc.arg('max_results');
+
+-- name: SetBackupChecksum :exec
+UPDATE public.backups
+SET checksum_sha256 = $2,
+    size_bytes      = $3
+WHERE id = $1;
+
+-- name: StartBackupVerification :execrows
+UPDATE public.backups
+SET verification_status     = 'VERIFYING',
+    verification_started_at = now()
+WHERE id = sqlc.arg('id')
+  AND (verification_status <> 'VERIFYING' OR verification_started_at < sqlc.arg('stale_before'));
+
+-- name: UpdateBackupVerification :one
+UPDATE public.backups
+SET verification_status     = $2,
+    verification_error      = $3,
+    verification_started_at = NULL,
+    verified_at             = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: ResetBackupVerification :exec
+UPDATE public.backups
+SET verification_status     = $2,
+    verification_error      = $3,
+    verification_started_at = NULL
+WHERE id = $1;
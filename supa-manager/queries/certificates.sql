@@ -0,0 +1,88 @@
+-- name: GetAcmeAccount :one
+SELECT *
+FROM public.acme_accounts
+WHERE directory_url = $1;
+
+-- name: CreateAcmeAccount :exec
+INSERT INTO public.acme_accounts (directory_url, key_encrypted, account_url)
+VALUES ($1, $2, $3)
+ON CONFLICT (directory_url) DO NOTHING;
+
+-- name: RequestCertificate :one
+INSERT INTO public.certificates (name, domains, challenge)
+VALUES ($1, $2, $3)
+ON CONFLICT (name) DO UPDATE
+    SET domains   = excluded.domains,
+        challenge = excluded.challenge
+RETURNING *;
+
+-- name: GetCertificate :one
+SELECT *
+FROM public.certificates
+WHERE name = $1;
+
+-- name: GetIssuedCertificates :many
+SELECT *
+FROM public.certificates
+WHERE cert_pem IS NOT NULL
+  AND key_encrypted IS NOT NULL;
+
+-- name: GetDueCertificates :many
+SELECT *
+FROM public.certificates
+WHERE (locked_until IS NULL OR locked_until < now())
+  AND (status = 'pending'
+    OR (status = 'failed' AND updated_at < @retry_before)
+    OR (status = 'issued' AND not_after < @renew_before))
+ORDER BY id;
+
+-- name: ClaimCertificate :one
+UPDATE public.certificates
+SET locked_until = @locked_until
+WHERE id = @id
+  AND (locked_until IS NULL OR locked_until < now())
+RETURNING *;
+
+-- name: SaveIssuedCertificate :exec
+UPDATE public.certificates
+SET status        = 'issued',
+    cert_pem      = @cert_pem,
+    key_encrypted = @key_encrypted,
+    not_before    = @not_before,
+    not_after     = @not_after,
+    error         = NULL,
+    locked_until  = NULL,
+    updated_at    = now()
+WHERE id = @id;
+
+-- name: FailCertificate :exec
+UPDATE public.certificates
+SET status       = 'failed',
+    error        = @error,
+    locked_until = NULL,
+    updated_at   = now()
+WHERE id = @id;
+
+-- name: DeleteCertificate :exec
+DELETE
+FROM public.certificates
+WHERE name = $1;
+
+-- name: CreateAcmeChallenge :exec
+INSERT INTO public.acme_challenges (token, key_authorization, expires_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (token) DO UPDATE
+    SET key_authorization = excluded.key_authorization,
+        expires_at        = excluded.expires_at;
+
+-- name: GetAcmeChallenge :one
+SELECT *
+FROM public.acme_challenges
+WHERE token = $1
+  AND expires_at > now();
+
+-- name: DeleteAcmeChallenge :exec
+DELETE
+FROM public.acme_challenges
+WHERE token = $1
+   OR expires_at < now();
@@ -0,0 +1,61 @@
+-- name: CreateCustomHostname :one
+INSERT INTO public.custom_hostnames (project_id, hostname, verification_token)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetCustomHostnameByProject :one
+SELECT *
+FROM public.custom_hostnames
+WHERE project_id = $1;
+
+-- name: GetActiveCustomHostname :one
+SELECT *
+FROM public.custom_hostnames
+WHERE hostname = $1
+  AND status = 'active';
+
+-- name: IsCustomHostnameClaimed :one
+SELECT EXISTS(SELECT 1
+              FROM public.custom_hostnames
+              WHERE hostname = $1
+                AND status IN ('verified', 'active'));
+
+-- name: GetUnfinishedCustomHostnames :many
+SELECT *
+FROM public.custom_hostnames
+WHERE status IN ('pending_verification', 'verified')
+ORDER BY id;
+
+-- name: RecordCustomHostnameCheck :one
+UPDATE public.custom_hostnames
+SET error           = @error,
+    last_checked_at = now(),
+    updated_at      = now()
+WHERE id = @id
+RETURNING *;
+
+-- name: VerifyCustomHostname :one
+UPDATE public.custom_hostnames
+SET status          = 'verified',
+    error           = NULL,
+    last_checked_at = now(),
+    verified_at     = now(),
+    updated_at      = now()
+WHERE id = $1
+  AND status = 'pending_verification'
+RETURNING *;
+
+-- name: ActivateCustomHostname :one
+UPDATE public.custom_hostnames
+SET status       = 'active',
+    error        = NULL,
+    activated_at = now(),
+    updated_at   = now()
+WHERE id = $1
+  AND status = 'verified'
+RETURNING *;
+
+-- name: DeleteCustomHostname :exec
+DELETE
+FROM public.custom_hostnames
+WHERE id = $1;
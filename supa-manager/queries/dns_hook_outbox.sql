@@ -0,0 +1,41 @@
+-- name: EnqueueDnsHookUpdate :exec
+INSERT INTO public.dns_hook_outbox (type, hostname, project_ref, record_type, value)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ClaimDueDnsHookUpdate :one
+UPDATE public.dns_hook_outbox
+SET locked_until = @locked_until
+WHERE id IN (SELECT o.id
+             FROM public.dns_hook_outbox o
+             WHERE o.failed_at IS NULL
+               AND o.next_attempt_at <= now()
+               AND (o.locked_until IS NULL OR o.locked_until < now())
+               AND NOT EXISTS (SELECT 1
+                               FROM public.dns_hook_outbox earlier
+                               WHERE earlier.hostname = o.hostname
+                                 AND earlier.failed_at IS NULL
+                                 AND earlier.id < o.id)
+             ORDER BY o.id
+             LIMIT 1 FOR UPDATE SKIP LOCKED)
+RETURNING *;
+
+-- name: DeleteDnsHookUpdate :exec
+DELETE
+FROM public.dns_hook_outbox
+WHERE id = $1;
+
+-- name: RetryDnsHookUpdate :exec
+UPDATE public.dns_hook_outbox
+SET attempts        = attempts + 1,
+    next_attempt_at = @next_attempt_at,
+    locked_until    = NULL,
+    last_error      = @last_error
+WHERE id = @id;
+
+-- name: FailDnsHookUpdate :exec
+UPDATE public.dns_hook_outbox
+SET attempts     = attempts + 1,
+    failed_at    = now(),
+    locked_until = NULL,
+    last_error   = @last_error
+WHERE id = @id;
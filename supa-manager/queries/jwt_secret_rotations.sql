@@ -0,0 +1,43 @@
+-- name: CreateJwtSecretRotation :one
+INSERT INTO public.jwt_secret_rotations (project_id, change_tracking_id, requested_by)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetLatestJwtSecretRotation :one
+SELECT *
+FROM public.jwt_secret_rotations
+WHERE project_id = $1
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: UpdateJwtSecretRotationProgress :exec
+UPDATE public.jwt_secret_rotations
+SET status     = 'in_progress',
+    step       = @step,
+    progress   = @progress,
+    updated_at = now()
+WHERE id = @id;
+
+-- name: CompleteJwtSecretRotation :exec
+UPDATE public.jwt_secret_rotations
+SET status       = 'completed',
+    step         = 'completed',
+    progress     = 100,
+    updated_at   = now(),
+    completed_at = now()
+WHERE id = $1;
+
+-- name: FailJwtSecretRotation :exec
+UPDATE public.jwt_secret_rotations
+SET status       = 'failed',
+    error        = @error,
+    rolled_back  = @rolled_back,
+    updated_at   = now(),
+    completed_at = now()
+WHERE id = @id;
+
+-- name: GetStaleJwtSecretRotations :many
+SELECT *
+FROM public.jwt_secret_rotations
+WHERE status IN ('pending', 'in_progress')
+  AND updated_at < @updated_before;
@@ -0,0 +1,79 @@
+-- name: CreateMfaFactor :one
+INSERT INTO public.mfa_factors (account_id, friendly_name, factor_type, secret_encrypted)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetMfaFactor :one
+SELECT *
+FROM public.mfa_factors
+WHERE id = $1
+  AND account_id = $2;
+
+-- name: GetMfaFactorForUpdate :one
+SELECT *
+FROM public.mfa_factors
+WHERE id = $1
+  AND account_id = $2
+FOR UPDATE;
+
+-- name: GetMfaFactors :many
+SELECT *
+FROM public.mfa_factors
+WHERE account_id = $1
+ORDER BY created_at;
+
+-- name: CountVerifiedMfaFactors :one
+SELECT count(*)
+FROM public.mfa_factors
+WHERE account_id = $1
+  AND status = 'verified';
+
+-- name: VerifyMfaFactor :exec
+UPDATE public.mfa_factors
+SET status         = 'verified',
+    last_used_step = $2,
+    updated_at     = now()
+WHERE id = $1;
+
+-- name: DeleteMfaFactor :exec
+DELETE FROM public.mfa_factors
+WHERE id = $1
+  AND account_id = $2;
+
+-- name: CreateMfaChallenge :one
+INSERT INTO public.mfa_challenges (factor_id, ip)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetMfaChallengeForUpdate :one
+SELECT *
+FROM public.mfa_challenges
+WHERE id = $1
+  AND factor_id = $2
+FOR UPDATE;
+
+-- name: VerifyMfaChallenge :exec
+UPDATE public.mfa_challenges
+SET verified_at = now()
+WHERE id = $1;
+
+-- name: CreateMfaRecoveryCode :exec
+INSERT INTO public.mfa_recovery_codes (account_id, code_hash)
+VALUES ($1, $2);
+
+-- name: CountUnusedMfaRecoveryCodes :one
+SELECT count(*)
+FROM public.mfa_recovery_codes
+WHERE account_id = $1
+  AND used_at IS NULL;
+
+-- name: UseMfaRecoveryCode :execrows
+UPDATE public.mfa_recovery_codes
+SET used_at = now()
+WHERE account_id = $1
+  AND code_hash = $2
+  AND used_at IS NULL;
+
+-- name: DeleteMfaRecoveryCodes :exec
+DELETE FROM public.mfa_recovery_codes
+WHERE account_id = $1;
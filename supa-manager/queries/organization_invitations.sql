@@ -0,0 +1,45 @@
+-- name: CreateOrganizationInvitation :one
+INSERT INTO public.organization_invitations (organization_id, invited_email, role, token_hash, invited_by, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: DeletePendingOrganizationInvitations :exec
+DELETE FROM public.organization_invitations
+WHERE organization_id = @organization_id
+  AND lower(invited_email) = lower(@invited_email::text)
+  AND accepted_at IS NULL;
+
+-- name: GetPendingOrganizationInvitations :many
+SELECT *
+FROM public.organization_invitations
+WHERE organization_id = $1
+  AND accepted_at IS NULL
+ORDER BY created_at;
+
+-- name: GetPendingOrganizationInvitation :one
+SELECT *
+FROM public.organization_invitations
+WHERE id = $1
+  AND organization_id = $2
+  AND accepted_at IS NULL;
+
+-- name: DeleteOrganizationInvitation :execrows
+DELETE FROM public.organization_invitations
+WHERE id = $1
+  AND organization_id = $2
+  AND accepted_at IS NULL;
+
+-- name: GetOrganizationInvitationByToken :one
+SELECT *
+FROM public.organization_invitations
+WHERE token_hash = $1
+  AND accepted_at IS NULL;
+
+-- name: AcceptOrganizationInvitation :one
+UPDATE public.organization_invitations
+SET accepted_at = now(),
+    accepted_by = $2
+WHERE token_hash = $1
+  AND accepted_at IS NULL
+  AND expires_at > now()
+RETURNING *;
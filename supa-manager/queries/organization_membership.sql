@@ -1,4 +1,54 @@
 -- name: CreateOrganizationMembership :one
 INSERT INTO organization_membership (organization_id, account_id, role)
 VALUES ($1, $2, $3)
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: EnsureOrganizationMembership :exec
+INSERT INTO organization_membership (organization_id, account_id, role)
+VALUES ($1, $2, $3)
+ON CONFLICT (organization_id, account_id) DO NOTHING;
+
+-- name: GetOrganizationMemberRole :one
+SELECT role
+FROM organization_membership
+WHERE organization_id = $1
+  AND account_id = $2;
+
+-- name: GetOrganizationMembers :many
+SELECT a.id,
+       a.gotrue_id,
+       a.email,
+       a.username,
+       om.role,
+       EXISTS (SELECT 1 FROM public.mfa_factors f WHERE f.account_id = a.id AND f.status = 'verified') AS mfa_enabled,
+       EXISTS (SELECT 1 FROM public.account_identities i WHERE i.account_id = a.id) AS is_sso_user
+FROM organization_membership om
+         JOIN accounts a on a.id = om.account_id
+WHERE om.organization_id = $1
+ORDER BY om.created_at;
+
+-- name: GetOrganizationMemberByGotrueId :one
+SELECT a.id, om.role
+FROM organization_membership om
+         JOIN accounts a on a.id = om.account_id
+WHERE om.organization_id = $1
+  AND a.gotrue_id = $2;
+
+-- name: LockOrganizationOwners :many
+SELECT account_id
+FROM organization_membership
+WHERE organization_id = $1
+  AND role = 'OWNER'
+FOR UPDATE;
+
+-- name: UpdateOrganizationMemberRole :exec
+UPDATE organization_membership
+SET role       = $3,
+    updated_at = now()
+WHERE organization_id = $1
+  AND account_id = $2;
+
+-- name: DeleteOrganizationMembership :execrows
+DELETE FROM organization_membership
+WHERE organization_id = $1
+  AND account_id = $2;
@@ -1,11 +1,14 @@
 -- name: CreateOrganization :one
-INSERT INTO public.organizations (name, created_at, updated_at)
-VALUES ($1, now(), now())
+INSERT INTO public.organizations (name, kind, size, plan, billing_email, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, now(), now())
 RETURNING *;
 
 -- name: GetOrganizationById :one
 SELECT * FROM public.organizations WHERE slug = sqlc.arg('id');
 
+-- name: GetOrganization :one
+SELECT * FROM public.organizations WHERE id = $1;
+
 -- name: GetOrganizationsForAccountId :many
 SELECT o.*, om.role as member_role
 FROM organization_membership om
@@ -16,4 +19,23 @@ WHERE account_id = $1;
 SELECT o.id
 FROM organization_membership om
          JOIN organizations o on o.id = om.organization_id
-WHERE account_id = $1;
\ No newline at end of file
+WHERE account_id = $1;
+
+-- name: LockOrganization :one
+SELECT *
+FROM public.organizations
+WHERE id = $1
+FOR UPDATE;
+
+-- name: UpdateOrganization :one
+UPDATE public.organizations
+SET name          = $2,
+    billing_email = $3,
+    opt_in_tags   = $4,
+    updated_at    = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteOrganization :exec
+DELETE FROM public.organizations
+WHERE id = $1;
@@ -0,0 +1,38 @@
+-- name: CreatePersonalAccessToken :one
+INSERT INTO public.personal_access_tokens (account_id, name, token_hash, token_alias, read_only, organization_id, project_ref, aal, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetActivePersonalAccessToken :one
+SELECT *
+FROM public.personal_access_tokens
+WHERE token_hash = $1
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > now());
+
+-- name: GetPersonalAccessTokens :many
+SELECT *
+FROM public.personal_access_tokens
+WHERE account_id = $1
+  AND revoked_at IS NULL
+ORDER BY created_at DESC;
+
+-- name: CountPersonalAccessTokens :one
+SELECT count(*)
+FROM public.personal_access_tokens
+WHERE account_id = $1
+  AND revoked_at IS NULL;
+
+-- name: RevokePersonalAccessToken :execrows
+UPDATE public.personal_access_tokens
+SET revoked_at = now()
+WHERE id = $1
+  AND account_id = $2
+  AND revoked_at IS NULL;
+
+-- name: TouchPersonalAccessToken :exec
+UPDATE public.personal_access_tokens
+SET last_used_at = now(),
+    last_used_ip = $2
+WHERE id = $1
+  AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute');
@@ -0,0 +1,38 @@
+-- name: CreateProjectApiKey :one
+INSERT INTO public.project_api_keys (project_id, name, role, key_encrypted, key_alias, created_by, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetProjectApiKeys :many
+SELECT *
+FROM public.project_api_keys
+WHERE project_id = $1
+  AND revoked_at IS NULL
+ORDER BY created_at DESC;
+
+-- name: GetActiveProjectApiKeys :many
+SELECT *
+FROM public.project_api_keys
+WHERE project_id = $1
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > now())
+ORDER BY id;
+
+-- name: CountProjectApiKeys :one
+SELECT count(*)
+FROM public.project_api_keys
+WHERE project_id = $1
+  AND revoked_at IS NULL;
+
+-- name: RevokeProjectApiKey :execrows
+UPDATE public.project_api_keys
+SET revoked_at = now()
+WHERE id = $1
+  AND project_id = $2
+  AND revoked_at IS NULL;
+
+-- name: UpdateProjectApiKeySecret :exec
+UPDATE public.project_api_keys
+SET key_encrypted = @key_encrypted,
+    key_alias     = @key_alias
+WHERE id = @id;
@@ -5,8 +5,8 @@ FROM organization_membership om
 WHERE account_id = $1;
 
 -- name: CreateProject :one
-INSERT INTO project (project_ref, project_name, organization_id, status, jwt_secret, cloud_provider, region)
-VALUES ($1, $2, $3, 'PROVISIONING', $4, $5, $6)
+INSERT INTO project (project_ref, project_name, organization_id, status, jwt_secret, cloud_provider, region, db_password_encrypted)
+VALUES ($1, $2, $3, 'PROVISIONING', $4, $5, $6, $7)
 RETURNING *;
 
 -- name: GetProjectByRef :one
@@ -14,12 +14,34 @@ SELECT *
 FROM project
 WHERE project_ref = $1;
 
+-- name: GetProjectByID :one
+SELECT *
+FROM project
+WHERE id = $1;
+
+-- name: LockProject :exec
+SELECT id
+FROM project
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetProjectForUpdate :one
+SELECT *
+FROM project
+WHERE id = $1
+FOR UPDATE;
+
 -- name: UpdateProjectStatus :one
 UPDATE project
 SET status = $2, updated_at = now()
 WHERE project_ref = $1
 RETURNING *;
 
+-- name: SwapProjectStatus :execrows
+UPDATE project
+SET status = @status, updated_at = now()
+WHERE project_ref = @project_ref AND status = @expected_status;
+
 -- name: UpdateProjectInfrastructure :one
 UPDATE project
 SET docker_compose_path = $2,
@@ -42,4 +64,30 @@ ORDER BY created_at DESC;
 
 -- name: DeleteProject :exec
 DELETE FROM project
-WHERE project_ref = $1;
\ No newline at end of file
+WHERE project_ref = $1;
+
+-- name: GetProjectsForOrganization :many
+SELECT *
+FROM project
+WHERE organization_id = $1
+ORDER BY created_at;
+
+-- name: CountProjectsForOrganization :one
+SELECT count(*)
+FROM project
+WHERE organization_id = $1;
+
+-- name: UpdateProjectOrganization :one
+UPDATE project
+SET organization_id = $2, updated_at = now()
+WHERE project_ref = $1
+RETURNING *;
+
+-- name: UpdateProjectJwtSecret :one
+UPDATE project
+SET jwt_secret = $2,
+    anon_key = $3,
+    service_role_key = $4,
+    updated_at = now()
+WHERE project_ref = $1
+RETURNING *;
@@ -0,0 +1,16 @@
+-- name: CreateQueryHistory :exec
+INSERT INTO public.query_history (project_id, account_id, query, success, error_message, row_count, duration_ms)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: GetQueryHistory :many
+SELECT *
+FROM public.query_history
+WHERE project_id = $1
+  AND account_id = $2
+ORDER BY executed_at DESC
+LIMIT $3;
+
+-- name: DeleteQueryHistory :exec
+DELETE FROM public.query_history
+WHERE project_id = $1
+  AND account_id = $2;
@@ -0,0 +1,45 @@
+-- name: HitRateLimit :one
+INSERT INTO public.rate_limits (key, hits, window_start)
+VALUES (@key, 1, now())
+ON CONFLICT (key) DO UPDATE SET hits         = CASE
+                                                   WHEN rate_limits.window_start <= now() - make_interval(secs => @window_seconds::int)
+                                                       THEN 1
+                                                   ELSE rate_limits.hits + 1 END,
+                                window_start = CASE
+                                                   WHEN rate_limits.window_start <= now() - make_interval(secs => @window_seconds::int)
+                                                       THEN now()
+                                                   ELSE rate_limits.window_start END,
+                                updated_at   = now()
+RETURNING *;
+
+-- name: RecordRateLimitFailure :one
+INSERT INTO public.rate_limits (key, failures)
+VALUES (@key, 1)
+ON CONFLICT (key) DO UPDATE SET failures   = CASE
+                                                 WHEN rate_limits.updated_at <= now() - make_interval(secs => @window_seconds::int)
+                                                     THEN 1
+                                                 ELSE rate_limits.failures + 1 END,
+                                updated_at = now()
+RETURNING *;
+
+-- name: LockRateLimitKey :exec
+UPDATE public.rate_limits
+SET locked_until = @locked_until
+WHERE key = @key;
+
+-- name: GetRateLimit :one
+SELECT *
+FROM public.rate_limits
+WHERE key = $1;
+
+-- name: ResetRateLimitFailures :exec
+UPDATE public.rate_limits
+SET failures     = 0,
+    locked_until = NULL
+WHERE key = $1;
+
+-- name: PruneRateLimits :exec
+DELETE
+FROM public.rate_limits
+WHERE updated_at < @updated_before
+  AND (locked_until IS NULL OR locked_until < now());
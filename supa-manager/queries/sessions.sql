@@ -0,0 +1,84 @@
+-- name: CreateSession :one
+INSERT INTO public.sessions (family_id, parent_id, account_id, token_hash, user_agent, ip, expires_at, aal, amr)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetSessionByTokenHashForUpdate :one
+SELECT *
+FROM public.sessions
+WHERE token_hash = $1
+FOR UPDATE;
+
+-- name: GetActiveSessionForUpdate :one
+SELECT *
+FROM public.sessions
+WHERE family_id = $1
+  AND account_id = $2
+  AND revoked_at IS NULL
+  AND rotated_at IS NULL
+  AND expires_at > now()
+FOR UPDATE;
+
+-- name: RotateSession :exec
+UPDATE public.sessions
+SET rotated_at = now()
+WHERE id = $1;
+
+-- name: SetSessionSuccessor :exec
+UPDATE public.sessions
+SET successor_token_encrypted = $2
+WHERE id = $1;
+
+-- name: ClearSessionSuccessors :exec
+UPDATE public.sessions
+SET successor_token_encrypted = NULL
+WHERE account_id = @account_id
+  AND successor_token_encrypted IS NOT NULL
+  AND rotated_at < @rotated_before;
+
+-- name: IsSessionActive :one
+SELECT EXISTS (
+    SELECT 1
+    FROM public.sessions
+    WHERE family_id = $1
+      AND revoked_at IS NULL
+      AND rotated_at IS NULL
+      AND expires_at > now()
+);
+
+-- name: GetActiveSessions :many
+SELECT *
+FROM public.sessions
+WHERE account_id = $1
+  AND revoked_at IS NULL
+  AND rotated_at IS NULL
+  AND expires_at > now()
+ORDER BY created_at DESC;
+
+-- name: RevokeSessionFamily :exec
+UPDATE public.sessions
+SET revoked_at     = now(),
+    revoked_reason = $3
+WHERE family_id = $1
+  AND account_id = $2
+  AND revoked_at IS NULL;
+
+-- name: RevokeOtherSessions :exec
+UPDATE public.sessions
+SET revoked_at     = now(),
+    revoked_reason = $3
+WHERE account_id = $1
+  AND family_id <> $2
+  AND revoked_at IS NULL;
+
+-- name: RevokeAllSessions :exec
+UPDATE public.sessions
+SET revoked_at     = now(),
+    revoked_reason = $2
+WHERE account_id = $1
+  AND revoked_at IS NULL;
+
+-- name: DeleteExpiredSessions :exec
+DELETE FROM public.sessions
+WHERE account_id = $1
+  AND expires_at < now() - interval '7 days';
@@ -0,0 +1,26 @@
+-- name: CreateSsoState :exec
+INSERT INTO public.sso_states (state_hash, code_verifier, nonce, redirect_to)
+VALUES ($1, $2, $3, $4);
+
+-- name: ConsumeSsoState :one
+DELETE FROM public.sso_states
+WHERE state_hash = $1
+RETURNING *;
+
+-- name: DeleteExpiredSsoStates :exec
+DELETE FROM public.sso_states
+WHERE created_at < now() - interval '10 minutes';
+
+-- name: GetAccountIdentity :one
+SELECT *
+FROM public.account_identities
+WHERE provider = $1
+  AND subject = $2;
+
+-- name: UpsertAccountIdentity :exec
+INSERT INTO public.account_identities (provider, subject, account_id, email, claims)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (provider, subject) DO UPDATE
+    SET email           = excluded.email,
+        claims          = excluded.claims,
+        last_sign_in_at = now();
@@ -0,0 +1,36 @@
+-- name: CreateUserContent :one
+INSERT INTO public.user_content (id, project_id, owner_id, last_updated_by, type, visibility, name, description, content, favorite, folder_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: GetUserContentByID :one
+SELECT *
+FROM public.user_content
+WHERE id = $1
+  AND project_id = $2;
+
+-- name: GetVisibleUserContent :many
+SELECT *
+FROM public.user_content
+WHERE project_id = sqlc.arg('project_id')
+  AND (owner_id = sqlc.arg('account_id') OR visibility <> 'user')
+  AND (type = sqlc.narg('content_type') OR sqlc.narg('content_type') IS NULL)
+ORDER BY updated_at DESC;
+
+-- name: UpdateUserContent :one
+UPDATE public.user_content
+SET type            = $2,
+    visibility      = $3,
+    name            = $4,
+    description     = $5,
+    content         = $6,
+    favorite        = $7,
+    folder_id       = $8,
+    last_updated_by = $9,
+    updated_at      = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteUserContent :exec
+DELETE FROM public.user_content
+WHERE id = $1;
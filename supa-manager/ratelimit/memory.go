@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps limiter state in the process, limits are per replica
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+}
+
+type memoryEntry struct {
+	hits        int
+	windowStart time.Time
+	failures    int
+	lockedUntil time.Time
+	updatedAt   time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]*memoryEntry{}}
+}
+
+func (m *MemoryStore) entry(key string, now time.Time) *memoryEntry {
+	entry, ok := m.entries[key]
+	if !ok {
+		entry = &memoryEntry{windowStart: now, updatedAt: now}
+		m.entries[key] = entry
+	}
+	return entry
+}
+
+func (m *MemoryStore) Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	entry := m.entry(key, now)
+	if !entry.windowStart.After(now.Add(-window)) {
+		entry.hits = 0
+		entry.windowStart = now
+	}
+	entry.hits++
+	entry.updatedAt = now
+	return entry.hits, entry.windowStart.Add(window), nil
+}
+
+func (m *MemoryStore) Fail(ctx context.Context, key string, window time.Duration) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	entry := m.entry(key, now)
+	if !entry.updatedAt.After(now.Add(-window)) {
+		entry.failures = 0
+	}
+	entry.failures++
+	entry.updatedAt = now
+	return entry.failures, nil
+}
+
+func (m *MemoryStore) Lock(ctx context.Context, key string, until time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entry(key, time.Now()).lockedUntil = until
+	return nil
+}
+
+func (m *MemoryStore) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if entry, ok := m.entries[key]; ok {
+		return entry.lockedUntil, nil
+	}
+	return time.Time{}, nil
+}
+
+func (m *MemoryStore) Reset(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if entry, ok := m.entries[key]; ok {
+		entry.failures = 0
+		entry.lockedUntil = time.Time{}
+	}
+	return nil
+}
+
+func (m *MemoryStore) Prune(ctx context.Context, age time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for key, entry := range m.entries {
+		if entry.updatedAt.Before(now.Add(-age)) && entry.lockedUntil.Before(now) {
+			delete(m.entries, key)
+		}
+	}
+	return nil
+}
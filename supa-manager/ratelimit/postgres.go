@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"supamanager.io/supa-manager/database"
+)
+
+// PostgresStore keeps limiter state in the rate_limits table so every replica enforces the same limits
+type PostgresStore struct {
+	queries *database.Queries
+}
+
+func NewPostgresStore(queries *database.Queries) *PostgresStore {
+	return &PostgresStore{queries: queries}
+}
+
+// seconds rounds a window up to whole seconds, the precision the table works with
+func seconds(window time.Duration) int32 {
+	return int32(max((window+time.Second-1)/time.Second, 1))
+}
+
+func (p *PostgresStore) Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	row, err := p.queries.HitRateLimit(ctx, database.HitRateLimitParams{
+		Key:           key,
+		WindowSeconds: seconds(window),
+	})
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return int(row.Hits), row.WindowStart.Time.Add(window), nil
+}
+
+func (p *PostgresStore) Fail(ctx context.Context, key string, window time.Duration) (int, error) {
+	row, err := p.queries.RecordRateLimitFailure(ctx, database.RecordRateLimitFailureParams{
+		Key:           key,
+		WindowSeconds: seconds(window),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(row.Failures), nil
+}
+
+func (p *PostgresStore) Lock(ctx context.Context, key string, until time.Time) error {
+	return p.queries.LockRateLimitKey(ctx, database.LockRateLimitKeyParams{
+		Key:         key,
+		LockedUntil: pgtype.Timestamptz{Time: until, Valid: true},
+	})
+}
+
+func (p *PostgresStore) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	row, err := p.queries.GetRateLimit(ctx, key)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return row.LockedUntil.Time, nil
+}
+
+func (p *PostgresStore) Reset(ctx context.Context, key string) error {
+	return p.queries.ResetRateLimitFailures(ctx, key)
+}
+
+func (p *PostgresStore) Prune(ctx context.Context, age time.Duration) error {
+	return p.queries.PruneRateLimits(ctx, pgtype.Timestamptz{Time: time.Now().Add(-age), Valid: true})
+}
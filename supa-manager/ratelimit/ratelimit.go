@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Store keeps limiter state. MemoryStore serves a single replica, PostgresStore is shared by all of them
+type Store interface {
+	// Hit counts a request for key in a fixed window and returns the requests counted in the
+	// window so far and when it ends
+	Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error)
+
+	// Fail counts a consecutive failure for key and returns the failures so far. Failures are
+	// forgotten once window passed without one
+	Fail(ctx context.Context, key string, window time.Duration) (int, error)
+
+	// Lock refuses key until the given time
+	Lock(ctx context.Context, key string, until time.Time) error
+
+	// LockedUntil returns when the lock on key ends, the zero time when it isn't locked
+	LockedUntil(ctx context.Context, key string) (time.Time, error)
+
+	// Reset forgets the failures and the lock of key
+	Reset(ctx context.Context, key string) error
+
+	// Prune drops the state of keys untouched for longer than age and not locked
+	Prune(ctx context.Context, age time.Duration) error
+}
+
+// Limit allows Requests per Window, a limit of zero requests is disabled
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// Result is the outcome of counting a request against a limit
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// Lockout locks a key out once it failed Threshold times in a row, for Base at first and twice as
+// long with every further failure, up to Max. Failures are forgotten after Window without one
+type Lockout struct {
+	Threshold int
+	Base      time.Duration
+	Max       time.Duration
+	Window    time.Duration
+}
+
+// Duration is how long a key is locked out after its nth consecutive failure
+func (l Lockout) Duration(failures int) time.Duration {
+	if l.Threshold <= 0 || failures < l.Threshold {
+		return 0
+	}
+	duration := l.Base
+	for i := l.Threshold; i < failures && duration < l.Max; i++ {
+		duration *= 2
+	}
+	return min(duration, l.Max)
+}
+
+// Limiter applies limits and lockouts on top of a store
+type Limiter struct {
+	store Store
+	now   func() time.Time
+}
+
+func New(store Store) *Limiter {
+	return &Limiter{store: store, now: time.Now}
+}
+
+// Allow counts a request for key and reports whether it is within the limit
+func (l *Limiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	if limit.Requests <= 0 {
+		return Result{Allowed: true}, nil
+	}
+	hits, reset, err := l.store.Hit(ctx, key, limit.Window)
+	if err != nil {
+		return Result{}, err
+	}
+	result := Result{
+		Allowed:   hits <= limit.Requests,
+		Limit:     limit.Requests,
+		Remaining: max(limit.Requests-hits, 0),
+	}
+	if !result.Allowed {
+		result.RetryAfter = max(reset.Sub(l.now()), time.Second)
+	}
+	return result, nil
+}
+
+// Locked returns how long key stays locked out, zero when it isn't
+func (l *Limiter) Locked(ctx context.Context, key string) (time.Duration, error) {
+	until, err := l.store.LockedUntil(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return max(until.Sub(l.now()), 0), nil
+}
+
+// Fail records a failure for key and locks it out when the lockout says so, returning for how long
+func (l *Limiter) Fail(ctx context.Context, key string, lockout Lockout) (time.Duration, error) {
+	failures, err := l.store.Fail(ctx, key, lockout.Window)
+	if err != nil {
+		return 0, err
+	}
+	duration := lockout.Duration(failures)
+	if duration == 0 {
+		return 0, nil
+	}
+	return duration, l.store.Lock(ctx, key, l.now().Add(duration))
+}
+
+// Succeed clears the failures of key after a successful attempt
+func (l *Limiter) Succeed(ctx context.Context, key string) error {
+	return l.store.Reset(ctx, key)
+}
+
+// Prune drops the state of keys untouched for longer than age
+func (l *Limiter) Prune(ctx context.Context, age time.Duration) error {
+	return l.store.Prune(ctx, age)
+}
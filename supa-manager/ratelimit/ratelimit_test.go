@@ -0,0 +1,206 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	lockout := Lockout{Threshold: 3, Base: 30 * time.Second, Max: 5 * time.Minute, Window: time.Hour}
+
+	tests := []struct {
+		name     string
+		lockout  Lockout
+		failures int
+		want     time.Duration
+	}{
+		{"below threshold", lockout, 2, 0},
+		{"at threshold", lockout, 3, 30 * time.Second},
+		{"doubles after threshold", lockout, 4, time.Minute},
+		{"doubles again", lockout, 5, 2 * time.Minute},
+		{"capped at max", lockout, 7, 5 * time.Minute},
+		{"stays capped", lockout, 1000, 5 * time.Minute},
+		{"disabled without threshold", Lockout{Base: time.Second, Max: time.Minute}, 100, 0},
+		{"base above max", Lockout{Threshold: 1, Base: time.Hour, Max: time.Minute}, 1, time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.lockout.Duration(tt.failures); got != tt.want {
+				t.Errorf("Duration(%d) = %s, want %s", tt.failures, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimiterAllow(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    Limit
+		requests int
+		allowed  int
+	}{
+		{"within limit", Limit{Requests: 5, Window: time.Minute}, 5, 5},
+		{"over limit", Limit{Requests: 3, Window: time.Minute}, 10, 3},
+		{"disabled", Limit{Requests: 0, Window: time.Minute}, 100, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := New(NewMemoryStore())
+			allowed := 0
+			for i := range tt.requests {
+				result, err := limiter.Allow(context.Background(), "key", tt.limit)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if result.Allowed {
+					allowed++
+					continue
+				}
+				if result.Remaining != 0 {
+					t.Errorf("request %d: Remaining = %d on a refused request", i+1, result.Remaining)
+				}
+				if result.RetryAfter < time.Second || result.RetryAfter > tt.limit.Window {
+					t.Errorf("request %d: RetryAfter = %s, want between 1s and the window", i+1, result.RetryAfter)
+				}
+			}
+			if allowed != tt.allowed {
+				t.Errorf("allowed %d of %d requests, want %d", allowed, tt.requests, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestLimiterAllowKeysAreIndependent(t *testing.T) {
+	limiter := New(NewMemoryStore())
+	limit := Limit{Requests: 1, Window: time.Minute}
+	ctx := context.Background()
+
+	if result, _ := limiter.Allow(ctx, "auth:ip:10.0.0.1", limit); !result.Allowed {
+		t.Fatal("first request of a key refused")
+	}
+	if result, _ := limiter.Allow(ctx, "auth:ip:10.0.0.1", limit); result.Allowed {
+		t.Fatal("second request of a key allowed")
+	}
+	if result, _ := limiter.Allow(ctx, "auth:ip:10.0.0.2", limit); !result.Allowed {
+		t.Fatal("another key was limited by the first one")
+	}
+}
+
+func TestLimiterAllowWindowResets(t *testing.T) {
+	limiter := New(NewMemoryStore())
+	limit := Limit{Requests: 1, Window: 50 * time.Millisecond}
+	ctx := context.Background()
+
+	if result, _ := limiter.Allow(ctx, "key", limit); !result.Allowed {
+		t.Fatal("first request refused")
+	}
+	if result, _ := limiter.Allow(ctx, "key", limit); result.Allowed {
+		t.Fatal("request over the limit allowed")
+	}
+	time.Sleep(60 * time.Millisecond)
+	if result, _ := limiter.Allow(ctx, "key", limit); !result.Allowed {
+		t.Fatal("request refused after the window passed")
+	}
+}
+
+func TestLimiterFailLocksOut(t *testing.T) {
+	limiter := New(NewMemoryStore())
+	lockout := Lockout{Threshold: 3, Base: time.Minute, Max: time.Hour, Window: time.Hour}
+	ctx := context.Background()
+
+	for i := 1; i <= 2; i++ {
+		locked, err := limiter.Fail(ctx, "key", lockout)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if locked != 0 {
+			t.Fatalf("locked out after %d failures, threshold is 3", i)
+		}
+	}
+	if remaining, _ := limiter.Locked(ctx, "key"); remaining != 0 {
+		t.Fatalf("Locked = %s before the threshold", remaining)
+	}
+
+	locked, err := limiter.Fail(ctx, "key", lockout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locked != time.Minute {
+		t.Fatalf("locked out for %s at the threshold, want 1m", locked)
+	}
+	if remaining, _ := limiter.Locked(ctx, "key"); remaining <= 0 || remaining > time.Minute {
+		t.Fatalf("Locked = %s, want up to 1m", remaining)
+	}
+	if remaining, _ := limiter.Locked(ctx, "other"); remaining != 0 {
+		t.Fatalf("Locked = %s for a key that never failed", remaining)
+	}
+
+	if locked, _ := limiter.Fail(ctx, "key", lockout); locked != 2*time.Minute {
+		t.Fatalf("locked out for %s after another failure, want 2m", locked)
+	}
+
+	if err := limiter.Succeed(ctx, "key"); err != nil {
+		t.Fatal(err)
+	}
+	if remaining, _ := limiter.Locked(ctx, "key"); remaining != 0 {
+		t.Fatalf("Locked = %s after a successful attempt", remaining)
+	}
+	if locked, _ := limiter.Fail(ctx, "key", lockout); locked != 0 {
+		t.Fatal("failures before a successful attempt still counted")
+	}
+}
+
+func TestLimiterLockExpires(t *testing.T) {
+	limiter := New(NewMemoryStore())
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, err := limiter.Fail(ctx, "key", Lockout{Threshold: 1, Base: time.Minute, Max: time.Minute, Window: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	if remaining, _ := limiter.Locked(ctx, "key"); remaining != time.Minute {
+		t.Fatalf("Locked = %s, want 1m", remaining)
+	}
+	now = now.Add(time.Minute + time.Second)
+	if remaining, _ := limiter.Locked(ctx, "key"); remaining != 0 {
+		t.Fatalf("Locked = %s after the lockout ended", remaining)
+	}
+}
+
+func TestMemoryStoreForgetsFailuresAfterWindow(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	window := 50 * time.Millisecond
+
+	for want := 1; want <= 2; want++ {
+		if failures, _ := store.Fail(ctx, "key", window); failures != want {
+			t.Fatalf("Fail = %d, want %d", failures, want)
+		}
+	}
+	time.Sleep(60 * time.Millisecond)
+	if failures, _ := store.Fail(ctx, "key", window); failures != 1 {
+		t.Fatalf("Fail = %d after the window passed, want 1", failures)
+	}
+}
+
+func TestMemoryStorePruneKeepsLockedKeys(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	store.Hit(ctx, "idle", time.Minute)
+	store.Fail(ctx, "locked", time.Minute)
+	store.Lock(ctx, "locked", time.Now().Add(time.Hour))
+	time.Sleep(10 * time.Millisecond)
+
+	if err := store.Prune(ctx, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.entries["idle"]; ok {
+		t.Error("idle key not pruned")
+	}
+	if until, _ := store.LockedUntil(ctx, "locked"); until.IsZero() {
+		t.Error("locked key pruned while still locked")
+	}
+}
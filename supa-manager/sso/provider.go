@@ -0,0 +1,315 @@
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidIDToken is returned when the ID token fails verification
+var ErrInvalidIDToken = errors.New("invalid id token")
+
+// discoveryTtl bounds how long the discovery document is cached
+const discoveryTtl = time.Hour
+
+// Config identifies the OIDC client registered at the identity provider
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+}
+
+// Discovery is the subset of the OpenID provider metadata the login flow needs
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+// Tokens is the token endpoint response of an authorization code exchange
+type Tokens struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+}
+
+// Claims are the verified claims of an ID token, merged with the userinfo response
+type Claims map[string]interface{}
+
+// Provider runs the authorization code flow with PKCE against an OpenID Connect provider
+type Provider struct {
+	config Config
+	client *http.Client
+
+	mu           sync.Mutex
+	discovery    *Discovery
+	discoveredAt time.Time
+	keys         *keySet
+}
+
+// NewProvider creates a provider, metadata is discovered lazily on first use
+func NewProvider(config Config) *Provider {
+	return &Provider{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// NewPKCE returns a code verifier and its S256 challenge
+func NewPKCE() (verifier string, challenge string, err error) {
+	verifier, err = randomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// NewState returns a random value for the state and nonce parameters
+func NewState() (string, error) {
+	return randomString(32)
+}
+
+func randomString(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Discover returns the provider metadata, cached for an hour
+func (p *Provider) Discover(ctx context.Context) (*Discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil && time.Since(p.discoveredAt) < discoveryTtl {
+		return p.discovery, nil
+	}
+
+	var discovery Discovery
+	wellKnown := strings.TrimRight(p.config.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, wellKnown, "", &discovery); err != nil {
+		return nil, fmt.Errorf("oidc discovery failed: %w", err)
+	}
+	if strings.TrimRight(discovery.Issuer, "/") != strings.TrimRight(p.config.Issuer, "/") {
+		return nil, fmt.Errorf("oidc discovery returned issuer %q, expected %q", discovery.Issuer, p.config.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JwksURI == "" {
+		return nil, errors.New("oidc discovery document is missing endpoints")
+	}
+
+	p.discovery = &discovery
+	p.discoveredAt = time.Now()
+	if p.keys == nil || p.keys.uri != discovery.JwksURI {
+		p.keys = newKeySet(discovery.JwksURI, p.client)
+	}
+	return p.discovery, nil
+}
+
+// AuthCodeURL returns the URL the browser is sent to for logging in at the provider
+func (p *Provider) AuthCodeURL(ctx context.Context, redirectURI string, state string, nonce string, codeChallenge string) (string, error) {
+	discovery, err := p.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("scope", strings.Join(p.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange trades an authorization code for tokens
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string, redirectURI string) (*Tokens, error) {
+	discovery, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("client_id", p.config.ClientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token exchange failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint returned status %d: %s", resp.StatusCode, body)
+	}
+
+	var tokens Tokens
+	if err := json.Unmarshal(body, &tokens); err != nil {
+		return nil, fmt.Errorf("invalid token response: %w", err)
+	}
+	if tokens.IDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+	return &tokens, nil
+}
+
+// VerifyIDToken checks the signature, issuer, audience, expiry and nonce of an ID token
+func (p *Provider) VerifyIDToken(ctx context.Context, raw string, nonce string) (Claims, error) {
+	discovery, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	keys := p.keys
+	p.mu.Unlock()
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return keys.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if claimNonce, _ := claims["nonce"].(string); claimNonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return nil, fmt.Errorf("%w: missing sub", ErrInvalidIDToken)
+	}
+	return Claims(claims), nil
+}
+
+// UserInfo merges the userinfo response into claims, the ID token wins on conflicts.
+// Providers often leave groups or profile claims out of the ID token
+func (p *Provider) UserInfo(ctx context.Context, accessToken string, claims Claims) (Claims, error) {
+	discovery, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if discovery.UserinfoEndpoint == "" || accessToken == "" {
+		return claims, nil
+	}
+
+	var info map[string]interface{}
+	if err := p.getJSON(ctx, discovery.UserinfoEndpoint, accessToken, &info); err != nil {
+		return nil, fmt.Errorf("userinfo request failed: %w", err)
+	}
+	// The userinfo subject must match the ID token, OIDC Core 5.3.2
+	if sub, _ := info["sub"].(string); sub != claims["sub"] {
+		return nil, errors.New("userinfo subject does not match the id token")
+	}
+
+	merged := Claims{}
+	for k, v := range info {
+		merged[k] = v
+	}
+	for k, v := range claims {
+		merged[k] = v
+	}
+	return merged, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, target string, bearer string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", target, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
+
+// String returns a string claim, empty when missing
+func (c Claims) String(name string) string {
+	value, _ := c[name].(string)
+	return value
+}
+
+// Strings returns a claim holding a list of strings, a single string is treated as a one element list
+func (c Claims) Strings(name string) []string {
+	switch value := c[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		result := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// EmailVerified reports whether the provider vouches for the email claim
+func (c Claims) EmailVerified() bool {
+	switch value := c["email_verified"].(type) {
+	case bool:
+		return value
+	case string:
+		return value == "true"
+	default:
+		return false
+	}
+}
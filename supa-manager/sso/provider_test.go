@@ -0,0 +1,390 @@
+package sso
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var testRSAKey = sync.OnceValue(func() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return key
+})
+
+// fakeIssuer is an OpenID provider serving discovery, its keys, a token endpoint and userinfo
+type fakeIssuer struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	ecKey    *ecdsa.PrivateKey
+	issuer   string // the issuer announced by discovery, the server URL unless changed
+	idToken  string
+	userinfo map[string]interface{}
+
+	mu         sync.Mutex
+	jwksHits   int
+	tokenForm  url.Values
+	tokenAuth  [2]string
+	infoBearer string
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	t.Helper()
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeIssuer{key: testRSAKey(), ecKey: ecKey}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Discovery{
+			Issuer:                f.issuer,
+			AuthorizationEndpoint: f.server.URL + "/authorize?tenant=1",
+			TokenEndpoint:         f.server.URL + "/token",
+			UserinfoEndpoint:      f.server.URL + "/userinfo",
+			JwksURI:               f.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.jwksHits++
+		f.mu.Unlock()
+		encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "use": "sig", "n": encode(f.key.N.Bytes()), "e": encode(big.NewInt(int64(f.key.E)).Bytes())},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": encode(f.ecKey.X.Bytes()), "y": encode(f.ecKey.Y.Bytes())},
+			{"kty": "RSA", "kid": "enc", "use": "enc", "n": encode(f.key.N.Bytes()), "e": encode(big.NewInt(int64(f.key.E)).Bytes())},
+			{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": "AAAA"},
+		}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		user, pass, _ := r.BasicAuth()
+		f.mu.Lock()
+		f.tokenForm = r.PostForm
+		f.tokenAuth = [2]string{user, pass}
+		f.mu.Unlock()
+		json.NewEncoder(w).Encode(Tokens{AccessToken: "access", TokenType: "Bearer", IDToken: f.idToken})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.infoBearer = r.Header.Get("Authorization")
+		f.mu.Unlock()
+		json.NewEncoder(w).Encode(f.userinfo)
+	})
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	f.issuer = f.server.URL
+	return f
+}
+
+func (f *fakeIssuer) provider() *Provider {
+	return NewProvider(Config{Issuer: f.server.URL, ClientID: "supa-manager", ClientSecret: "s&cret", Scopes: []string{"openid", "email"}})
+}
+
+func (f *fakeIssuer) claims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":   f.server.URL,
+		"aud":   "supa-manager",
+		"sub":   "user-1",
+		"nonce": "nonce",
+		"email": "user@example.com",
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}
+}
+
+func (f *fakeIssuer) sign(t *testing.T, method jwt.SigningMethod, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	var key interface{} = f.key
+	if _, ok := method.(*jwt.SigningMethodECDSA); ok {
+		key = f.ecKey
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	f := newFakeIssuer(t)
+	with := func(name string, value interface{}) jwt.MapClaims {
+		claims := f.claims()
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+	hmacWithPublicKey := func(t *testing.T) string {
+		// the RSA public key used as an HMAC secret, the classic algorithm confusion
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, f.claims())
+		token.Header["kid"] = "rsa"
+		signed, err := token.SignedString(f.key.PublicKey.N.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name    string
+		token   func(t *testing.T) string
+		wantErr bool
+	}{
+		{"valid RS256", func(t *testing.T) string { return f.sign(t, jwt.SigningMethodRS256, "rsa", f.claims()) }, false},
+		{"valid ES256", func(t *testing.T) string { return f.sign(t, jwt.SigningMethodES256, "ec", f.claims()) }, false},
+		{"audience list", func(t *testing.T) string {
+			return f.sign(t, jwt.SigningMethodRS256, "rsa", with("aud", []string{"other", "supa-manager"}))
+		}, false},
+		{"other audience", func(t *testing.T) string { return f.sign(t, jwt.SigningMethodRS256, "rsa", with("aud", "other")) }, true},
+		{"other issuer", func(t *testing.T) string {
+			return f.sign(t, jwt.SigningMethodRS256, "rsa", with("iss", "https://evil.example.com"))
+		}, true},
+		{"expired", func(t *testing.T) string {
+			return f.sign(t, jwt.SigningMethodRS256, "rsa", with("exp", time.Now().Add(-2*time.Minute).Unix()))
+		}, true},
+		{"expired within leeway", func(t *testing.T) string {
+			return f.sign(t, jwt.SigningMethodRS256, "rsa", with("exp", time.Now().Add(-30*time.Second).Unix()))
+		}, false},
+		{"without expiry", func(t *testing.T) string { return f.sign(t, jwt.SigningMethodRS256, "rsa", with("exp", nil)) }, true},
+		{"issued in the future", func(t *testing.T) string {
+			return f.sign(t, jwt.SigningMethodRS256, "rsa", with("iat", time.Now().Add(time.Hour).Unix()))
+		}, true},
+		{"other nonce", func(t *testing.T) string { return f.sign(t, jwt.SigningMethodRS256, "rsa", with("nonce", "replayed")) }, true},
+		{"without nonce", func(t *testing.T) string { return f.sign(t, jwt.SigningMethodRS256, "rsa", with("nonce", nil)) }, true},
+		{"without subject", func(t *testing.T) string { return f.sign(t, jwt.SigningMethodRS256, "rsa", with("sub", nil)) }, true},
+		{"unknown key", func(t *testing.T) string { return f.sign(t, jwt.SigningMethodRS256, "other", f.claims()) }, true},
+		{"encryption key", func(t *testing.T) string { return f.sign(t, jwt.SigningMethodRS256, "enc", f.claims()) }, true},
+		{"key of the wrong type", func(t *testing.T) string { return f.sign(t, jwt.SigningMethodRS256, "ec", f.claims()) }, true},
+		{"HMAC with the public key", hmacWithPublicKey, true},
+		{"unsigned", func(t *testing.T) string {
+			signed, _ := jwt.NewWithClaims(jwt.SigningMethodNone, f.claims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+			return signed
+		}, true},
+		{"tampered", func(t *testing.T) string {
+			parts := strings.Split(f.sign(t, jwt.SigningMethodRS256, "rsa", f.claims()), ".")
+			payload, _ := json.Marshal(with("sub", "admin"))
+			return parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload) + "." + parts[2]
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := f.provider().VerifyIDToken(context.Background(), tt.token(t), "nonce")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyIDToken() error = %v, want error: %t", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidIDToken) {
+				t.Errorf("error %v isn't ErrInvalidIDToken", err)
+			}
+			if err == nil && claims.String("sub") != "user-1" {
+				t.Errorf("sub = %q", claims.String("sub"))
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenRefetchesKeysAtMostOncePerInterval(t *testing.T) {
+	f := newFakeIssuer(t)
+	p := f.provider()
+	ctx := context.Background()
+
+	if _, err := p.VerifyIDToken(ctx, f.sign(t, jwt.SigningMethodRS256, "rsa", f.claims()), "nonce"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := p.VerifyIDToken(ctx, f.sign(t, jwt.SigningMethodRS256, "unknown", f.claims()), "nonce"); err == nil {
+			t.Fatal("token signed with an unknown key accepted")
+		}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.jwksHits != 1 {
+		t.Errorf("fetched the keys %d times, unknown key ids must not hammer the provider", f.jwksHits)
+	}
+}
+
+func TestDiscoverRejectsOtherIssuer(t *testing.T) {
+	f := newFakeIssuer(t)
+	f.issuer = "https://evil.example.com"
+
+	if _, err := f.provider().Discover(context.Background()); err == nil {
+		t.Fatal("discovery announcing another issuer accepted")
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	f := newFakeIssuer(t)
+	verifier, challenge, err := NewPKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	if challenge != base64.RawURLEncoding.EncodeToString(sum[:]) {
+		t.Fatal("challenge isn't the S256 of the verifier")
+	}
+
+	raw, err := f.provider().AuthCodeURL(context.Background(), "http://localhost:8080/auth/sso/callback", "state", "nonce", challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	want := map[string]string{
+		"tenant":                "1",
+		"response_type":         "code",
+		"client_id":             "supa-manager",
+		"redirect_uri":          "http://localhost:8080/auth/sso/callback",
+		"scope":                 "openid email",
+		"state":                 "state",
+		"nonce":                 "nonce",
+		"code_challenge":        challenge,
+		"code_challenge_method": "S256",
+	}
+	for name, value := range want {
+		if got := query.Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestExchange(t *testing.T) {
+	f := newFakeIssuer(t)
+	f.idToken = "id-token"
+
+	tokens, err := f.provider().Exchange(context.Background(), "code", "verifier", "http://localhost:8080/auth/sso/callback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens.IDToken != "id-token" || tokens.AccessToken != "access" {
+		t.Errorf("tokens %+v", tokens)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for name, value := range map[string]string{"grant_type": "authorization_code", "code": "code", "code_verifier": "verifier"} {
+		if got := f.tokenForm.Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+	// client_secret_basic form-encodes the credentials, RFC 6749 2.3.1
+	if f.tokenAuth != [2]string{"supa-manager", "s%26cret"} {
+		t.Errorf("basic auth %q", f.tokenAuth)
+	}
+}
+
+func TestExchangeWithoutIDToken(t *testing.T) {
+	f := newFakeIssuer(t)
+	if _, err := f.provider().Exchange(context.Background(), "code", "verifier", "http://localhost"); err == nil {
+		t.Fatal("token response without an id_token accepted")
+	}
+}
+
+func TestUserInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		userinfo map[string]interface{}
+		wantErr  bool
+		want     map[string]interface{}
+	}{
+		{
+			name:     "merges claims",
+			userinfo: map[string]interface{}{"sub": "user-1", "groups": []interface{}{"admins"}, "email": "other@example.com"},
+			// the ID token wins on conflicts
+			want: map[string]interface{}{"email": "user@example.com", "groups": []interface{}{"admins"}},
+		},
+		{name: "other subject", userinfo: map[string]interface{}{"sub": "user-2", "email": "admin@example.com"}, wantErr: true},
+		{name: "without subject", userinfo: map[string]interface{}{"email": "admin@example.com"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeIssuer(t)
+			f.userinfo = tt.userinfo
+
+			merged, err := f.provider().UserInfo(context.Background(), "access", Claims{"sub": "user-1", "email": "user@example.com"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UserInfo() error = %v, want error: %t", err, tt.wantErr)
+			}
+			for name, value := range tt.want {
+				got, _ := json.Marshal(merged[name])
+				want, _ := json.Marshal(value)
+				if string(got) != string(want) {
+					t.Errorf("%s = %s, want %s", name, got, want)
+				}
+			}
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			if f.infoBearer != "Bearer access" {
+				t.Errorf("Authorization = %q", f.infoBearer)
+			}
+		})
+	}
+}
+
+func TestClaims(t *testing.T) {
+	claims := Claims{
+		"email":          "user@example.com",
+		"groups":         []interface{}{"admins", 1, "developers"},
+		"role":           "admin",
+		"email_verified": "true",
+	}
+	if got := claims.String("email"); got != "user@example.com" {
+		t.Errorf("String(email) = %q", got)
+	}
+	if got := claims.String("groups"); got != "" {
+		t.Errorf("String(groups) = %q, want empty for a non string", got)
+	}
+	if got := strings.Join(claims.Strings("groups"), ","); got != "admins,developers" {
+		t.Errorf("Strings(groups) = %q", got)
+	}
+	if got := strings.Join(claims.Strings("role"), ","); got != "admin" {
+		t.Errorf("Strings(role) = %q", got)
+	}
+
+	tests := []struct {
+		value interface{}
+		want  bool
+	}{
+		{true, true},
+		{false, false},
+		{"true", true},
+		{"false", false},
+		{"yes", false},
+		{nil, false},
+		{float64(1), false},
+	}
+	for _, tt := range tests {
+		if got := (Claims{"email_verified": tt.value}).EmailVerified(); got != tt.want {
+			t.Errorf("EmailVerified() with %#v = %t, want %t", tt.value, got, tt.want)
+		}
+	}
+}
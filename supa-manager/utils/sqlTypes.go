@@ -2,6 +2,7 @@ package utils
 
 import (
 	"github.com/jackc/pgx/v5/pgtype"
+	"time"
 )
 
 func PgTextToPointer(ns pgtype.Text) *string {
@@ -11,3 +12,27 @@ func PgTextToPointer(ns pgtype.Text) *string {
 
 	return nil
 }
+
+func PgTimestamptzToPointer(ts pgtype.Timestamptz) *time.Time {
+	if ts.Valid {
+		return &ts.Time
+	}
+
+	return nil
+}
+
+func PgInt4ToPointer(ni pgtype.Int4) *int32 {
+	if ni.Valid {
+		return &ni.Int32
+	}
+
+	return nil
+}
+
+func PgInt8ToPointer(ni pgtype.Int8) *int64 {
+	if ni.Valid {
+		return &ni.Int64
+	}
+
+	return nil
+}
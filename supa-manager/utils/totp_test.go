@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 seed of the RFC 6238 test vectors, "12345678901234567890" in base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 appendix B, the codes are the last six of its eight digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+
+	// authenticator apps may show the secret in lower case
+	if got, err := TOTPCode(strings.ToLower(rfc6238Secret), 1); err != nil || got != "287082" {
+		t.Errorf("TOTPCode of a lower case secret = %s, %v", got, err)
+	}
+	if _, err := TOTPCode("not base32!", 1); err == nil {
+		t.Error("TOTPCode of an invalid secret didn't fail")
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := TOTPStep(now)
+	code := func(step int64) string {
+		code, err := TOTPCode(rfc6238Secret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		wantStep int64
+		wantOk   bool
+	}{
+		{"current step", code(step), step, true},
+		{"with white space", " " + code(step) + "\n", step, true},
+		{"previous step", code(step - 1), step - 1, true},
+		{"next step", code(step + 1), step + 1, true},
+		{"two steps ago", code(step - 2), 0, false},
+		{"two steps ahead", code(step + 2), 0, false},
+		{"wrong code", "000000", 0, false},
+		{"too short", code(step)[:5], 0, false},
+		{"too long", code(step) + "0", 0, false},
+		{"empty", "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := ValidateTOTP(rfc6238Secret, tt.code, now)
+			if ok != tt.wantOk || gotStep != tt.wantStep {
+				t.Errorf("ValidateTOTP(%q) = %d, %t, want %d, %t", tt.code, gotStep, ok, tt.wantStep, tt.wantOk)
+			}
+		})
+	}
+
+	// A code is reported with its own step wherever it's checked in the window, so a replay
+	// later in the window is recognised by it
+	first, _ := ValidateTOTP(rfc6238Secret, code(step), now)
+	replayed, ok := ValidateTOTP(rfc6238Secret, code(step), now.Add(TOTPPeriod*time.Second))
+	if !ok || replayed != first {
+		t.Errorf("replayed code matched step %d, first matched %d", replayed, first)
+	}
+}
+
+func TestNewTOTPSecret(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 160 bits are 32 base32 characters
+	if len(secret) != 32 {
+		t.Errorf("secret %q has %d characters", secret, len(secret))
+	}
+	if _, err := TOTPCode(secret, 1); err != nil {
+		t.Errorf("TOTPCode of a new secret: %v", err)
+	}
+	other, _ := NewTOTPSecret()
+	if other == secret {
+		t.Error("two secrets are equal")
+	}
+}